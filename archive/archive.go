@@ -0,0 +1,156 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package archive exports fired schedules along with their final status to a blob store,
+// where they are kept after their rows expire, and reads them back.
+//
+// Every archive object holds the schedules of a partition of an app whose schedule time group falls in a window,
+// as gzip compressed JSON lines. The objects of an app are laid out by the day of the window start:
+//
+//	schedules/<app>/<yyyy-mm-dd>/<window start>-<window end>-<partition>.jsonl.gz
+//
+// Window bounds are unix seconds zero padded to ten digits, so the keys sort by time.
+// Windows never span two days, in UTC.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/myntra/goscheduler/store"
+)
+
+const (
+	schedulesPrefix   = "schedules/"
+	checkpointsPrefix = "checkpoints/"
+	objectExtension   = ".jsonl.gz"
+	dayLayout         = "2006-01-02"
+)
+
+// ErrNotFound is returned when no archived run of a schedule is found.
+var ErrNotFound = errors.New("archived schedule not found")
+
+// Record is an archived run of a schedule, along with its final status.
+type Record struct {
+	Schedule         store.Schedule `json:"schedule"`
+	ParentScheduleId string         `json:"parentScheduleId,omitempty"`
+	ArchivedAt       int64          `json:"archivedAt"`
+}
+
+// object is the key of an archive object broken down into its parts.
+type object struct {
+	key         string
+	start       time.Time
+	end         time.Time
+	partitionId int
+}
+
+// Get the prefix of the keys of the archive objects of an app.
+func appPrefix(appId string) string {
+	return schedulesPrefix + url.PathEscape(appId) + "/"
+}
+
+// Get the prefix of the keys of the archive objects of an app whose window starts on the day of t.
+func dayPrefix(appId string, t time.Time) string {
+	return appPrefix(appId) + t.UTC().Format(dayLayout) + "/"
+}
+
+// Get the key of the archive object of an app partition for the window [start, end).
+func objectKey(appId string, partitionId int, start, end time.Time) string {
+	return fmt.Sprintf("%s%010d-%010d-%d%s", dayPrefix(appId, start), start.Unix(), end.Unix(), partitionId, objectExtension)
+}
+
+// Get the key of the checkpoint holding the end of the last window archived for an app partition.
+func checkpointKey(appId string, partitionId int) string {
+	return fmt.Sprintf("%s%s/%d", checkpointsPrefix, url.PathEscape(appId), partitionId)
+}
+
+// Break the key of an archive object down into its window and partition.
+func parseObjectKey(key string) (object, error) {
+	name := key[strings.LastIndex(key, "/")+1:]
+	parts := strings.Split(strings.TrimSuffix(name, objectExtension), "-")
+	if !strings.HasSuffix(name, objectExtension) || len(parts) != 3 {
+		return object{}, errors.New(fmt.Sprintf("invalid archive object key %s", key))
+	}
+
+	var values [3]int64
+	for i, part := range parts {
+		value, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return object{}, errors.New(fmt.Sprintf("invalid archive object key %s", key))
+		}
+		values[i] = value
+	}
+
+	return object{
+		key:         key,
+		start:       time.Unix(values[0], 0),
+		end:         time.Unix(values[1], 0),
+		partitionId: int(values[2]),
+	}, nil
+}
+
+// Encode records as gzip compressed JSON lines.
+func encode(records []Record) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	encoder := json.NewEncoder(writer)
+
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decode gzip compressed JSON lines into records.
+func decode(data []byte) ([]Record, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var record Record
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/blob"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/dao"
+	"github.com/myntra/goscheduler/payload"
+	"github.com/myntra/goscheduler/store"
+	"github.com/myntra/goscheduler/util"
+)
+
+const (
+	defaultInterval = 300
+	defaultDelay    = 600
+	defaultWindow   = 60
+	// Windows of a partition archived in a run at most, so a backlog is caught up over several runs
+	maxWindowsPerRun = 24
+	secondsPerDay    = 24 * 60 * 60
+)
+
+// Archiver periodically exports the fired schedules of the partitions owned by the node to the blob store.
+// The end of the last window archived for every partition is kept in the blob store as a checkpoint,
+// so the node a partition moves to resumes where the previous owner stopped.
+type Archiver struct {
+	config      conf.ArchiveConfig
+	appConfig   conf.AppLevelConfiguration
+	cronApp     string
+	address     string
+	clusterDao  dao.ClusterDao
+	scheduleDao dao.ScheduleDao
+	store       blob.Store
+	payloads    *payload.Store
+	now         func() time.Time
+}
+
+// NewArchiver creates an archiver of the partitions assigned to the node of the configuration.
+// Offloaded payloads are read from payloads, which is nil if offloading is disabled.
+func NewArchiver(config *conf.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, store blob.Store, payloads *payload.Store) *Archiver {
+	archiveConfig := config.Archive
+	if archiveConfig.Interval <= 0 {
+		archiveConfig.Interval = defaultInterval
+	}
+	if archiveConfig.Delay <= 0 {
+		archiveConfig.Delay = defaultDelay
+	}
+	if archiveConfig.Window <= 0 {
+		archiveConfig.Window = defaultWindow
+	}
+
+	return &Archiver{
+		config:      archiveConfig,
+		appConfig:   config.AppLevelConfiguration,
+		cronApp:     config.CronConfig.App,
+		address:     config.Cluster.Address,
+		clusterDao:  clusterDao,
+		scheduleDao: scheduleDao,
+		store:       store,
+		payloads:    payloads,
+		now:         time.Now,
+	}
+}
+
+// Start archiving every Interval seconds in the background.
+func (a *Archiver) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Duration(a.config.Interval) * time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := a.Run(); err != nil {
+				glog.Errorf("Archiving fired schedules failed with error %s", err.Error())
+			}
+		}
+	}()
+}
+
+// Run archives the windows of every partition owned by the node which ended at least Delay seconds ago.
+// The schedules of the cron app are not archived.
+func (a *Archiver) Run() error {
+	var errs []string
+
+	for _, entity := range a.clusterDao.GetAllEntitiesInfoOfNode(a.address) {
+		appId := entity.GetAppName()
+		if appId == a.cronApp {
+			continue
+		}
+
+		if err := a.archivePartition(appId, entity.GetPartitionId()); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", entity.Id, err.Error()))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Archive the pending windows of a partition, up to maxWindowsPerRun of them.
+// The checkpoint is moved past every window written, including windows without any schedule.
+func (a *Archiver) archivePartition(appId string, partitionId int) error {
+	app, err := a.clusterDao.GetApp(appId)
+	if err != nil {
+		return err
+	}
+
+	start, err := a.getCheckpoint(app, partitionId)
+	if err != nil {
+		return err
+	}
+
+	end := a.now().Add(-time.Duration(a.config.Delay) * time.Second).Truncate(time.Minute)
+	for windows := 0; start.Before(end) && windows < maxWindowsPerRun; windows++ {
+		windowEnd := a.windowEnd(start, end)
+
+		records, err := a.collect(app, partitionId, start, windowEnd)
+		if err != nil {
+			return err
+		}
+
+		if len(records) > 0 {
+			data, err := encode(records)
+			if err != nil {
+				return err
+			}
+			if err = a.store.Put(objectKey(appId, partitionId, start, windowEnd), data); err != nil {
+				return err
+			}
+			glog.Infof("Archived %d schedules of %s.%d from %s to %s", len(records), appId, partitionId, start, windowEnd)
+		}
+
+		if err = a.store.Put(checkpointKey(appId, partitionId), []byte(strconv.FormatInt(windowEnd.Unix(), 10))); err != nil {
+			return err
+		}
+		start = windowEnd
+	}
+
+	return nil
+}
+
+// Get the start of the next window to archive for a partition.
+// Partitions without a checkpoint, or with one older than the retention period of the app,
+// start from the oldest schedule time group whose schedules have not expired yet.
+func (a *Archiver) getCheckpoint(app store.App, partitionId int) (time.Time, error) {
+	oldest := a.now().Add(-time.Duration(app.GetBufferTTL(a.appConfig.FiredScheduleRetentionPeriod)) * time.Second).Truncate(time.Minute)
+
+	data, err := a.store.Get(checkpointKey(app.AppId, partitionId))
+	switch {
+	case err == blob.ErrNotFound:
+		return oldest, nil
+	case err != nil:
+		return time.Time{}, err
+	}
+
+	seconds, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("invalid archive checkpoint %q of %s.%d", string(data), app.AppId, partitionId))
+	}
+
+	if checkpoint := time.Unix(seconds, 0); checkpoint.After(oldest) {
+		return checkpoint, nil
+	}
+	return oldest, nil
+}
+
+// Get the end of the window starting at start, which is Window minutes later
+// unless the day in UTC or the archivable time groups end before.
+func (a *Archiver) windowEnd(start, end time.Time) time.Time {
+	windowEnd := start.Add(time.Duration(a.config.Window) * time.Minute)
+
+	if midnight := time.Unix(start.Unix()-start.Unix()%secondsPerDay+secondsPerDay, 0); midnight.Before(windowEnd) {
+		windowEnd = midnight
+	}
+	if end.Before(windowEnd) {
+		windowEnd = end
+	}
+	return windowEnd
+}
+
+// Get the records of the schedules of a partition, enriched with their status, whose schedule time group is in [start, end).
+// The records hold the offloaded payloads themselves rather than references to them, and are ordered by schedule time.
+func (a *Archiver) collect(app store.App, partitionId int, start, end time.Time) ([]Record, error) {
+	var records []Record
+	archivedAt := a.now().Unix()
+
+	for bucket := start; bucket.Before(end); bucket = bucket.Add(time.Minute) {
+		pageState := []byte(nil)
+
+		for {
+			var page []store.Schedule
+			_map := make(map[string]interface{})
+			iter := a.scheduleDao.GetSchedulesForEntity(app.AppId, partitionId, bucket, pageState)
+
+			for iter.MapScan(_map) {
+				var schedule store.Schedule
+				if err := schedule.CreateScheduleFromCassandraMap(_map); err != nil {
+					_ = iter.Close()
+					return nil, err
+				}
+				page = append(page, schedule)
+				_map = make(map[string]interface{})
+			}
+
+			pageState = iter.PageState()
+			if err := iter.Close(); err != nil {
+				return nil, err
+			}
+
+			enriched, err := a.scheduleDao.OptimizedEnrichSchedule(page)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, schedule := range enriched {
+				// Offloaded payloads are archived inline, as they are deleted once the schedule expires
+				resolved, err := a.payloads.Resolve(schedule.Payload)
+				switch {
+				case errors.Is(err, blob.ErrNotFound):
+					glog.Errorf("Payload of schedule %s is missing, archiving its reference: %s", schedule.ScheduleId, err.Error())
+				case err != nil:
+					return nil, err
+				default:
+					schedule.Payload = resolved
+				}
+
+				record := Record{Schedule: schedule, ArchivedAt: archivedAt}
+				if !util.IsZeroUUID(schedule.ParentScheduleId) {
+					record.ParentScheduleId = schedule.ParentScheduleId.String()
+				}
+				records = append(records, record)
+			}
+
+			if len(pageState) == 0 {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Schedule.ScheduleTime < records[j].Schedule.ScheduleTime
+	})
+	return records, nil
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package archive
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/blob"
+	e "github.com/myntra/goscheduler/cluster_entity"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/dao"
+	"github.com/myntra/goscheduler/payload"
+	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixture struct {
+	archiver    *Archiver
+	reader      *Reader
+	scheduleDao dao.ScheduleDao
+	store       blob.Store
+	payloads    *payload.Store
+	app         store.App
+	now         time.Time
+}
+
+func setup(t *testing.T) fixture {
+	if _, ok := store.Registry[constants.DefaultCallback]; !ok {
+		store.Registry[constants.DefaultCallback] = func() store.Callback { return &store.HttpCallback{} }
+	}
+
+	config := conf.NewConfig(conf.WithArchive(t.TempDir()))
+	clusterDao := dao.NewClusterDaoImplInMemory(config)
+	scheduleDao := dao.NewScheduleDaoImplInMemory(config)
+
+	blobs, err := blob.NewFileStore(config.Archive.Path)
+	require.NoError(t, err)
+
+	app := store.App{AppId: "archive", Partitions: 2, Active: true}
+	require.NoError(t, clusterDao.InsertApp(app))
+	for partition := 0; partition < 2; partition++ {
+		require.NoError(t, clusterDao.CreateEntity(e.EntityInfo{Id: app.AppId + "." + strconv.Itoa(partition)}))
+	}
+
+	payloadBlobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	payloads := payload.NewStore(payloadBlobs, 16)
+
+	now := time.Now()
+	archiver := NewArchiver(config, clusterDao, scheduleDao, blobs, payloads)
+	archiver.now = func() time.Time { return now }
+
+	return fixture{
+		archiver:    archiver,
+		reader:      NewReader(blobs),
+		scheduleDao: scheduleDao,
+		store:       blobs,
+		payloads:    payloads,
+		app:         app,
+		now:         now,
+	}
+}
+
+func (f fixture) createSchedule(t *testing.T, partitionId int, scheduleTime time.Time) store.Schedule {
+	schedule := store.Schedule{
+		ScheduleId:    gocql.TimeUUID(),
+		AppId:         f.app.AppId,
+		PartitionId:   partitionId,
+		Payload:       "{}",
+		ScheduleTime:  scheduleTime.Unix(),
+		ScheduleGroup: scheduleTime.Truncate(time.Minute).Unix(),
+		Callback:      &store.HttpCallback{Type: constants.DefaultCallback, Details: store.Details{Url: "http://example.com/callback", Method: "POST"}},
+	}
+
+	created, err := f.scheduleDao.CreateSchedule(schedule, f.app)
+	require.NoError(t, err)
+	return created
+}
+
+func TestArchiver_Run(t *testing.T) {
+	f := setup(t)
+
+	start := f.now.Add(-2 * time.Hour).Truncate(time.Minute)
+	for partition := 0; partition < 2; partition++ {
+		require.NoError(t, f.store.Put(checkpointKey(f.app.AppId, partition), []byte(strconv.FormatInt(start.Unix(), 10))))
+	}
+
+	succeeded := f.createSchedule(t, 0, f.now.Add(-90*time.Minute))
+	missed := f.createSchedule(t, 0, f.now.Add(-30*time.Minute))
+	other := f.createSchedule(t, 1, f.now.Add(-60*time.Minute))
+	recent := f.createSchedule(t, 0, f.now.Add(-5*time.Minute))
+
+	succeeded.Status = store.Success
+	require.NoError(t, f.scheduleDao.UpdateStatus([]store.Schedule{succeeded}, f.app))
+
+	require.NoError(t, f.archiver.Run())
+
+	record, err := f.reader.Get(f.app.AppId, succeeded.ScheduleId)
+	require.NoError(t, err)
+	assert.Equal(t, store.Success, record.Schedule.Status)
+	assert.Equal(t, "{}", record.Schedule.Payload)
+	assert.Equal(t, f.now.Unix(), record.ArchivedAt)
+
+	record, err = f.reader.Get(f.app.AppId, missed.ScheduleId)
+	require.NoError(t, err)
+	assert.Equal(t, store.Miss, record.Schedule.Status)
+
+	_, err = f.reader.Get(f.app.AppId, other.ScheduleId)
+	assert.NoError(t, err)
+
+	// Schedules fired less than Delay seconds ago are left for a later run
+	_, err = f.reader.Get(f.app.AppId, recent.ScheduleId)
+	assert.Equal(t, ErrNotFound, err)
+
+	checkpoint, err := f.archiver.getCheckpoint(f.app, 0)
+	require.NoError(t, err)
+	assert.Equal(t, f.now.Add(-time.Duration(f.archiver.config.Delay)*time.Second).Truncate(time.Minute), checkpoint)
+
+	// Runs pick up from the checkpoint
+	f.archiver.now = func() time.Time { return f.now.Add(10 * time.Minute) }
+	require.NoError(t, f.archiver.Run())
+
+	_, err = f.reader.Get(f.app.AppId, recent.ScheduleId)
+	assert.NoError(t, err)
+}
+
+func TestArchiver_OffloadedPayload(t *testing.T) {
+	f := setup(t)
+
+	start := f.now.Add(-2 * time.Hour).Truncate(time.Minute)
+	require.NoError(t, f.store.Put(checkpointKey(f.app.AppId, 0), []byte(strconv.FormatInt(start.Unix(), 10))))
+
+	scheduleTime := f.now.Add(-time.Hour)
+	schedule := store.Schedule{
+		ScheduleId:    gocql.TimeUUID(),
+		AppId:         f.app.AppId,
+		PartitionId:   0,
+		ScheduleTime:  scheduleTime.Unix(),
+		ScheduleGroup: scheduleTime.Truncate(time.Minute).Unix(),
+		Callback:      &store.HttpCallback{Type: constants.DefaultCallback, Details: store.Details{Url: "http://example.com/callback", Method: "POST"}},
+	}
+	reference, offloaded, err := f.payloads.Offload(schedule.ScheduleId, `{"large": "payload"}`, f.now.Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, offloaded)
+	schedule.Payload = reference
+
+	_, err = f.scheduleDao.CreateSchedule(schedule, f.app)
+	require.NoError(t, err)
+	require.NoError(t, f.archiver.Run())
+
+	record, err := f.reader.Get(f.app.AppId, schedule.ScheduleId)
+	require.NoError(t, err)
+	assert.Equal(t, `{"large": "payload"}`, record.Schedule.Payload)
+}
+
+func TestArchiver_Windows(t *testing.T) {
+	f := setup(t)
+	midnight := time.Unix(f.now.Unix()-f.now.Unix()%secondsPerDay, 0)
+
+	for _, test := range []struct {
+		start    time.Time
+		end      time.Time
+		expected time.Time
+	}{
+		{start: midnight.Add(time.Hour), end: midnight.Add(5 * time.Hour), expected: midnight.Add(2 * time.Hour)},
+		{start: midnight.Add(time.Hour), end: midnight.Add(90 * time.Minute), expected: midnight.Add(90 * time.Minute)},
+		{start: midnight.Add(-30 * time.Minute), end: midnight.Add(5 * time.Hour), expected: midnight},
+	} {
+		assert.Equal(t, test.expected, f.archiver.windowEnd(test.start, test.end))
+	}
+}
+
+func TestReader_Query(t *testing.T) {
+	f := setup(t)
+
+	start := f.now.Add(-3 * time.Hour).Truncate(time.Minute)
+	for partition := 0; partition < 2; partition++ {
+		require.NoError(t, f.store.Put(checkpointKey(f.app.AppId, partition), []byte(strconv.FormatInt(start.Unix(), 10))))
+	}
+
+	var ids []gocql.UUID
+	for i := 0; i < 5; i++ {
+		ids = append(ids, f.createSchedule(t, i%2, start.Add(time.Duration(i*30+1)*time.Minute)).ScheduleId)
+	}
+	require.NoError(t, f.archiver.Run())
+
+	timeRange := dao.Range{StartTime: start.Add(30 * time.Minute), EndTime: start.Add(121 * time.Minute)}
+
+	var found []gocql.UUID
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		records, next, err := f.reader.Query(f.app.AppId, timeRange, 2, cursor)
+		require.NoError(t, err)
+		for _, record := range records {
+			found = append(found, record.Schedule.ScheduleId)
+		}
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+	assert.ElementsMatch(t, ids[1:5], found)
+
+	_, _, err := f.reader.Query(f.app.AppId, timeRange, 2, "not a cursor")
+	assert.Equal(t, ErrInvalidCursor, err)
+}
+
+func TestEncoding(t *testing.T) {
+	parent := gocql.TimeUUID()
+	records := []Record{
+		{Schedule: store.Schedule{ScheduleId: gocql.TimeUUID(), AppId: "archive", Payload: "first", Status: store.Failure}},
+		{Schedule: store.Schedule{ScheduleId: gocql.TimeUUID(), AppId: "archive", Payload: "second", RunNumber: 2}, ParentScheduleId: parent.String()},
+	}
+
+	data, err := encode(records)
+	require.NoError(t, err)
+
+	decoded, err := decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, records, decoded)
+
+	key := objectKey("app/with slash", 3, time.Unix(60, 0), time.Unix(120, 0))
+	assert.Equal(t, "schedules/app%2Fwith%20slash/1970-01-01/0000000060-0000000120-3.jsonl.gz", key)
+
+	object, err := parseObjectKey(key)
+	require.NoError(t, err)
+	assert.Equal(t, object.start, time.Unix(60, 0))
+	assert.Equal(t, object.end, time.Unix(120, 0))
+	assert.Equal(t, 3, object.partitionId)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/blob"
+	"github.com/myntra/goscheduler/dao"
+)
+
+// Schedules may be created with a schedule time in the past, objects ending this long before the creation
+// of a schedule are still searched for it.
+const pastScheduleMargin = 24 * time.Hour
+
+// ErrInvalidCursor is returned for cursors which were not returned by Query.
+var ErrInvalidCursor = errors.New("invalid archive cursor")
+
+// Reader finds archived schedules in the blob store.
+type Reader struct {
+	store blob.Store
+}
+
+// NewReader creates a reader of the archive objects in the store.
+func NewReader(store blob.Store) *Reader {
+	return &Reader{store: store}
+}
+
+// Get finds the archived run of a schedule of an app.
+// Time based schedule ids carry their creation time, so the objects of the windows ending well before it are skipped,
+// the others are searched in the order of their window.
+// Returns ErrNotFound if the schedule is not archived.
+func (r *Reader) Get(appId string, scheduleId gocql.UUID) (Record, error) {
+	keys, err := r.store.List(appPrefix(appId))
+	if err != nil {
+		return Record{}, err
+	}
+
+	var from time.Time
+	if scheduleId.Version() == 1 {
+		from = scheduleId.Time().Add(-pastScheduleMargin)
+	}
+
+	for _, key := range keys {
+		object, err := parseObjectKey(key)
+		if err != nil {
+			glog.Errorf("Skipping archive object: %s", err.Error())
+			continue
+		}
+		if !object.end.After(from) {
+			continue
+		}
+
+		records, err := r.read(key)
+		if err != nil {
+			return Record{}, err
+		}
+		for _, record := range records {
+			if record.Schedule.ScheduleId == scheduleId {
+				return record, nil
+			}
+		}
+	}
+
+	return Record{}, ErrNotFound
+}
+
+// Query finds up to size archived runs of the schedules of an app whose schedule time is in the time range, both inclusive.
+// Runs are ordered by their archive window, and by schedule time within a window.
+// The returned cursor resumes the query after the last run returned, it is empty once all the runs are returned.
+func (r *Reader) Query(appId string, timeRange dao.Range, size int, cursor string) ([]Record, string, error) {
+	var records []Record
+
+	afterKey, afterLine, err := parseCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	startDay := timeRange.StartTime.Unix() - timeRange.StartTime.Unix()%secondsPerDay
+	for day := time.Unix(startDay, 0); !day.After(timeRange.EndTime); day = day.Add(secondsPerDay * time.Second) {
+		keys, err := r.store.List(dayPrefix(appId, day))
+		if err != nil {
+			return nil, "", err
+		}
+
+		for _, key := range keys {
+			if key < afterKey {
+				continue
+			}
+
+			object, err := parseObjectKey(key)
+			if err != nil {
+				glog.Errorf("Skipping archive object: %s", err.Error())
+				continue
+			}
+			if !object.end.After(timeRange.StartTime) || object.start.After(timeRange.EndTime) {
+				continue
+			}
+
+			archived, err := r.read(key)
+			if err != nil {
+				return nil, "", err
+			}
+
+			for line, record := range archived {
+				if key == afterKey && line <= afterLine {
+					continue
+				}
+
+				scheduleTime := time.Unix(record.Schedule.ScheduleTime, 0)
+				if scheduleTime.Before(timeRange.StartTime) || scheduleTime.After(timeRange.EndTime) {
+					continue
+				}
+
+				records = append(records, record)
+				if len(records) == size {
+					return records, fmt.Sprintf("%s#%d", key, line), nil
+				}
+			}
+		}
+	}
+
+	return records, "", nil
+}
+
+// Read the records of an archive object.
+func (r *Reader) read(key string) ([]Record, error) {
+	data, err := r.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// Break a cursor down into the key of the archive object and the line of the last record returned.
+func parseCursor(cursor string) (string, int, error) {
+	if cursor == "" {
+		return "", -1, nil
+	}
+
+	i := strings.LastIndex(cursor, "#")
+	if i < 0 || !strings.HasPrefix(cursor, schedulesPrefix) {
+		return "", 0, ErrInvalidCursor
+	}
+
+	line, err := strconv.Atoi(cursor[i+1:])
+	if err != nil || line < 0 {
+		return "", 0, ErrInvalidCursor
+	}
+	return cursor[:i], line, nil
+}
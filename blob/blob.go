@@ -0,0 +1,42 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package blob keeps opaque objects under slash separated keys, on a local filesystem or any object store
+// implementing Store.
+package blob
+
+import "errors"
+
+// ErrNotFound is returned when reading an object which does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// ErrInvalidKey is returned for keys which are empty, absolute or have empty, "." or ".." elements.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// Store keeps objects under slash separated keys, e.g. "archive/app/2023-01-01/0-60.jsonl.gz".
+type Store interface {
+	// Put writes the object, replacing the object with the same key if any.
+	Put(key string, data []byte) error
+	// Get reads the object, returning ErrNotFound if it does not exist.
+	Get(key string) ([]byte, error)
+	// List returns the keys starting with the prefix in lexical order.
+	List(prefix string) ([]string, error)
+	// Delete removes the object, removing an object which does not exist is not an error.
+	Delete(key string) error
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package blob
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Prefix of the files objects are written to before being renamed to their key
+const tempPrefix = ".tmp-"
+
+// FileStore keeps every object in a file under the root directory, the key being the path of the file.
+// Objects are written to a temporary file first and renamed, so readers never see partial objects.
+type FileStore struct {
+	root string
+}
+
+// NewFileStore creates the root directory if it does not exist and returns a store keeping objects under it.
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &FileStore{root: root}, nil
+}
+
+// Get the path of the file of the object with the key.
+// Returns ErrInvalidKey for keys which could point outside of the root directory.
+func (f *FileStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key {
+		return "", ErrInvalidKey
+	}
+	for _, element := range strings.Split(key, "/") {
+		if element == "." || element == ".." || strings.HasPrefix(element, tempPrefix) {
+			return "", ErrInvalidKey
+		}
+	}
+	return filepath.Join(f.root, filepath.FromSlash(key)), nil
+}
+
+func (f *FileStore) Put(key string, data []byte) error {
+	name, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(name)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(dir, tempPrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err = temp.Write(data); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err = temp.Sync(); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), name)
+}
+
+func (f *FileStore) Get(key string) ([]byte, error) {
+	name, err := f.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// List walks the directory the prefix points into, so prefixes ending with a slash are the cheapest.
+func (f *FileStore) List(prefix string) ([]string, error) {
+	dir := f.root
+	if i := strings.LastIndex(prefix, "/"); i > 0 {
+		dir = filepath.Join(f.root, filepath.FromSlash(path.Clean(prefix[:i])))
+	}
+
+	var keys []string
+	err := filepath.WalkDir(dir, func(name string, entry fs.DirEntry, err error) error {
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			return nil
+		case err != nil:
+			return err
+		case entry.IsDir() || strings.HasPrefix(entry.Name(), tempPrefix):
+			return nil
+		}
+
+		rel, err := filepath.Rel(f.root, name)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+
+	sort.Strings(keys)
+	return keys, err
+}
+
+func (f *FileStore) Delete(key string) error {
+	name, err := f.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package blob
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStore(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewFileStore(root)
+	assert.NoError(t, err)
+
+	for _, key := range []string{"archive/app/2023-01-02/b", "archive/app/2023-01-01/a", "archive/other/a", "top"} {
+		assert.NoError(t, store.Put(key, []byte(key)))
+	}
+
+	data, err := store.Get("archive/app/2023-01-01/a")
+	assert.NoError(t, err)
+	assert.Equal(t, "archive/app/2023-01-01/a", string(data))
+
+	assert.NoError(t, store.Put("top", []byte("replaced")))
+	data, err = store.Get("top")
+	assert.NoError(t, err)
+	assert.Equal(t, "replaced", string(data))
+
+	_, err = store.Get("archive/app/missing")
+	assert.Equal(t, ErrNotFound, err)
+
+	keys, err := store.List("archive/app/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"archive/app/2023-01-01/a", "archive/app/2023-01-02/b"}, keys)
+
+	keys, err = store.List("archive/app/2023-01-0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"archive/app/2023-01-01/a", "archive/app/2023-01-02/b"}, keys)
+
+	keys, err = store.List("missing/")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	keys, err = store.List("")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 4)
+
+	assert.NoError(t, store.Delete("archive/other/a"))
+	assert.NoError(t, store.Delete("archive/other/a"))
+	_, err = store.Get("archive/other/a")
+	assert.Equal(t, ErrNotFound, err)
+
+	// Files left behind by interrupted writes are not listed
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "archive", "app", tempPrefix+"1"), []byte("partial"), 0644))
+	keys, err = store.List("archive/app/")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+func TestFileStore_InvalidKeys(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	for _, key := range []string{"", "/abs", "a/../../b", "a//b", "a/./b", "a/", ".tmp-1"} {
+		assert.Equal(t, ErrInvalidKey, store.Put(key, nil), key)
+		_, err = store.Get(key)
+		assert.Equal(t, ErrInvalidKey, err, key)
+		assert.Equal(t, ErrInvalidKey, store.Delete(key), key)
+	}
+}
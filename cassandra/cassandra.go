@@ -21,6 +21,7 @@ package cassandra
 
 import (
 	"errors"
+	"fmt"
 	"github.com/gocql/gocql"
 	"github.com/golang/glog"
 	"github.com/myntra/goscheduler/conf"
@@ -38,7 +39,8 @@ func getCassandraHosts(cassandraHosts string) []string {
 	return strings.Split(cassandraHosts, ",")
 }
 
-//skip this for production envs
+// Deprecated: CassandraInit executes the statements of a CQL file without tracking them,
+// the schema is created from the migrations embedded in the binary instead, see GetMigrationStore.
 func CassandraInit(cassandraConfig conf.CassandraConfig, cassandraInitialisationFile string) {
 	createSession, err := GetSessionInterface(cassandraConfig, "")
 
@@ -68,33 +70,138 @@ func CassandraInit(cassandraConfig conf.CassandraConfig, cassandraInitialisation
 	createSession.Close()
 }
 
-// withPool sets the host selection policy for the given cluster configuration
-// to a DC-aware round-robin policy if the data center is specified in the
-// Cassandra configuration. This can improve query performance by selecting
-// nodes that are closer to the data center where the queries are being run.
-func withPool(cluster *gocql.ClusterConfig, config conf.CassandraConfig) *gocql.ClusterConfig {
+// hostPolicy gets the host selection policy of the configuration.
+// The token aware policy falls back to the DC aware policy if the data center is specified,
+// to the round-robin policy otherwise, for the queries whose partition is unknown.
+func hostPolicy(config conf.CassandraConfig) (gocql.HostSelectionPolicy, error) {
+	fallback := gocql.RoundRobinHostPolicy()
 	if len(config.DataCenter) != 0 {
-		cluster.PoolConfig = gocql.PoolConfig{
-			HostSelectionPolicy: gocql.DCAwareRoundRobinPolicy(config.DataCenter)}
+		fallback = gocql.DCAwareRoundRobinPolicy(config.DataCenter)
 	}
 
-	return cluster
+	switch config.HostPolicy {
+	case "":
+		return fallback, nil
+	case conf.RoundRobinHostPolicy:
+		return gocql.RoundRobinHostPolicy(), nil
+	case conf.DCAwareHostPolicy:
+		if len(config.DataCenter) == 0 {
+			return nil, errors.New("the dc-aware host policy requires the data center")
+		}
+		return fallback, nil
+	case conf.TokenAwareHostPolicy:
+		return gocql.TokenAwareHostPolicy(fallback, gocql.ShuffleReplicas()), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown host policy %q", config.HostPolicy))
+	}
 }
 
-// Deprecated: This function is used to get a Cassandra gocql.Session.
-// In order to get the ability to mock methods we are using GetSessionInterface which provides wrapper over gocql.Session
-func GetSession(cassandraConfig conf.CassandraConfig, keyspace string) (*gocql.Session, error) {
-	hosts := getCassandraHosts(cassandraConfig.Hosts)
-	glog.Infof("Cassandra hosts to connnect %s ", hosts)
+// RetryPolicy gets the retry policy of the queries from the configuration, the policy is applied to the sessions
+// and to the queries setting their own retry policy. Failed queries are retried NumRetry times.
+func RetryPolicy(config conf.CassandraConfig) gocql.RetryPolicy {
+	switch config.RetryPolicy.Type {
+	case conf.ExponentialRetryPolicy:
+		return &gocql.ExponentialBackoffRetryPolicy{
+			NumRetries: config.NumRetry,
+			Min:        time.Duration(config.RetryPolicy.MinDelay) * time.Millisecond,
+			Max:        time.Duration(config.RetryPolicy.MaxDelay) * time.Millisecond,
+		}
+	case conf.DowngradingRetryPolicy:
+		return &gocql.DowngradingConsistencyRetryPolicy{ConsistencyLevelsToTry: config.RetryPolicy.DowngradeTo}
+	default:
+		return &gocql.SimpleRetryPolicy{NumRetries: config.NumRetry}
+	}
+}
+
+// validateRetryPolicy checks the retry policy of the configuration
+func validateRetryPolicy(config conf.CassandraConfig) error {
+	switch config.RetryPolicy.Type {
+	case "", conf.SimpleRetryPolicy:
+		return nil
+	case conf.ExponentialRetryPolicy:
+		if config.RetryPolicy.MinDelay <= 0 || config.RetryPolicy.MaxDelay < config.RetryPolicy.MinDelay {
+			return errors.New("the exponential retry policy requires a positive minimum delay not above the maximum delay")
+		}
+		return nil
+	case conf.DowngradingRetryPolicy:
+		if len(config.RetryPolicy.DowngradeTo) == 0 {
+			return errors.New("the downgrading retry policy requires the consistency levels to downgrade to")
+		}
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("unknown retry policy %q", config.RetryPolicy.Type))
+	}
+}
+
+// newCluster creates the configuration of the sessions to the keyspace from the Cassandra configuration.
+// The hosts, consistency, timeouts and connection pool are set along with the host selection and retry policies,
+// the password authentication and TLS if they are configured.
+// Returns an error if a policy of the configuration is invalid.
+func newCluster(config conf.CassandraConfig, keyspace string) (*gocql.ClusterConfig, error) {
+	hosts := getCassandraHosts(config.Hosts)
+	glog.Infof("Cassandra hosts to connect to %s for keyspace %s", hosts, keyspace)
 
 	cluster := gocql.NewCluster(hosts...)
 	cluster.Keyspace = keyspace
-	cluster.Consistency = cassandraConfig.Consistency
-	cluster.Timeout = time.Duration(cassandraConfig.ConnectionPool.InitialConnectTimeout) * time.Millisecond
-	cluster.ConnectTimeout = time.Duration(cassandraConfig.ConnectionPool.ConnectTimeout) * time.Millisecond
-	cluster.NumConns = cassandraConfig.ConnectionPool.MaxNumConnections
+	cluster.Consistency = config.Consistency
+	cluster.Timeout = time.Duration(config.ConnectionPool.InitialConnectTimeout) * time.Millisecond
+	if config.Timeout > 0 {
+		cluster.Timeout = time.Duration(config.Timeout) * time.Millisecond
+	}
+	cluster.WriteTimeout = cluster.Timeout
+	if config.WriteTimeout > 0 {
+		cluster.WriteTimeout = time.Duration(config.WriteTimeout) * time.Millisecond
+	}
+	cluster.ConnectTimeout = time.Duration(config.ConnectionPool.ConnectTimeout) * time.Millisecond
+	cluster.NumConns = config.ConnectionPool.MaxNumConnections
+
+	policy, err := hostPolicy(config)
+	if err != nil {
+		return nil, err
+	}
+	cluster.PoolConfig = gocql.PoolConfig{HostSelectionPolicy: policy}
 
-	withPool(cluster, cassandraConfig)
+	if config.DataCenterOnly {
+		if len(config.DataCenter) == 0 {
+			return nil, errors.New("connecting to the data center only requires the data center")
+		}
+		cluster.HostFilter = gocql.DataCentreHostFilter(config.DataCenter)
+	}
+
+	if err = validateRetryPolicy(config); err != nil {
+		return nil, err
+	}
+	cluster.RetryPolicy = RetryPolicy(config)
+
+	if len(config.Auth.Username) != 0 {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: config.Auth.Username,
+			Password: config.Auth.Password,
+		}
+	}
+
+	if config.TLS.Enabled {
+		if (len(config.TLS.CertPath) == 0) != (len(config.TLS.KeyPath) == 0) {
+			return nil, errors.New("the client certificate and its key are required together")
+		}
+		cluster.SslOpts = &gocql.SslOptions{
+			CaPath:                 config.TLS.CaPath,
+			CertPath:               config.TLS.CertPath,
+			KeyPath:                config.TLS.KeyPath,
+			EnableHostVerification: config.TLS.EnableHostVerification,
+		}
+	}
+
+	return cluster, nil
+}
+
+// Deprecated: This function is used to get a Cassandra gocql.Session.
+// In order to get the ability to mock methods we are using GetSessionInterface which provides wrapper over gocql.Session
+func GetSession(cassandraConfig conf.CassandraConfig, keyspace string) (*gocql.Session, error) {
+	cluster, err := newCluster(cassandraConfig, keyspace)
+	if err != nil {
+		return nil, err
+	}
 
 	session, err := cluster.CreateSession()
 
@@ -106,23 +213,15 @@ func GetSession(cassandraConfig conf.CassandraConfig, keyspace string) (*gocql.S
 }
 
 // GetSessionInterface returns a new Cassandra session interface for the specified
-// keyspace using the given Cassandra configuration. The function creates a new
-// cluster configuration with the specified hosts and keyspace, and sets various
-// parameters such as page size, consistency level, and connection timeouts. The
-// function also applies optional modifications to the cluster configuration using
-// the withPool and withAuthenticator functions. Finally, the function creates a
+// keyspace using the given Cassandra configuration. The cluster configuration is created
+// by newCluster, so the sessions of the cluster and schedule keyspaces share the same
+// authentication, TLS, host selection, retry and timeout settings. Finally, the function creates a
 // new session and returns a wrapper object that implements the db_wrapper.SessionInterface
 func GetSessionInterface(cassandraConfig conf.CassandraConfig, keyspace string) (db_wrapper.SessionInterface, error) {
-	hosts := getCassandraHosts(cassandraConfig.Hosts)
-	glog.Infof("Cassandra hosts to connect to %s for keyspace %s", hosts, keyspace)
-
-	cluster := gocql.NewCluster(hosts...)
-	cluster.Keyspace = keyspace
-	cluster.Consistency = cassandraConfig.Consistency
-	cluster.Timeout = time.Duration(cassandraConfig.ConnectionPool.InitialConnectTimeout) * time.Millisecond
-	cluster.ConnectTimeout = time.Duration(cassandraConfig.ConnectionPool.ConnectTimeout) * time.Millisecond
-	cluster.NumConns = cassandraConfig.ConnectionPool.MaxNumConnections
-	withPool(cluster, cassandraConfig)
+	cluster, err := newCluster(cassandraConfig, keyspace)
+	if err != nil {
+		return nil, err
+	}
 
 	session, err := cluster.CreateSession()
 	if err != nil {
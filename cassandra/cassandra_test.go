@@ -0,0 +1,112 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package cassandra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() conf.CassandraConfig {
+	return conf.CassandraConfig{
+		NumRetry:    3,
+		Hosts:       "10.0.0.1,10.0.0.2",
+		Consistency: gocql.LocalQuorum,
+		ConnectionPool: conf.ConnectionPool{
+			InitialConnectTimeout: 1000,
+			ConnectTimeout:        5000,
+			MaxNumConnections:     4,
+		},
+	}
+}
+
+func TestNewCluster(t *testing.T) {
+	config := testConfig()
+	config.DataCenter = "dc1"
+	config.DataCenterOnly = true
+	config.HostPolicy = conf.TokenAwareHostPolicy
+	config.Timeout = 200
+	config.WriteTimeout = 500
+	config.Auth = conf.CassandraAuth{Username: "goscheduler", Password: "secret"}
+	config.TLS = conf.CassandraTLS{Enabled: true, CaPath: "/etc/cassandra/ca.pem", CertPath: "/etc/cassandra/client.pem", KeyPath: "/etc/cassandra/client.key", EnableHostVerification: true}
+
+	cluster, err := newCluster(config, "schedule_management")
+	require.Nil(t, err)
+
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, cluster.Hosts)
+	assert.Equal(t, "schedule_management", cluster.Keyspace)
+	assert.Equal(t, gocql.LocalQuorum, cluster.Consistency)
+	assert.Equal(t, 200*time.Millisecond, cluster.Timeout)
+	assert.Equal(t, 500*time.Millisecond, cluster.WriteTimeout)
+	assert.Equal(t, 5*time.Second, cluster.ConnectTimeout)
+	assert.Equal(t, 4, cluster.NumConns)
+	assert.IsType(t, gocql.TokenAwareHostPolicy(nil), cluster.PoolConfig.HostSelectionPolicy)
+	assert.False(t, cluster.HostFilter.Accept(&gocql.HostInfo{}))
+	assert.Equal(t, &gocql.SimpleRetryPolicy{NumRetries: 3}, cluster.RetryPolicy)
+	assert.Equal(t, gocql.PasswordAuthenticator{Username: "goscheduler", Password: "secret"}, cluster.Authenticator)
+	assert.Equal(t, &gocql.SslOptions{CaPath: "/etc/cassandra/ca.pem", CertPath: "/etc/cassandra/client.pem", KeyPath: "/etc/cassandra/client.key", EnableHostVerification: true}, cluster.SslOpts)
+}
+
+func TestNewCluster_Defaults(t *testing.T) {
+	cluster, err := newCluster(testConfig(), "")
+	require.Nil(t, err)
+
+	assert.Equal(t, time.Second, cluster.Timeout)
+	assert.Equal(t, time.Second, cluster.WriteTimeout)
+	assert.Nil(t, cluster.HostFilter)
+	assert.Nil(t, cluster.Authenticator)
+	assert.Nil(t, cluster.SslOpts)
+}
+
+func TestNewCluster_Invalid(t *testing.T) {
+	for name, update := range map[string]func(*conf.CassandraConfig){
+		"unknown host policy":           func(c *conf.CassandraConfig) { c.HostPolicy = "nearest" },
+		"dc-aware without data center":  func(c *conf.CassandraConfig) { c.HostPolicy = conf.DCAwareHostPolicy },
+		"data center only without name": func(c *conf.CassandraConfig) { c.DataCenterOnly = true },
+		"unknown retry policy":          func(c *conf.CassandraConfig) { c.RetryPolicy.Type = "forever" },
+		"exponential without delays":    func(c *conf.CassandraConfig) { c.RetryPolicy.Type = conf.ExponentialRetryPolicy },
+		"downgrading without levels":    func(c *conf.CassandraConfig) { c.RetryPolicy.Type = conf.DowngradingRetryPolicy },
+		"certificate without key": func(c *conf.CassandraConfig) {
+			c.TLS = conf.CassandraTLS{Enabled: true, CertPath: "/etc/cassandra/client.pem"}
+		},
+	} {
+		config := testConfig()
+		update(&config)
+
+		_, err := newCluster(config, "")
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	config := testConfig()
+	assert.Equal(t, &gocql.SimpleRetryPolicy{NumRetries: 3}, RetryPolicy(config))
+
+	config.RetryPolicy = conf.CassandraRetry{Type: conf.ExponentialRetryPolicy, MinDelay: 100, MaxDelay: 2000}
+	assert.Equal(t, &gocql.ExponentialBackoffRetryPolicy{NumRetries: 3, Min: 100 * time.Millisecond, Max: 2 * time.Second}, RetryPolicy(config))
+
+	config.RetryPolicy = conf.CassandraRetry{Type: conf.DowngradingRetryPolicy, DowngradeTo: []gocql.Consistency{gocql.LocalOne}}
+	assert.Equal(t, &gocql.DowngradingConsistencyRetryPolicy{ConsistencyLevelsToTry: []gocql.Consistency{gocql.LocalOne}}, RetryPolicy(config))
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cassandra
+
+import (
+	"embed"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/db_wrapper"
+	"github.com/myntra/goscheduler/migration"
+)
+
+//go:embed migrations/*.cql
+var migrations embed.FS
+
+const (
+	// Id of the single row of the migration lock
+	migrationLockId = "migrations"
+	// The lock expires if its holder dies while migrating, so it has to outlive the slowest migration
+	migrationLockTTL = 10 * time.Minute
+	// How long a node waits for the lock held by another node
+	migrationLockWait = 15 * time.Minute
+	// Interval between the attempts to take the lock
+	migrationLockRetryInterval = time.Second
+)
+
+// The tables tracking the migrations live in the cluster keyspace, which is created before the first migration runs
+var migrationBootstrap = []string{
+	"CREATE KEYSPACE IF NOT EXISTS cluster WITH replication = {'class': 'SimpleStrategy', 'replication_factor' : 3} AND durable_writes = true",
+	"CREATE TABLE IF NOT EXISTS cluster.schema_migrations (version int, name text, checksum text, applied_at timestamp, PRIMARY KEY (version))",
+	"CREATE TABLE IF NOT EXISTS cluster.schema_migrations_lock (id text, owner text, acquired_at timestamp, PRIMARY KEY (id))",
+}
+
+const (
+	queryAppliedMigrations = "SELECT version, name, checksum, applied_at FROM cluster.schema_migrations"
+	queryRecordMigration   = "INSERT INTO cluster.schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)"
+	queryTakeMigrationLock = "INSERT INTO cluster.schema_migrations_lock (id, owner, acquired_at) VALUES (?, ?, ?) IF NOT EXISTS USING TTL ?"
+	queryFreeMigrationLock = "DELETE FROM cluster.schema_migrations_lock WHERE id = ? IF owner = ?"
+	queryViews             = "SELECT view_name FROM system_schema.views WHERE keyspace_name IN ('schedule_management', 'cluster') LIMIT 1"
+	queryLookupsChecked    = "SELECT passed_at FROM cluster.lookup_checks WHERE name = ?"
+	queryRecordLookupCheck = "INSERT INTO cluster.lookup_checks (name, passed_at) VALUES (?, ?)"
+)
+
+const (
+	// Version of the migration dropping the materialized views replaced by the lookup tables
+	dropViewsVersion = 8
+	// Name of the lookup_checks row recording that the lookup tables passed lookups check
+	lookupsCheckName = "lookups"
+)
+
+// Checksums of the earlier revisions of the migrations. The initial migration created the view_schedules and nodes
+// materialized views, which are dropped by a later migration once the lookup tables replacing them are backfilled.
+var replacedChecksums = map[int][]string{
+	1: {"40ecc8275dec10002031e722ceabea39fbe38d04802c1a38960b4412c5c4df66"},
+}
+
+// Migrations gets the migrations of the Cassandra schema embedded in the binary
+func Migrations() ([]migration.Migration, error) {
+	loaded, err := migration.Load(migrations, "migrations", ".cql")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range loaded {
+		loaded[i].Replaces = replacedChecksums[loaded[i].Version]
+	}
+	return loaded, nil
+}
+
+// RecordLookupsCheck records that the lookup tables passed lookups check,
+// which releases the migration dropping the materialized views they replace
+func RecordLookupsCheck(session db_wrapper.SessionInterface) error {
+	return session.Query(queryRecordLookupCheck, lookupsCheckName, time.Now()).Exec()
+}
+
+// MigrationStore records the migrations applied to the Cassandra schema in the cluster.schema_migrations table.
+// The migration lock is a lightweight transaction on the cluster.schema_migrations_lock table,
+// it expires after migrationLockTTL if its holder dies.
+// Cassandra cannot roll back schema changes, so the statements of a migration should be safe to run again
+// in case the migration fails halfway.
+type MigrationStore struct {
+	Session db_wrapper.SessionInterface
+	owner   string
+}
+
+func NewMigrationStore(session db_wrapper.SessionInterface) *MigrationStore {
+	return &MigrationStore{Session: session, owner: gocql.TimeUUID().String()}
+}
+
+// GetMigrationStore connects to the Cassandra cluster without a keyspace and returns the migration store
+// along with the function closing the session.
+func GetMigrationStore(config conf.CassandraConfig) (*MigrationStore, func(), error) {
+	session, err := GetSessionInterface(config, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewMigrationStore(session), session.Close, nil
+}
+
+// Create the tables tracking the migrations
+func (m *MigrationStore) bootstrap() error {
+	for _, stmt := range migrationBootstrap {
+		if err := m.Session.Query(stmt).Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lock creates the tables tracking the migrations if needed and waits until the migration lock is taken.
+// ErrLockTimeout is returned if another node holds it for longer than migrationLockWait.
+func (m *MigrationStore) Lock() (func(), error) {
+	if err := m.bootstrap(); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(migrationLockWait)
+	for {
+		holder := make(map[string]interface{})
+		applied, err := m.Session.Query(queryTakeMigrationLock, migrationLockId, m.owner, time.Now(), int(migrationLockTTL.Seconds())).
+			MapScanCAS(holder)
+		switch {
+		case err != nil:
+			return nil, err
+		case applied:
+			return m.unlock, nil
+		case time.Now().After(deadline):
+			return nil, migration.ErrLockTimeout
+		}
+
+		glog.Infof("Migration lock is held by %v since %v, waiting", holder["owner"], holder["acquired_at"])
+		time.Sleep(migrationLockRetryInterval)
+	}
+}
+
+func (m *MigrationStore) unlock() {
+	if _, err := m.Session.Query(queryFreeMigrationLock, migrationLockId, m.owner).MapScanCAS(make(map[string]interface{})); err != nil {
+		glog.Errorf("Releasing the migration lock failed with error %s, it expires in %v", err.Error(), migrationLockTTL)
+	}
+}
+
+// Applied gets the records of the applied migrations, none if the tables tracking them do not exist yet
+func (m *MigrationStore) Applied() ([]migration.Record, error) {
+	var records []migration.Record
+	var record migration.Record
+
+	iter := m.Session.Query(queryAppliedMigrations).Iter()
+	for iter.Scan(&record.Version, &record.Name, &record.Checksum, &record.AppliedAt) {
+		records = append(records, record)
+	}
+
+	err := iter.Close()
+	var requestErr gocql.RequestError
+	if errors.As(err, &requestErr) && requestErr.Code() == gocql.ErrCodeInvalid {
+		glog.Infof("Migrations are not tracked yet: %s", err.Error())
+		return nil, nil
+	}
+	return records, err
+}
+
+// Apply runs the statements of the migration one by one and records it.
+// Columns added by an earlier attempt of a migration which failed halfway are skipped.
+func (m *MigrationStore) Apply(migration migration.Migration) error {
+	for _, stmt := range splitStatements(migration.Script) {
+		glog.Infof("Executing %s", stmt)
+		err := m.Session.Query(stmt).Exec()
+		switch {
+		case isExistingColumn(stmt, err):
+			glog.Infof("Columns of %s exist already: %s", stmt, err.Error())
+		case err != nil:
+			return err
+		}
+	}
+
+	return m.Session.Query(queryRecordMigration, migration.Version, migration.Name, migration.Checksum, time.Now()).Exec()
+}
+
+// Held holds back the migration dropping the materialized views of a cluster created before the lookup tables,
+// until the lookup tables are backfilled and have passed lookups check. Nodes still running a version reading the views
+// keep working until then. Clusters created without the views have nothing to wait for.
+func (m *MigrationStore) Held(migration migration.Migration) (string, error) {
+	if migration.Version != dropViewsVersion {
+		return "", nil
+	}
+
+	var name string
+	err := m.Session.Query(queryViews).Scan(&name)
+	switch {
+	case err == gocql.ErrNotFound:
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+
+	var passedAt time.Time
+	err = m.Session.Query(queryLookupsChecked, lookupsCheckName).Scan(&passedAt)
+	var requestErr gocql.RequestError
+	switch {
+	case err == gocql.ErrNotFound || (errors.As(err, &requestErr) && requestErr.Code() == gocql.ErrCodeInvalid):
+		return "the lookup tables replacing the materialized views have not passed lookups check, run lookups backfill and lookups check", nil
+	case err != nil:
+		return "", err
+	}
+	return "", nil
+}
+
+// Check if the error of a statement adding columns is due to the columns existing already,
+// Cassandra having no IF NOT EXISTS for the columns of an ALTER TABLE
+func isExistingColumn(stmt string, err error) bool {
+	var requestErr gocql.RequestError
+	return errors.As(err, &requestErr) &&
+		requestErr.Code() == gocql.ErrCodeInvalid &&
+		strings.HasPrefix(strings.ToUpper(stmt), "ALTER TABLE") &&
+		strings.Contains(requestErr.Message(), "conflicts with an existing column")
+}
+
+// Split a CQL script into its statements, dropping the comment lines
+func splitStatements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			lines = append(lines, line)
+		}
+	}
+
+	var stmts []string
+	for _, stmt := range strings.Split(strings.Join(lines, "\n"), ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
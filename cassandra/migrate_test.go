@@ -0,0 +1,146 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package cassandra
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/myntra/goscheduler/migration"
+	"github.com/myntra/goscheduler/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migrations are numbered without gaps")
+		assert.NotEmpty(t, splitStatements(m.Script))
+		assert.NotContains(t, m.Script, "CREATE MATERIALIZED VIEW")
+	}
+	assert.NotEmpty(t, migrations[0].Replaces)
+	assert.Equal(t, "drop_materialized_views", migrations[dropViewsVersion-1].Name)
+}
+
+func TestMigrationStore_Held(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := mocks.NewMockSessionInterface(ctrl)
+	store := NewMigrationStore(session)
+	dropViews := migration.Migration{Version: dropViewsVersion, Name: "drop_materialized_views"}
+
+	scan := func(stmt string, err error, args ...interface{}) {
+		query := mocks.NewMockQueryInterface(ctrl)
+		query.EXPECT().Scan(gomock.Any()).Return(err)
+		session.EXPECT().Query(stmt, args...).Return(query)
+	}
+
+	// Other migrations are never held
+	reason, err := store.Held(migration.Migration{Version: 1, Name: "initial"})
+	assert.Nil(t, err)
+	assert.Empty(t, reason)
+
+	// Clusters created without the views
+	scan(queryViews, gocql.ErrNotFound)
+	reason, err = store.Held(dropViews)
+	assert.Nil(t, err)
+	assert.Empty(t, reason)
+
+	// Views exist and the lookup check has not passed, or its table is not created yet
+	scan(queryViews, nil)
+	scan(queryLookupsChecked, gocql.ErrNotFound, lookupsCheckName)
+	reason, err = store.Held(dropViews)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, reason)
+
+	scan(queryViews, nil)
+	scan(queryLookupsChecked, requestError{code: gocql.ErrCodeInvalid, message: "unconfigured table lookup_checks"}, lookupsCheckName)
+	reason, err = store.Held(dropViews)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, reason)
+
+	// Views exist and the lookup check passed
+	scan(queryViews, nil)
+	scan(queryLookupsChecked, nil, lookupsCheckName)
+	reason, err = store.Held(dropViews)
+	assert.Nil(t, err)
+	assert.Empty(t, reason)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := "-- Tables\nCREATE TABLE a (id int PRIMARY KEY);\n\n  -- Indexes\nCREATE INDEX ON a (id);\n"
+	assert.Equal(t, []string{"CREATE TABLE a (id int PRIMARY KEY)", "CREATE INDEX ON a (id)"}, splitStatements(script))
+}
+
+// requestError is a Cassandra error response
+type requestError struct {
+	code    int
+	message string
+}
+
+func (e requestError) Code() int       { return e.code }
+func (e requestError) Message() string { return e.message }
+func (e requestError) Error() string   { return e.message }
+
+func TestIsExistingColumn(t *testing.T) {
+	existing := requestError{code: gocql.ErrCodeInvalid, message: "Invalid column name priority because it conflicts with an existing column"}
+	alter := "ALTER TABLE schedule_management.schedules ADD (priority text)"
+
+	assert.True(t, isExistingColumn(alter, existing))
+	assert.False(t, isExistingColumn(alter, nil))
+	assert.False(t, isExistingColumn(alter, requestError{code: gocql.ErrCodeInvalid, message: "Undefined column name"}))
+	assert.False(t, isExistingColumn("CREATE TABLE cluster.a (id int PRIMARY KEY)", existing))
+}
+
+func TestMigrationStore_Up(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	session := mocks.NewMockSessionInterface(ctrl)
+	query := mocks.NewMockQueryInterface(ctrl)
+	iter := mocks.NewMockIterInterface(ctrl)
+	store := NewMigrationStore(session)
+
+	for _, stmt := range migrationBootstrap {
+		session.EXPECT().Query(stmt).Return(query)
+	}
+	session.EXPECT().Query(queryTakeMigrationLock, migrationLockId, store.owner, gomock.Any(), gomock.Any()).Return(query).Times(2)
+	session.EXPECT().Query(queryAppliedMigrations).Return(query)
+	session.EXPECT().Query("CREATE TABLE cluster.a (id int PRIMARY KEY)").Return(query)
+	session.EXPECT().Query(queryRecordMigration, 1, "initial", gomock.Any(), gomock.Any()).Return(query)
+	session.EXPECT().Query(queryFreeMigrationLock, migrationLockId, store.owner).Return(query)
+
+	query.EXPECT().Exec().Return(nil).Times(len(migrationBootstrap) + 2)
+	query.EXPECT().Iter().Return(iter)
+	iter.EXPECT().Scan(gomock.Any()).Return(false)
+	iter.EXPECT().Close().Return(nil)
+
+	// The lock is held by another node on the first attempt
+	first := query.EXPECT().MapScanCAS(gomock.Any()).Return(false, nil)
+	query.EXPECT().MapScanCAS(gomock.Any()).Return(true, nil).After(first).Times(2)
+
+	applied, err := migration.Up(store, []migration.Migration{{Version: 1, Name: "initial", Script: "CREATE TABLE cluster.a (id int PRIMARY KEY);"}})
+	assert.Nil(t, err)
+	assert.Len(t, applied, 1)
+}
@@ -39,3 +39,7 @@ func (d *DummySupervisor) DeactivateApp(app store.App) {
 // Implement if required
 func (d *DummySupervisor) ActivateApp(app store.App) {
 }
+
+// Implement if required
+func (d *DummySupervisor) AppDetailsUpdated(appName string) {
+}
@@ -486,6 +486,11 @@ func (s *Supervisor) appDetailsUpdateBroadcast(appName string) {
 	}
 }
 
+// AppDetailsUpdated broadcasts the change of app details so that every node drops its cached copy
+func (s *Supervisor) AppDetailsUpdated(appName string) {
+	s.appDetailsUpdateBroadcast(appName)
+}
+
 // AppDetailsUpdateEventHandler receives app update event
 // Invalidates cache based on appName
 func (s *Supervisor) AppDetailsUpdateEventHandler(ctx json.Context, request *AppNames) (*Response, error) {
@@ -32,4 +32,6 @@ type SupervisorHandler interface {
 	DeactivateApp(app store.App)
 	// ActivateApp activates the specified application.
 	ActivateApp(app store.App)
+	// AppDetailsUpdated invalidates the cached details of the specified application on all the nodes.
+	AppDetailsUpdated(appName string)
 }
@@ -0,0 +1,250 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package connectors
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/myntra/goscheduler/store"
+)
+
+// tokens are refreshed this long before they actually expire
+const tokenExpiryDelta = 30 * time.Second
+
+type oauthToken struct {
+	profile     store.AuthProfile
+	accessToken string
+	expiry      time.Time
+}
+
+type mtlsClient struct {
+	profile store.AuthProfile
+	client  *http.Client
+}
+
+// AuthResolver resolves the per-app auth profiles referenced by http callbacks. OAuth2 tokens and mTLS clients
+// are cached per app and profile, and rebuilt when the profile changes or the token is about to expire.
+type AuthResolver struct {
+	client      *http.Client
+	tokenClient *http.Client
+	mu          sync.Mutex
+	tokens      map[string]oauthToken
+	clients     map[string]mtlsClient
+}
+
+// NewAuthResolver creates a new AuthResolver using the given client as a template for mTLS clients, and the token
+// client for token requests. Token urls come from the configuration of the app rather than from schedules, so the
+// token client need not be bound by the callback network policy, only by the link-local ranges always denied.
+func NewAuthResolver(client *http.Client, tokenClient *http.Client) *AuthResolver {
+	return &AuthResolver{
+		client:      client,
+		tokenClient: tokenClient,
+		tokens:      make(map[string]oauthToken),
+		clients:     make(map[string]mtlsClient),
+	}
+}
+
+// getProfile returns the auth profile referenced by the http callback of the schedule, if any
+func getProfile(input store.Schedule, app store.App) (store.AuthProfile, bool, error) {
+	name := input.Callback.(*store.HttpCallback).Details.AuthProfile
+	if name == "" {
+		return store.AuthProfile{}, false, nil
+	}
+
+	profile, ok := app.Configuration.GetAuthProfile(name)
+	if !ok {
+		return store.AuthProfile{}, false, fmt.Errorf("auth profile %s is not configured for app %s", name, app.AppId)
+	}
+
+	return profile, true, nil
+}
+
+// Authorize sets the credentials of the auth profile referenced by the schedule on the request
+func (a *AuthResolver) Authorize(req *http.Request, input store.Schedule, app store.App) error {
+	profile, ok, err := getProfile(input, app)
+	if err != nil || !ok {
+		return err
+	}
+
+	switch profile.Type {
+	case store.AuthTypeOAuth2:
+		token, err := a.getToken(profileKey(app.AppId, input), profile)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	case store.AuthTypeBasic:
+		password, err := store.ResolveSecret(profile.PasswordRef)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(profile.Username, password)
+	}
+
+	return nil
+}
+
+// Client returns the http client to be used for the schedule, which is the default one unless an mTLS profile is referenced
+func (a *AuthResolver) Client(input store.Schedule, app store.App) (*http.Client, error) {
+	profile, ok, err := getProfile(input, app)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || profile.Type != store.AuthTypeMTLS {
+		return a.client, nil
+	}
+
+	key := profileKey(app.AppId, input)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if cached, ok := a.clients[key]; ok && reflect.DeepEqual(cached.profile, profile) {
+		return cached.client, nil
+	}
+
+	client, err := a.newMTLSClient(profile)
+	if err != nil {
+		return nil, err
+	}
+	a.clients[key] = mtlsClient{profile: profile, client: client}
+
+	return client, nil
+}
+
+// Invalidate drops the cached token of a profile, used when the callback endpoint rejects it
+func (a *AuthResolver) Invalidate(input store.Schedule, app store.App) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.tokens, profileKey(app.AppId, input))
+}
+
+func (a *AuthResolver) getToken(key string, profile store.AuthProfile) (string, error) {
+	a.mu.Lock()
+	cached, ok := a.tokens[key]
+	a.mu.Unlock()
+
+	if ok && reflect.DeepEqual(cached.profile, profile) && time.Now().Add(tokenExpiryDelta).Before(cached.expiry) {
+		return cached.accessToken, nil
+	}
+
+	token, err := a.fetchToken(profile)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	a.tokens[key] = token
+	a.mu.Unlock()
+
+	return token.accessToken, nil
+}
+
+// fetchToken requests a new access token using the OAuth2 client-credentials grant
+func (a *AuthResolver) fetchToken(profile store.AuthProfile) (oauthToken, error) {
+	secret, err := store.ResolveSecret(profile.ClientSecretRef)
+	if err != nil {
+		return oauthToken{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(profile.Scopes) > 0 {
+		form.Set("scope", strings.Join(profile.Scopes, " "))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, profile.TokenUrl, strings.NewReader(form.Encode()))
+	if err != nil {
+		return oauthToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(profile.ClientId), url.QueryEscape(secret))
+
+	response, err := a.tokenClient.Do(req)
+	if err != nil {
+		return oauthToken{}, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return oauthToken{}, err
+	}
+	if !isSuccess(response) {
+		return oauthToken{}, fmt.Errorf("token request to %s failed with status %s", profile.TokenUrl, response.Status)
+	}
+
+	var tokenResponse struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err = json.Unmarshal(body, &tokenResponse); err != nil {
+		return oauthToken{}, err
+	}
+	if tokenResponse.AccessToken == "" {
+		return oauthToken{}, errors.New("token response does not contain an access_token")
+	}
+
+	return oauthToken{
+		profile:     profile,
+		accessToken: tokenResponse.AccessToken,
+		expiry:      time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second),
+	}, nil
+}
+
+func (a *AuthResolver) newMTLSClient(profile store.AuthProfile) (*http.Client, error) {
+	cert, err := tls.LoadX509KeyPair(profile.CertFile, profile.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	if profile.CAFile != "" {
+		ca, err := ioutil.ReadFile(profile.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", profile.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := newCallbackTransport()
+	transport.TLSClientConfig = tlsConfig
+	return newCallbackClient(a.client.Timeout, transport), nil
+}
+
+func profileKey(appId string, input store.Schedule) string {
+	return appId + "/" + input.Callback.(*store.HttpCallback).Details.AuthProfile
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package connectors
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/myntra/goscheduler/conf"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func authSchedule(app s.App, profile string) s.Schedule {
+	return s.Schedule{AppId: app.AppId, ScheduleTime: time.Now().Unix(), Callback: &s.HttpCallback{
+		Type:    "http",
+		Details: s.Details{Url: "https://example.com", Method: http.MethodPost, AuthProfile: profile},
+	}}
+}
+
+func authorize(t *testing.T, resolver *AuthResolver, schedule s.Schedule, app s.App) string {
+	req, err := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	require.Nil(t, err)
+	require.Nil(t, resolver.Authorize(req, schedule, app))
+	return req.Header.Get("Authorization")
+}
+
+// writeCertificate writes a self signed certificate and its key to dir, returning their paths
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "goscheduler"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	require.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile
+}
+
+func TestAuthResolver_Token(t *testing.T) {
+	var fetched int32
+	expiresIn := int32(3600)
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetched, 1)
+		if id, secret, ok := r.BasicAuth(); !ok || id != "client" || secret != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token": "token", "expires_in": %d}`, atomic.LoadInt32(&expiresIn))
+	}))
+	defer tokens.Close()
+	t.Setenv("GOSCHEDULER_TEST_CLIENT_SECRET", "secret")
+
+	profile := s.AuthProfile{Type: s.AuthTypeOAuth2, TokenUrl: tokens.URL, ClientId: "client", ClientSecretRef: "env:GOSCHEDULER_TEST_CLIENT_SECRET"}
+	app := s.App{AppId: "auth", Configuration: s.Configuration{AuthProfiles: map[string]s.AuthProfile{"oauth": profile}}}
+	schedule := authSchedule(app, "oauth")
+	resolver := NewAuthResolver(&http.Client{Timeout: time.Second}, &http.Client{Timeout: time.Second})
+
+	// The token is cached across requests
+	assert.Equal(t, "Bearer token", authorize(t, resolver, schedule, app))
+	assert.Equal(t, "Bearer token", authorize(t, resolver, schedule, app))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+
+	// An invalidated token is fetched again
+	resolver.Invalidate(schedule, app)
+	assert.Equal(t, "Bearer token", authorize(t, resolver, schedule, app))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetched))
+
+	// A changed profile gets a new token
+	profile.Scopes = []string{"schedules"}
+	app.Configuration.AuthProfiles["oauth"] = profile
+	assert.Equal(t, "Bearer token", authorize(t, resolver, schedule, app))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&fetched))
+
+	// A token about to expire is not reused
+	atomic.StoreInt32(&expiresIn, 10)
+	resolver.Invalidate(schedule, app)
+	authorize(t, resolver, schedule, app)
+	authorize(t, resolver, schedule, app)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&fetched))
+
+	// A rejected token request fails the authorization
+	t.Setenv("GOSCHEDULER_TEST_CLIENT_SECRET", "wrong")
+	resolver.Invalidate(schedule, app)
+	req, err := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	require.Nil(t, err)
+	assert.NotNil(t, resolver.Authorize(req, schedule, app))
+}
+
+func TestAuthResolver_Authorize(t *testing.T) {
+	t.Setenv("GOSCHEDULER_TEST_PASSWORD", "password")
+	app := s.App{AppId: "auth", Configuration: s.Configuration{AuthProfiles: map[string]s.AuthProfile{
+		"basic": {Type: s.AuthTypeBasic, Username: "user", PasswordRef: "env:GOSCHEDULER_TEST_PASSWORD"},
+	}}}
+	resolver := NewAuthResolver(&http.Client{}, &http.Client{})
+
+	req, err := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	require.Nil(t, err)
+	require.Nil(t, resolver.Authorize(req, authSchedule(app, "basic"), app))
+	user, password, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "password", password)
+
+	assert.Equal(t, "", authorize(t, resolver, authSchedule(app, ""), app))
+
+	req, err = http.NewRequest(http.MethodPost, "https://example.com", nil)
+	require.Nil(t, err)
+	assert.NotNil(t, resolver.Authorize(req, authSchedule(app, "missing"), app))
+}
+
+func TestAuthResolver_Client(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir)
+
+	profile := s.AuthProfile{Type: s.AuthTypeMTLS, CertFile: certFile, KeyFile: keyFile}
+	app := s.App{AppId: "auth", Configuration: s.Configuration{AuthProfiles: map[string]s.AuthProfile{"mtls": profile}}}
+	schedule := authSchedule(app, "mtls")
+	defaultClient := &http.Client{Timeout: time.Second}
+	resolver := NewAuthResolver(defaultClient, defaultClient)
+
+	// Schedules without an mTLS profile use the default client
+	client, err := resolver.Client(authSchedule(app, ""), app)
+	require.Nil(t, err)
+	assert.Same(t, defaultClient, client)
+
+	// The mTLS client is cached per app and profile
+	client, err = resolver.Client(schedule, app)
+	require.Nil(t, err)
+	assert.NotSame(t, defaultClient, client)
+	assert.Equal(t, defaultClient.Timeout, client.Timeout)
+	cached, err := resolver.Client(schedule, app)
+	require.Nil(t, err)
+	assert.Same(t, client, cached)
+
+	// A changed profile gets a new client
+	profile.CAFile = certFile
+	app.Configuration.AuthProfiles["mtls"] = profile
+	rebuilt, err := resolver.Client(schedule, app)
+	require.Nil(t, err)
+	assert.NotSame(t, client, rebuilt)
+
+	// Unreadable certificates fail
+	profile.CertFile = filepath.Join(dir, "missing.crt")
+	app.Configuration.AuthProfiles["mtls"] = profile
+	_, err = resolver.Client(schedule, app)
+	assert.NotNil(t, err)
+}
+
+func TestAuthResolver_TokenOnPrivateNetwork(t *testing.T) {
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	}))
+	defer tokens.Close()
+
+	// Callbacks to the private networks are denied by default, token requests to the internal auth server are not
+	t.Setenv("GOSCHEDULER_TEST_CLIENT_SECRET", "secret")
+
+	config := conf.NewConfig()
+	config.HttpConnector.TimeoutMillis = 1000
+	connector := NewConnector(config, nil, nil, nil)
+
+	app := s.App{AppId: "auth", Configuration: s.Configuration{AuthProfiles: map[string]s.AuthProfile{
+		"internal": {Type: s.AuthTypeOAuth2, TokenUrl: tokens.URL, ClientId: "client", ClientSecretRef: "env:GOSCHEDULER_TEST_CLIENT_SECRET"},
+	}}}
+	assert.Equal(t, "Bearer token", authorize(t, connector.Auth, authSchedule(app, "internal"), app))
+
+	// The callback client is still bound by the network policy
+	_, err := connector.HttpClient.Get(tokens.URL)
+	assert.ErrorIs(t, err, ErrDeniedAddress)
+
+	// Token requests never reach the link-local metadata endpoints
+	app.Configuration.AuthProfiles["metadata"] = s.AuthProfile{Type: s.AuthTypeOAuth2, TokenUrl: "http://169.254.169.254/token", ClientId: "client", ClientSecretRef: "env:GOSCHEDULER_TEST_CLIENT_SECRET"}
+	req, err := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	require.Nil(t, err)
+	assert.ErrorIs(t, connector.Auth.Authorize(req, authSchedule(app, "metadata"), app), ErrDeniedAddress)
+}
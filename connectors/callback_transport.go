@@ -0,0 +1,105 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package connectors
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/myntra/goscheduler/store"
+)
+
+// Maximum number of redirects followed for a callback, same as the default of http.Client
+const maxRedirects = 10
+
+// ErrDeniedAddress is returned for callbacks to an address in a denied network
+var ErrDeniedAddress = errors.New("callback address is in a denied network")
+
+// callbackConfigurationKey is the request context key of the configuration of the app a callback belongs to
+type callbackConfigurationKey struct{}
+
+// newCallbackTransport returns a transport which refuses to connect to the addresses denied by the callback network policy.
+// The resolved address is checked right before connecting, so a host which resolves to a denied address is refused
+// even if it resolved to an allowed one when the schedule was created.
+func newCallbackTransport() *http.Transport {
+	return newRestrictedTransport(store.CallbackNetworks)
+}
+
+// newTokenTransport returns the transport of the token requests of auth profiles,
+// which can reach private networks but never the link-local ones
+func newTokenTransport() *http.Transport {
+	return newRestrictedTransport(store.LinkLocalNetworks)
+}
+
+// newRestrictedTransport returns a transport checking the resolved address of every connection against the policy
+func newRestrictedTransport(policy func() store.NetworkPolicy) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkDialAddress(policy(), address)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy resolves the host by itself, so requests are dialed directly for their address to be checked
+	transport.Proxy = nil
+	return transport
+}
+
+// checkDialAddress checks the resolved address of a connection against the network policy
+func checkDialAddress(policy store.NetworkPolicy, address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !policy.AllowsIP(ip) {
+		return fmt.Errorf("%w: %s", ErrDeniedAddress, address)
+	}
+	return nil
+}
+
+// checkCallbackRedirect stops redirects to urls outside the allowed callback hosts of the app
+func checkCallbackRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	configuration, ok := req.Context().Value(callbackConfigurationKey{}).(store.Configuration)
+	if ok && !configuration.AllowsCallbackUrl(req.URL.String()) {
+		return fmt.Errorf("redirect to %s is not allowed for the app", req.URL.Redacted())
+	}
+	return nil
+}
+
+// newCallbackClient creates the http client used for callbacks
+func newCallbackClient(timeout time.Duration, transport *http.Transport) *http.Client {
+	return &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: checkCallbackRedirect,
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package connectors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/myntra/goscheduler/conf"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackTransport_Defaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	config := conf.NewConfig()
+	config.HttpConnector.TimeoutMillis = 1000
+	connector := NewConnector(config, nil, nil, nil)
+
+	// A default configured connector refuses the metadata endpoint and the private networks
+	_, err := connector.HttpClient.Get("http://169.254.169.254/latest/meta-data")
+	assert.ErrorIs(t, err, ErrDeniedAddress)
+	_, err = connector.HttpClient.Get(server.URL)
+	assert.ErrorIs(t, err, ErrDeniedAddress)
+}
+
+func TestCallbackTransport_AllowPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	require.Nil(t, s.InitializeCallbackNetworks(true, nil))
+	defer func() { _ = s.InitializeCallbackNetworks(false, nil) }()
+
+	config := conf.NewConfig()
+	config.HttpConnector.TimeoutMillis = 1000
+	connector := NewConnector(config, nil, nil, nil)
+
+	response, err := connector.HttpClient.Get(server.URL)
+	require.Nil(t, err)
+	_ = response.Body.Close()
+
+	// Link-local addresses stay denied
+	_, err = connector.HttpClient.Get("http://169.254.169.254/latest/meta-data")
+	assert.ErrorIs(t, err, ErrDeniedAddress)
+	_, err = connector.HttpClient.Get("http://[fe80::1]/")
+	assert.ErrorIs(t, err, ErrDeniedAddress)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/myntra/goscheduler/conf"
 	"github.com/myntra/goscheduler/dao"
 	"github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/payload"
 	"net/http"
 	"time"
 )
@@ -33,19 +34,21 @@ type Connector struct {
 	ClusterDao  dao.ClusterDao
 	ScheduleDao dao.ScheduleDao
 	HttpClient  *http.Client
+	Auth        *AuthResolver
 	Monitor     monitoring.Monitor
+	Payloads    *payload.Store
 }
 
 // NewConnector creates a new Connector instance with the given configuration, DAOs, and monitoring.
 func NewConnector(config *conf.Configuration, clusterDao dao.ClusterDao, scheduleDAO dao.ScheduleDao, monitor monitoring.Monitor) *Connector {
-	client := &http.Client{
-		Timeout: config.HttpConnector.TimeoutMillis * time.Millisecond,
-	}
+	timeout := config.HttpConnector.TimeoutMillis * time.Millisecond
+	client := newCallbackClient(timeout, newCallbackTransport())
 	return &Connector{
 		Config:      config,
 		ClusterDao:  clusterDao,
 		ScheduleDao: scheduleDAO,
 		HttpClient:  client,
+		Auth:        NewAuthResolver(client, &http.Client{Timeout: timeout, Transport: newTokenTransport()}),
 		Monitor:     monitor,
 	}
 }
@@ -24,13 +24,18 @@ import (
 	"github.com/golang/glog"
 	"github.com/myntra/goscheduler/cron"
 	s "github.com/myntra/goscheduler/store"
+	"sort"
 	"time"
 )
 
+// Time before a run a recurring schedule without any numbered run is counted from at most
+const maxRunNumberLookback = 366 * 24 * time.Hour
+
 // Creates one time schedules for a recurring schedule.
 // Listens for a create task event on the channel. And creates the schedule if the cron expression matches
 // any time within the duration window.
 // If the time doesn't match or if a schedule already exists at time then the creation will be skipped.
+// Runs falling on a time blocked by the calendar of the schedule are skipped or shifted to the next business day.
 // The method records any errors occurred during execution and recovers.
 func (c *Connector) createSchedules(tasks <-chan s.CreateScheduleTask) {
 	for task := range tasks {
@@ -57,22 +62,54 @@ func (c *Connector) createSchedules(tasks <-chan s.CreateScheduleTask) {
 		}
 
 		existing := map[time.Time]bool{}
+		offset := s.JitterOffset(parent.ScheduleId, parent.GetJitter(app, c.Config.AppLevelConfiguration.DefaultJitter))
+		numbering := newRunNumbering(_cron, parent, offset)
 		switch runs, _, err := c.ScheduleDao.GetScheduleRuns(parent.ScheduleId, int64(task.Duration/time.Minute), "future", nil); {
 		case err == nil, err == gocql.ErrNotFound:
 			for _, run := range runs {
 				existing[time.Unix(run.ScheduleGroup, 0)] = true
+				numbering.add(run)
 			}
 		default:
 			glog.Errorf("Error getting future runs for %s", parent.ScheduleId)
 			continue
 		}
 
-		for _time := task.From.Add(task.Duration); _time.After(task.From); _time = _time.Add(time.Minute * -1) {
+		calendar, hasCalendar := c.getCalendar(parent)
+		numbering.add(c.getLatestRun(parent))
+
+		// Runs are created from the earliest one, so each run is numbered from the one created before it
+		for _time := task.From.Add(time.Minute); !_time.After(task.From.Add(task.Duration)); _time = _time.Add(time.Minute) {
+
+			if !_cron.Match(_time) {
+				continue
+			}
+
+			at := _time
+			if hasCalendar && calendar.IsBlocked(at) {
+				if parent.GetCalendarPolicy() == s.CalendarSkip {
+					glog.Infof("Skipping run of cron %s at %s blocked by calendar %s", parent.ScheduleId, at, calendar.Name)
+					continue
+				}
+
+				var ok bool
+				if at, ok = calendar.NextBusinessTime(at); !ok {
+					glog.Errorf("No business day found to shift run of cron %s at %s with calendar %s", parent.ScheduleId, _time, calendar.Name)
+					continue
+				}
+				glog.Infof("Shifting run of cron %s from %s to %s by calendar %s", parent.ScheduleId, _time, at, calendar.Name)
+			}
 
-			if _, found := existing[_time]; !found && _cron.Match(_time) {
+			// runs are spread by the jitter of the parent, so they are looked up by their jittered time group
+			group := time.Unix(60*((at.Unix()+offset)/60), 0)
+			if _, found := existing[group]; !found {
+				existing[group] = true
 
-				clone := parent.CloneAsOneTime(_time)
+				clone := parent.CloneAsOneTime(at)
+				clone.RunNumber = numbering.number(_time)
+				clone.NominalTime = _time.Unix()
 				clone.SetFields(app)
+				clone.ApplyJitter(app, c.Config.AppLevelConfiguration.DefaultJitter)
 				if errs := clone.ValidateSchedule(app, c.Config.AppLevelConfiguration); len(errs) != 0 {
 					glog.Errorf(
 						"Validation failed for one time schedule %v of cron %s with errors %v",
@@ -86,11 +123,94 @@ func (c *Connector) createSchedules(tasks <-chan s.CreateScheduleTask) {
 						clone, parent.ScheduleId, err.Error())
 					continue
 				}
+				numbering.addAt(_time, clone.RunNumber)
 			}
 		}
 	}
 }
 
+// Get the calendar of a recurring schedule.
+// Returns false if the schedule has no calendar, or if its calendar cannot be found in which case all runs are created.
+func (c *Connector) getCalendar(parent s.Schedule) (s.Calendar, bool) {
+	if parent.Calendar == "" {
+		return s.Calendar{}, false
+	}
+
+	calendar, err := c.ClusterDao.GetCalendar(parent.Calendar)
+	if err != nil {
+		glog.Errorf("Error getting calendar %s of cron %s, creating runs without it: %v", parent.Calendar, parent.ScheduleId, err)
+		return s.Calendar{}, false
+	}
+
+	return calendar, true
+}
+
+// Get the latest past run of a recurring schedule.
+// A zero schedule is returned if there is no such run.
+func (c *Connector) getLatestRun(parent s.Schedule) s.Schedule {
+	runs, _, err := c.ScheduleDao.GetScheduleRuns(parent.ScheduleId, 1, "past", nil)
+	if err != nil || len(runs) == 0 {
+		return s.Schedule{}
+	}
+	return runs[0]
+}
+
+// numberedRun is the time and run number of a run of a recurring schedule
+type numberedRun struct {
+	at     time.Time
+	number int
+}
+
+// runNumbering numbers the runs of a recurring schedule by counting the cron matches since the closest numbered run before them.
+// Schedules without any numbered run before, like the ones created before runs were numbered, are counted from their creation,
+// but at most maxRunNumberLookback back so that numbering an old schedule stays cheap.
+// Runs are placed at their nominal time, the cron time before their calendar shift and jitter.
+type runNumbering struct {
+	cron    cron.Expression
+	created time.Time
+	offset  int64
+	runs    []numberedRun
+}
+
+// newRunNumbering numbers the runs of the parent, whose runs are spread by the given jitter offset
+func newRunNumbering(_cron cron.Expression, parent s.Schedule, offset int64) *runNumbering {
+	return &runNumbering{cron: _cron, created: parent.ScheduleId.Time(), offset: offset}
+}
+
+// add a run of the schedule, runs without a number are ignored.
+// Runs created before their nominal time was stored are placed at their schedule time without the jitter of the parent.
+func (n *runNumbering) add(run s.Schedule) {
+	switch {
+	case run.RunNumber == 0:
+	case run.NominalTime != 0:
+		n.addAt(time.Unix(run.NominalTime, 0), run.RunNumber)
+	default:
+		n.addAt(time.Unix(run.ScheduleTime-n.offset, 0), run.RunNumber)
+	}
+}
+
+// addAt adds the run number of the run at the given time
+func (n *runNumbering) addAt(at time.Time, number int) {
+	i := sort.Search(len(n.runs), func(i int) bool { return n.runs[i].at.After(at) })
+	n.runs = append(n.runs, numberedRun{})
+	copy(n.runs[i+1:], n.runs[i:])
+	n.runs[i] = numberedRun{at: at, number: number}
+}
+
+// number finds the run number of the run at the given time
+func (n *runNumbering) number(at time.Time) int {
+	if i := sort.Search(len(n.runs), func(i int) bool { return !n.runs[i].at.Before(at) }); i > 0 {
+		previous := n.runs[i-1]
+		return previous.number + n.cron.Count(previous.at.In(at.Location()), at)
+	}
+
+	from := n.created
+	if earliest := at.Add(-maxRunNumberLookback); from.Before(earliest) {
+		from = earliest
+	}
+	return n.cron.Count(from.In(at.Location()), at)
+}
+
 // Start count number of go routines to listen on the task channel
 // The routines on receiving the messages will create one time schedules.
 func (c *Connector) StartScheduleCreateWorkers(tasks <-chan s.CreateScheduleTask) {
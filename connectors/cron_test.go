@@ -0,0 +1,72 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package connectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/cron"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunNumbering(t *testing.T) {
+	hourly, errs := cron.Parse("0 * * * *")
+	require.Empty(t, errs)
+
+	created := time.Date(2023, 1, 1, 0, 30, 0, 0, time.UTC)
+	parent := s.Schedule{ScheduleId: gocql.UUIDFromTime(created)}
+
+	// Without numbered runs, runs are counted from the creation of the schedule
+	numbering := newRunNumbering(hourly, parent, 0)
+	assert.Equal(t, 3, numbering.number(time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC)))
+
+	// Runs are counted from the closest numbered run before them
+	numbering.add(s.Schedule{ScheduleTime: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC).Unix(), RunNumber: 10})
+	numbering.add(s.Schedule{ScheduleTime: time.Date(2023, 1, 1, 5, 0, 0, 0, time.UTC).Unix(), RunNumber: 5})
+	numbering.add(s.Schedule{ScheduleTime: time.Date(2023, 1, 1, 6, 0, 0, 0, time.UTC).Unix()})
+	assert.Equal(t, 7, numbering.number(time.Date(2023, 1, 1, 7, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 12, numbering.number(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)))
+
+	numbering.addAt(time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC), 20)
+	assert.Equal(t, 21, numbering.number(time.Date(2023, 1, 1, 13, 0, 0, 0, time.UTC)))
+
+	// Schedules without numbered runs are counted from a year back at most
+	old := newRunNumbering(hourly, s.Schedule{ScheduleId: gocql.UUIDFromTime(created.AddDate(-5, 0, 0))}, 0)
+	at := time.Date(2023, 1, 1, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, int(maxRunNumberLookback/time.Hour), old.number(at))
+
+	// Runs are placed at their nominal time, not at their schedule time shifted by a calendar and spread by the jitter
+	offset := int64(90 * time.Minute / time.Second)
+	spread := newRunNumbering(hourly, parent, offset)
+	spread.add(s.Schedule{
+		ScheduleTime: time.Date(2023, 1, 1, 9, 0, 0, 0, time.UTC).Unix() + offset,
+		NominalTime:  time.Date(2023, 1, 1, 5, 0, 0, 0, time.UTC).Unix(),
+		RunNumber:    50,
+	})
+	assert.Equal(t, 52, spread.number(time.Date(2023, 1, 1, 7, 0, 0, 0, time.UTC)))
+
+	// Runs created before the nominal time was stored are placed at their schedule time without the jitter
+	spread.add(s.Schedule{ScheduleTime: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC).Unix() + offset, RunNumber: 60})
+	assert.Equal(t, 61, spread.number(time.Date(2023, 1, 1, 11, 0, 0, 0, time.UTC)))
+}
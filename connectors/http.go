@@ -21,19 +21,27 @@ package connectors
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"github.com/gocql/gocql"
 	"github.com/golang/glog"
 	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/payload"
 	"github.com/myntra/goscheduler/store"
 	"github.com/myntra/goscheduler/util"
 	"net/http"
 	"net/http/httputil"
 	"runtime/debug"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Callback attempts for apps without a retry policy
+const defaultMaxAttempts = 3
+
 // trim trims message to max number of characters
 func trim(message string) string {
 	if len(message) < 200 {
@@ -60,18 +68,35 @@ func isSuccess(response *http.Response) bool {
 		response.StatusCode <= constants.HttpResponseSuccessStatusCodeHigherBound)
 }
 
-// createRequest creates a new HTTP request from a given input schedule
-func createRequest(input store.Schedule) (*http.Request, error) {
-	glog.Infof("Method: %s, URL: %s, Headers: %+v", input.Callback.(*store.HttpCallback).Details.Method, input.Callback.(*store.HttpCallback).Details.Url, input.Callback.(*store.HttpCallback).Details.Headers)
-	jsonStr := []byte(input.Payload)
-	req, err := http.NewRequest(input.Callback.(*store.HttpCallback).Details.Method, input.Callback.(*store.HttpCallback).Details.Url, bytes.NewBuffer(jsonStr))
+// createRequest creates a new HTTP request from a given input schedule, authorized with the app's auth profile referenced by the callback
+// Templated callbacks have their url, headers and payload rendered with the fire time variables of the schedule
+func (c *Connector) createRequest(input store.Schedule, app store.App) (*http.Request, error) {
+	details, payload, err := input.Callback.(*store.HttpCallback).Render(input.Payload, store.NewTemplateData(input, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	// The configuration may have changed and templated urls may render differently since the schedule was created
+	if !app.Configuration.AllowsCallbackUrl(details.Url) {
+		return nil, fmt.Errorf("callback url %s is not allowed for app %s", details.Url, app.AppId)
+	}
+
+	glog.Infof("Method: %s, URL: %s, Headers: %+v", details.Method, details.Url, details.Headers)
+	jsonStr := []byte(payload)
+	ctx := context.WithValue(context.Background(), callbackConfigurationKey{}, app.Configuration)
+	req, err := http.NewRequestWithContext(ctx, details.Method, details.Url, bytes.NewBuffer(jsonStr))
 	if err != nil {
 		return nil, err
 	}
 
-	setRequestHeaders(req, input)
+	setRequestHeaders(req, input, details.Headers)
 	handleRequestDump(req, input.ScheduleId)
 
+	// Credentials are set after the dump so that they never end up in the logs
+	if err = c.Auth.Authorize(req, input, app); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
@@ -86,9 +111,9 @@ func handleRequestDump(req *http.Request, scheduleID gocql.UUID) {
 }
 
 // setRequestHeaders sets the required headers for the request
-func setRequestHeaders(req *http.Request, input store.Schedule) {
+func setRequestHeaders(req *http.Request, input store.Schedule, headers map[string]string) {
 	req.Header.Set("Content-Type", "application/json")
-	for header, value := range input.Callback.(*store.HttpCallback).Details.Headers {
+	for header, value := range headers {
 		req.Header.Set(header, value)
 	}
 
@@ -145,36 +170,150 @@ func (c *Connector) processSchedule(scheduleWrapper store.ScheduleWrapper) {
 	app := scheduleWrapper.App
 	isReconciliation := scheduleWrapper.IsReconciliation
 
+	if !isReconciliation {
+		c.updateUsage(result, -1)
+	}
+
+	if now := time.Now(); result.IsExpired(now) {
+		c.expire(result, app, isReconciliation, now)
+		return
+	}
+
 	glog.Infof("Callback fired for schedule with schedule id %s and schedule entity %+v", result.ScheduleId.String(), result)
+
+	// Offloaded payloads are loaded only for firing, the reference is kept in the result
+	input := result
+	loaded, err := c.Payloads.Resolve(result.Payload)
+	if err != nil {
+		c.handleCallbackResult(nil, err, result, app, isReconciliation)
+		return
+	}
+	input.Payload = loaded
+
+	if fanout, ok := result.Callback.(*store.FanoutCallback); ok {
+		c.processFanout(fanout, input, result, app, isReconciliation)
+		return
+	}
+
 	response, err := c.recordTiming(func() (response *http.Response, err error) {
-		return c.retryPost(result, app)
+		response, _, err = c.retryPost(input, app)
+		return response, err
 	}, result.AppId, result.PartitionId)
 
 	c.handleCallbackResult(response, err, result, app, isReconciliation)
 }
 
-// handleCallbackResult processes the result of a callback, updating the schedule status and sending the updated ScheduleWrapper to the AggregationTaskQueue
-func (c *Connector) handleCallbackResult(response *http.Response, err error, result store.Schedule, app store.App, isReconciliation bool) {
+// expire marks a schedule which is fired after its deadline as expired without making the callback
+func (c *Connector) expire(result store.Schedule, app store.App, isReconciliation bool, now time.Time) {
+	glog.Infof("Schedule id %s expired at %d, not firing the callback", result.ScheduleId.String(), result.Deadline)
+
+	result.Status = store.Expired
+	result.ErrorMessage = fmt.Sprintf("deadline %s exceeded by %s",
+		time.Unix(result.Deadline, 0).Format(store.DefaultTimeLayout),
+		now.Sub(time.Unix(result.Deadline, 0)).Truncate(time.Second))
+
+	if c.Monitor != nil {
+		c.Monitor.IncCounter(constants.ExpiredScheduleCount, map[string]string{"appId": result.AppId, "partitionId": strconv.Itoa(result.PartitionId)}, 1)
+	}
+
+	c.finishCallback(result, app, isReconciliation)
+}
+
+// processFanout fires the callbacks to all the targets of a fan-out schedule concurrently and aggregates their statuses.
+// During reconciliation only the targets which haven't succeeded yet are fired again.
+// The callbacks are made with the payload of fired, which is the result with its payload loaded.
+func (c *Connector) processFanout(fanout *store.FanoutCallback, fired, result store.Schedule, app store.App, isReconciliation bool) {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	statuses := make(map[string]store.TargetStatus, len(fanout.Targets))
+
+	for _, target := range fanout.Targets {
+		previous, found := result.TargetStatuses[target.Name]
+		if isReconciliation && found && previous.Status == store.Success {
+			statuses[target.Name] = previous
+			continue
+		}
+
+		wg.Add(1)
+		go func(target store.Target, previous store.TargetStatus) {
+			defer wg.Done()
+
+			input := fired
+			input.Callback = target.Callback()
+
+			var attempts int
+			response, err := c.recordTiming(func() (response *http.Response, err error) {
+				response, attempts, err = c.retryPost(input, app)
+				return response, err
+			}, result.AppId, result.PartitionId)
+
+			status, errorMessage := c.callbackStatus(response, err, input)
+			glog.Infof("Callback to target %s of schedule id %s finished with status %s", target.Name, result.ScheduleId.String(), status)
+
+			lock.Lock()
+			statuses[target.Name] = store.TargetStatus{
+				Status:       status,
+				Attempts:     previous.Attempts + attempts,
+				ErrorMessage: errorMessage,
+			}
+			lock.Unlock()
+		}(target, previous)
+	}
+
+	wg.Wait()
+
+	result.TargetStatuses = statuses
+	result.Status = store.AggregateStatus(statuses)
+	result.ErrorMessage = trim(fanoutErrorMessage(statuses))
+
+	c.finishCallback(result, app, isReconciliation)
+}
+
+// fanoutErrorMessage joins the error messages of the failed targets
+func fanoutErrorMessage(statuses map[string]store.TargetStatus) string {
+	var messages []string
+	for name, status := range statuses {
+		if status.Status != store.Success {
+			messages = append(messages, name+": "+status.ErrorMessage)
+		}
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
+
+// callbackStatus finds the status and error message of a callback from its response
+func (c *Connector) callbackStatus(response *http.Response, err error, result store.Schedule) (store.Status, string) {
 	if err != nil {
 		c.recordHTTPCallback(result.AppId, result.PartitionId, constants.Fail)
 		glog.Errorf("Callback failed for schedule id %s with error %s", result.ScheduleId.String(), err.Error())
 
-		result.Status = store.Failure
-		result.ErrorMessage = trim(err.Error())
+		return store.Failure, trim(err.Error())
 	} else if !isSuccess(response) {
 		c.recordHTTPCallback(result.AppId, result.PartitionId, constants.Fail)
 		glog.Errorf("Callback failed for schedule id %s with response %+v", result.ScheduleId.String(), response)
 
-		result.Status = store.Failure
-		result.ErrorMessage = trim(response.Status)
-	} else {
-		c.recordHTTPCallback(result.AppId, result.PartitionId, constants.Success)
-		glog.Infof("Callback success for schedule id %s with response %+v", result.ScheduleId.String(), response)
-
-		result.Status = store.Success
-		result.ErrorMessage = ""
+		if response == nil {
+			return store.Failure, "no response received"
+		}
+		return store.Failure, trim(response.Status)
 	}
 
+	c.recordHTTPCallback(result.AppId, result.PartitionId, constants.Success)
+	glog.Infof("Callback success for schedule id %s with response %+v", result.ScheduleId.String(), response)
+
+	return store.Success, ""
+}
+
+// handleCallbackResult processes the result of a callback, updating the schedule status and sending the updated ScheduleWrapper to the AggregationTaskQueue
+func (c *Connector) handleCallbackResult(response *http.Response, err error, result store.Schedule, app store.App, isReconciliation bool) {
+	result.Status, result.ErrorMessage = c.callbackStatus(response, err, result)
+	c.finishCallback(result, app, isReconciliation)
+}
+
+// finishCallback creates the next schedule of the chain, records the reconciliation history if required and sends the updated ScheduleWrapper to the AggregationTaskQueue
+func (c *Connector) finishCallback(result store.Schedule, app store.App, isReconciliation bool) {
+	c.materializeChain(&result, app)
+
 	if isReconciliation {
 		result.UpdateReconciliationHistory(result.Status, result.ErrorMessage)
 	}
@@ -185,15 +324,113 @@ func (c *Connector) handleCallbackResult(response *http.Response, err error, res
 	}
 }
 
+// materializeChain creates the onSuccess or onFailure schedule of a chained schedule based on its status.
+// The id of the created schedule is recorded on the fired schedule so that it is created only once.
+func (c *Connector) materializeChain(result *store.Schedule, app store.App) {
+	if result.NextScheduleId != "" {
+		return
+	}
+
+	template := result.NextChainTemplate()
+	if template == nil {
+		return
+	}
+
+	next, err := template.Materialize(*result, time.Now())
+	if err != nil {
+		glog.Errorf("Creating next schedule of chain for schedule id %s failed with error %s", result.ScheduleId.String(), err.Error())
+		return
+	}
+
+	next.SetFields(app)
+	next.ApplyJitter(app, c.Config.AppLevelConfiguration.DefaultJitter)
+	if err = c.copyPayload(&next, app); err != nil {
+		glog.Errorf("Copying payload of next schedule of chain for schedule id %s failed with error %s", result.ScheduleId.String(), err.Error())
+		return
+	}
+	if errs := next.ValidateSchedule(app, c.Config.AppLevelConfiguration); len(errs) != 0 {
+		glog.Errorf("Validation failed for next schedule of chain for schedule id %s with errors %v", result.ScheduleId.String(), errs)
+		return
+	}
+
+	if next, err = c.ScheduleDao.CreateSchedule(next, app); err != nil {
+		glog.Errorf("Creation failed for next schedule of chain for schedule id %s with error %s", result.ScheduleId.String(), err.Error())
+		return
+	}
+
+	glog.Infof("Created schedule %s of chain for schedule id %s with status %s", next.ScheduleId.String(), result.ScheduleId.String(), result.Status)
+	result.NextScheduleId = next.ScheduleId.String()
+	c.updateUsage(next, 1)
+}
+
+// copyPayload gives the next schedule of a chain its own copy of an offloaded payload inherited from the previous schedule,
+// since the payload of the previous schedule expires along with it.
+func (c *Connector) copyPayload(next *store.Schedule, app store.App) error {
+	if !payload.IsReference(next.Payload) {
+		return nil
+	}
+
+	data, err := c.Payloads.Resolve(next.Payload)
+	if err != nil {
+		return err
+	}
+
+	bufferTTL := app.GetBufferTTL(c.Config.AppLevelConfiguration.FiredScheduleRetentionPeriod)
+	expiresAt := time.Unix(next.ScheduleTime, 0).Add(time.Duration(bufferTTL) * time.Second)
+	next.Payload, _, err = c.Payloads.Offload(next.ScheduleId, data, expiresAt)
+	return err
+}
+
+// updateUsage adds delta to the usage of the quota the schedule is counted against.
+// One time schedules stop counting against the pending quota once they are fired.
+func (c *Connector) updateUsage(schedule store.Schedule, delta int64) {
+	kind, counted := schedule.UsageKind()
+	if !counted {
+		return
+	}
+
+	if err := c.ScheduleDao.UpdateUsage(schedule.AppId, kind, delta); err != nil {
+		glog.Errorf("Updating %s usage of app %s by %d failed with error %s", kind, schedule.AppId, delta, err.Error())
+	}
+}
+
 // listen processes ScheduleWrapper items from the provided channel
-func (c *Connector) listen(buf chan store.ScheduleWrapper) {
-	for sw := range buf {
-		c.processSchedule(sw)
+func (c *Connector) listen(high, normal, low chan store.ScheduleWrapper) {
+	for {
+		c.processSchedule(nextTask(high, normal, low))
 	}
 }
 
-// retryPost attempts to execute an HTTP request according to the schedule and app provided, retrying up to the specified maximum number of attempts
-func (c *Connector) retryPost(input store.Schedule, app store.App) (*http.Response, error) {
+// nextTask returns the next schedule to be fired, preferring high over normal and normal over low priority schedules
+// whenever more than one of them is waiting
+func nextTask(high, normal, low chan store.ScheduleWrapper) store.ScheduleWrapper {
+	select {
+	case sw := <-high:
+		return sw
+	default:
+	}
+
+	select {
+	case sw := <-high:
+		return sw
+	case sw := <-normal:
+		return sw
+	default:
+	}
+
+	select {
+	case sw := <-high:
+		return sw
+	case sw := <-normal:
+		return sw
+	case sw := <-low:
+		return sw
+	}
+}
+
+// retryPost attempts to execute an HTTP request according to the schedule and app provided, retrying as per the retry policy of the app.
+// The number of attempts made is returned along with the response.
+func (c *Connector) retryPost(input store.Schedule, app store.App) (*http.Response, int, error) {
 	defer func() {
 		if r := recover(); r != nil {
 			glog.Errorf("Recovered in RetryPost from error %s with stacktrace %s", r, string(debug.Stack()))
@@ -201,39 +438,47 @@ func (c *Connector) retryPost(input store.Schedule, app store.App) (*http.Respon
 	}()
 
 	attempts := 0
-	maxAttempts := 3
+	policy := app.Configuration.GetRetryPolicy(defaultMaxAttempts)
 
 	for {
 		attempts++
 		glog.Infof("POSTING SCHEDULE %s\nATTEMPT %d ", input.ScheduleId, attempts)
-		url := input.Callback.(*store.HttpCallback).Details.Url
-		glog.Infof("URL: %s", url)
+		req, err := c.createRequest(input, app)
+		if err != nil {
+			return nil, attempts, err
+		}
 
-		req, err := createRequest(input)
+		client, err := c.Auth.Client(input, app)
 		if err != nil {
-			return nil, err
+			return nil, attempts, err
 		}
 
-		response, err := c.HttpClient.Do(req)
+		response, err := client.Do(req)
 		handleResponseDump(input, response, attempts, err)
 
-		retry := shouldRetry(maxAttempts, attempts, response)
+		// Fetch a new token on the next attempt in case the cached one was revoked
+		if response != nil && response.StatusCode == http.StatusUnauthorized {
+			c.Auth.Invalidate(input, app)
+		}
+
+		retry := shouldRetry(policy.MaxAttempts, attempts, response)
 		if retry {
 			c.recordHTTPCallback(input.AppId, input.PartitionId, constants.Retry)
+			time.Sleep(policy.Delay(attempts))
 		} else {
-			return response, err
+			return response, attempts, err
 		}
 	}
 }
 
-func (c *Connector) createWorkerPool(buf chan store.ScheduleWrapper) {
+func (c *Connector) createWorkerPool(high, normal, low chan store.ScheduleWrapper) {
 	noOfWorkers := c.Config.HttpConnector.Routines
 	for i := 0; i < noOfWorkers; i++ {
 		fmt.Printf("\nInitializing worker for *HTTP* connector %d", i)
-		go c.listen(buf)
+		go c.listen(high, normal, low)
 	}
 }
 
 func (c *Connector) initHttpWorkers() {
-	go c.createWorkerPool(store.HttpTaskQueue)
+	go c.createWorkerPool(store.HighPriorityHttpTaskQueue, store.HttpTaskQueue, store.LowPriorityHttpTaskQueue)
 }
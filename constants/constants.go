@@ -33,6 +33,16 @@ const (
 	GetScheduleRuns                          = "GetScheduleRuns"
 	GetAppSchedule                           = "GetAppSchedule"
 	GetCronSchedule                          = "GetCronSchedule"
+	GetArchivedSchedule                      = "GetArchivedSchedule"
+	GetArchivedSchedules                     = "GetArchivedSchedules"
+	ImportSchedules                          = "ImportSchedules"
+	GetLabelSchedules                        = "GetLabelSchedules"
+	DeleteLabelSchedules                     = "DeleteLabelSchedules"
+	CreateCalendar                           = "CreateCalendar"
+	ImportCalendar                           = "ImportCalendar"
+	GetCalendar                              = "GetCalendar"
+	GetCalendars                             = "GetCalendars"
+	DeleteCalendar                           = "DeleteCalendar"
 	Success                                  = "Success"
 	StatusType                               = "statusType"
 	StatusCode                               = "statusCode"
@@ -55,12 +65,20 @@ const (
 	PollerKeySep                             = "."
 	BulkAction                               = "BulkAction"
 	DefaultCallback                          = "http"
+	FanoutCallback                           = "fanout"
 	HttpResponseSuccessStatusCodeLowerBound  = 200
 	HttpResponseSuccessStatusCodeHigherBound = 299
 	CreateConfiguration                      = "CreateConfiguration"
 	GetConfiguration                         = "GetConfiguration"
 	UpdateConfiguration                      = "UpdateConfiguration"
 	DeleteConfiguration                      = "DeleteConfiguration"
+	GetConfigurationHistory                  = "GetConfigurationHistory"
+	CreateApiKey                             = "CreateApiKey"
+	RotateApiKey                             = "RotateApiKey"
+	DeleteApiKey                             = "DeleteApiKey"
+	ApiKeyHeader                             = "X-Api-Key"
+	RetryAfter                               = "Retry-After"
+	GetAppUsage                              = "GetAppUsage"
 	DCPrefix                                 = "_"
 )
 
@@ -69,6 +87,7 @@ const (
 	HttpRequestsDuration              = "http_requests_duration"
 	CallbackStatusCount               = "callback_status_count"
 	CallbackDuration                  = "callback_duration"
+	ExpiredScheduleCount              = "expired_schedule_count"
 	CreateSchedule                    = "create_schedule"
 	CreateRecurringSchedule           = "create_recurring_schedule"
 	CreateOneTimeSchedule             = "create_one_time_schedule"
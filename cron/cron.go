@@ -526,3 +526,15 @@ func (expression Expression) Match(time time.Time) bool {
 		contains(toInt64(expression.Month), int64(time.Month())) &&
 		contains(toInt64(expression.Weekday), int64(time.Weekday()))
 }
+
+// Count the number of minutes in the interval (from, to] that match the cron expression.
+func (expression Expression) Count(from, to time.Time) int {
+	count := 0
+	for t := from.Truncate(time.Minute).Add(time.Minute); !t.After(to); t = t.Add(time.Minute) {
+		if expression.Match(t) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -567,3 +567,29 @@ func TestMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestCount(t *testing.T) {
+	asTime := func(value string) time.Time {
+		t, _ := time.Parse("2006-01-02 15:04", value)
+		return t
+	}
+
+	everyFifteen, _ := Parse("*/15 * * * *")
+	hourly, _ := Parse("0 * * * *")
+
+	for _, test := range []struct {
+		Cron     Expression
+		From     time.Time
+		To       time.Time
+		Expected int
+	}{
+		{everyFifteen, asTime("2020-05-01 10:00"), asTime("2020-05-01 11:00"), 4},
+		{everyFifteen, asTime("2020-05-01 10:01"), asTime("2020-05-01 10:14"), 0},
+		{hourly, asTime("2020-05-01 10:30"), asTime("2020-05-02 10:00"), 24},
+		{hourly, asTime("2020-05-01 10:00"), asTime("2020-05-01 09:00"), 0},
+	} {
+		if count := test.Cron.Count(test.From, test.To); count != test.Expected {
+			t.Errorf("Got count %d for cron %v between %v and %v, expected %d", count, test.Cron, test.From, test.To, test.Expected)
+		}
+	}
+}
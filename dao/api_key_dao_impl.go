@@ -0,0 +1,61 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"encoding/json"
+
+	"github.com/myntra/goscheduler/store"
+)
+
+var (
+	KeyApiKeyTable    = "api_keys"
+	QueryInsertApiKey = "INSERT INTO " + KeyApiKeyTable + " (id, api_key) VALUES (?, ?)"
+	KeyApiKeyById     = "SELECT api_key FROM " + KeyApiKeyTable + " WHERE id = ?"
+	QueryDeleteApiKey = "DELETE FROM " + KeyApiKeyTable + " WHERE id = ?"
+)
+
+// InsertApiKey creates or replaces the api key with the same id
+func (c *ClusterDaoImplCassandra) InsertApiKey(key store.ApiKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	return c.Session.Query(QueryInsertApiKey, key.Id, string(data)).Exec()
+}
+
+// GetApiKey gets the api key with the given id, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplCassandra) GetApiKey(id string) (store.ApiKey, error) {
+	var data string
+	var key store.ApiKey
+
+	if err := c.Session.Query(KeyApiKeyById, id).Consistency(c.Conf.ClusterDB.DBConfig.Consistency).Scan(&data); err != nil {
+		return key, err
+	}
+
+	err := json.Unmarshal([]byte(data), &key)
+	return key, err
+}
+
+// DeleteApiKey deletes the api key with the given id
+func (c *ClusterDaoImplCassandra) DeleteApiKey(id string) error {
+	return c.Session.Query(QueryDeleteApiKey, id).Exec()
+}
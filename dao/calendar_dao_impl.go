@@ -0,0 +1,81 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"encoding/json"
+
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/store"
+)
+
+var (
+	KeyCalendarTable    = "calendars"
+	QueryInsertCalendar = "INSERT INTO " + KeyCalendarTable + " (name, calendar) VALUES (?, ?)"
+	KeyCalendarByName   = "SELECT calendar FROM " + KeyCalendarTable + " WHERE name = ?"
+	KeyGetAllCalendars  = "SELECT calendar FROM " + KeyCalendarTable
+	QueryDeleteCalendar = "DELETE FROM " + KeyCalendarTable + " WHERE name = ?"
+)
+
+// InsertCalendar creates or replaces the calendar with the same name
+func (c *ClusterDaoImplCassandra) InsertCalendar(calendar store.Calendar) error {
+	data, err := json.Marshal(calendar)
+	if err != nil {
+		return err
+	}
+
+	return c.Session.Query(QueryInsertCalendar, calendar.Name, string(data)).Exec()
+}
+
+// GetCalendar gets the calendar with the given name, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplCassandra) GetCalendar(name string) (store.Calendar, error) {
+	var data string
+	var calendar store.Calendar
+
+	if err := c.Session.Query(KeyCalendarByName, name).Consistency(c.Conf.ClusterDB.DBConfig.Consistency).Scan(&data); err != nil {
+		return calendar, err
+	}
+
+	err := json.Unmarshal([]byte(data), &calendar)
+	return calendar, err
+}
+
+// GetCalendars gets all the calendars
+func (c *ClusterDaoImplCassandra) GetCalendars() ([]store.Calendar, error) {
+	var data string
+	calendars := []store.Calendar{}
+
+	iter := c.Session.Query(KeyGetAllCalendars).Consistency(c.Conf.ClusterDB.DBConfig.Consistency).PageSize(c.Conf.ClusterDB.DBConfig.PageSize).Iter()
+	for iter.Scan(&data) {
+		var calendar store.Calendar
+		if err := json.Unmarshal([]byte(data), &calendar); err != nil {
+			glog.Errorf("Error: %s while unmarshalling calendar: %s", err.Error(), data)
+			continue
+		}
+		calendars = append(calendars, calendar)
+	}
+
+	return calendars, iter.Close()
+}
+
+// DeleteCalendar deletes the calendar with the given name
+func (c *ClusterDaoImplCassandra) DeleteCalendar(name string) error {
+	return c.Session.Query(QueryDeleteCalendar, name).Exec()
+}
@@ -39,6 +39,14 @@ type ClusterDao interface {
 	GetDCAwareApp(appName string) (store.App, error)
 	CreateConfigurations(appId string, configuration store.Configuration) (store.Configuration, error)
 	GetConfiguration(appId string) (store.Configuration, error)
-	UpdateConfiguration(appId string, configuration store.Configuration) (store.Configuration, error)
+	UpdateConfiguration(appId string, patch store.ConfigurationPatch) (store.Configuration, error)
 	DeleteConfiguration(appId string) (store.Configuration, error)
+	GetConfigurationHistory(appId string) ([]store.ConfigurationVersion, error)
+	InsertCalendar(calendar store.Calendar) error
+	GetCalendar(name string) (store.Calendar, error)
+	GetCalendars() ([]store.Calendar, error)
+	DeleteCalendar(name string) error
+	InsertApiKey(key store.ApiKey) error
+	GetApiKey(id string) (store.ApiKey, error)
+	DeleteApiKey(id string) error
 }
@@ -27,8 +27,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/gocql/gocql"
 	"github.com/golang/glog"
-	"github.com/imdario/mergo"
 	"github.com/myntra/goscheduler/cassandra"
 	e "github.com/myntra/goscheduler/cluster_entity"
 	"github.com/myntra/goscheduler/conf"
@@ -52,23 +52,26 @@ type ClusterDaoImplCassandra struct {
 
 var (
 	KeyEntityTable = "entity"
-	KeyNodeTable   = "nodes"
+	KeyNodeTable   = "entities_by_node"
 	KeyAppTable    = "apps"
 	MaxConfigApp   = "maxConfig"
 
-	KeyEntitiesOfNode       = "SELECT id, status FROM " + KeyNodeTable + " WHERE nodename='%s';"
-	KeyGetAllEntities       = "SELECT id, nodename, status, history FROM " + KeyEntityTable + ";"
-	KeyGetEntity            = "SELECT id, nodename, status, history FROM " + KeyEntityTable + " WHERE id='%s';"
-	KeyUpdateEntityInfo     = "UPDATE " + KeyEntityTable + " SET nodename='%s', status=%d, history='%s' WHERE id='%s';"
-	QueryInsertEntity       = "INSERT INTO " + KeyEntityTable + " (id, nodename, status) VALUES (?, ?, ?)"
-	QueryInsertApp          = "INSERT INTO " + KeyAppTable + " (id, partitions, active, configuration) VALUES (?, ?, ?, ?)"
-	KeyAppById              = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + " WHERE id='%s';"
-	KeyAppByIds             = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + " WHERE id in (?, ?);"
-	KeyGelAllApps           = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + ";"
-	QueryUpdateAppStatus    = "UPDATE " + KeyAppTable + " set active = %s where id='%s'"
-	QueryGetConfig          = "SELECT configuration FROM " + KeyAppTable + " WHERE id='%s';"
-	QueryUpdateConfig       = "UPDATE " + KeyAppTable + " SET configuration='%s' WHERE id='%s';"
-	KeyGetAllEntitiesForApp = "SELECT id, nodename, status, history FROM " + KeyEntityTable + " WHERE id in %s;"
+	KeyEntitiesOfNode        = "SELECT id, status FROM " + KeyNodeTable + " WHERE nodename='%s';"
+	KeyGetAllEntitiesOfNodes = "SELECT nodename, id, status FROM " + KeyNodeTable + ";"
+	KeyGetAllEntities        = "SELECT id, nodename, status, history FROM " + KeyEntityTable + ";"
+	KeyGetEntity             = "SELECT id, nodename, status, history FROM " + KeyEntityTable + " WHERE id='%s';"
+	KeyUpdateEntityInfo      = "UPDATE " + KeyEntityTable + " SET nodename='%s', status=%d, history='%s' WHERE id='%s';"
+	QueryInsertEntity        = "INSERT INTO " + KeyEntityTable + " (id, nodename, status) VALUES (?, ?, ?)"
+	QueryInsertEntityOfNode  = "INSERT INTO " + KeyNodeTable + " (nodename, id, status) VALUES (?, ?, ?)"
+	QueryDeleteEntityOfNode  = "DELETE FROM " + KeyNodeTable + " WHERE nodename = ? AND id = ?"
+	QueryInsertApp           = "INSERT INTO " + KeyAppTable + " (id, partitions, active, configuration) VALUES (?, ?, ?, ?)"
+	KeyAppById               = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + " WHERE id='%s';"
+	KeyAppByIds              = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + " WHERE id in (?, ?);"
+	KeyGelAllApps            = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + ";"
+	QueryUpdateAppStatus     = "UPDATE " + KeyAppTable + " set active = %s where id='%s'"
+	QueryGetConfig           = "SELECT configuration FROM " + KeyAppTable + " WHERE id='%s';"
+	QueryUpdateConfig        = "UPDATE " + KeyAppTable + " SET configuration=? WHERE id=?"
+	KeyGetAllEntitiesForApp  = "SELECT id, nodename, status, history FROM " + KeyEntityTable + " WHERE id in %s;"
 )
 
 // TODO: Should we make it singleton?
@@ -235,7 +238,7 @@ func (c *ClusterDaoImplCassandra) GetAllEntitiesForApp(appId string) ([]e.Entity
 	//getting app info
 	app, err := c.GetApp(appId)
 	if err != nil {
-		glog.Errorf("Error: %s while getting app info for GetAllEntitiesForApp: %+v for app: %s", err.Error(), appId)
+		glog.Errorf("Error: %s while getting app info for GetAllEntitiesForApp for app: %s", err.Error(), appId)
 		return nil, err
 	}
 
@@ -258,7 +261,7 @@ func (c *ClusterDaoImplCassandra) GetAllEntitiesForApp(appId string) ([]e.Entity
 	}
 
 	if err := iter.Close(); err != nil {
-		glog.Errorf("Error: %s while getting poller info for GetAllEntitiesForApp: %+v for app: %s", err.Error(), appId)
+		glog.Errorf("Error: %s while getting poller info for GetAllEntitiesForApp for app: %s", err.Error(), appId)
 		return nil, err
 	}
 
@@ -367,6 +370,7 @@ func (c *ClusterDaoImplCassandra) cache(app store.App) store.App {
 
 // UpdateEntityStatus updates the status of a specific entity in the Cassandra database.
 // It also updates the history of the entity status changes.
+// The entities_by_node lookup rows are moved along with the entity in a logged batch.
 func (c *ClusterDaoImplCassandra) UpdateEntityStatus(id string, nodename string, status int) error {
 	entity := c.GetEntityInfo(id)
 
@@ -383,15 +387,25 @@ func (c *ClusterDaoImplCassandra) UpdateEntityStatus(id string, nodename string,
 	}
 	query := fmt.Sprintf(KeyUpdateEntityInfo, nodename, status, history, id)
 	glog.Info(query)
-	return c.Session.Query(query).Exec()
+
+	batch := gocql.NewBatch(gocql.LoggedBatch)
+	batch.Query(query)
+	if entity.Node != "" && entity.Node != nodename {
+		batch.Query(QueryDeleteEntityOfNode, entity.Node, id)
+	}
+	batch.Query(QueryInsertEntityOfNode, nodename, id, status)
+
+	return c.Session.ExecuteBatch(batch)
 }
 
 // CreateEntity creates a new entity in the Cassandra database in a disabled state.
+// The entity and its entities_by_node lookup row are written in a logged batch.
 func (c *ClusterDaoImplCassandra) CreateEntity(entityInfo e.EntityInfo) error {
-	return c.Session.Query(QueryInsertEntity,
-		entityInfo.Id,
-		c.Conf.Cluster.Address,
-		0).Exec()
+	batch := gocql.NewBatch(gocql.LoggedBatch)
+	batch.Query(QueryInsertEntity, entityInfo.Id, c.Conf.Cluster.Address, 0)
+	batch.Query(QueryInsertEntityOfNode, c.Conf.Cluster.Address, entityInfo.Id, 0)
+
+	return c.Session.ExecuteBatch(batch)
 }
 
 // GetApps retrieves application data from the Cassandra database.
@@ -514,25 +528,22 @@ func (c *ClusterDaoImplCassandra) getDCAwareApp(appName string) (store.App, erro
 }
 
 // Create configuration for a given appId and configuration
+// ErrInvalidConfiguration is returned if the configuration fails validation
 func (c *ClusterDaoImplCassandra) CreateConfigurations(appId string, configuration store.Configuration) (store.Configuration, error) {
 	var err error
-	var query string
-	var config []byte
 
 	if appId != MaxConfigApp {
 		if err = c.ValidateConfigurations(configuration); err != nil {
-			return store.Configuration{}, err
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
 		}
 	}
 
-	if config, err = json.Marshal(configuration); err != nil {
-		return store.Configuration{}, err
+	if err = c.writeConfiguration(appId, configuration); err != nil {
+		return configuration, err
 	}
 
-	query = fmt.Sprintf(QueryUpdateConfig, string(config), appId)
-	glog.Info(query)
-
-	return configuration, c.Session.Query(query).Exec()
+	c.recordConfigurationChange(appId, ConfigurationCreated, configuration)
+	return configuration, nil
 }
 
 // Get app configurations for a given appId
@@ -555,59 +566,101 @@ func (c *ClusterDaoImplCassandra) GetConfiguration(appId string) (store.Configur
 	return configuration, nil
 }
 
-// Update the configurations for given appId and configurations
-func (c *ClusterDaoImplCassandra) UpdateConfiguration(appId string, configuration store.Configuration) (store.Configuration, error) {
+// Update the configurations for given appId with a merge patch
+// Only the fields present in the patch are changed, fields set to null are cleared, the patched configuration
+// is validated and ErrInvalidConfiguration is returned if it fails
+func (c *ClusterDaoImplCassandra) UpdateConfiguration(appId string, patch store.ConfigurationPatch) (store.Configuration, error) {
 	var err error
-	var query string
-	var config []byte
 	var existingConfig store.Configuration
 
-	if appId != MaxConfigApp {
-		if err = c.ValidateConfigurations(configuration); err != nil {
-			return store.Configuration{}, err
-		}
-	}
-
 	if existingConfig, err = c.GetConfiguration(appId); err != nil {
 		return store.Configuration{}, err
 	}
 
-	if err = mergo.Merge(&configuration, existingConfig); err != nil {
-		return store.Configuration{}, err
+	configuration, err := patch.Apply(existingConfig)
+	if err != nil {
+		return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
 	}
 
-	if config, err = json.Marshal(configuration); err != nil {
-		return configuration, nil
+	if appId != MaxConfigApp {
+		if err = c.ValidateConfigurations(configuration); err != nil {
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+		}
 	}
 
-	query = fmt.Sprintf(QueryUpdateConfig, string(config), appId)
-	glog.Info(query)
+	if err = c.writeConfiguration(appId, configuration); err != nil {
+		return configuration, err
+	}
 
-	return configuration, c.Session.Query(query).Exec()
+	c.recordConfigurationChange(appId, ConfigurationUpdated, configuration)
+	return configuration, nil
 }
 
 // Delete the configurations for a given appId
 func (c *ClusterDaoImplCassandra) DeleteConfiguration(appId string) (store.Configuration, error) {
-	var query string
+	if err := c.writeConfiguration(appId, store.Configuration{}); err != nil {
+		return store.Configuration{}, err
+	}
 
-	// empty config
-	config, _ := json.Marshal(store.Configuration{})
+	c.recordConfigurationChange(appId, ConfigurationDeleted, store.Configuration{})
+	return store.Configuration{}, nil
+}
 
-	query = fmt.Sprintf(QueryUpdateConfig, string(config), appId)
-	glog.Info(query)
+// writeConfiguration replaces the configuration of the app.
+// The configuration holds free-form strings, like callback hosts and auth profiles, so it is always bound and never logged.
+func (c *ClusterDaoImplCassandra) writeConfiguration(appId string, configuration store.Configuration) error {
+	config, err := json.Marshal(configuration)
+	if err != nil {
+		return err
+	}
 
-	return store.Configuration{}, c.Session.Query(query).Exec()
+	return c.Session.Query(QueryUpdateConfig, string(config), appId).Exec()
+}
+
+// recordConfigurationChange appends a configuration which was written to the history of the app.
+// The configuration is in effect by then, so a failure is only logged and the caller still gets the nodes to reload the app.
+func (c *ClusterDaoImplCassandra) recordConfigurationChange(appId string, operation string, configuration store.Configuration) {
+	if err := c.recordConfigurationVersion(appId, operation, configuration); err != nil {
+		glog.Errorf("Recording the %s of the configuration of app %s in its history failed with error %s", operation, appId, err.Error())
+	}
 }
 
 // App configurations are validated against max configs
 func (c *ClusterDaoImplCassandra) ValidateConfigurations(config store.Configuration) error {
+	return validateConfigurations(config, c)
+}
+
+// Validate the configuration of an app and check it against the max configs of the cluster
+func validateConfigurations(config store.Configuration, c ClusterDao) error {
 	var app store.App
 	var err error
 
-	if config == (store.Configuration{}) {
+	if config.IsEmpty() {
 		return nil
 	}
 
+	for name, profile := range config.AuthProfiles {
+		if err = profile.Validate(); err != nil {
+			return errors.New(fmt.Sprintf("invalid auth profile %s: %s", name, err.Error()))
+		}
+	}
+
+	if err = config.DedupPolicy.Validate(); err != nil {
+		return err
+	}
+
+	if err = store.ValidateJitter(config.Jitter); err != nil {
+		return err
+	}
+
+	if err = config.Priority.Validate(); err != nil {
+		return err
+	}
+
+	if err = config.ValidatePolicies(); err != nil {
+		return err
+	}
+
 	if app, err = c.GetApp(MaxConfigApp); err != nil {
 		return err
 	}
@@ -626,3 +679,51 @@ func (c *ClusterDaoImplCassandra) ValidateConfigurations(config store.Configurat
 
 	return nil
 }
+
+// Create the maxConfig app and the app of the cron schedules if they do not exist,
+// so that a single node starts on a fresh store without any app being registered from outside.
+func createDefaultApps(c ClusterDao, config *conf.Configuration) {
+	if _, err := c.GetApp(MaxConfigApp); err == gocql.ErrNotFound {
+		maxConfigApp := store.App{
+			AppId:      MaxConfigApp,
+			Partitions: 0,
+			Active:     false,
+			Configuration: store.Configuration{
+				FutureScheduleCreationPeriod: config.AppLevelConfiguration.FutureScheduleCreationPeriod,
+				FiredScheduleRetentionPeriod: config.AppLevelConfiguration.FiredScheduleRetentionPeriod,
+				PayloadSize:                  config.AppLevelConfiguration.PayloadSize,
+				HttpRetries:                  config.AppLevelConfiguration.HttpRetries,
+				HttpTimeout:                  config.AppLevelConfiguration.HttpTimeout,
+			},
+		}
+
+		if err = c.InsertApp(maxConfigApp); err != nil {
+			glog.Fatal(err)
+		}
+		glog.Info("maxConfig app created!")
+	} else if err != nil {
+		glog.Fatal(err)
+	}
+
+	if _, err := c.GetApp(config.CronConfig.App); err == gocql.ErrNotFound {
+		cronApp := store.App{
+			AppId:      config.CronConfig.App,
+			Partitions: config.Poller.DefaultCount,
+			Active:     true,
+		}
+
+		if err = c.InsertApp(cronApp); err != nil {
+			glog.Fatal(err)
+		}
+
+		for partition := uint32(0); partition < cronApp.Partitions; partition++ {
+			entity := e.EntityInfo{Id: cronApp.AppId + constants.PollerKeySep + strconv.Itoa(int(partition))}
+			if err = c.CreateEntity(entity); err != nil {
+				glog.Fatal(err)
+			}
+		}
+		glog.Info("Cron app created!")
+	} else if err != nil {
+		glog.Fatal(err)
+	}
+}
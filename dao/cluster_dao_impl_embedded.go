@@ -0,0 +1,529 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	e "github.com/myntra/goscheduler/cluster_entity"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/embedded"
+	p "github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ClusterDaoImplEmbedded keeps the apps, the entities and the cluster wide configuration in an embedded database file.
+type ClusterDaoImplEmbedded struct {
+	DB      *bolt.DB
+	AppMap  AppMap
+	Conf    *conf.Configuration
+	Monitor p.Monitor
+}
+
+func GetClusterDaoImplEmbedded(conf *conf.Configuration, monitor p.Monitor) *ClusterDaoImplEmbedded {
+	db, err := embedded.GetDB(conf.Storage.Embedded)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Embedded database initialisation failed for path %s with error %s", conf.Storage.Embedded.Path, err.Error()))
+		panic(err)
+	}
+
+	dao := NewClusterDaoImplEmbedded(db, conf, monitor)
+	createDefaultApps(dao, conf)
+	return dao
+}
+
+// NewClusterDaoImplEmbedded creates the DAO over an open database.
+func NewClusterDaoImplEmbedded(db *bolt.DB, conf *conf.Configuration, monitor p.Monitor) *ClusterDaoImplEmbedded {
+	return &ClusterDaoImplEmbedded{
+		DB:   db,
+		Conf: conf,
+		AppMap: AppMap{
+			lock: sync.RWMutex{},
+			m:    make(map[string]store.App),
+		},
+		Monitor: monitor,
+	}
+}
+
+// Get the value stored under the key of the bucket, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplEmbedded) get(bucket []byte, key string, value interface{}) error {
+	return c.DB.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get([]byte(key))
+		if data == nil {
+			return gocql.ErrNotFound
+		}
+		return json.Unmarshal(data, value)
+	})
+}
+
+// Store the value under the key of the bucket
+func (c *ClusterDaoImplEmbedded) put(bucket []byte, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(key), data)
+	})
+}
+
+// Delete the value stored under the key of the bucket
+func (c *ClusterDaoImplEmbedded) delete(bucket []byte, key string) error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(key))
+	})
+}
+
+// Get the entities passing the filter
+func (c *ClusterDaoImplEmbedded) getEntities(filter func(entity e.EntityInfo) bool) ([]e.EntityInfo, error) {
+	var entities []e.EntityInfo
+
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(embedded.EntitiesBucket).ForEach(func(k, v []byte) error {
+			var entity e.EntityInfo
+			if err := json.Unmarshal(v, &entity); err != nil {
+				return err
+			}
+			if filter(entity) {
+				entities = append(entities, entity)
+			}
+			return nil
+		})
+	})
+
+	return entities, err
+}
+
+// Get all the entities assigned to the node
+// Raise a fatal exception in case there is an exception getting all entities
+func (c *ClusterDaoImplEmbedded) GetAllEntitiesInfoOfNode(nodeName string) []e.EntityInfo {
+	entities, err := c.getEntities(func(entity e.EntityInfo) bool {
+		return entity.Node == nodeName
+	})
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	glog.Infof("GetAllEntitiesInfoOfNode result is : %+v", entities)
+	return entities
+}
+
+// Get all the entities
+// Raise a fatal exception in case there is an exception getting all entities
+func (c *ClusterDaoImplEmbedded) GetAllEntitiesInfo() []e.EntityInfo {
+	entities, err := c.getEntities(func(entity e.EntityInfo) bool {
+		return true
+	})
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	glog.Infof("GetAllEntitiesInfo result is : %+v", entities)
+	return entities
+}
+
+// Get all the entities of single app
+func (c *ClusterDaoImplEmbedded) GetAllEntitiesForApp(appId string) ([]e.EntityInfo, error) {
+	app, err := c.GetApp(appId)
+	if err != nil {
+		glog.Errorf("Error: %s while getting app info for GetAllEntitiesForApp for app: %s", err.Error(), appId)
+		return nil, err
+	}
+
+	var entities []e.EntityInfo
+	for i := uint32(0); i < app.Partitions; i++ {
+		var entity e.EntityInfo
+		err := c.get(embedded.EntitiesBucket, fmt.Sprintf("%v.%d", app.AppId, i), &entity)
+		switch {
+		case err == gocql.ErrNotFound:
+			continue
+		case err != nil:
+			glog.Errorf("Error: %s while getting poller info for GetAllEntitiesForApp for app: %s", err.Error(), appId)
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+
+	glog.Infof("GetAllEntitiesInfo result is : %+v", entities)
+	return entities, nil
+}
+
+// Get entity by id, an empty entity is returned if there is none
+func (c *ClusterDaoImplEmbedded) GetEntityInfo(id string) e.EntityInfo {
+	var entity e.EntityInfo
+
+	if err := c.get(embedded.EntitiesBucket, id, &entity); err != nil {
+		glog.Errorf("Error %s while getting entity %s", err.Error(), id)
+		return e.EntityInfo{}
+	}
+
+	glog.Infof("GetEntityInfo result for %s is : %+v", id, entity)
+	return entity
+}
+
+// UpdateEntityStatus updates the status of a specific entity along with the history of its status changes.
+func (c *ClusterDaoImplEmbedded) UpdateEntityStatus(id string, nodename string, status int) error {
+	entity := c.GetEntityInfo(id)
+
+	history := ""
+
+	if entity.History != "" || entity.Node != "" {
+		toAppend := "{" + entity.Node + ":" + strconv.Itoa(entity.Status) + "}"
+		history = entity.History + "->" + toAppend
+	}
+
+	historyLen := len(history)
+	if historyLen > c.Conf.ClusterDB.EntityHistorySize {
+		history = history[historyLen-c.Conf.ClusterDB.EntityHistorySize : historyLen]
+	}
+
+	return c.put(embedded.EntitiesBucket, id, e.EntityInfo{Id: id, Node: nodename, Status: status, History: history})
+}
+
+// CreateEntity creates a new entity in a disabled state.
+// The history of an existing entity is kept.
+func (c *ClusterDaoImplEmbedded) CreateEntity(entityInfo e.EntityInfo) error {
+	var entity e.EntityInfo
+	if err := c.get(embedded.EntitiesBucket, entityInfo.Id, &entity); err != nil && err != gocql.ErrNotFound {
+		return err
+	}
+
+	return c.put(embedded.EntitiesBucket, entityInfo.Id, e.EntityInfo{Id: entityInfo.Id, Node: c.Conf.Cluster.Address, History: entity.History})
+}
+
+// By default if Active parameter is not set it will create the app in deactivated state
+func (c *ClusterDaoImplEmbedded) InsertApp(app store.App) error {
+	if app.AppId != MaxConfigApp {
+		if err := c.ValidateConfigurations(app.Configuration); err != nil {
+			return err
+		}
+	}
+
+	return c.put(embedded.AppsBucket, app.AppId, app)
+}
+
+func (c *ClusterDaoImplEmbedded) GetApp(appName string) (store.App, error) {
+	if app, found := c.check(appName); found {
+		return app, nil
+	}
+	return c.getApp(appName)
+}
+
+// getApp gets specified app from DB and puts into in memory cache
+// gocql.ErrNotFound is returned if there is no such app
+func (c *ClusterDaoImplEmbedded) getApp(appName string) (store.App, error) {
+	var app store.App
+
+	if err := c.get(embedded.AppsBucket, appName, &app); err != nil {
+		if err != gocql.ErrNotFound {
+			glog.Errorf("Error %s while getting app %s", err.Error(), appName)
+		}
+		return store.App{}, err
+	}
+
+	return c.cache(app), nil
+}
+
+// Checks whether app is found in in-memory cache or not
+func (c *ClusterDaoImplEmbedded) check(appName string) (store.App, bool) {
+	c.AppMap.lock.RLock()
+	app, found := c.AppMap.m[appName]
+	c.AppMap.lock.RUnlock()
+
+	return app, found
+}
+
+// cache adds the app in in-memory cache
+func (c *ClusterDaoImplEmbedded) cache(app store.App) store.App {
+	c.AppMap.lock.Lock()
+	c.AppMap.m[app.AppId] = app
+	c.AppMap.lock.Unlock()
+	return app
+}
+
+// GetApps returns the app with the given id, or all the apps if the id is empty.
+func (c *ClusterDaoImplEmbedded) GetApps(appId string) ([]store.App, error) {
+	if appId != "" {
+		app, err := c.getApp(appId)
+		if err != nil {
+			return nil, err
+		}
+		return []store.App{app}, nil
+	}
+
+	var apps []store.App
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(embedded.AppsBucket).ForEach(func(k, v []byte) error {
+			var app store.App
+			if err := json.Unmarshal(v, &app); err != nil {
+				glog.Errorf("Error: %s while unmarshalling app: %s", err.Error(), string(k))
+				return nil
+			}
+			apps = append(apps, app)
+			return nil
+		})
+	})
+	if err != nil {
+		return apps, err
+	}
+
+	glog.V(constants.INFO).Infof("Get all apps result is : +%v", apps)
+	return apps, nil
+}
+
+// Read the app stored under the id in the transaction and write it back once changed
+func updateApp(tx *bolt.Tx, appId string, change func(app *store.App)) error {
+	apps := tx.Bucket(embedded.AppsBucket)
+
+	app := store.App{AppId: appId}
+	if data := apps.Get([]byte(appId)); data != nil {
+		if err := json.Unmarshal(data, &app); err != nil {
+			return err
+		}
+	}
+
+	change(&app)
+
+	data, err := json.Marshal(app)
+	if err != nil {
+		return err
+	}
+	return apps.Put([]byte(appId), data)
+}
+
+// UpdateAppActiveStatus updates the active status of an application.
+func (c *ClusterDaoImplEmbedded) UpdateAppActiveStatus(appName string, activeStatus bool) error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		return updateApp(tx, appName, func(app *store.App) {
+			app.Active = activeStatus
+		})
+	})
+}
+
+// InvalidateSingleAppCache removes a specific app from the AppMap cache.
+func (c *ClusterDaoImplEmbedded) InvalidateSingleAppCache(appName string) {
+	c.AppMap.lock.Lock()
+	delete(c.AppMap.m, appName)
+	c.AppMap.lock.Unlock()
+}
+
+func (c *ClusterDaoImplEmbedded) GetDCAwareApp(appName string) (store.App, error) {
+	c.AppMap.lock.RLock()
+	dcPrefixedApp, dcPrefixedFound := c.AppMap.m[c.Conf.DCConfig.Prefix+appName]
+	fallbackApp, fallbackFound := c.AppMap.m[appName]
+	c.AppMap.lock.RUnlock()
+
+	if dcPrefixedFound {
+		return dcPrefixedApp, nil
+	} else if fallbackFound {
+		return fallbackApp, nil
+	}
+
+	return c.getDCAwareApp(appName)
+}
+
+// get DC aware app from DB, preferring the app prefixed with the DC over the app itself
+func (c *ClusterDaoImplEmbedded) getDCAwareApp(appName string) (store.App, error) {
+	dcPrefixedAppName := c.Conf.DCConfig.Prefix + constants.DCPrefix + appName
+
+	for _, name := range []string{dcPrefixedAppName, appName} {
+		app, err := c.getApp(name)
+		switch {
+		case err == gocql.ErrNotFound:
+			continue
+		case err != nil:
+			glog.Infof("Error occurred while fetching apps: %+v", err)
+			return store.App{}, err
+		}
+		return app, nil
+	}
+	return store.App{}, nil
+}
+
+// Write the configuration of the app and append it to its history in a single transaction
+// The version of the change is the one following the latest version of the history of the app.
+func (c *ClusterDaoImplEmbedded) writeConfiguration(appId string, operation string, configuration store.Configuration) error {
+	return c.DB.Update(func(tx *bolt.Tx) error {
+		err := updateApp(tx, appId, func(app *store.App) {
+			app.Configuration = configuration
+		})
+		if err != nil {
+			return err
+		}
+
+		prefix := joinKey(appId, "")
+		version := uint32(1)
+
+		cursor := tx.Bucket(embedded.ConfigurationHistoryBucket).Cursor()
+		if k, _ := seekBefore(cursor, prefix, nil); k != nil && bytes.HasPrefix(k, prefix) {
+			version = binary.BigEndian.Uint32(k[len(prefix):]) + 1
+		}
+
+		data, err := json.Marshal(store.ConfigurationVersion{
+			Version:       int(version),
+			Operation:     operation,
+			Configuration: configuration,
+			UpdatedAt:     time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.Bucket(embedded.ConfigurationHistoryBucket).Put(appendUint32(prefix, version), data)
+	})
+}
+
+// Create configuration for a given appId and configuration
+// ErrInvalidConfiguration is returned if the configuration fails validation
+func (c *ClusterDaoImplEmbedded) CreateConfigurations(appId string, configuration store.Configuration) (store.Configuration, error) {
+	if appId != MaxConfigApp {
+		if err := c.ValidateConfigurations(configuration); err != nil {
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+		}
+	}
+
+	return configuration, c.writeConfiguration(appId, ConfigurationCreated, configuration)
+}
+
+// Get app configurations for a given appId
+func (c *ClusterDaoImplEmbedded) GetConfiguration(appId string) (store.Configuration, error) {
+	var app store.App
+	err := c.get(embedded.AppsBucket, appId, &app)
+	return app.Configuration, err
+}
+
+// Update the configurations for given appId with a merge patch
+// Only the fields present in the patch are changed, fields set to null are cleared, the patched configuration
+// is validated and ErrInvalidConfiguration is returned if it fails
+func (c *ClusterDaoImplEmbedded) UpdateConfiguration(appId string, patch store.ConfigurationPatch) (store.Configuration, error) {
+	existingConfig, err := c.GetConfiguration(appId)
+	if err != nil {
+		return store.Configuration{}, err
+	}
+
+	configuration, err := patch.Apply(existingConfig)
+	if err != nil {
+		return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+	}
+
+	if appId != MaxConfigApp {
+		if err = c.ValidateConfigurations(configuration); err != nil {
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+		}
+	}
+
+	return configuration, c.writeConfiguration(appId, ConfigurationUpdated, configuration)
+}
+
+// Delete the configurations for a given appId
+func (c *ClusterDaoImplEmbedded) DeleteConfiguration(appId string) (store.Configuration, error) {
+	return store.Configuration{}, c.writeConfiguration(appId, ConfigurationDeleted, store.Configuration{})
+}
+
+// GetConfigurationHistory gets the configuration changes of an app, latest first
+func (c *ClusterDaoImplEmbedded) GetConfigurationHistory(appId string) ([]store.ConfigurationVersion, error) {
+	history := []store.ConfigurationVersion{}
+
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		prefix := joinKey(appId, "")
+		cursor := tx.Bucket(embedded.ConfigurationHistoryBucket).Cursor()
+
+		for k, v := seekBefore(cursor, prefix, nil); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Prev() {
+			var version store.ConfigurationVersion
+			if err := json.Unmarshal(v, &version); err != nil {
+				glog.Errorf("Error: %s while unmarshalling version %d of the configuration of app %s", err.Error(), binary.BigEndian.Uint32(k[len(prefix):]), appId)
+				continue
+			}
+			history = append(history, version)
+		}
+		return nil
+	})
+
+	return history, err
+}
+
+// App configurations are validated against max configs
+func (c *ClusterDaoImplEmbedded) ValidateConfigurations(config store.Configuration) error {
+	return validateConfigurations(config, c)
+}
+
+// InsertCalendar creates or replaces the calendar with the same name
+func (c *ClusterDaoImplEmbedded) InsertCalendar(calendar store.Calendar) error {
+	return c.put(embedded.CalendarsBucket, calendar.Name, calendar)
+}
+
+// GetCalendar gets the calendar with the given name, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplEmbedded) GetCalendar(name string) (store.Calendar, error) {
+	var calendar store.Calendar
+	err := c.get(embedded.CalendarsBucket, name, &calendar)
+	return calendar, err
+}
+
+// GetCalendars gets all the calendars, sorted by name
+func (c *ClusterDaoImplEmbedded) GetCalendars() ([]store.Calendar, error) {
+	calendars := []store.Calendar{}
+
+	err := c.DB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(embedded.CalendarsBucket).ForEach(func(k, v []byte) error {
+			var calendar store.Calendar
+			if err := json.Unmarshal(v, &calendar); err != nil {
+				glog.Errorf("Error: %s while unmarshalling calendar: %s", err.Error(), string(v))
+				return nil
+			}
+			calendars = append(calendars, calendar)
+			return nil
+		})
+	})
+
+	return calendars, err
+}
+
+// DeleteCalendar deletes the calendar with the given name
+func (c *ClusterDaoImplEmbedded) DeleteCalendar(name string) error {
+	return c.delete(embedded.CalendarsBucket, name)
+}
+
+// InsertApiKey creates or replaces the api key with the same id
+func (c *ClusterDaoImplEmbedded) InsertApiKey(key store.ApiKey) error {
+	return c.put(embedded.ApiKeysBucket, key.Id, key)
+}
+
+// GetApiKey gets the api key with the given id, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplEmbedded) GetApiKey(id string) (store.ApiKey, error) {
+	var key store.ApiKey
+	err := c.get(embedded.ApiKeysBucket, id, &key)
+	return key, err
+}
+
+// DeleteApiKey deletes the api key with the given id
+func (c *ClusterDaoImplEmbedded) DeleteApiKey(id string) error {
+	return c.delete(embedded.ApiKeysBucket, id)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+	e "github.com/myntra/goscheduler/cluster_entity"
+	"github.com/myntra/goscheduler/conf"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupEmbeddedClusterDao(t *testing.T) *ClusterDaoImplEmbedded {
+	db, config := setupEmbeddedDB(t)
+	config.ClusterDB = conf.ClusterDBConfig{EntityHistorySize: 20}
+	config.Cluster = conf.ClusterConfig{Address: "127.0.0.1:9091"}
+	config.CronConfig = conf.CronConfig{App: "Athena"}
+	config.Poller = conf.PollerConfig{DefaultCount: 2}
+	config.AppLevelConfiguration = conf.AppLevelConfiguration{PayloadSize: 1000, HttpRetries: 5, HttpTimeout: 1000}
+
+	return NewClusterDaoImplEmbedded(db, config, nil)
+}
+
+func TestClusterDaoImplEmbedded_CreateDefaultApps(t *testing.T) {
+	dao := setupEmbeddedClusterDao(t)
+	createDefaultApps(dao, dao.Conf)
+
+	maxConfig, err := dao.GetApp(MaxConfigApp)
+	assert.Nil(t, err)
+	assert.Equal(t, s.Configuration{PayloadSize: 1000, HttpRetries: 5, HttpTimeout: 1000}, maxConfig.Configuration)
+
+	cronApp, err := dao.GetApp("Athena")
+	assert.Nil(t, err)
+	assert.True(t, cronApp.Active)
+
+	entities, err := dao.GetAllEntitiesForApp("Athena")
+	assert.Nil(t, err)
+	assert.Equal(t, []e.EntityInfo{
+		{Id: "Athena.0", Node: "127.0.0.1:9091"},
+		{Id: "Athena.1", Node: "127.0.0.1:9091"},
+	}, entities)
+}
+
+func TestClusterDaoImplEmbedded_GetApp(t *testing.T) {
+	dao := setupEmbeddedClusterDao(t)
+	createDefaultApps(dao, dao.Conf)
+
+	app := s.App{AppId: "Test", Partitions: 2, Active: true, Configuration: s.Configuration{PayloadSize: 100}}
+	assert.Nil(t, dao.InsertApp(app))
+
+	found, err := dao.GetApp("Test")
+	assert.Nil(t, err)
+	assert.Equal(t, app, found)
+
+	assert.Nil(t, dao.UpdateAppActiveStatus("Test", false))
+	apps, err := dao.GetApps("Test")
+	assert.Nil(t, err)
+	assert.False(t, apps[0].Active)
+
+	_, err = dao.GetApp("Unknown")
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
+
+func TestClusterDaoImplEmbedded_UpdateEntityStatus(t *testing.T) {
+	dao := setupEmbeddedClusterDao(t)
+
+	assert.Nil(t, dao.UpdateEntityStatus("Test.0", "node1", 1))
+	assert.Nil(t, dao.UpdateEntityStatus("Test.0", "node2", 1))
+
+	assert.Equal(t, e.EntityInfo{Id: "Test.0", Node: "node2", Status: 1, History: "->{node1:1}"}, dao.GetEntityInfo("Test.0"))
+	assert.Equal(t, []e.EntityInfo{{Id: "Test.0", Node: "node2", Status: 1, History: "->{node1:1}"}}, dao.GetAllEntitiesInfoOfNode("node2"))
+	assert.Empty(t, dao.GetAllEntitiesInfoOfNode("node1"))
+}
+
+func TestClusterDaoImplEmbedded_UpdateConfiguration(t *testing.T) {
+	dao := setupEmbeddedClusterDao(t)
+	createDefaultApps(dao, dao.Conf)
+
+	_, err := dao.CreateConfigurations("Test", s.Configuration{PayloadSize: 100, HttpRetries: 2})
+	assert.Nil(t, err)
+
+	configuration, err := dao.UpdateConfiguration("Test", s.PatchOf(s.Configuration{PayloadSize: 200}))
+	assert.Nil(t, err)
+	assert.Equal(t, s.Configuration{PayloadSize: 200, HttpRetries: 2}, configuration)
+
+	// Invalid configurations are not written
+	_, err = dao.UpdateConfiguration("Test", s.PatchOf(s.Configuration{PayloadSize: 2000}))
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+
+	history, err := dao.GetConfigurationHistory("Test")
+	assert.Nil(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, 2, history[0].Version)
+	assert.Equal(t, ConfigurationUpdated, history[0].Operation)
+	assert.Equal(t, ConfigurationCreated, history[1].Operation)
+}
+
+func TestClusterDaoImplEmbedded_GetCalendar(t *testing.T) {
+	dao := setupEmbeddedClusterDao(t)
+
+	_, err := dao.GetCalendar("holidays")
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	assert.Nil(t, dao.InsertCalendar(s.Calendar{Name: "holidays"}))
+	calendars, err := dao.GetCalendars()
+	assert.Nil(t, err)
+	assert.Equal(t, []s.Calendar{{Name: "holidays"}}, calendars)
+
+	assert.Nil(t, dao.DeleteCalendar("holidays"))
+	_, err = dao.GetCalendar("holidays")
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	_, err = dao.GetApiKey("key")
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
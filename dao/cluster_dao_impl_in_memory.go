@@ -0,0 +1,388 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	e "github.com/myntra/goscheduler/cluster_entity"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/store"
+)
+
+// ClusterDaoImplInMemory keeps the apps, the entities and the cluster wide configuration in memory,
+// for tests exercising the flows built on the DAO.
+// The maxConfig app and the app of the cron schedules are created along with the DAO, like the embedded backend does.
+type ClusterDaoImplInMemory struct {
+	Conf *conf.Configuration
+
+	lock      sync.RWMutex
+	apps      map[string]store.App
+	entities  map[string]e.EntityInfo
+	history   map[string][]store.ConfigurationVersion
+	calendars map[string]store.Calendar
+	apiKeys   map[string]store.ApiKey
+}
+
+func NewClusterDaoImplInMemory(conf *conf.Configuration) *ClusterDaoImplInMemory {
+	dao := &ClusterDaoImplInMemory{
+		Conf:      conf,
+		apps:      make(map[string]store.App),
+		entities:  make(map[string]e.EntityInfo),
+		history:   make(map[string][]store.ConfigurationVersion),
+		calendars: make(map[string]store.Calendar),
+		apiKeys:   make(map[string]store.ApiKey),
+	}
+
+	createDefaultApps(dao, conf)
+	return dao
+}
+
+// Get the entities passing the filter, sorted by id
+func (c *ClusterDaoImplInMemory) getEntities(filter func(entity e.EntityInfo) bool) []e.EntityInfo {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var entities []e.EntityInfo
+	for _, entity := range c.entities {
+		if filter(entity) {
+			entities = append(entities, entity)
+		}
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].Id < entities[j].Id
+	})
+	return entities
+}
+
+// Get all the entities assigned to the node
+func (c *ClusterDaoImplInMemory) GetAllEntitiesInfoOfNode(nodeName string) []e.EntityInfo {
+	return c.getEntities(func(entity e.EntityInfo) bool {
+		return entity.Node == nodeName
+	})
+}
+
+// Get all the entities
+func (c *ClusterDaoImplInMemory) GetAllEntitiesInfo() []e.EntityInfo {
+	return c.getEntities(func(entity e.EntityInfo) bool {
+		return true
+	})
+}
+
+// Get all the entities of single app
+func (c *ClusterDaoImplInMemory) GetAllEntitiesForApp(appId string) ([]e.EntityInfo, error) {
+	app, err := c.GetApp(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	for i := uint32(0); i < app.Partitions; i++ {
+		ids[fmt.Sprintf("%v.%d", app.AppId, i)] = true
+	}
+
+	return c.getEntities(func(entity e.EntityInfo) bool {
+		return ids[entity.Id]
+	}), nil
+}
+
+// Get entity by id, an empty entity is returned if there is none
+func (c *ClusterDaoImplInMemory) GetEntityInfo(id string) e.EntityInfo {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.entities[id]
+}
+
+// UpdateEntityStatus updates the status of a specific entity along with the history of its status changes.
+func (c *ClusterDaoImplInMemory) UpdateEntityStatus(id string, nodeName string, status int) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entity := c.entities[id]
+	history := ""
+
+	if entity.History != "" || entity.Node != "" {
+		toAppend := "{" + entity.Node + ":" + strconv.Itoa(entity.Status) + "}"
+		history = entity.History + "->" + toAppend
+	}
+
+	historyLen := len(history)
+	if historyLen > c.Conf.ClusterDB.EntityHistorySize {
+		history = history[historyLen-c.Conf.ClusterDB.EntityHistorySize : historyLen]
+	}
+
+	c.entities[id] = e.EntityInfo{Id: id, Node: nodeName, Status: status, History: history}
+	return nil
+}
+
+// CreateEntity creates a new entity in a disabled state, the history of an existing entity is kept.
+func (c *ClusterDaoImplInMemory) CreateEntity(info e.EntityInfo) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entities[info.Id] = e.EntityInfo{Id: info.Id, Node: c.Conf.Cluster.Address, History: c.entities[info.Id].History}
+	return nil
+}
+
+// Get the app with the given id, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplInMemory) GetApp(appName string) (store.App, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	app, found := c.apps[appName]
+	if !found {
+		return store.App{}, gocql.ErrNotFound
+	}
+	return app, nil
+}
+
+// Apps are read from memory on every call, so there is no cache to invalidate
+func (c *ClusterDaoImplInMemory) InvalidateSingleAppCache(appName string) {
+}
+
+// By default if Active parameter is not set it will create the app in deactivated state
+func (c *ClusterDaoImplInMemory) InsertApp(app store.App) error {
+	if app.AppId != MaxConfigApp {
+		if err := c.ValidateConfigurations(app.Configuration); err != nil {
+			return err
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.apps[app.AppId] = app
+	return nil
+}
+
+// UpdateAppActiveStatus updates the active status of an application.
+func (c *ClusterDaoImplInMemory) UpdateAppActiveStatus(appName string, activeStatus bool) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	app := c.apps[appName]
+	app.AppId = appName
+	app.Active = activeStatus
+	c.apps[appName] = app
+	return nil
+}
+
+// GetApps returns the app with the given id, or all the apps sorted by id if the id is empty.
+func (c *ClusterDaoImplInMemory) GetApps(appId string) ([]store.App, error) {
+	if appId != "" {
+		app, err := c.GetApp(appId)
+		if err != nil {
+			return nil, err
+		}
+		return []store.App{app}, nil
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var apps []store.App
+	for _, app := range c.apps {
+		apps = append(apps, app)
+	}
+
+	sort.Slice(apps, func(i, j int) bool {
+		return apps[i].AppId < apps[j].AppId
+	})
+	return apps, nil
+}
+
+// Get the app prefixed with the DC, falling back to the app itself.
+// An empty app is returned if there is neither.
+func (c *ClusterDaoImplInMemory) GetDCAwareApp(appName string) (store.App, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if app, found := c.apps[c.Conf.DCConfig.Prefix+constants.DCPrefix+appName]; found {
+		return app, nil
+	}
+	return c.apps[appName], nil
+}
+
+// Write the configuration of the app and append it to its history
+func (c *ClusterDaoImplInMemory) writeConfiguration(appId string, operation string, configuration store.Configuration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	app := c.apps[appId]
+	app.AppId = appId
+	app.Configuration = configuration
+	c.apps[appId] = app
+
+	c.history[appId] = append(c.history[appId], store.ConfigurationVersion{
+		Version:       len(c.history[appId]) + 1,
+		Operation:     operation,
+		Configuration: configuration,
+		UpdatedAt:     time.Now(),
+	})
+}
+
+// Create configuration for a given appId and configuration
+// ErrInvalidConfiguration is returned if the configuration fails validation
+func (c *ClusterDaoImplInMemory) CreateConfigurations(appId string, configuration store.Configuration) (store.Configuration, error) {
+	if appId != MaxConfigApp {
+		if err := c.ValidateConfigurations(configuration); err != nil {
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+		}
+	}
+
+	c.writeConfiguration(appId, ConfigurationCreated, configuration)
+	return configuration, nil
+}
+
+// Get app configurations for a given appId
+func (c *ClusterDaoImplInMemory) GetConfiguration(appId string) (store.Configuration, error) {
+	app, err := c.GetApp(appId)
+	return app.Configuration, err
+}
+
+// Update the configurations for given appId with a merge patch
+// Only the fields present in the patch are changed, fields set to null are cleared, the patched configuration
+// is validated and ErrInvalidConfiguration is returned if it fails
+func (c *ClusterDaoImplInMemory) UpdateConfiguration(appId string, patch store.ConfigurationPatch) (store.Configuration, error) {
+	existingConfig, err := c.GetConfiguration(appId)
+	if err != nil {
+		return store.Configuration{}, err
+	}
+
+	configuration, err := patch.Apply(existingConfig)
+	if err != nil {
+		return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+	}
+
+	if appId != MaxConfigApp {
+		if err = c.ValidateConfigurations(configuration); err != nil {
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+		}
+	}
+
+	c.writeConfiguration(appId, ConfigurationUpdated, configuration)
+	return configuration, nil
+}
+
+// Delete the configurations for a given appId
+func (c *ClusterDaoImplInMemory) DeleteConfiguration(appId string) (store.Configuration, error) {
+	c.writeConfiguration(appId, ConfigurationDeleted, store.Configuration{})
+	return store.Configuration{}, nil
+}
+
+// GetConfigurationHistory gets the configuration changes of an app, latest first
+func (c *ClusterDaoImplInMemory) GetConfigurationHistory(appId string) ([]store.ConfigurationVersion, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	history := []store.ConfigurationVersion{}
+	for i := len(c.history[appId]) - 1; i >= 0; i-- {
+		history = append(history, c.history[appId][i])
+	}
+	return history, nil
+}
+
+// App configurations are validated against max configs
+func (c *ClusterDaoImplInMemory) ValidateConfigurations(config store.Configuration) error {
+	return validateConfigurations(config, c)
+}
+
+// InsertCalendar creates or replaces the calendar with the same name
+func (c *ClusterDaoImplInMemory) InsertCalendar(calendar store.Calendar) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.calendars[calendar.Name] = calendar
+	return nil
+}
+
+// GetCalendar gets the calendar with the given name, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplInMemory) GetCalendar(name string) (store.Calendar, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	calendar, found := c.calendars[name]
+	if !found {
+		return store.Calendar{}, gocql.ErrNotFound
+	}
+	return calendar, nil
+}
+
+// GetCalendars gets all the calendars, sorted by name
+func (c *ClusterDaoImplInMemory) GetCalendars() ([]store.Calendar, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	calendars := []store.Calendar{}
+	for _, calendar := range c.calendars {
+		calendars = append(calendars, calendar)
+	}
+
+	sort.Slice(calendars, func(i, j int) bool {
+		return calendars[i].Name < calendars[j].Name
+	})
+	return calendars, nil
+}
+
+// DeleteCalendar deletes the calendar with the given name
+func (c *ClusterDaoImplInMemory) DeleteCalendar(name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.calendars, name)
+	return nil
+}
+
+// InsertApiKey creates or replaces the api key with the same id
+func (c *ClusterDaoImplInMemory) InsertApiKey(key store.ApiKey) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.apiKeys[key.Id] = key
+	return nil
+}
+
+// GetApiKey gets the api key with the given id, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplInMemory) GetApiKey(id string) (store.ApiKey, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	key, found := c.apiKeys[id]
+	if !found {
+		return store.ApiKey{}, gocql.ErrNotFound
+	}
+	return key, nil
+}
+
+// DeleteApiKey deletes the api key with the given id
+func (c *ClusterDaoImplInMemory) DeleteApiKey(id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.apiKeys, id)
+	return nil
+}
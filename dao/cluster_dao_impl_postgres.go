@@ -0,0 +1,571 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/lib/pq"
+	e "github.com/myntra/goscheduler/cluster_entity"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	p "github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/postgres"
+	"github.com/myntra/goscheduler/store"
+)
+
+var (
+	PgKeyEntitiesOfNode       = "SELECT id, status FROM " + KeyEntityTable + " WHERE nodename = $1"
+	PgKeyGetAllEntities       = "SELECT id, nodename, status, history FROM " + KeyEntityTable
+	PgKeyGetEntity            = "SELECT id, nodename, status, history FROM " + KeyEntityTable + " WHERE id = $1"
+	PgKeyGetAllEntitiesForApp = "SELECT id, nodename, status, history FROM " + KeyEntityTable + " WHERE id = ANY($1)"
+	PgQueryUpsertEntityInfo   = "INSERT INTO " + KeyEntityTable + " (id, nodename, status, history) VALUES ($1, $2, $3, $4) " +
+		"ON CONFLICT (id) DO UPDATE SET nodename = EXCLUDED.nodename, status = EXCLUDED.status, history = EXCLUDED.history"
+	PgQueryInsertEntity = "INSERT INTO " + KeyEntityTable + " (id, nodename, status) VALUES ($1, $2, $3) " +
+		"ON CONFLICT (id) DO UPDATE SET nodename = EXCLUDED.nodename, status = EXCLUDED.status"
+	PgQueryInsertApp = "INSERT INTO " + KeyAppTable + " (id, partitions, active, configuration) VALUES ($1, $2, $3, $4) " +
+		"ON CONFLICT (id) DO UPDATE SET partitions = EXCLUDED.partitions, active = EXCLUDED.active, configuration = EXCLUDED.configuration"
+	PgKeyAppById            = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + " WHERE id = $1"
+	PgKeyAppByIds           = "SELECT id, partitions, active, configuration FROM " + KeyAppTable + " WHERE id IN ($1, $2)"
+	PgKeyGelAllApps         = "SELECT id, partitions, active, configuration FROM " + KeyAppTable
+	PgQueryUpdateAppStatus  = "UPDATE " + KeyAppTable + " SET active = $1 WHERE id = $2"
+	PgQueryGetConfig        = "SELECT configuration FROM " + KeyAppTable + " WHERE id = $1"
+	PgQueryUpdateConfig     = "UPDATE " + KeyAppTable + " SET configuration = $1 WHERE id = $2"
+	PgQueryInsertCalendar   = "INSERT INTO " + KeyCalendarTable + " (name, calendar) VALUES ($1, $2) ON CONFLICT (name) DO UPDATE SET calendar = EXCLUDED.calendar"
+	PgKeyCalendarByName     = "SELECT calendar FROM " + KeyCalendarTable + " WHERE name = $1"
+	PgKeyGetAllCalendars    = "SELECT calendar FROM " + KeyCalendarTable + " ORDER BY name"
+	PgQueryDeleteCalendar   = "DELETE FROM " + KeyCalendarTable + " WHERE name = $1"
+	PgQueryInsertApiKey     = "INSERT INTO " + KeyApiKeyTable + " (id, api_key) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET api_key = EXCLUDED.api_key"
+	PgKeyApiKeyById         = "SELECT api_key FROM " + KeyApiKeyTable + " WHERE id = $1"
+	PgQueryDeleteApiKey     = "DELETE FROM " + KeyApiKeyTable + " WHERE id = $1"
+	PgKeyConfigurationOfApp = "SELECT version, operation, configuration, updated_at FROM " + KeyConfigurationHistoryTable + " WHERE app_id = $1 ORDER BY version DESC"
+
+	// The version is derived from the latest one while the row of the app is locked by the update of its configuration
+	PgQueryInsertConfigurationVersion = "INSERT INTO " + KeyConfigurationHistoryTable + " (app_id, version, operation, configuration, updated_at) " +
+		"SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4 FROM " + KeyConfigurationHistoryTable + " WHERE app_id = $1"
+)
+
+// ClusterDaoImplPostgres keeps the apps, the entities and the cluster wide configuration in PostgreSQL.
+type ClusterDaoImplPostgres struct {
+	DB      *sql.DB
+	AppMap  AppMap
+	Conf    *conf.Configuration
+	Monitor p.Monitor
+}
+
+func GetClusterDaoImplPostgres(conf *conf.Configuration, monitor p.Monitor) *ClusterDaoImplPostgres {
+	db, err := postgres.GetDB(conf.Storage.Postgres)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Postgres initialisation failed with error %s", err.Error()))
+		panic(err)
+	}
+	return NewClusterDaoImplPostgres(db, conf, monitor)
+}
+
+// NewClusterDaoImplPostgres creates the DAO over an open database.
+func NewClusterDaoImplPostgres(db *sql.DB, conf *conf.Configuration, monitor p.Monitor) *ClusterDaoImplPostgres {
+	return &ClusterDaoImplPostgres{
+		DB:   db,
+		Conf: conf,
+		AppMap: AppMap{
+			lock: sync.RWMutex{},
+			m:    make(map[string]store.App),
+		},
+		Monitor: monitor,
+	}
+}
+
+// Scan the entities fetched by the query
+func (c *ClusterDaoImplPostgres) getEntities(query string, args ...interface{}) ([]e.EntityInfo, error) {
+	var entities []e.EntityInfo
+
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity e.EntityInfo
+		if err = rows.Scan(&entity.Id, &entity.Node, &entity.Status, &entity.History); err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, rows.Err()
+}
+
+// Get all the entities assigned to the node
+// Raise a fatal exception in case there is an exception getting all entities
+func (c *ClusterDaoImplPostgres) GetAllEntitiesInfoOfNode(nodeName string) []e.EntityInfo {
+	var entities []e.EntityInfo
+
+	rows, err := c.DB.Query(PgKeyEntitiesOfNode, nodeName)
+	if err != nil {
+		glog.Fatal(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entity := e.EntityInfo{Node: nodeName}
+		if err = rows.Scan(&entity.Id, &entity.Status); err != nil {
+			glog.Fatal(err)
+		}
+		entities = append(entities, entity)
+	}
+
+	if err = rows.Err(); err != nil {
+		glog.Fatal(err)
+	}
+
+	glog.Infof("GetAllEntitiesInfoOfNode result is : %+v", entities)
+	return entities
+}
+
+// Get all the entities
+// Raise a fatal exception in case there is an exception getting all entities
+func (c *ClusterDaoImplPostgres) GetAllEntitiesInfo() []e.EntityInfo {
+	entities, err := c.getEntities(PgKeyGetAllEntities)
+	if err != nil {
+		glog.Fatal(err)
+	}
+
+	glog.Infof("GetAllEntitiesInfo result is : %+v", entities)
+	return entities
+}
+
+// Get all the entities of single app
+func (c *ClusterDaoImplPostgres) GetAllEntitiesForApp(appId string) ([]e.EntityInfo, error) {
+	app, err := c.GetApp(appId)
+	if err != nil {
+		glog.Errorf("Error: %s while getting app info for GetAllEntitiesForApp for app: %s", err.Error(), appId)
+		return nil, err
+	}
+
+	ids := make([]string, app.Partitions)
+	for i := uint32(0); i < app.Partitions; i++ {
+		ids[i] = fmt.Sprintf("%v.%d", app.AppId, i)
+	}
+
+	entities, err := c.getEntities(PgKeyGetAllEntitiesForApp, pq.Array(ids))
+	if err != nil {
+		glog.Errorf("Error: %s while getting poller info for GetAllEntitiesForApp for app: %s", err.Error(), appId)
+		return nil, err
+	}
+
+	glog.Infof("GetAllEntitiesInfo result is : %+v", entities)
+	return entities, nil
+}
+
+// Get entity by id, an empty entity is returned if there is none
+func (c *ClusterDaoImplPostgres) GetEntityInfo(id string) e.EntityInfo {
+	var entity e.EntityInfo
+
+	if err := c.DB.QueryRow(PgKeyGetEntity, id).Scan(&entity.Id, &entity.Node, &entity.Status, &entity.History); err != nil {
+		glog.Errorf("Error %s while getting entity %s", err.Error(), id)
+		return e.EntityInfo{}
+	}
+
+	glog.Infof("GetEntityInfo result for %s is : %+v", id, entity)
+	return entity
+}
+
+// UpdateEntityStatus updates the status of a specific entity along with the history of its status changes.
+func (c *ClusterDaoImplPostgres) UpdateEntityStatus(id string, nodename string, status int) error {
+	entity := c.GetEntityInfo(id)
+
+	history := ""
+
+	if entity.History != "" || entity.Node != "" {
+		toAppend := "{" + entity.Node + ":" + strconv.Itoa(entity.Status) + "}"
+		history = entity.History + "->" + toAppend
+	}
+
+	historyLen := len(history)
+	if historyLen > c.Conf.ClusterDB.EntityHistorySize {
+		history = history[historyLen-c.Conf.ClusterDB.EntityHistorySize : historyLen]
+	}
+
+	_, err := c.DB.Exec(PgQueryUpsertEntityInfo, id, nodename, status, history)
+	return err
+}
+
+// CreateEntity creates a new entity in a disabled state.
+func (c *ClusterDaoImplPostgres) CreateEntity(entityInfo e.EntityInfo) error {
+	_, err := c.DB.Exec(PgQueryInsertEntity, entityInfo.Id, c.Conf.Cluster.Address, 0)
+	return err
+}
+
+// By default if Active parameter is not set it will create the app in deactivated state
+func (c *ClusterDaoImplPostgres) InsertApp(app store.App) error {
+	var err error
+	var config []byte
+
+	if app.AppId != MaxConfigApp {
+		if err = c.ValidateConfigurations(app.Configuration); err != nil {
+			return err
+		}
+	}
+
+	if config, err = json.Marshal(app.Configuration); err != nil {
+		return err
+	}
+
+	_, err = c.DB.Exec(PgQueryInsertApp, app.AppId, int64(app.Partitions), app.Active, string(config))
+	return err
+}
+
+func (c *ClusterDaoImplPostgres) GetApp(appName string) (store.App, error) {
+	if app, found := c.check(appName); found {
+		return app, nil
+	}
+	return c.getApp(appName)
+}
+
+// Scan the apps fetched by the query
+func (c *ClusterDaoImplPostgres) getApps(query string, args ...interface{}) ([]store.App, error) {
+	var apps []store.App
+
+	rows, err := c.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var app store.App
+		var partitions int64
+		var config string
+		if err = rows.Scan(&app.AppId, &partitions, &app.Active, &config); err != nil {
+			return nil, err
+		}
+
+		app.Partitions = uint32(partitions)
+		if err := json.Unmarshal([]byte(config), &app.Configuration); err != nil {
+			glog.Errorf("Error: %s while unmarshalling config: %+v for app: %s", err.Error(), config, app.AppId)
+			app.Configuration = store.Configuration{}
+		}
+		apps = append(apps, app)
+	}
+	return apps, rows.Err()
+}
+
+// getApp gets specified app from DB and puts into in memory cache
+// gocql.ErrNotFound is returned if there is no such app
+func (c *ClusterDaoImplPostgres) getApp(appName string) (store.App, error) {
+	apps, err := c.getApps(PgKeyAppById, appName)
+	switch {
+	case err != nil:
+		glog.Errorf("Error %s while getting app %s", err.Error(), appName)
+		return store.App{}, err
+	case len(apps) == 0:
+		return store.App{}, notFound(sql.ErrNoRows)
+	}
+
+	return c.cache(apps[0]), nil
+}
+
+// Checks whether app is found in in-memory cache or not
+func (c *ClusterDaoImplPostgres) check(appName string) (store.App, bool) {
+	c.AppMap.lock.RLock()
+	app, found := c.AppMap.m[appName]
+	c.AppMap.lock.RUnlock()
+
+	return app, found
+}
+
+// cache adds the app in in-memory cache
+func (c *ClusterDaoImplPostgres) cache(app store.App) store.App {
+	c.AppMap.lock.Lock()
+	c.AppMap.m[app.AppId] = app
+	c.AppMap.lock.Unlock()
+	return app
+}
+
+// GetApps returns the app with the given id, or all the apps if the id is empty.
+func (c *ClusterDaoImplPostgres) GetApps(appId string) ([]store.App, error) {
+	if appId != "" {
+		app, err := c.getApp(appId)
+		if err != nil {
+			return nil, err
+		}
+		return []store.App{app}, nil
+	}
+
+	apps, err := c.getApps(PgKeyGelAllApps)
+	if err != nil {
+		return apps, err
+	}
+
+	glog.V(constants.INFO).Infof("Get all apps result is : +%v", apps)
+	return apps, nil
+}
+
+// UpdateAppActiveStatus updates the active status of an application.
+func (c *ClusterDaoImplPostgres) UpdateAppActiveStatus(appName string, activeStatus bool) error {
+	_, err := c.DB.Exec(PgQueryUpdateAppStatus, activeStatus, appName)
+	return err
+}
+
+// InvalidateSingleAppCache removes a specific app from the AppMap cache.
+func (c *ClusterDaoImplPostgres) InvalidateSingleAppCache(appName string) {
+	c.AppMap.lock.Lock()
+	delete(c.AppMap.m, appName)
+	c.AppMap.lock.Unlock()
+}
+
+func (c *ClusterDaoImplPostgres) GetDCAwareApp(appName string) (store.App, error) {
+	c.AppMap.lock.RLock()
+	dcPrefixedApp, dcPrefixedFound := c.AppMap.m[c.Conf.DCConfig.Prefix+appName]
+	fallbackApp, fallbackFound := c.AppMap.m[appName]
+	c.AppMap.lock.RUnlock()
+
+	if dcPrefixedFound {
+		return dcPrefixedApp, nil
+	} else if fallbackFound {
+		return fallbackApp, nil
+	}
+
+	return c.getDCAwareApp(appName)
+}
+
+// get DC aware app from DB, preferring the app prefixed with the DC over the app itself
+func (c *ClusterDaoImplPostgres) getDCAwareApp(appName string) (store.App, error) {
+	dcPrefixedAppName := c.Conf.DCConfig.Prefix + constants.DCPrefix + appName
+
+	apps, err := c.getApps(PgKeyAppByIds, dcPrefixedAppName, appName)
+	if err != nil {
+		glog.Infof("Error occurred while fetching apps: %+v", err)
+		return store.App{}, err
+	}
+
+	appIdToApp := make(map[string]store.App)
+	for _, app := range apps {
+		appIdToApp[app.AppId] = app
+	}
+
+	if app, ok := appIdToApp[dcPrefixedAppName]; ok {
+		return c.cache(app), nil
+	} else if fallbackApp, ok := appIdToApp[appName]; ok {
+		return c.cache(fallbackApp), nil
+	}
+	return store.App{}, nil
+}
+
+// Write the configuration of the app and append it to its history in a single transaction
+func (c *ClusterDaoImplPostgres) writeConfiguration(appId string, operation string, configuration store.Configuration) error {
+	config, err := json.Marshal(configuration)
+	if err != nil {
+		return err
+	}
+
+	tx, err := c.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(PgQueryUpdateConfig, string(config), appId); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(PgQueryInsertConfigurationVersion, appId, operation, string(config), time.Now()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Create configuration for a given appId and configuration
+// ErrInvalidConfiguration is returned if the configuration fails validation
+func (c *ClusterDaoImplPostgres) CreateConfigurations(appId string, configuration store.Configuration) (store.Configuration, error) {
+	if appId != MaxConfigApp {
+		if err := c.ValidateConfigurations(configuration); err != nil {
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+		}
+	}
+
+	return configuration, c.writeConfiguration(appId, ConfigurationCreated, configuration)
+}
+
+// Get app configurations for a given appId
+func (c *ClusterDaoImplPostgres) GetConfiguration(appId string) (store.Configuration, error) {
+	var config string
+	var configuration store.Configuration
+
+	if err := c.DB.QueryRow(PgQueryGetConfig, appId).Scan(&config); err != nil {
+		return configuration, notFound(err)
+	}
+
+	err := json.Unmarshal([]byte(config), &configuration)
+	return configuration, err
+}
+
+// Update the configurations for given appId with a merge patch
+// Only the fields present in the patch are changed, fields set to null are cleared, the patched configuration
+// is validated and ErrInvalidConfiguration is returned if it fails
+func (c *ClusterDaoImplPostgres) UpdateConfiguration(appId string, patch store.ConfigurationPatch) (store.Configuration, error) {
+	existingConfig, err := c.GetConfiguration(appId)
+	if err != nil {
+		return store.Configuration{}, err
+	}
+
+	configuration, err := patch.Apply(existingConfig)
+	if err != nil {
+		return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+	}
+
+	if appId != MaxConfigApp {
+		if err = c.ValidateConfigurations(configuration); err != nil {
+			return store.Configuration{}, fmt.Errorf("%w: %s", ErrInvalidConfiguration, err.Error())
+		}
+	}
+
+	return configuration, c.writeConfiguration(appId, ConfigurationUpdated, configuration)
+}
+
+// Delete the configurations for a given appId
+func (c *ClusterDaoImplPostgres) DeleteConfiguration(appId string) (store.Configuration, error) {
+	return store.Configuration{}, c.writeConfiguration(appId, ConfigurationDeleted, store.Configuration{})
+}
+
+// GetConfigurationHistory gets the configuration changes of an app, latest first
+func (c *ClusterDaoImplPostgres) GetConfigurationHistory(appId string) ([]store.ConfigurationVersion, error) {
+	history := []store.ConfigurationVersion{}
+
+	rows, err := c.DB.Query(PgKeyConfigurationOfApp, appId)
+	if err != nil {
+		return history, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		var version store.ConfigurationVersion
+		if err = rows.Scan(&version.Version, &version.Operation, &data, &version.UpdatedAt); err != nil {
+			return history, err
+		}
+		if err := json.Unmarshal([]byte(data), &version.Configuration); err != nil {
+			glog.Errorf("Error: %s while unmarshalling version %d of the configuration of app %s", err.Error(), version.Version, appId)
+			continue
+		}
+		history = append(history, version)
+	}
+
+	return history, rows.Err()
+}
+
+// App configurations are validated against max configs
+func (c *ClusterDaoImplPostgres) ValidateConfigurations(config store.Configuration) error {
+	return validateConfigurations(config, c)
+}
+
+// InsertCalendar creates or replaces the calendar with the same name
+func (c *ClusterDaoImplPostgres) InsertCalendar(calendar store.Calendar) error {
+	data, err := json.Marshal(calendar)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DB.Exec(PgQueryInsertCalendar, calendar.Name, string(data))
+	return err
+}
+
+// GetCalendar gets the calendar with the given name, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplPostgres) GetCalendar(name string) (store.Calendar, error) {
+	var data string
+	var calendar store.Calendar
+
+	if err := c.DB.QueryRow(PgKeyCalendarByName, name).Scan(&data); err != nil {
+		return calendar, notFound(err)
+	}
+
+	err := json.Unmarshal([]byte(data), &calendar)
+	return calendar, err
+}
+
+// GetCalendars gets all the calendars
+func (c *ClusterDaoImplPostgres) GetCalendars() ([]store.Calendar, error) {
+	calendars := []store.Calendar{}
+
+	rows, err := c.DB.Query(PgKeyGetAllCalendars)
+	if err != nil {
+		return calendars, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err = rows.Scan(&data); err != nil {
+			return calendars, err
+		}
+
+		var calendar store.Calendar
+		if err := json.Unmarshal([]byte(data), &calendar); err != nil {
+			glog.Errorf("Error: %s while unmarshalling calendar: %s", err.Error(), data)
+			continue
+		}
+		calendars = append(calendars, calendar)
+	}
+
+	return calendars, rows.Err()
+}
+
+// DeleteCalendar deletes the calendar with the given name
+func (c *ClusterDaoImplPostgres) DeleteCalendar(name string) error {
+	_, err := c.DB.Exec(PgQueryDeleteCalendar, name)
+	return err
+}
+
+// InsertApiKey creates or replaces the api key with the same id
+func (c *ClusterDaoImplPostgres) InsertApiKey(key store.ApiKey) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DB.Exec(PgQueryInsertApiKey, key.Id, string(data))
+	return err
+}
+
+// GetApiKey gets the api key with the given id, gocql.ErrNotFound is returned if there is none
+func (c *ClusterDaoImplPostgres) GetApiKey(id string) (store.ApiKey, error) {
+	var data string
+	var key store.ApiKey
+
+	if err := c.DB.QueryRow(PgKeyApiKeyById, id).Scan(&data); err != nil {
+		return key, notFound(err)
+	}
+
+	err := json.Unmarshal([]byte(data), &key)
+	return key, err
+}
+
+// DeleteApiKey deletes the api key with the given id
+func (c *ClusterDaoImplPostgres) DeleteApiKey(id string) error {
+	_, err := c.DB.Exec(PgQueryDeleteApiKey, id)
+	return err
+}
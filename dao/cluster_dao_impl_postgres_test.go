@@ -0,0 +1,121 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/conf"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupClusterPostgresMocks(t *testing.T) (*ClusterDaoImplPostgres, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	dao := NewClusterDaoImplPostgres(db, &conf.Configuration{
+		ClusterDB: conf.ClusterDBConfig{EntityHistorySize: 20},
+		Cluster:   conf.ClusterConfig{Address: "127.0.0.1:9091"},
+	}, nil)
+
+	return dao, mock
+}
+
+func TestClusterDaoImplPostgres_GetApp(t *testing.T) {
+	dao, mock := setupClusterPostgresMocks(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(PgKeyAppById)).WithArgs("Test").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "partitions", "active", "configuration"}).
+			AddRow("Test", 2, true, `{"payloadSize":100}`))
+	mock.ExpectQuery(regexp.QuoteMeta(PgKeyAppById)).WithArgs("Unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "partitions", "active", "configuration"}))
+
+	// The app is cached after it is fetched once
+	for i := 0; i < 2; i++ {
+		app, err := dao.GetApp("Test")
+		assert.Nil(t, err)
+		assert.Equal(t, s.App{AppId: "Test", Partitions: 2, Active: true, Configuration: s.Configuration{PayloadSize: 100}}, app)
+	}
+
+	_, err := dao.GetApp("Unknown")
+	assert.Equal(t, gocql.ErrNotFound, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestClusterDaoImplPostgres_UpdateEntityStatus(t *testing.T) {
+	dao, mock := setupClusterPostgresMocks(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(PgKeyGetEntity)).WithArgs("Test.0").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nodename", "status", "history"}).AddRow("Test.0", "node1", 1, ""))
+	mock.ExpectExec(regexp.QuoteMeta(PgQueryUpsertEntityInfo)).WithArgs("Test.0", "node2", 1, "->{node1:1}").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.Nil(t, dao.UpdateEntityStatus("Test.0", "node2", 1))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestClusterDaoImplPostgres_UpdateConfiguration(t *testing.T) {
+	dao, mock := setupClusterPostgresMocks(t)
+	dao.cache(s.App{AppId: MaxConfigApp, Configuration: s.Configuration{PayloadSize: 1000, HttpRetries: 5, HttpTimeout: 1000}})
+
+	mock.ExpectQuery(regexp.QuoteMeta(PgQueryGetConfig)).WithArgs("Test").
+		WillReturnRows(sqlmock.NewRows([]string{"configuration"}).AddRow(`{"payloadSize":100,"httpRetries":2}`))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(PgQueryUpdateConfig)).WithArgs(`{"payloadSize":200,"httpRetries":2}`, "Test").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(PgQueryInsertConfigurationVersion)).
+		WithArgs("Test", ConfigurationUpdated, `{"payloadSize":200,"httpRetries":2}`, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	configuration, err := dao.UpdateConfiguration("Test", s.PatchOf(s.Configuration{PayloadSize: 200}))
+	assert.Nil(t, err)
+	assert.Equal(t, s.Configuration{PayloadSize: 200, HttpRetries: 2}, configuration)
+
+	// Invalid configurations are not written
+	mock.ExpectQuery(regexp.QuoteMeta(PgQueryGetConfig)).WithArgs("Test").
+		WillReturnRows(sqlmock.NewRows([]string{"configuration"}).AddRow(`{}`))
+
+	_, err = dao.UpdateConfiguration("Test", s.PatchOf(s.Configuration{PayloadSize: 2000}))
+	assert.ErrorIs(t, err, ErrInvalidConfiguration)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestClusterDaoImplPostgres_GetCalendar(t *testing.T) {
+	dao, mock := setupClusterPostgresMocks(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(PgKeyCalendarByName)).WithArgs("holidays").
+		WillReturnRows(sqlmock.NewRows([]string{"calendar"}))
+	mock.ExpectQuery(regexp.QuoteMeta(PgKeyApiKeyById)).WithArgs("key").
+		WillReturnRows(sqlmock.NewRows([]string{"api_key"}))
+
+	_, err := dao.GetCalendar("holidays")
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	_, err = dao.GetApiKey("key")
+	assert.Equal(t, gocql.ErrNotFound, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
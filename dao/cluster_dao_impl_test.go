@@ -21,11 +21,13 @@ package dao
 
 import (
 	"errors"
+	"github.com/gocql/gocql"
 	"github.com/golang/mock/gomock"
 	e "github.com/myntra/goscheduler/cluster_entity"
 	"github.com/myntra/goscheduler/conf"
 	"github.com/myntra/goscheduler/mocks"
 	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
 	"sync"
 	"testing"
 )
@@ -33,13 +35,12 @@ import (
 func setupClusterDaoMocks(t *testing.T) (*ClusterDaoImplCassandra, *mocks.MockSessionInterface, *mocks.MockQueryInterface, *mocks.MockIterInterface, *gomock.Controller) {
 	dao := &ClusterDaoImplCassandra{
 		Conf: &conf.Configuration{
-			Cluster: conf.ClusterConfig{
-				PageSize: 10,
-				NumRetry: 2,
-			},
 			ClusterDB: conf.ClusterDBConfig{
-				ClusterKeySpace:   "",
-				DBConfig:          conf.CassandraConfig{},
+				ClusterKeySpace: "",
+				DBConfig: conf.CassandraConfig{
+					PageSize: 10,
+					NumRetry: 2,
+				},
 				EntityHistorySize: 0,
 			},
 			Poller: conf.PollerConfig{
@@ -508,10 +509,42 @@ func TestClusterDaoImplCassandra_UpdateEntityStatus(t *testing.T) {
 					Scan(gomock.All()).
 					Return(nil).
 					Times(1),
+				m.
+					EXPECT().
+					ExecuteBatch(gomock.Any()).
+					DoAndReturn(func(batch *gocql.Batch) error {
+						assert.Equal(t, 2, batch.Size())
+						assert.Equal(t, QueryInsertEntityOfNode, batch.Entries[1].Stmt)
+						return nil
+					}).
+					Times(1),
+			},
+			nil,
+		},
+		{
+			[]interface{}{
+				"Bruce",
+				"localhost",
+				1,
+			},
+			[]*gomock.Call{
 				mq.
 					EXPECT().
-					Exec().
-					Return(nil).
+					Scan(gomock.All()).
+					DoAndReturn(func(dest ...interface{}) error {
+						*dest[1].(*string) = "127.0.0.1"
+						return nil
+					}).
+					Times(1),
+				m.
+					EXPECT().
+					ExecuteBatch(gomock.Any()).
+					DoAndReturn(func(batch *gocql.Batch) error {
+						assert.Equal(t, 3, batch.Size())
+						assert.Equal(t, QueryDeleteEntityOfNode, batch.Entries[1].Stmt)
+						assert.Equal(t, []interface{}{"127.0.0.1", "Bruce"}, batch.Entries[1].Args)
+						return nil
+					}).
 					Times(1),
 			},
 			nil,
@@ -528,15 +561,16 @@ func TestClusterDaoImplCassandra_UpdateEntityStatus(t *testing.T) {
 					Scan(gomock.All()).
 					Return(errors.New("error getting app")).
 					Times(1),
-				mq.
+				m.
 					EXPECT().
-					Exec().
+					ExecuteBatch(gomock.Any()).
 					Return(errors.New("error updating app")).
 					Times(1),
 			},
 			errors.New("error updating app"),
 		},
 	} {
+		gomock.InOrder(test.Mock...)
 		if err := dao.UpdateEntityStatus(test.Input[0].(string), test.Input[1].(string), test.Input[2].(int)); err != nil && err.Error() != test.Expected.Error() {
 			t.Errorf("Got error: %+v, expected: %+v", err, test.Expected)
 		}
@@ -544,11 +578,9 @@ func TestClusterDaoImplCassandra_UpdateEntityStatus(t *testing.T) {
 }
 
 func TestClusterDaoImplCassandra_CreateEntity(t *testing.T) {
-	dao, m, mq, _, ctrl := setupClusterDaoMocks(t)
+	dao, m, _, _, ctrl := setupClusterDaoMocks(t)
 	defer ctrl.Finish()
 
-	m.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mq).AnyTimes()
-
 	// GetEntityInfo success
 	for _, test := range []struct {
 		Input    e.EntityInfo
@@ -562,10 +594,15 @@ func TestClusterDaoImplCassandra_CreateEntity(t *testing.T) {
 				Status:  1,
 				History: "",
 			},
-			mq.
+			m.
 				EXPECT().
-				Exec().
-				Return(nil).
+				ExecuteBatch(gomock.Any()).
+				DoAndReturn(func(batch *gocql.Batch) error {
+					assert.Equal(t, 2, batch.Size())
+					assert.Equal(t, QueryInsertEntity, batch.Entries[0].Stmt)
+					assert.Equal(t, QueryInsertEntityOfNode, batch.Entries[1].Stmt)
+					return nil
+				}).
 				Times(1),
 			nil,
 		},
@@ -576,9 +613,9 @@ func TestClusterDaoImplCassandra_CreateEntity(t *testing.T) {
 				Status:  1,
 				History: "",
 			},
-			mq.
+			m.
 				EXPECT().
-				Exec().
+				ExecuteBatch(gomock.Any()).
 				Return(errors.New("error creating entity")).
 				Times(1),
 			errors.New("error creating entity"),
@@ -0,0 +1,102 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/store"
+)
+
+const (
+	ConfigurationCreated = "create"
+	ConfigurationUpdated = "update"
+	ConfigurationDeleted = "delete"
+
+	maxConfigurationVersionAttempts = 3
+)
+
+var (
+	// ErrInvalidConfiguration is returned when an app configuration fails validation
+	ErrInvalidConfiguration = errors.New("invalid configuration")
+
+	KeyConfigurationHistoryTable    = "app_configuration_history"
+	QueryInsertConfigurationVersion = "INSERT INTO " + KeyConfigurationHistoryTable + " (app_id, version, operation, configuration, updated_at) VALUES (?, ?, ?, ?, ?) IF NOT EXISTS"
+	KeyLatestConfigurationVersion   = "SELECT version FROM " + KeyConfigurationHistoryTable + " WHERE app_id = ? LIMIT 1"
+	KeyConfigurationHistoryOfApp    = "SELECT version, operation, configuration, updated_at FROM " + KeyConfigurationHistoryTable + " WHERE app_id = ?"
+)
+
+// recordConfigurationVersion appends the configuration of the app to its history under the next version.
+// Versions are claimed with a lightweight transaction so that concurrent changes never share a version.
+func (c *ClusterDaoImplCassandra) recordConfigurationVersion(appId string, operation string, configuration store.Configuration) error {
+	data, err := json.Marshal(configuration)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; attempt < maxConfigurationVersionAttempts; attempt++ {
+		var latest int
+		err = c.Session.Query(KeyLatestConfigurationVersion, appId).Consistency(c.Conf.ClusterDB.DBConfig.Consistency).Scan(&latest)
+		if err != nil && err != gocql.ErrNotFound {
+			return err
+		}
+
+		applied, err := c.Session.Query(QueryInsertConfigurationVersion, appId, latest+1, operation, string(data), time.Now()).
+			MapScanCAS(make(map[string]interface{}))
+		if err != nil {
+			return err
+		}
+
+		if applied {
+			return nil
+		}
+
+		glog.Infof("Configuration version %d of app %s was taken concurrently, retrying", latest+1, appId)
+	}
+
+	return fmt.Errorf("could not record the configuration history of app %s", appId)
+}
+
+// GetConfigurationHistory gets the configuration changes of an app, latest first
+func (c *ClusterDaoImplCassandra) GetConfigurationHistory(appId string) ([]store.ConfigurationVersion, error) {
+	var data string
+	var version store.ConfigurationVersion
+	history := []store.ConfigurationVersion{}
+
+	iter := c.Session.Query(KeyConfigurationHistoryOfApp, appId).
+		Consistency(c.Conf.ClusterDB.DBConfig.Consistency).
+		PageSize(c.Conf.ClusterDB.DBConfig.PageSize).
+		Iter()
+	for iter.Scan(&version.Version, &version.Operation, &data, &version.UpdatedAt) {
+		if err := json.Unmarshal([]byte(data), &version.Configuration); err != nil {
+			glog.Errorf("Error: %s while unmarshalling version %d of the configuration of app %s", err.Error(), version.Version, appId)
+			continue
+		}
+		history = append(history, version)
+		version = store.ConfigurationVersion{}
+	}
+
+	return history, iter.Close()
+}
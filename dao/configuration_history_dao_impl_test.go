@@ -0,0 +1,107 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/myntra/goscheduler/store"
+)
+
+func TestClusterDaoImplCassandra_RecordConfigurationVersion(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		latest   error
+		applied  []bool
+		expected bool
+	}{
+		{"first version", gocql.ErrNotFound, []bool{true}, true},
+		{"retried after a concurrent change", nil, []bool{false, true}, true},
+		{"gives up after repeated concurrent changes", nil, []bool{false, false, false}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dao, m, mq, _, ctrl := setupClusterDaoMocks(t)
+			defer ctrl.Finish()
+
+			m.EXPECT().Query(KeyLatestConfigurationVersion, "test").Return(mq).Times(len(test.applied))
+			m.EXPECT().Query(QueryInsertConfigurationVersion, "test", gomock.Any(), ConfigurationUpdated, gomock.Any(), gomock.Any()).Return(mq).Times(len(test.applied))
+			mq.EXPECT().Consistency(gomock.Any()).Return(mq).AnyTimes()
+			mq.EXPECT().Scan(gomock.Any()).SetArg(0, 1).Return(test.latest).Times(len(test.applied))
+			for _, applied := range test.applied {
+				mq.EXPECT().MapScanCAS(gomock.Any()).Return(applied, nil).Times(1)
+			}
+
+			err := dao.recordConfigurationVersion("test", ConfigurationUpdated, store.Configuration{Jitter: 60})
+			if (err == nil) != test.expected {
+				t.Errorf("recordConfigurationVersion() error = %v, want success %t", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestClusterDaoImplCassandra_CreateConfigurationsInvalid(t *testing.T) {
+	dao, _, _, _, ctrl := setupClusterDaoMocks(t)
+	defer ctrl.Finish()
+
+	_, err := dao.CreateConfigurations("test", store.Configuration{RetryPolicy: &store.RetryPolicy{MaxAttempts: 0}})
+	if !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("CreateConfigurations() error = %v, want %v", err, ErrInvalidConfiguration)
+	}
+}
+
+func TestClusterDaoImplCassandra_CreateConfigurationsBound(t *testing.T) {
+	dao, m, mq, _, ctrl := setupClusterDaoMocks(t)
+	defer ctrl.Finish()
+
+	configuration := store.Configuration{AllowedCallbackHosts: []string{"https://example.com/it's'); DROP TABLE apps; --"}}
+	data, _ := json.Marshal(configuration)
+
+	m.EXPECT().Query(QueryUpdateConfig, string(data), MaxConfigApp).Return(mq).Times(1)
+	mq.EXPECT().Exec().Return(nil).Times(1)
+	m.EXPECT().Query(KeyLatestConfigurationVersion, MaxConfigApp).Return(mq).Times(1)
+	m.EXPECT().Query(QueryInsertConfigurationVersion, MaxConfigApp, 1, ConfigurationCreated, string(data), gomock.Any()).Return(mq).Times(1)
+	mq.EXPECT().Consistency(gomock.Any()).Return(mq).AnyTimes()
+	mq.EXPECT().Scan(gomock.Any()).Return(gocql.ErrNotFound).Times(1)
+	mq.EXPECT().MapScanCAS(gomock.Any()).Return(true, nil).Times(1)
+
+	if _, err := dao.CreateConfigurations(MaxConfigApp, configuration); err != nil {
+		t.Errorf("CreateConfigurations() error = %v", err)
+	}
+}
+
+func TestClusterDaoImplCassandra_DeleteConfigurationHistoryFailure(t *testing.T) {
+	dao, m, mq, _, ctrl := setupClusterDaoMocks(t)
+	defer ctrl.Finish()
+
+	m.EXPECT().Query(QueryUpdateConfig, "{}", "test").Return(mq).Times(1)
+	mq.EXPECT().Exec().Return(nil).Times(1)
+	m.EXPECT().Query(KeyLatestConfigurationVersion, "test").Return(mq).Times(1)
+	mq.EXPECT().Consistency(gomock.Any()).Return(mq).AnyTimes()
+	mq.EXPECT().Scan(gomock.Any()).Return(errors.New("unavailable")).Times(1)
+
+	// The configuration is written, so the change succeeds for the nodes to reload the app
+	if _, err := dao.DeleteConfiguration("test"); err != nil {
+		t.Errorf("DeleteConfiguration() error = %v, want nil", err)
+	}
+}
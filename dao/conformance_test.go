@@ -0,0 +1,137 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/dao"
+	"github.com/myntra/goscheduler/dao/daotest"
+	"github.com/myntra/goscheduler/embedded"
+	"github.com/myntra/goscheduler/postgres"
+)
+
+func newConformanceConfig() *conf.Configuration {
+	return &conf.Configuration{
+		Cluster:                  conf.ClusterConfig{Address: "127.0.0.1:9091"},
+		ClusterDB:                conf.ClusterDBConfig{EntityHistorySize: 20},
+		CronConfig:               conf.CronConfig{App: "Athena"},
+		Poller:                   conf.PollerConfig{DefaultCount: 2},
+		AggregateSchedulesConfig: conf.AggregateSchedulesConfig{FlushPeriod: 60},
+		AppLevelConfiguration: conf.AppLevelConfiguration{
+			FiredScheduleRetentionPeriod: 1,
+			PayloadSize:                  1000,
+			HttpRetries:                  5,
+			HttpTimeout:                  1000,
+		},
+	}
+}
+
+func TestInMemoryConformance(t *testing.T) {
+	daotest.RunScheduleDaoSuite(t, func(t *testing.T) dao.ScheduleDao {
+		return dao.NewScheduleDaoImplInMemory(newConformanceConfig())
+	})
+	daotest.RunClusterDaoSuite(t, func(t *testing.T) dao.ClusterDao {
+		return dao.NewClusterDaoImplInMemory(newConformanceConfig())
+	})
+}
+
+func newEmbeddedConformanceConfig(t *testing.T) *conf.Configuration {
+	config := newConformanceConfig()
+	config.Storage = conf.StorageConfig{
+		Backend:  conf.EmbeddedBackend,
+		Embedded: conf.EmbeddedConfig{Path: filepath.Join(t.TempDir(), "goscheduler.db"), PageSize: 2},
+	}
+	return config
+}
+
+func TestEmbeddedConformance(t *testing.T) {
+	daotest.RunScheduleDaoSuite(t, func(t *testing.T) dao.ScheduleDao {
+		config := newEmbeddedConformanceConfig(t)
+		db, err := embedded.GetDB(config.Storage.Embedded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dao.NewScheduleDaoImplEmbedded(db, config, nil)
+	})
+	daotest.RunClusterDaoSuite(t, func(t *testing.T) dao.ClusterDao {
+		config := newEmbeddedConformanceConfig(t)
+		db, err := embedded.GetDB(config.Storage.Embedded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dao.NewClusterDaoImplEmbedded(db, config, nil)
+	})
+}
+
+// The Postgres suites run against the database of the GOSCHEDULER_TEST_POSTGRES connection string, migrated if needed
+func TestPostgresConformance(t *testing.T) {
+	connectionString := os.Getenv("GOSCHEDULER_TEST_POSTGRES")
+	if connectionString == "" {
+		t.Skip("GOSCHEDULER_TEST_POSTGRES is not set")
+	}
+
+	config := newConformanceConfig()
+	config.Storage = conf.StorageConfig{
+		Backend:  conf.PostgresBackend,
+		Postgres: conf.PostgresConfig{ConnectionString: connectionString, PageSize: 2},
+	}
+
+	db, err := postgres.GetDB(config.Storage.Postgres)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = postgres.Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	daotest.RunScheduleDaoSuite(t, func(t *testing.T) dao.ScheduleDao {
+		return dao.NewScheduleDaoImplPostgres(db, config, nil)
+	})
+	daotest.RunClusterDaoSuite(t, func(t *testing.T) dao.ClusterDao {
+		return dao.NewClusterDaoImplPostgres(db, config, nil)
+	})
+}
+
+// The Cassandra suites run against the cluster of the comma separated GOSCHEDULER_TEST_CASSANDRA hosts,
+// whose keyspaces are created with `goscheduler migrate up`
+func TestCassandraConformance(t *testing.T) {
+	hosts := os.Getenv("GOSCHEDULER_TEST_CASSANDRA")
+	if hosts == "" {
+		t.Skip("GOSCHEDULER_TEST_CASSANDRA is not set")
+	}
+
+	config := conf.NewConfig()
+	config.ScheduleDB.DBConfig.Hosts = hosts
+	config.ClusterDB.DBConfig.Hosts = hosts
+	config.AppLevelConfiguration.FiredScheduleRetentionPeriod = 1
+
+	scheduleDao := dao.GetScheduleDaoImpl(config, nil)
+	clusterDao := dao.GetClusterDaoImpl(config, nil)
+
+	daotest.RunScheduleDaoSuite(t, func(t *testing.T) dao.ScheduleDao {
+		return scheduleDao
+	})
+	daotest.RunClusterDaoSuite(t, func(t *testing.T) dao.ClusterDao {
+		return clusterDao
+	})
+}
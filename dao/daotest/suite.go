@@ -0,0 +1,784 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package daotest provides the conformance suites every storage backend of the schedule and cluster DAOs must pass.
+// A backend runs the suites from its own tests with a factory returning a DAO over empty or shared storage,
+// the suites only rely on the data they create themselves.
+package daotest
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/dao"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ScheduleDaoFactory returns the schedule DAO under test
+type ScheduleDaoFactory func(t *testing.T) dao.ScheduleDao
+
+// ClusterDaoFactory returns the cluster DAO under test
+type ClusterDaoFactory func(t *testing.T) dao.ClusterDao
+
+// Get an app id unique to the test, so the suites can run against storage shared with other tests
+func newAppId() string {
+	return "daotest_" + strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.Itoa(rand.Intn(1000))
+}
+
+func newApp(partitions uint32) s.App {
+	return s.App{
+		AppId:         newAppId(),
+		Partitions:    partitions,
+		Active:        true,
+		Configuration: s.Configuration{FiredScheduleRetentionPeriod: 1},
+	}
+}
+
+// Get a one time schedule of the app at the given time, in the first partition
+func newSchedule(app s.App, scheduleTime time.Time) s.Schedule {
+	return s.Schedule{
+		ScheduleId:    gocql.TimeUUID(),
+		AppId:         app.AppId,
+		PartitionId:   0,
+		Payload:       "{}",
+		ScheduleTime:  scheduleTime.Unix(),
+		ScheduleGroup: scheduleTime.Truncate(time.Minute).Unix(),
+		Callback:      &s.HttpCallback{Type: constants.DefaultCallback, Details: s.Details{Url: "http://example.com/callback", Method: "POST"}},
+	}
+}
+
+// Get the start of a minute an hour from now, so the schedules of a test are in the future and in the same hour
+func baseTime() time.Time {
+	return time.Now().Add(time.Hour).Truncate(time.Minute)
+}
+
+func scheduleIds(schedules []s.Schedule) []gocql.UUID {
+	ids := make([]gocql.UUID, 0, len(schedules))
+	for _, schedule := range schedules {
+		ids = append(ids, schedule.ScheduleId)
+	}
+	return ids
+}
+
+func registerCallbacks() {
+	if _, ok := s.Registry[constants.DefaultCallback]; !ok {
+		s.Registry[constants.DefaultCallback] = func() s.Callback { return &s.HttpCallback{} }
+	}
+}
+
+// RunScheduleDaoSuite checks the behaviour of a schedule DAO against the contract of dao.ScheduleDao
+func RunScheduleDaoSuite(t *testing.T, factory ScheduleDaoFactory) {
+	registerCallbacks()
+
+	for _, test := range []struct {
+		name string
+		run  func(t *testing.T, d dao.ScheduleDao)
+	}{
+		{"CreateSchedule", testCreateSchedule},
+		{"ScheduleNotFound", testScheduleNotFound},
+		{"UpdateStatus", testUpdateStatus},
+		{"DeleteSchedule", testDeleteSchedule},
+		{"RecurringSchedule", testRecurringSchedule},
+		{"ScheduleRuns", testScheduleRuns},
+		{"PaginatedSchedules", testPaginatedSchedules},
+		{"SchedulesForEntity", testSchedulesForEntity},
+		{"BulkAction", testBulkAction},
+		{"DedupSchedule", testDedupSchedule},
+		{"Labels", testLabels},
+		{"LabelsOfDeletedSchedules", testLabelsOfDeletedSchedules},
+		{"Usage", testUsage},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, factory(t))
+		})
+	}
+}
+
+func testCreateSchedule(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+	schedule := newSchedule(app, baseTime().Add(10*time.Second))
+
+	_, err := d.CreateSchedule(schedule, app)
+	require.Nil(t, err)
+
+	found, err := d.GetSchedule(schedule.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, schedule.ScheduleId, found.ScheduleId)
+	assert.Equal(t, app.AppId, found.AppId)
+	assert.Equal(t, schedule.ScheduleTime, found.ScheduleTime)
+	assert.Equal(t, schedule.ScheduleGroup, found.ScheduleGroup)
+	assert.Equal(t, schedule.Payload, found.Payload)
+	assert.Equal(t, schedule.GetCallbackDetails(), found.GetCallbackDetails())
+
+	found, err = d.GetEnrichedSchedule(schedule.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, s.Scheduled, found.Status)
+}
+
+func testScheduleNotFound(t *testing.T, d dao.ScheduleDao) {
+	id := gocql.TimeUUID()
+
+	_, err := d.GetSchedule(id)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	_, err = d.GetEnrichedSchedule(id)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	_, err = d.DeleteSchedule(id)
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
+
+func testUpdateStatus(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+	fired := newSchedule(app, baseTime())
+	pending := newSchedule(app, baseTime())
+
+	for _, schedule := range []s.Schedule{fired, pending} {
+		_, err := d.CreateSchedule(schedule, app)
+		require.Nil(t, err)
+	}
+
+	fired.Status = s.Failure
+	fired.ErrorMessage = "callback failed"
+	require.Nil(t, d.UpdateStatus([]s.Schedule{fired}, app))
+
+	found, err := d.GetEnrichedSchedule(fired.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, s.Failure, found.Status)
+	assert.Equal(t, "callback failed", found.ErrorMessage)
+
+	found, err = d.GetSchedule(fired.ScheduleId)
+	require.Nil(t, err)
+	require.Nil(t, d.EnrichSchedule(&found))
+	assert.Equal(t, s.Failure, found.Status)
+
+	enriched, err := d.OptimizedEnrichSchedule([]s.Schedule{fired, pending})
+	require.Nil(t, err)
+	require.Len(t, enriched, 2)
+
+	statuses := map[gocql.UUID]s.Status{}
+	for _, schedule := range enriched {
+		statuses[schedule.ScheduleId] = schedule.Status
+	}
+	assert.Equal(t, map[gocql.UUID]s.Status{fired.ScheduleId: s.Failure, pending.ScheduleId: s.Scheduled}, statuses)
+}
+
+func testDeleteSchedule(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+	schedule := newSchedule(app, baseTime())
+
+	_, err := d.CreateSchedule(schedule, app)
+	require.Nil(t, err)
+
+	deleted, err := d.DeleteSchedule(schedule.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, schedule.ScheduleId, deleted.ScheduleId)
+
+	_, err = d.GetSchedule(schedule.ScheduleId)
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
+
+// Get a recurring schedule of the app, in the first partition
+func newRecurringSchedule(app s.App) s.Schedule {
+	schedule := newSchedule(app, time.Now())
+	schedule.ScheduleTime = 0
+	schedule.ScheduleGroup = 0
+	schedule.CronExpression = "*/5 * * * *"
+	return schedule
+}
+
+func testRecurringSchedule(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+	schedule := newRecurringSchedule(app)
+
+	created, err := d.CreateSchedule(schedule, app)
+	require.Nil(t, err)
+	assert.Equal(t, s.Scheduled, created.Status)
+
+	found, err := d.GetSchedule(schedule.ScheduleId)
+	require.Nil(t, err)
+	assert.True(t, found.IsRecurring())
+	assert.Equal(t, schedule.CronExpression, found.CronExpression)
+	assert.Equal(t, s.Scheduled, found.Status)
+
+	schedules, errs := d.GetCronSchedulesByApp(app.AppId, s.Scheduled)
+	assert.Empty(t, errs)
+	assert.Equal(t, []gocql.UUID{schedule.ScheduleId}, scheduleIds(schedules))
+
+	partitionSchedules, partitionErrs := d.GetRecurringScheduleByPartition(schedule.PartitionId)
+	assert.Empty(t, partitionErrs)
+	assert.Contains(t, scheduleIds(partitionSchedules), schedule.ScheduleId)
+
+	deleted, err := d.DeleteSchedule(schedule.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, s.Deleted, deleted.Status)
+
+	found, err = d.GetSchedule(schedule.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, s.Deleted, found.Status)
+
+	schedules, _ = d.GetCronSchedulesByApp(app.AppId, s.Scheduled)
+	assert.Empty(t, schedules)
+
+	schedules, _ = d.GetCronSchedulesByApp(app.AppId, s.Deleted)
+	assert.Equal(t, []gocql.UUID{schedule.ScheduleId}, scheduleIds(schedules))
+}
+
+func testScheduleRuns(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+	parent := newRecurringSchedule(app)
+
+	_, err := d.CreateSchedule(parent, app)
+	require.Nil(t, err)
+
+	now := time.Now().Truncate(time.Minute)
+	past := parent.CloneAsOneTime(now.Add(-10 * time.Minute))
+	future := parent.CloneAsOneTime(now.Add(10 * time.Minute))
+	farthest := parent.CloneAsOneTime(now.Add(20 * time.Minute))
+	// A run shifted by a calendar keeps the time the cron matched
+	future.RunNumber = 3
+	future.NominalTime = now.Add(5 * time.Minute).Unix()
+
+	for _, run := range []s.Schedule{past, future, farthest} {
+		_, err = d.CreateRun(run, app)
+		require.Nil(t, err)
+	}
+
+	runs, _, err := d.GetScheduleRuns(parent.ScheduleId, 10, "past", nil)
+	require.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{past.ScheduleId}, scheduleIds(runs))
+
+	runs, _, err = d.GetScheduleRuns(parent.ScheduleId, 10, "future", nil)
+	require.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{future.ScheduleId, farthest.ScheduleId}, scheduleIds(runs))
+	assert.Equal(t, future.RunNumber, runs[0].RunNumber)
+	assert.Equal(t, future.NominalTime, runs[0].NominalTime)
+	assert.Zero(t, runs[1].NominalTime)
+
+	runs, _, err = d.GetScheduleRuns(parent.ScheduleId, 10, "", nil)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []gocql.UUID{past.ScheduleId, future.ScheduleId, farthest.ScheduleId}, scheduleIds(runs))
+
+	found, err := d.GetSchedule(future.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, parent.ScheduleId, found.ParentScheduleId)
+
+	// Deleting the recurring schedule removes the runs which have not fired yet
+	_, err = d.DeleteSchedule(parent.ScheduleId)
+	require.Nil(t, err)
+
+	_, err = d.GetSchedule(future.ScheduleId)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	_, err = d.GetSchedule(past.ScheduleId)
+	assert.Nil(t, err)
+}
+
+func testPaginatedSchedules(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(2)
+	base := baseTime()
+
+	var created []gocql.UUID
+	var fired s.Schedule
+	for i := 0; i < 5; i++ {
+		schedule := newSchedule(app, base.Add(time.Duration(i)*time.Minute))
+		schedule.PartitionId = i % 2
+		_, err := d.CreateSchedule(schedule, app)
+		require.Nil(t, err)
+
+		created = append(created, schedule.ScheduleId)
+		fired = schedule
+	}
+
+	// A schedule of another app in the same time range is not returned
+	_, err := d.CreateSchedule(newSchedule(newApp(2), base), app)
+	require.Nil(t, err)
+
+	fired.Status = s.Success
+	require.Nil(t, d.UpdateStatus([]s.Schedule{fired}, app))
+
+	timeRange := dao.Range{StartTime: base, EndTime: base.Add(time.Hour)}
+
+	var found []gocql.UUID
+	var pageState []byte
+	continuation := time.Unix(0, 0)
+	for pages := 0; pages < 10; pages++ {
+		var schedules []s.Schedule
+		schedules, pageState, continuation, err = d.GetPaginatedSchedules(app.AppId, int(app.Partitions), timeRange, 2, "", pageState, continuation)
+		require.Nil(t, err)
+		assert.LessOrEqual(t, len(schedules), 2)
+
+		found = append(found, scheduleIds(schedules)...)
+		if len(pageState) == 0 {
+			break
+		}
+	}
+	assert.ElementsMatch(t, created, found)
+
+	schedules, _, _, err := d.GetPaginatedSchedules(app.AppId, int(app.Partitions), timeRange, 10, s.Success, nil, time.Unix(0, 0))
+	require.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{fired.ScheduleId}, scheduleIds(schedules))
+}
+
+// Get the ids of all the schedules of an app partition in a time bucket, following the page states
+func entityScheduleIds(t *testing.T, d dao.ScheduleDao, app s.App, partitionId int, timeBucket time.Time) []gocql.UUID {
+	var ids []gocql.UUID
+	var pageState []byte
+
+	for {
+		iter := d.GetSchedulesForEntity(app.AppId, partitionId, timeBucket, pageState)
+		row := make(map[string]interface{})
+		for iter.MapScan(row) {
+			var schedule s.Schedule
+			require.Nil(t, schedule.CreateScheduleFromCassandraMap(row))
+			ids = append(ids, schedule.ScheduleId)
+			row = make(map[string]interface{})
+		}
+
+		pageState = iter.PageState()
+		require.Nil(t, iter.Close())
+		if len(pageState) == 0 {
+			return ids
+		}
+	}
+}
+
+func testSchedulesForEntity(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(2)
+	base := baseTime()
+
+	var partition []gocql.UUID
+	for i := 0; i < 3; i++ {
+		schedule := newSchedule(app, base.Add(time.Duration(i)*time.Second))
+		_, err := d.CreateSchedule(schedule, app)
+		require.Nil(t, err)
+		partition = append(partition, schedule.ScheduleId)
+	}
+
+	other := newSchedule(app, base)
+	other.PartitionId = 1
+	_, err := d.CreateSchedule(other, app)
+	require.Nil(t, err)
+
+	later := newSchedule(app, base.Add(time.Minute))
+	_, err = d.CreateSchedule(later, app)
+	require.Nil(t, err)
+
+	assert.ElementsMatch(t, partition, entityScheduleIds(t, d, app, 0, base))
+	assert.Equal(t, []gocql.UUID{other.ScheduleId}, entityScheduleIds(t, d, app, 1, base))
+	assert.Equal(t, []gocql.UUID{later.ScheduleId}, entityScheduleIds(t, d, app, 0, base.Add(time.Minute)))
+}
+
+func testBulkAction(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+	base := baseTime()
+
+	pending := newSchedule(app, base)
+	fired := newSchedule(app, base)
+	for _, schedule := range []s.Schedule{pending, fired} {
+		_, err := d.CreateSchedule(schedule, app)
+		require.Nil(t, err)
+	}
+
+	fired.Status = s.Success
+	require.Nil(t, d.UpdateStatus([]s.Schedule{fired}, app))
+
+	require.Nil(t, d.BulkAction(app, 0, base, []s.Status{s.Scheduled}, s.Delete))
+
+	_, err := d.GetSchedule(pending.ScheduleId)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	_, err = d.GetSchedule(fired.ScheduleId)
+	assert.Nil(t, err)
+}
+
+func testDedupSchedule(t *testing.T, d dao.ScheduleDao) {
+	for _, test := range []struct {
+		name         string
+		policy       s.DedupPolicy
+		fired        bool
+		err          error
+		deduplicated bool
+		replaced     bool
+	}{
+		{name: "pending schedule is rejected", policy: s.DedupReject, err: dao.ErrDuplicateSchedule},
+		{name: "pending schedule is kept", policy: s.DedupKeepFirst, deduplicated: true},
+		{name: "pending schedule is replaced", policy: s.DedupReplace, replaced: true},
+		{name: "fired schedule releases the key", policy: s.DedupReject, fired: true},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			app := newApp(1)
+			app.Configuration.DedupPolicy = test.policy
+
+			first := newSchedule(app, baseTime())
+			first.DedupKey = "order-1"
+			_, err := d.CreateSchedule(first, app)
+			require.Nil(t, err)
+
+			if test.fired {
+				first.Status = s.Success
+				require.Nil(t, d.UpdateStatus([]s.Schedule{first}, app))
+			}
+
+			second := newSchedule(app, baseTime())
+			second.DedupKey = "order-1"
+			created, err := d.CreateSchedule(second, app)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.deduplicated, created.Deduplicated)
+			if test.deduplicated {
+				assert.Equal(t, first.ScheduleId, created.ScheduleId)
+			}
+			if assert.Equal(t, test.replaced, created.Replaced != nil) && test.replaced {
+				assert.Equal(t, first.ScheduleId, created.Replaced.ScheduleId)
+				assert.Equal(t, first.Payload, created.Replaced.Payload)
+			}
+
+			_, err = d.GetSchedule(first.ScheduleId)
+			assert.Equal(t, test.replaced, err == gocql.ErrNotFound)
+
+			_, err = d.GetSchedule(second.ScheduleId)
+			assert.Equal(t, test.err != nil || test.deduplicated, err == gocql.ErrNotFound)
+		})
+	}
+}
+
+func testLabels(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+
+	europe := newSchedule(app, baseTime())
+	europe.Labels = map[string]string{"region": "eu", "tier": "gold"}
+	asia := newSchedule(app, baseTime())
+	asia.Labels = map[string]string{"region": "asia", "tier": "gold"}
+
+	for _, schedule := range []s.Schedule{europe, asia} {
+		_, err := d.CreateSchedule(schedule, app)
+		require.Nil(t, err)
+	}
+
+	schedules, _, err := d.GetSchedulesByLabel(app.AppId, []string{"tier:gold"}, 10, nil)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []gocql.UUID{europe.ScheduleId, asia.ScheduleId}, scheduleIds(schedules))
+
+	schedules, _, err = d.GetSchedulesByLabel(app.AppId, []string{"tier:gold", "region:eu"}, 10, nil)
+	require.Nil(t, err)
+	require.Equal(t, []gocql.UUID{europe.ScheduleId}, scheduleIds(schedules))
+	assert.Equal(t, europe.Labels, schedules[0].Labels)
+
+	require.Nil(t, d.DeleteLabels(europe))
+
+	schedules, _, err = d.GetSchedulesByLabel(app.AppId, []string{"tier:gold"}, 10, nil)
+	require.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{asia.ScheduleId}, scheduleIds(schedules))
+
+	labels, err := d.GetLabels(app.AppId, []gocql.UUID{europe.ScheduleId, asia.ScheduleId})
+	require.Nil(t, err)
+	assert.Equal(t, map[gocql.UUID]map[string]string{asia.ScheduleId: asia.Labels}, labels)
+}
+
+// Label rows are removed along with the schedules which are deleted or replaced by a dedup key
+func testLabelsOfDeletedSchedules(t *testing.T, d dao.ScheduleDao) {
+	app := newApp(1)
+	app.Configuration.DedupPolicy = s.DedupReplace
+	labels := map[string]string{"tier": "gold"}
+
+	oneTime := newSchedule(app, baseTime())
+	oneTime.Labels = labels
+	recurring := newRecurringSchedule(app)
+	recurring.Labels = labels
+	replaced := newSchedule(app, baseTime())
+	replaced.Labels = labels
+	replaced.DedupKey = "order-1"
+
+	for _, schedule := range []s.Schedule{oneTime, recurring, replaced} {
+		_, err := d.CreateSchedule(schedule, app)
+		require.Nil(t, err)
+	}
+
+	for _, schedule := range []s.Schedule{oneTime, recurring} {
+		_, err := d.DeleteSchedule(schedule.ScheduleId)
+		require.Nil(t, err)
+	}
+
+	replacement := newSchedule(app, baseTime())
+	replacement.Labels = map[string]string{"tier": "silver"}
+	replacement.DedupKey = "order-1"
+	_, err := d.CreateSchedule(replacement, app)
+	require.Nil(t, err)
+
+	found, err := d.GetLabels(app.AppId, []gocql.UUID{oneTime.ScheduleId, recurring.ScheduleId, replaced.ScheduleId, replacement.ScheduleId})
+	require.Nil(t, err)
+	assert.Equal(t, map[gocql.UUID]map[string]string{replacement.ScheduleId: replacement.Labels}, found)
+
+	schedules, _, err := d.GetSchedulesByLabel(app.AppId, []string{"tier:gold"}, 10, nil)
+	require.Nil(t, err)
+	assert.Empty(t, schedules)
+}
+
+func testUsage(t *testing.T, d dao.ScheduleDao) {
+	appId := newAppId()
+
+	usage, err := d.GetUsage(appId)
+	require.Nil(t, err)
+	assert.Equal(t, s.Usage{}, usage)
+
+	require.Nil(t, d.UpdateUsage(appId, s.PendingUsage, 2))
+	require.Nil(t, d.UpdateUsage(appId, s.PendingUsage, -1))
+	require.Nil(t, d.UpdateUsage(appId, s.RecurringUsage, 3))
+
+	usage, err = d.GetUsage(appId)
+	require.Nil(t, err)
+	assert.Equal(t, s.Usage{PendingSchedules: 1, RecurringSchedules: 3}, usage)
+
+	// Releasing more schedules than are counted, e.g. the ones created before the usage was tracked, stops at zero
+	require.Nil(t, d.UpdateUsage(appId, s.PendingUsage, -2))
+	require.Nil(t, d.UpdateUsage(newAppId(), s.RecurringUsage, -1))
+	require.Nil(t, d.UpdateUsage(appId, s.PendingUsage, 1))
+
+	usage, err = d.GetUsage(appId)
+	require.Nil(t, err)
+	assert.Equal(t, s.Usage{PendingSchedules: 1, RecurringSchedules: 3}, usage)
+}
+
+// RunClusterDaoSuite checks the behaviour of a cluster DAO against the contract of dao.ClusterDao
+func RunClusterDaoSuite(t *testing.T, factory ClusterDaoFactory) {
+	for _, test := range []struct {
+		name string
+		run  func(t *testing.T, d dao.ClusterDao)
+	}{
+		{"Apps", testApps},
+		{"Entities", testEntities},
+		{"Configurations", testConfigurations},
+		{"ConfigurationMergePatch", testConfigurationMergePatch},
+		{"Calendars", testCalendars},
+		{"ApiKeys", testApiKeys},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, factory(t))
+		})
+	}
+}
+
+func testApps(t *testing.T, d dao.ClusterDao) {
+	app := newApp(2)
+	app.Configuration = s.Configuration{}
+	require.Nil(t, d.InsertApp(app))
+
+	found, err := d.GetApp(app.AppId)
+	require.Nil(t, err)
+	assert.Equal(t, app, found)
+
+	apps, err := d.GetApps(app.AppId)
+	require.Nil(t, err)
+	assert.Equal(t, []s.App{app}, apps)
+
+	found, err = d.GetDCAwareApp(app.AppId)
+	require.Nil(t, err)
+	assert.Equal(t, app.AppId, found.AppId)
+
+	require.Nil(t, d.UpdateAppActiveStatus(app.AppId, false))
+	d.InvalidateSingleAppCache(app.AppId)
+
+	found, err = d.GetApp(app.AppId)
+	require.Nil(t, err)
+	assert.False(t, found.Active)
+
+	_, err = d.GetApp(newAppId())
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
+
+func testEntities(t *testing.T, d dao.ClusterDao) {
+	app := newApp(2)
+	app.Configuration = s.Configuration{}
+	require.Nil(t, d.InsertApp(app))
+
+	appId := app.AppId
+	node := appId + "-node"
+
+	require.Nil(t, d.UpdateEntityStatus(appId+".0", "other-node", 0))
+	require.Nil(t, d.UpdateEntityStatus(appId+".0", node, 1))
+	require.Nil(t, d.UpdateEntityStatus(appId+".1", node, 1))
+
+	entity := d.GetEntityInfo(appId + ".0")
+	assert.Equal(t, node, entity.Node)
+	assert.Equal(t, 1, entity.Status)
+
+	var ids []string
+	for _, entity := range d.GetAllEntitiesInfoOfNode(node) {
+		ids = append(ids, entity.Id)
+	}
+	assert.ElementsMatch(t, []string{appId + ".0", appId + ".1"}, ids)
+
+	entities, err := d.GetAllEntitiesForApp(appId)
+	require.Nil(t, err)
+	assert.Len(t, entities, 2)
+
+	ids = nil
+	for _, entity := range d.GetAllEntitiesInfo() {
+		ids = append(ids, entity.Id)
+	}
+	assert.Contains(t, ids, appId+".1")
+}
+
+// Get the max configs of the cluster, creating them if the storage has none
+func maxConfiguration(t *testing.T, d dao.ClusterDao) s.Configuration {
+	app, err := d.GetApp(dao.MaxConfigApp)
+	if err == gocql.ErrNotFound {
+		app = s.App{
+			AppId:         dao.MaxConfigApp,
+			Configuration: s.Configuration{PayloadSize: 4096, HttpRetries: 5, HttpTimeout: 5000, FiredScheduleRetentionPeriod: 7, FutureScheduleCreationPeriod: 30},
+		}
+		require.Nil(t, d.InsertApp(app))
+		d.InvalidateSingleAppCache(dao.MaxConfigApp)
+	} else {
+		require.Nil(t, err)
+	}
+	return app.Configuration
+}
+
+func testConfigurations(t *testing.T, d dao.ClusterDao) {
+	max := maxConfiguration(t, d)
+	app := newApp(1)
+	app.Configuration = s.Configuration{}
+	require.Nil(t, d.InsertApp(app))
+
+	configuration := s.Configuration{PayloadSize: max.PayloadSize, DedupPolicy: s.DedupReject}
+	_, err := d.CreateConfigurations(app.AppId, configuration)
+	require.Nil(t, err)
+
+	found, err := d.GetConfiguration(app.AppId)
+	require.Nil(t, err)
+	assert.Equal(t, configuration, found)
+
+	updated, err := d.UpdateConfiguration(app.AppId, s.PatchOf(s.Configuration{DedupPolicy: s.DedupKeepFirst}))
+	require.Nil(t, err)
+	assert.Equal(t, s.Configuration{PayloadSize: max.PayloadSize, DedupPolicy: s.DedupKeepFirst}, updated)
+
+	// Invalid configurations are not written
+	_, err = d.UpdateConfiguration(app.AppId, s.PatchOf(s.Configuration{PayloadSize: max.PayloadSize + 1}))
+	assert.ErrorIs(t, err, dao.ErrInvalidConfiguration)
+
+	found, err = d.GetConfiguration(app.AppId)
+	require.Nil(t, err)
+	assert.Equal(t, updated, found)
+
+	_, err = d.DeleteConfiguration(app.AppId)
+	require.Nil(t, err)
+
+	found, err = d.GetConfiguration(app.AppId)
+	require.Nil(t, err)
+	assert.True(t, found.IsEmpty())
+
+	history, err := d.GetConfigurationHistory(app.AppId)
+	require.Nil(t, err)
+	require.Len(t, history, 3)
+
+	var operations []string
+	for _, version := range history {
+		operations = append(operations, version.Operation)
+	}
+	assert.Equal(t, []string{dao.ConfigurationDeleted, dao.ConfigurationUpdated, dao.ConfigurationCreated}, operations)
+	assert.Equal(t, 3, history[0].Version)
+	assert.Equal(t, updated, history[1].Configuration)
+}
+
+func testConfigurationMergePatch(t *testing.T, d dao.ClusterDao) {
+	max := maxConfiguration(t, d)
+	app := newApp(1)
+	app.Configuration = s.Configuration{}
+	require.Nil(t, d.InsertApp(app))
+
+	configuration := s.Configuration{
+		PayloadSize:          max.PayloadSize,
+		RetryPolicy:          &s.RetryPolicy{MaxAttempts: 2},
+		AllowedCallbackHosts: []string{"api.example.com"},
+	}
+	_, err := d.CreateConfigurations(app.AppId, configuration)
+	require.Nil(t, err)
+
+	// Fields set to null are cleared, the other ones are left as they are
+	updated, err := d.UpdateConfiguration(app.AppId, s.ConfigurationPatch(`{"retryPolicy": null, "allowedCallbackHosts": null}`))
+	require.Nil(t, err)
+	assert.Equal(t, s.Configuration{PayloadSize: max.PayloadSize}, updated)
+
+	found, err := d.GetConfiguration(app.AppId)
+	require.Nil(t, err)
+	assert.Equal(t, updated, found)
+
+	_, err = d.UpdateConfiguration(app.AppId, s.ConfigurationPatch(`[]`))
+	assert.ErrorIs(t, err, dao.ErrInvalidConfiguration)
+}
+
+func testCalendars(t *testing.T, d dao.ClusterDao) {
+	calendar := s.Calendar{Name: newAppId(), Timezone: "UTC", Dates: []string{"2030-01-01"}, Weekend: []string{"Sunday"}}
+
+	_, err := d.GetCalendar(calendar.Name)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	require.Nil(t, d.InsertCalendar(calendar))
+
+	found, err := d.GetCalendar(calendar.Name)
+	require.Nil(t, err)
+	assert.Equal(t, calendar, found)
+
+	calendars, err := d.GetCalendars()
+	require.Nil(t, err)
+	assert.Contains(t, calendars, calendar)
+
+	require.Nil(t, d.DeleteCalendar(calendar.Name))
+
+	_, err = d.GetCalendar(calendar.Name)
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
+
+func testApiKeys(t *testing.T, d dao.ClusterDao) {
+	key := s.ApiKey{
+		Id:        newAppId(),
+		Apps:      []string{"app"},
+		Role:      s.AppWriter,
+		Hash:      "hash",
+		CreatedAt: time.Now().Truncate(time.Second),
+	}
+
+	_, err := d.GetApiKey(key.Id)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	require.Nil(t, d.InsertApiKey(key))
+
+	found, err := d.GetApiKey(key.Id)
+	require.Nil(t, err)
+	assert.Equal(t, key.Id, found.Id)
+	assert.Equal(t, key.Apps, found.Apps)
+	assert.Equal(t, key.Role, found.Role)
+	assert.Equal(t, key.Hash, found.Hash)
+	assert.True(t, key.CreatedAt.Equal(found.CreatedAt))
+
+	require.Nil(t, d.DeleteApiKey(key.Id))
+
+	_, err = d.GetApiKey(key.Id)
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
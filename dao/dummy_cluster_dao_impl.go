@@ -82,6 +82,20 @@ func (d DummyClusterDaoImpl) GetApp(appName string) (store.App, error) {
 		return store.App{}, nil
 	case "testAppNotActive":
 		return store.App{Active: false}, nil
+	case "quotaExceededApp", "usageFetchFailureApp":
+		return store.App{
+			AppId:         appName,
+			Partitions:    1,
+			Active:        true,
+			Configuration: store.Configuration{FutureScheduleCreationPeriod: 1000, Quotas: &store.Quotas{PendingSchedules: 10, RecurringSchedules: 1}},
+		}, nil
+	case "rateLimitedApp":
+		return store.App{
+			AppId:         appName,
+			Partitions:    1,
+			Active:        true,
+			Configuration: store.Configuration{FutureScheduleCreationPeriod: 1000, RateLimit: &store.RateLimit{Requests: 1, Period: "1h"}},
+		}, nil
 	default:
 		return store.App{
 			AppId:         appName,
@@ -126,6 +140,8 @@ func (d DummyClusterDaoImpl) CreateConfigurations(appId string, configuration st
 	switch appId {
 	case "testCreateConfigurationsError":
 		return store.Configuration{}, errors.New(fmt.Sprintf("Error creating configurations for app %s", appId))
+	case "testInvalidConfiguration":
+		return store.Configuration{}, ErrInvalidConfiguration
 	default:
 		return store.Configuration{}, nil
 	}
@@ -140,10 +156,12 @@ func (d DummyClusterDaoImpl) GetConfiguration(appId string) (store.Configuration
 	}
 }
 
-func (d DummyClusterDaoImpl) UpdateConfiguration(appId string, configuration store.Configuration) (store.Configuration, error) {
+func (d DummyClusterDaoImpl) UpdateConfiguration(appId string, patch store.ConfigurationPatch) (store.Configuration, error) {
 	switch appId {
 	case "testUpdateConfigurationError":
 		return store.Configuration{}, errors.New(fmt.Sprintf("Error updating configurations for app %s", appId))
+	case "testInvalidConfiguration":
+		return store.Configuration{}, ErrInvalidConfiguration
 	default:
 		return store.Configuration{}, nil
 	}
@@ -158,6 +176,15 @@ func (d DummyClusterDaoImpl) DeleteConfiguration(appId string) (store.Configurat
 	}
 }
 
+func (d DummyClusterDaoImpl) GetConfigurationHistory(appId string) ([]store.ConfigurationVersion, error) {
+	switch appId {
+	case "testGetConfigurationHistoryError":
+		return nil, errors.New(fmt.Sprintf("Error getting configuration history for app %s", appId))
+	default:
+		return []store.ConfigurationVersion{{Version: 1, Operation: ConfigurationCreated}}, nil
+	}
+}
+
 func (d DummyClusterDaoImpl) GetApps(appId string) ([]store.App, error) {
 	switch appId {
 	case "testGetAppsError":
@@ -194,3 +221,69 @@ func (d DummyClusterDaoImpl) GetApps(appId string) ([]store.App, error) {
 func (d DummyClusterDaoImpl) GetDCAwareApp(appName string) (store.App, error) {
 	return store.App{}, nil
 }
+
+func (d DummyClusterDaoImpl) GetAllEntitiesForApp(appId string) ([]e.EntityInfo, error) {
+	return []e.EntityInfo{}, nil
+}
+
+func (d DummyClusterDaoImpl) InsertCalendar(calendar store.Calendar) error {
+	switch calendar.Name {
+	case "calendarInsertFailure":
+		return errors.New("error")
+	}
+	return nil
+}
+
+func (d DummyClusterDaoImpl) GetCalendar(name string) (store.Calendar, error) {
+	switch name {
+	case "calendarNotFound":
+		return store.Calendar{}, gocql.ErrNotFound
+	case "calendarFetchFailure":
+		return store.Calendar{}, errors.New("error")
+	default:
+		return store.Calendar{Name: name, Dates: []string{"2023-12-25"}}, nil
+	}
+}
+
+func (d DummyClusterDaoImpl) GetCalendars() ([]store.Calendar, error) {
+	return []store.Calendar{{Name: "holidays", Dates: []string{"2023-12-25"}}}, nil
+}
+
+func (d DummyClusterDaoImpl) DeleteCalendar(name string) error {
+	switch name {
+	case "calendarDeleteFailure":
+		return errors.New("error")
+	}
+	return nil
+}
+
+func (d DummyClusterDaoImpl) InsertApiKey(key store.ApiKey) error {
+	for _, app := range key.Apps {
+		if app == "apiKeyInsertFailure" {
+			return errors.New("error")
+		}
+	}
+	return nil
+}
+
+// GetApiKey returns keys whose secret is "secret", the role and app of the key are taken from ids like "app-writer.test"
+func (d DummyClusterDaoImpl) GetApiKey(id string) (store.ApiKey, error) {
+	switch id {
+	case "apiKeyNotFound":
+		return store.ApiKey{}, gocql.ErrNotFound
+	case "apiKeyFetchFailure":
+		return store.ApiKey{}, errors.New("error")
+	case "admin":
+		return store.ApiKey{Id: id, Role: store.Admin, Hash: store.HashSecret("secret")}, nil
+	default:
+		return store.ApiKey{Id: id, Apps: []string{"test"}, Role: store.Role(id), Hash: store.HashSecret("secret")}, nil
+	}
+}
+
+func (d DummyClusterDaoImpl) DeleteApiKey(id string) error {
+	switch id {
+	case "apiKeyDeleteFailure":
+		return errors.New("error")
+	}
+	return nil
+}
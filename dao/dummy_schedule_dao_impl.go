@@ -33,6 +33,8 @@ func (d *DummyScheduleDaoImpl) CreateSchedule(schedule s.Schedule, app s.App) (s
 	switch schedule.AppId {
 	case "createScheduleFailureApp":
 		return schedule, errors.New("error")
+	case "duplicateScheduleApp":
+		return schedule, ErrDuplicateSchedule
 	}
 	return schedule, nil
 }
@@ -96,11 +98,7 @@ func (d *DummyScheduleDaoImpl) GetScheduleRuns(uuid gocql.UUID, size int64, when
 					ScheduleGroup: 1,
 					Callback: &s.HttpCallback{
 						Type: "exampleType",
-						Details: struct {
-							Url     string            `json:"url"`
-							Method  string            `json:"method"`
-							Headers map[string]string `json:"headers"`
-						}{
+						Details: s.Details{
 							Url:    "http://example.com/callback",
 							Method: "TEST",
 							Headers: map[string]string{
@@ -154,11 +152,7 @@ func (d *DummyScheduleDaoImpl) GetCronSchedulesByApp(appId string, status s.Stat
 				ScheduleGroup: 1,
 				Callback: &s.HttpCallback{
 					Type: "exampleType",
-					Details: struct {
-						Url     string            `json:"url"`
-						Method  string            `json:"method"`
-						Headers map[string]string `json:"headers"`
-					}{
+					Details: s.Details{
 						Url:    "http://example.com/callback",
 						Method: "TEST",
 						Headers: map[string]string{
@@ -186,11 +180,7 @@ func (d *DummyScheduleDaoImpl) GetCronSchedulesByApp(appId string, status s.Stat
 				ScheduleGroup: 1,
 				Callback: &s.HttpCallback{
 					Type: "exampleType",
-					Details: struct {
-						Url     string            `json:"url"`
-						Method  string            `json:"method"`
-						Headers map[string]string `json:"headers"`
-					}{
+					Details: s.Details{
 						Url:    "http://example.com/callback",
 						Method: "TEST",
 						Headers: map[string]string{
@@ -211,3 +201,60 @@ func (d *DummyScheduleDaoImpl) GetCronSchedulesByApp(appId string, status s.Stat
 func (d *DummyScheduleDaoImpl) BulkAction(app s.App, partitionId int, scheduleTimeGroup time.Time, status []s.Status, actionType s.ActionType) error {
 	return nil
 }
+
+func (d *DummyScheduleDaoImpl) GetLabels(appId string, scheduleIds []gocql.UUID) (map[gocql.UUID]map[string]string, error) {
+	return map[gocql.UUID]map[string]string{}, nil
+}
+
+func (d *DummyScheduleDaoImpl) GetSchedulesByLabel(appId string, selectors []string, size int64, pageState []byte) ([]s.Schedule, []byte, error) {
+	switch appId {
+	case "labelFetchFailureApp":
+		return nil, nil, errors.New("error")
+	case "labelDeleteFailureApp":
+		// The schedule no longer exists, so its label rows are deleted on their own
+		return []s.Schedule{
+			{
+				ScheduleId: gocql.UUID{},
+				AppId:      appId,
+				Labels:     map[string]string{"tenant": "acme"},
+			},
+		}, nil, nil
+	case "labelApp":
+		return []s.Schedule{
+			{
+				ScheduleId: gocql.MustRandomUUID(),
+				AppId:      appId,
+				Labels:     map[string]string{"tenant": "acme"},
+			},
+			{
+				ScheduleId: gocql.MustRandomUUID(),
+				AppId:      appId,
+				Labels:     map[string]string{"tenant": "acme", "env": "prod"},
+			},
+		}, nil, nil
+	default:
+		return []s.Schedule{}, nil, nil
+	}
+}
+
+func (d *DummyScheduleDaoImpl) DeleteLabels(schedule s.Schedule) error {
+	switch schedule.AppId {
+	case "labelDeleteFailureApp":
+		return errors.New("error")
+	}
+	return nil
+}
+
+func (d *DummyScheduleDaoImpl) UpdateUsage(appId string, kind s.UsageKind, delta int64) error {
+	return nil
+}
+
+func (d *DummyScheduleDaoImpl) GetUsage(appId string) (s.Usage, error) {
+	switch appId {
+	case "usageFetchFailureApp":
+		return s.Usage{}, errors.New("error")
+	case "quotaExceededApp":
+		return s.Usage{PendingSchedules: 10, RecurringSchedules: 1}, nil
+	}
+	return s.Usage{PendingSchedules: 1}, nil
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Separates the variable length parts of the keys of the embedded database
+const keySep = 0x00
+
+// Columns of the schedules returned by GetSchedulesForEntity, in the order the Cassandra implementation selects them
+var entityScheduleColumns = []string{
+	"app_id",
+	"partition_id",
+	"schedule_time_group",
+	"schedule_id",
+	"callback_type",
+	"callback_details",
+	"payload",
+	"schedule_time",
+	"parent_schedule_id",
+	"run_number",
+	"chain",
+	"deadline",
+	"priority",
+}
+
+// embeddedSchedule is a row of the schedules bucket
+type embeddedSchedule struct {
+	AppId            string     `json:"appId"`
+	PartitionId      int        `json:"partitionId"`
+	ScheduleGroup    int64      `json:"scheduleGroup"`
+	ScheduleId       gocql.UUID `json:"scheduleId"`
+	ScheduleTime     int64      `json:"scheduleTime"`
+	Payload          string     `json:"payload"`
+	CallbackType     string     `json:"callbackType"`
+	CallbackDetails  string     `json:"callbackDetails"`
+	ParentScheduleId gocql.UUID `json:"parentScheduleId"`
+	RunNumber        int        `json:"runNumber"`
+	NominalTime      int64      `json:"nominalTime,omitempty"`
+	Chain            string     `json:"chain"`
+	Deadline         int64      `json:"deadline"`
+	Priority         string     `json:"priority"`
+	ExpiresAt        int64      `json:"expiresAt"`
+}
+
+// Get the row in the shape of the maps scanned from Cassandra
+func (r embeddedSchedule) toMap() map[string]interface{} {
+	var deadline, nominalTime time.Time
+	if r.Deadline != 0 {
+		deadline = time.Unix(r.Deadline, 0)
+	}
+	if r.NominalTime != 0 {
+		nominalTime = time.Unix(r.NominalTime, 0)
+	}
+
+	return map[string]interface{}{
+		"app_id":              r.AppId,
+		"partition_id":        r.PartitionId,
+		"schedule_time_group": time.Unix(r.ScheduleGroup, 0),
+		"schedule_id":         r.ScheduleId,
+		"callback_type":       r.CallbackType,
+		"callback_details":    r.CallbackDetails,
+		"payload":             r.Payload,
+		"schedule_time":       time.Unix(r.ScheduleTime, 0),
+		"parent_schedule_id":  r.ParentScheduleId,
+		"run_number":          r.RunNumber,
+		"nominal_time":        nominalTime,
+		"chain":               r.Chain,
+		"deadline":            deadline,
+		"priority":            r.Priority,
+	}
+}
+
+// Key of the row, sorted by app, schedule time group and partition
+func (r embeddedSchedule) key() []byte {
+	return scheduleKey(r.AppId, r.ScheduleGroup, r.PartitionId, r.ScheduleId)
+}
+
+// Key of the row in the runs of its parent schedule
+func (r embeddedSchedule) runKey() []byte {
+	key := append([]byte{}, r.ParentScheduleId[:]...)
+	key = appendUint64(key, uint64(r.ScheduleGroup))
+	return append(key, r.ScheduleId[:]...)
+}
+
+// embeddedStatus is a row of the status bucket, keyed like the schedule it belongs to
+type embeddedStatus struct {
+	Status                string `json:"status"`
+	ErrorMsg              string `json:"errorMsg"`
+	ReconciliationHistory string `json:"reconciliationHistory"`
+	TargetStatus          string `json:"targetStatus"`
+	NextScheduleId        string `json:"nextScheduleId"`
+	ExpiresAt             int64  `json:"expiresAt"`
+}
+
+func (r embeddedStatus) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"schedule_status":        r.Status,
+		"error_msg":              r.ErrorMsg,
+		"reconciliation_history": r.ReconciliationHistory,
+		"target_status":          r.TargetStatus,
+		"next_schedule_id":       r.NextScheduleId,
+	}
+}
+
+// embeddedRecurringSchedule is a row of the recurring schedules bucket, keyed by schedule id
+type embeddedRecurringSchedule struct {
+	ScheduleId      gocql.UUID `json:"scheduleId"`
+	AppId           string     `json:"appId"`
+	PartitionId     int        `json:"partitionId"`
+	Payload         string     `json:"payload"`
+	CallbackType    string     `json:"callbackType"`
+	CallbackDetails string     `json:"callbackDetails"`
+	CronExpression  string     `json:"cronExpression"`
+	Status          string     `json:"status"`
+	ExpiresAfter    string     `json:"expiresAfter"`
+	Jitter          string     `json:"jitter"`
+	Calendar        string     `json:"calendar"`
+	CalendarPolicy  string     `json:"calendarPolicy"`
+	Priority        string     `json:"priority"`
+}
+
+func (r embeddedRecurringSchedule) toMap() map[string]interface{} {
+	return map[string]interface{}{
+		"schedule_id":      r.ScheduleId,
+		"app_id":           r.AppId,
+		"partition_id":     r.PartitionId,
+		"payload":          r.Payload,
+		"callback_type":    r.CallbackType,
+		"callback_details": r.CallbackDetails,
+		"cron_expression":  r.CronExpression,
+		"status":           r.Status,
+		"expires_after":    r.ExpiresAfter,
+		"jitter":           r.Jitter,
+		"calendar":         r.Calendar,
+		"calendar_policy":  r.CalendarPolicy,
+		"priority":         r.Priority,
+	}
+}
+
+// embeddedExpiring is a row of the dedup key and label buckets
+type embeddedExpiring struct {
+	ScheduleId gocql.UUID        `json:"scheduleId"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	ExpiresAt  int64             `json:"expiresAt"`
+}
+
+// Check whether a row with the given expiry has expired, rows with no expiry never do
+func expired(expiresAt int64, now time.Time) bool {
+	return expiresAt != 0 && expiresAt <= now.Unix()
+}
+
+// Get the unix time at which a row written now expires, ttl seconds later
+func expiresAtUnix(ttl int) int64 {
+	return time.Now().Unix() + int64(ttl)
+}
+
+func appendUint64(key []byte, value uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], value)
+	return append(key, b[:]...)
+}
+
+func appendUint32(key []byte, value uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], value)
+	return append(key, b[:]...)
+}
+
+// Prefix of the keys of the schedules of an app in a schedule time group
+func scheduleGroupPrefix(appId string, group int64) []byte {
+	key := append([]byte(appId), keySep)
+	return appendUint64(key, uint64(group))
+}
+
+func scheduleKey(appId string, group int64, partitionId int, id gocql.UUID) []byte {
+	key := appendUint32(scheduleGroupPrefix(appId, group), uint32(partitionId))
+	return append(key, id[:]...)
+}
+
+// Get the schedule time group and the partition of a schedule key
+func parseScheduleKey(key []byte) (int64, int) {
+	offset := bytes.IndexByte(key, keySep) + 1
+	return int64(binary.BigEndian.Uint64(key[offset : offset+8])), int(binary.BigEndian.Uint32(key[offset+8 : offset+12]))
+}
+
+// Join the parts of a key with the separator
+func joinKey(parts ...string) []byte {
+	var key []byte
+	for i, part := range parts {
+		if i > 0 {
+			key = append(key, keySep)
+		}
+		key = append(key, part...)
+	}
+	return key
+}
+
+// Get the smallest key greater than all the keys with the prefix, nil if there is none
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// Position the cursor on the first key with the prefix, or the first one after the page state if it is set
+func seekAfter(c *bolt.Cursor, prefix []byte, pageState []byte) ([]byte, []byte) {
+	if len(pageState) == 0 {
+		return c.Seek(prefix)
+	}
+
+	k, v := c.Seek(pageState)
+	if k != nil && bytes.Equal(k, pageState) {
+		return c.Next()
+	}
+	return k, v
+}
+
+// Position the cursor on the last key with the prefix, or the last one before the page state if it is set
+func seekBefore(c *bolt.Cursor, prefix []byte, pageState []byte) ([]byte, []byte) {
+	start := pageState
+	if len(start) == 0 {
+		start = prefixEnd(prefix)
+	}
+
+	if start == nil {
+		return c.Last()
+	}
+	if k, _ := c.Seek(start); k == nil {
+		return c.Last()
+	}
+	return c.Prev()
+}
+
+// Build a schedule from its row, enriched with the status if it has one
+func enrichedEmbeddedSchedule(row embeddedSchedule, status *embeddedStatus, flushPeriod int) (store.Schedule, error) {
+	var schedule store.Schedule
+	if err := schedule.CreateScheduleFromCassandraMap(row.toMap()); err != nil {
+		return schedule, err
+	}
+
+	if status == nil {
+		schedule.SetUnknownStatus(flushPeriod)
+		return schedule, nil
+	}
+
+	return schedule, schedule.SetStatus(status.toMap())
+}
@@ -0,0 +1,395 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/cassandra"
+	e "github.com/myntra/goscheduler/cluster_entity"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/store"
+)
+
+// Maximum number of inconsistent row ids sampled in a LookupReport
+const maxLookupSamples = 100
+
+const insertScheduleLookup string = "INSERT INTO schedules_by_id (" +
+	"schedule_id," +
+	"app_id," +
+	"partition_id," +
+	"schedule_time_group) VALUES (?, ?, ?, ?) USING TTL ?"
+
+const deleteScheduleLookup string = "DELETE FROM schedules_by_id WHERE schedule_id = ?"
+
+const selectScheduleLookup string = "SELECT " +
+	"app_id," +
+	"partition_id," +
+	"schedule_time_group " +
+	"FROM schedules_by_id " +
+	"WHERE schedule_id = ?"
+
+const scanScheduleKeys string = "SELECT " +
+	"app_id," +
+	"partition_id," +
+	"schedule_time_group," +
+	"schedule_id," +
+	"TTL(schedule_time) " +
+	"FROM schedules"
+
+const scanScheduleLookups string = "SELECT " +
+	"schedule_id," +
+	"app_id," +
+	"partition_id," +
+	"schedule_time_group " +
+	"FROM schedules_by_id"
+
+const scanLabels string = "SELECT " +
+	"schedule_id," +
+	"app_id," +
+	"labels," +
+	"TTL(labels) " +
+	"FROM schedules_by_label"
+
+const selectScheduleKey string = "SELECT schedule_id " +
+	"FROM schedules " +
+	"WHERE app_id = ? " +
+	"AND partition_id = ? " +
+	"AND schedule_time_group = ? " +
+	"AND schedule_id = ?"
+
+// LookupReport summarises how a lookup table compares with the table it indexes.
+type LookupReport struct {
+	// Name of the lookup table
+	Table string
+	// Number of rows of the indexed table which were read
+	Checked int
+	// Number of lookup rows written or deleted by a backfill
+	Written int
+	// Number of rows of the indexed table without a matching lookup row
+	Missing int
+	// Number of lookup rows pointing to a row which does not exist or has moved
+	Stale int
+	// Ids of at most maxLookupSamples missing or stale rows
+	Samples []string
+}
+
+// Consistent tells if no missing or stale lookup rows were found.
+func (r LookupReport) Consistent() bool {
+	return r.Missing == 0 && r.Stale == 0
+}
+
+func (r *LookupReport) missing(id string) {
+	r.Missing++
+	r.sample(id)
+}
+
+func (r *LookupReport) stale(id string) {
+	r.Stale++
+	r.sample(id)
+}
+
+func (r *LookupReport) sample(id string) {
+	if len(r.Samples) < maxLookupSamples {
+		r.Samples = append(r.Samples, id)
+	}
+}
+
+// Add the lookup row of a one time schedule to a batch writing the schedule.
+func addScheduleLookup(batch *gocql.Batch, schedule store.Schedule, ttl int) {
+	batch.Query(
+		insertScheduleLookup,
+		schedule.ScheduleId,
+		schedule.AppId,
+		schedule.PartitionId,
+		schedule.ScheduleGroup*constants.SecondsToMillis,
+		ttl)
+}
+
+// Get the partition key of a one time schedule from the schedules_by_id lookup table.
+// Returns gocql.ErrNotFound if the schedule has no lookup row.
+func (s *ScheduleDaoImpl) getScheduleKey(uuid gocql.UUID) (store.Schedule, error) {
+	var appId string
+	var partitionId int
+	var scheduleGroup time.Time
+
+	err := s.Session.Query(selectScheduleLookup, uuid).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+		Scan(&appId, &partitionId, &scheduleGroup)
+	if err != nil {
+		return store.Schedule{}, err
+	}
+
+	return store.Schedule{
+		ScheduleId:    uuid,
+		AppId:         appId,
+		PartitionId:   partitionId,
+		ScheduleGroup: scheduleGroup.Unix(),
+	}, nil
+}
+
+// Iterate over the keys of every one time schedule along with the remaining TTL of the row.
+// Returns the first error returned by the callback or raised while reading the rows.
+func (s *ScheduleDaoImpl) scanScheduleKeys(callback func(key store.Schedule, ttl int) error) error {
+	var appId string
+	var partitionId int
+	var scheduleGroup time.Time
+	var scheduleId gocql.UUID
+	var ttl int
+
+	iter := s.Session.Query(scanScheduleKeys).
+		PageSize(s.Conf.ScheduleDB.DBConfig.PageSize).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+		Iter()
+
+	for iter.Scan(&appId, &partitionId, &scheduleGroup, &scheduleId, &ttl) {
+		key := store.Schedule{
+			ScheduleId:    scheduleId,
+			AppId:         appId,
+			PartitionId:   partitionId,
+			ScheduleGroup: scheduleGroup.Unix(),
+		}
+		if err := callback(key, ttl); err != nil {
+			_ = iter.Close()
+			return err
+		}
+	}
+
+	return iter.Close()
+}
+
+// Write the schedules_by_id lookup row of every one time schedule.
+// The lookup rows expire along with the schedules they point to.
+// Schedules deleted while the backfill runs may leave a lookup row behind, which is harmless since
+// reads verify the schedule row and the lookup row expires with the TTL of the deleted schedule.
+func (s *ScheduleDaoImpl) BackfillScheduleLookups() (LookupReport, error) {
+	report := LookupReport{Table: "schedules_by_id"}
+
+	err := s.scanScheduleKeys(func(key store.Schedule, ttl int) error {
+		report.Checked++
+
+		err := s.Session.Query(
+			insertScheduleLookup,
+			key.ScheduleId,
+			key.AppId,
+			key.PartitionId,
+			key.ScheduleGroup*constants.SecondsToMillis,
+			ttl).
+			RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+			Exec()
+		if err != nil {
+			return err
+		}
+
+		report.Written++
+		if report.Written%10000 == 0 {
+			glog.Infof("Backfilled %d schedule lookups", report.Written)
+		}
+		return nil
+	})
+
+	return report, err
+}
+
+// Write the labels_by_schedule lookup row of every labelled schedule from the label rows.
+// A schedule has a label row per label, all holding the same labels, so its lookup row is written once per label.
+// The lookup rows expire along with the label rows they are written from.
+func (s *ScheduleDaoImpl) BackfillLabelLookups() (LookupReport, error) {
+	report := LookupReport{Table: "labels_by_schedule"}
+
+	var scheduleId gocql.UUID
+	var appId, labels string
+	var ttl int
+
+	iter := s.Session.Query(scanLabels).
+		PageSize(s.Conf.ScheduleDB.DBConfig.PageSize).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+		Iter()
+
+	for iter.Scan(&scheduleId, &appId, &labels, &ttl) {
+		report.Checked++
+
+		err := s.Session.Query(insertLabelLookup, scheduleId, appId, labels, ttl).
+			RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+			Exec()
+		if err != nil {
+			_ = iter.Close()
+			return report, err
+		}
+
+		report.Written++
+	}
+
+	return report, iter.Close()
+}
+
+// Compare the schedules_by_id lookup table with the schedules table.
+// Schedules without a lookup row, or with one pointing to another partition, are counted as missing.
+// Lookup rows pointing to a schedule which does not exist are counted as stale.
+func (s *ScheduleDaoImpl) CheckScheduleLookups() (LookupReport, error) {
+	report := LookupReport{Table: "schedules_by_id"}
+
+	err := s.scanScheduleKeys(func(key store.Schedule, _ int) error {
+		report.Checked++
+
+		lookup, err := s.getScheduleKey(key.ScheduleId)
+		switch {
+		case err == gocql.ErrNotFound:
+			report.missing(key.ScheduleId.String())
+		case err != nil:
+			return err
+		case lookup.AppId != key.AppId || lookup.PartitionId != key.PartitionId || lookup.ScheduleGroup != key.ScheduleGroup:
+			report.missing(key.ScheduleId.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	var appId string
+	var partitionId int
+	var scheduleGroup time.Time
+	var scheduleId gocql.UUID
+
+	iter := s.Session.Query(scanScheduleLookups).
+		PageSize(s.Conf.ScheduleDB.DBConfig.PageSize).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+		Iter()
+
+	for iter.Scan(&scheduleId, &appId, &partitionId, &scheduleGroup) {
+		var id gocql.UUID
+		err = s.Session.Query(selectScheduleKey, appId, partitionId, scheduleGroup, scheduleId).
+			RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+			Scan(&id)
+		switch {
+		case err == gocql.ErrNotFound:
+			report.stale(scheduleId.String())
+		case err != nil:
+			_ = iter.Close()
+			return report, err
+		}
+	}
+
+	return report, iter.Close()
+}
+
+// Get the entities_by_node lookup rows keyed by node and entity id, with the status of the entity as the value.
+func (c *ClusterDaoImplCassandra) getNodeLookups() (map[[2]string]int, error) {
+	var nodeName string
+	var id string
+	var status int
+
+	lookups := make(map[[2]string]int)
+	iter := c.Session.
+		Query(KeyGetAllEntitiesOfNodes).
+		Consistency(c.Conf.ClusterDB.DBConfig.Consistency).
+		PageSize(c.Conf.ClusterDB.DBConfig.PageSize).
+		Iter()
+
+	for iter.Scan(&nodeName, &id, &status) {
+		lookups[[2]string{nodeName, id}] = status
+	}
+
+	return lookups, iter.Close()
+}
+
+// Compare the entities with the entities_by_node lookup table.
+// Returns the entities whose lookup row is missing or has another status, and the lookup rows of entities
+// which do not exist or are assigned to another node.
+func (c *ClusterDaoImplCassandra) diffNodeLookups() ([]e.EntityInfo, [][2]string, int, error) {
+	lookups, err := c.getNodeLookups()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	entities := c.GetAllEntitiesInfo()
+	nodes := make(map[string]string, len(entities))
+
+	var missing []e.EntityInfo
+	for _, entity := range entities {
+		nodes[entity.Id] = entity.Node
+		if entity.Node == "" {
+			continue
+		}
+		if status, ok := lookups[[2]string{entity.Node, entity.Id}]; !ok || status != entity.Status {
+			missing = append(missing, entity)
+		}
+	}
+
+	var stale [][2]string
+	for key := range lookups {
+		if node, ok := nodes[key[1]]; !ok || node != key[0] {
+			stale = append(stale, key)
+		}
+	}
+
+	return missing, stale, len(entities), nil
+}
+
+// Write the entities_by_node lookup rows of the entities whose row is missing or outdated,
+// and delete the lookup rows of entities which are no longer assigned to the node.
+func (c *ClusterDaoImplCassandra) BackfillNodeLookups() (LookupReport, error) {
+	report := LookupReport{Table: KeyNodeTable}
+
+	missing, stale, checked, err := c.diffNodeLookups()
+	if err != nil {
+		return report, err
+	}
+	report.Checked = checked
+
+	for _, entity := range missing {
+		if err = c.Session.Query(QueryInsertEntityOfNode, entity.Node, entity.Id, entity.Status).Exec(); err != nil {
+			return report, err
+		}
+		report.Written++
+	}
+
+	for _, key := range stale {
+		if err = c.Session.Query(QueryDeleteEntityOfNode, key[0], key[1]).Exec(); err != nil {
+			return report, err
+		}
+		report.Written++
+	}
+
+	return report, nil
+}
+
+// Compare the entities_by_node lookup table with the entity table.
+func (c *ClusterDaoImplCassandra) CheckNodeLookups() (LookupReport, error) {
+	report := LookupReport{Table: KeyNodeTable}
+
+	missing, stale, checked, err := c.diffNodeLookups()
+	if err != nil {
+		return report, err
+	}
+	report.Checked = checked
+
+	for _, entity := range missing {
+		report.missing(entity.Id)
+	}
+	for _, key := range stale {
+		report.stale(key[1])
+	}
+
+	return report, nil
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/myntra/goscheduler/db_wrapper"
+	"github.com/myntra/goscheduler/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// Get an iterator mock scanning the supplied rows into the destinations of Scan.
+func mockRows(ctrl *gomock.Controller, rows [][]interface{}) *mocks.MockIterInterface {
+	iter := mocks.NewMockIterInterface(ctrl)
+	next := 0
+	iter.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) bool {
+		if next == len(rows) {
+			return false
+		}
+		for i, value := range rows[next] {
+			switch d := dest[i].(type) {
+			case *string:
+				*d = value.(string)
+			case *int:
+				*d = value.(int)
+			case *time.Time:
+				*d = value.(time.Time)
+			case *gocql.UUID:
+				*d = value.(gocql.UUID)
+			}
+		}
+		next++
+		return true
+	}).AnyTimes()
+	iter.EXPECT().Close().Return(nil).AnyTimes()
+	return iter
+}
+
+// Get a query mock returning an iterator over the supplied rows.
+func mockScan(ctrl *gomock.Controller, rows [][]interface{}) *mocks.MockQueryInterface {
+	query := mocks.NewMockQueryInterface(ctrl)
+	query.EXPECT().PageSize(gomock.Any()).Return(query).AnyTimes()
+	query.EXPECT().Consistency(gomock.Any()).Return(query).AnyTimes()
+	query.EXPECT().RetryPolicy(gomock.Any()).Return(query).AnyTimes()
+	query.EXPECT().Iter().Return(mockRows(ctrl, rows)).AnyTimes()
+	return query
+}
+
+func TestScheduleDaoImpl_CheckScheduleLookups(t *testing.T) {
+	dao, m, _, _, ctrl := setupMocks(t)
+	defer ctrl.Finish()
+
+	group := time.Unix(1700000040, 0)
+	consistent, missing, moved, orphan := gocql.TimeUUID(), gocql.TimeUUID(), gocql.TimeUUID(), gocql.TimeUUID()
+
+	m.EXPECT().Query(scanScheduleKeys).Return(mockScan(ctrl, [][]interface{}{
+		{"Test", 0, group, consistent, 60},
+		{"Test", 0, group, missing, 60},
+		{"Test", 0, group, moved, 60},
+	}))
+	m.EXPECT().Query(scanScheduleLookups).Return(mockScan(ctrl, [][]interface{}{
+		{consistent, "Test", 0, group},
+		{moved, "Test", 1, group},
+		{orphan, "Test", 0, group},
+	}))
+
+	m.EXPECT().Query(selectScheduleLookup, gomock.Any()).DoAndReturn(func(_ string, args ...interface{}) db_wrapper.QueryInterface {
+		query := mocks.NewMockQueryInterface(ctrl)
+		query.EXPECT().RetryPolicy(gomock.Any()).Return(query)
+		query.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+			switch args[0].(gocql.UUID) {
+			case missing:
+				return gocql.ErrNotFound
+			case moved:
+				*dest[1].(*int) = 1
+			}
+			*dest[0].(*string) = "Test"
+			*dest[2].(*time.Time) = group
+			return nil
+		})
+		return query
+	}).Times(3)
+
+	m.EXPECT().Query(selectScheduleKey, gomock.Any()).DoAndReturn(func(_ string, args ...interface{}) db_wrapper.QueryInterface {
+		query := mocks.NewMockQueryInterface(ctrl)
+		query.EXPECT().RetryPolicy(gomock.Any()).Return(query)
+		query.EXPECT().Scan(gomock.Any()).DoAndReturn(func(...interface{}) error {
+			if args[3].(gocql.UUID) == consistent {
+				return nil
+			}
+			return gocql.ErrNotFound
+		})
+		return query
+	}).Times(3)
+
+	report, err := dao.CheckScheduleLookups()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, 2, report.Missing)
+	assert.Equal(t, 2, report.Stale)
+	assert.Equal(t, []string{missing.String(), moved.String(), moved.String(), orphan.String()}, report.Samples)
+	assert.False(t, report.Consistent())
+}
+
+func TestScheduleDaoImpl_BackfillScheduleLookups(t *testing.T) {
+	dao, m, _, _, ctrl := setupMocks(t)
+	defer ctrl.Finish()
+
+	group := time.Unix(1700000040, 0)
+	first, second := gocql.TimeUUID(), gocql.TimeUUID()
+
+	m.EXPECT().Query(scanScheduleKeys).Return(mockScan(ctrl, [][]interface{}{
+		{"Test", 0, group, first, 60},
+		{"Test", 1, group, second, 120},
+	}))
+
+	insert := mocks.NewMockQueryInterface(ctrl)
+	insert.EXPECT().RetryPolicy(gomock.Any()).Return(insert).Times(2)
+	gomock.InOrder(
+		m.EXPECT().Query(insertScheduleLookup, first, "Test", 0, group.Unix()*1000, 60).Return(insert),
+		insert.EXPECT().Exec().Return(nil),
+		m.EXPECT().Query(insertScheduleLookup, second, "Test", 1, group.Unix()*1000, 120).Return(insert),
+		insert.EXPECT().Exec().Return(errors.New("write timeout")),
+	)
+
+	report, err := dao.BackfillScheduleLookups()
+	assert.EqualError(t, err, "write timeout")
+	assert.Equal(t, 2, report.Checked)
+	assert.Equal(t, 1, report.Written)
+}
+
+func TestScheduleDaoImpl_BackfillLabelLookups(t *testing.T) {
+	dao, m, _, _, ctrl := setupMocks(t)
+	defer ctrl.Finish()
+
+	labelled := gocql.TimeUUID()
+	labels := `{"region":"eu","tier":"gold"}`
+
+	m.EXPECT().Query(scanLabels).Return(mockScan(ctrl, [][]interface{}{
+		{labelled, "Test", labels, 0},
+		{labelled, "Test", labels, 0},
+	}))
+
+	insert := mocks.NewMockQueryInterface(ctrl)
+	insert.EXPECT().RetryPolicy(gomock.Any()).Return(insert).Times(2)
+	insert.EXPECT().Exec().Return(nil).Times(2)
+	m.EXPECT().Query(insertLabelLookup, labelled, "Test", labels, 0).Return(insert).Times(2)
+
+	report, err := dao.BackfillLabelLookups()
+	assert.Nil(t, err)
+	assert.Equal(t, LookupReport{Table: "labels_by_schedule", Checked: 2, Written: 2}, report)
+}
+
+func TestClusterDaoImplCassandra_NodeLookups(t *testing.T) {
+	entities := [][]interface{}{
+		{"Test.0", "node-1", 1, ""},
+		{"Test.1", "node-2", 1, ""},
+		{"Test.2", "node-1", 0, ""},
+	}
+	lookups := [][]interface{}{
+		{"node-1", "Test.0", 1},
+		{"node-1", "Test.1", 1},
+		{"node-1", "Test.2", 1},
+		{"node-3", "Test.3", 1},
+	}
+
+	t.Run("check", func(t *testing.T) {
+		dao, m, _, _, ctrl := setupClusterDaoMocks(t)
+		defer ctrl.Finish()
+
+		m.EXPECT().Query(KeyGetAllEntitiesOfNodes).Return(mockScan(ctrl, lookups))
+		m.EXPECT().Query(KeyGetAllEntities).Return(mockScan(ctrl, entities))
+
+		report, err := dao.CheckNodeLookups()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Checked)
+		assert.Equal(t, 2, report.Missing)
+		assert.Equal(t, 2, report.Stale)
+		assert.ElementsMatch(t, []string{"Test.1", "Test.2", "Test.1", "Test.3"}, report.Samples)
+	})
+
+	t.Run("backfill", func(t *testing.T) {
+		dao, m, _, _, ctrl := setupClusterDaoMocks(t)
+		defer ctrl.Finish()
+
+		m.EXPECT().Query(KeyGetAllEntitiesOfNodes).Return(mockScan(ctrl, lookups))
+		m.EXPECT().Query(KeyGetAllEntities).Return(mockScan(ctrl, entities))
+
+		write := mocks.NewMockQueryInterface(ctrl)
+		write.EXPECT().Exec().Return(nil).Times(4)
+		m.EXPECT().Query(QueryInsertEntityOfNode, "node-2", "Test.1", 1).Return(write)
+		m.EXPECT().Query(QueryInsertEntityOfNode, "node-1", "Test.2", 0).Return(write)
+		m.EXPECT().Query(QueryDeleteEntityOfNode, "node-1", "Test.1").Return(write)
+		m.EXPECT().Query(QueryDeleteEntityOfNode, "node-3", "Test.3").Return(write)
+
+		report, err := dao.BackfillNodeLookups()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Checked)
+		assert.Equal(t, 4, report.Written)
+	})
+}
+
+func TestLookupReport_Samples(t *testing.T) {
+	report := LookupReport{}
+	for i := 0; i < maxLookupSamples+10; i++ {
+		report.missing("Test.0")
+	}
+
+	assert.Equal(t, maxLookupSamples+10, report.Missing)
+	assert.Len(t, report.Samples, maxLookupSamples)
+}
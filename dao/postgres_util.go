@@ -0,0 +1,424 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/lib/pq"
+)
+
+// Error codes raised by PostgreSQL when a row has no partition to go to and when a partition already exists
+const (
+	pgNoPartition      = "23514"
+	pgDuplicateTable   = "42P07"
+	pgUndefinedTable   = "42P01"
+	pgUniqueViolation  = "23505"
+	pgPartitionsLayout = "20060102"
+)
+
+// Tables of the schedule data partitioned by the day of the schedule time group
+var partitionedTables = []string{"schedules", "status"}
+
+type columnKind int
+
+const (
+	textColumn columnKind = iota
+	uuidColumn
+	timeColumn
+	intColumn
+)
+
+// Kinds of the columns which are not text, used to convert rows to the maps the schedules are built from
+var columnKinds = map[string]columnKind{
+	"schedule_id":         uuidColumn,
+	"parent_schedule_id":  uuidColumn,
+	"schedule_time_group": timeColumn,
+	"schedule_time":       timeColumn,
+	"deadline":            timeColumn,
+	"nominal_time":        timeColumn,
+	"partition_id":        intColumn,
+	"run_number":          intColumn,
+}
+
+// Convert the current row to a map having the same keys and value types as the maps scanned from Cassandra.
+// Null uuids and times are set to their zero values.
+func scanMap(rows *sql.Rows) (map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	dest := make([]interface{}, len(columns))
+	for i, column := range columns {
+		switch columnKinds[column] {
+		case timeColumn:
+			dest[i] = new(sql.NullTime)
+		case intColumn:
+			dest[i] = new(sql.NullInt64)
+		default:
+			dest[i] = new(sql.NullString)
+		}
+	}
+
+	if err = rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		switch value := dest[i].(type) {
+		case *sql.NullTime:
+			m[column] = value.Time
+		case *sql.NullInt64:
+			m[column] = int(value.Int64)
+		case *sql.NullString:
+			if columnKinds[column] != uuidColumn {
+				m[column] = value.String
+			} else if !value.Valid {
+				m[column] = gocql.UUID{}
+			} else if m[column], err = gocql.ParseUUID(value.String); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// Run the query and convert all the rows fetched to maps
+func queryMaps(db querier, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	_, maps, err := queryRows(db, query, args...)
+	return maps, err
+}
+
+// Run the query and convert all the rows fetched to maps, along with the names of the columns selected
+func queryRows(db querier, query string, args ...interface{}) ([]string, []map[string]interface{}, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var maps []map[string]interface{}
+	for rows.Next() {
+		m, err := scanMap(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		maps = append(maps, m)
+	}
+	return columns, maps, rows.Err()
+}
+
+// Run the query and convert the first row fetched to a map.
+// Returns gocql.ErrNotFound if no row is fetched, like the Cassandra implementation does.
+func queryMap(db querier, query string, args ...interface{}) (map[string]interface{}, error) {
+	maps, err := queryMaps(db, query, args...)
+	switch {
+	case err != nil:
+		return nil, err
+	case len(maps) == 0:
+		return nil, gocql.ErrNotFound
+	default:
+		return maps[0], nil
+	}
+}
+
+// querier is implemented by both sql.DB and sql.Tx
+type querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Translate sql.ErrNoRows to gocql.ErrNotFound, which is what the callers of the DAOs check for
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return gocql.ErrNotFound
+	}
+	return err
+}
+
+// Get the uuid as a string to be written to PostgreSQL, nil for the zero uuid
+func uuidValue(uuid gocql.UUID) interface{} {
+	if uuid == (gocql.UUID{}) {
+		return nil
+	}
+	return uuid.String()
+}
+
+// Get a unix timestamp in seconds as a time to be written to PostgreSQL, nil if it is not set
+func timeValue(seconds int64) interface{} {
+	if seconds == 0 {
+		return nil
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+// Get the time at which a row written now expires, ttl seconds later
+func expiresAt(ttl int) time.Time {
+	return time.Now().Add(time.Duration(ttl) * time.Second).UTC()
+}
+
+// pageKey is the key of the last row of a page.
+// PostgreSQL has no paging state, so pages are fetched from the rows following this key.
+type pageKey struct {
+	Group time.Time `json:"g,omitempty"`
+	Id    string    `json:"i"`
+}
+
+// Encode the key of the row as a page state, nil if there are no more rows
+func encodePageKey(group time.Time, id gocql.UUID, more bool) []byte {
+	if !more {
+		return nil
+	}
+	data, _ := json.Marshal(pageKey{Group: group, Id: id.String()})
+	return data
+}
+
+// Decode the page state, false if there is none and fetching starts from the first row
+func decodePageKey(pageState []byte) (pageKey, bool, error) {
+	var key pageKey
+	if len(pageState) == 0 {
+		return key, false, nil
+	}
+	if err := json.Unmarshal(pageState, &key); err != nil {
+		return key, false, fmt.Errorf("invalid page state: %s", err.Error())
+	}
+	return key, true, nil
+}
+
+// rowsIter implements db_wrapper.IterInterface over rows fetched from PostgreSQL,
+// so that the pages of schedules are consumed the same way as the ones fetched from Cassandra.
+type rowsIter struct {
+	rows      []map[string]interface{}
+	columns   []string
+	next      int
+	pageState []byte
+	err       error
+}
+
+func (i *rowsIter) Close() error {
+	return i.err
+}
+
+func (i *rowsIter) MapScan(m map[string]interface{}) bool {
+	if i.err != nil || i.next >= len(i.rows) {
+		return false
+	}
+
+	for column, value := range i.rows[i.next] {
+		m[column] = value
+	}
+	i.next++
+	return true
+}
+
+// Scan copies the columns of the current row, in the order they were selected, to the destinations
+func (i *rowsIter) Scan(dest ...interface{}) bool {
+	if i.err != nil || i.next >= len(i.rows) {
+		return false
+	}
+
+	for k := 0; k < len(dest) && k < len(i.columns); k++ {
+		target := reflect.ValueOf(dest[k])
+		value := reflect.ValueOf(i.rows[i.next][i.columns[k]])
+		if target.Kind() != reflect.Ptr || !value.IsValid() || !value.Type().AssignableTo(target.Elem().Type()) {
+			i.err = fmt.Errorf("can not scan column %s into %T", i.columns[k], dest[k])
+			return false
+		}
+		target.Elem().Set(value)
+	}
+	i.next++
+	return true
+}
+
+func (i *rowsIter) PageState() []byte {
+	return i.pageState
+}
+
+// partitionManager creates the daily partitions of the schedule data as they are written to
+// and drops them once all their rows have expired.
+type partitionManager struct {
+	db      *sql.DB
+	lock    sync.Mutex
+	created map[string]bool
+}
+
+func newPartitionManager(db *sql.DB) *partitionManager {
+	return &partitionManager{db: db, created: make(map[string]bool)}
+}
+
+// Name of the partition of the table holding the rows of the day
+func partitionName(table string, day time.Time) string {
+	return table + "_p" + day.UTC().Format(pgPartitionsLayout)
+}
+
+// ensure creates the partitions holding the rows of the day of the given time if they do not exist yet
+func (p *partitionManager) ensure(t time.Time) error {
+	day := t.UTC().Truncate(24 * time.Hour)
+	key := day.Format(pgPartitionsLayout)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.created[key] {
+		return nil
+	}
+
+	for _, table := range partitionedTables {
+		query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+			partitionName(table, day),
+			table,
+			day.Format(time.RFC3339),
+			day.Add(24*time.Hour).Format(time.RFC3339))
+
+		// Nodes creating the same partition concurrently may still race on the catalog,
+		// the loser fails on the unique index of pg_type rather than with a duplicate table
+		if _, err := p.db.Exec(query); err != nil && !isPgError(err, pgDuplicateTable) && !isPgError(err, pgUniqueViolation) {
+			return err
+		}
+	}
+
+	p.created[key] = true
+	return nil
+}
+
+// forget makes the next write to the day check its partitions again
+func (p *partitionManager) forget(day time.Time) {
+	p.lock.Lock()
+	delete(p.created, day.UTC().Format(pgPartitionsLayout))
+	p.lock.Unlock()
+}
+
+// exec runs a statement writing a row of the day of the given time, creating its partitions if required.
+// A partition dropped by another node after it was cached is created again and the statement retried once.
+func (p *partitionManager) exec(t time.Time, query string, args ...interface{}) error {
+	if err := p.ensure(t); err != nil {
+		return err
+	}
+
+	_, err := p.db.Exec(query, args...)
+	if isPgError(err, pgNoPartition) {
+		p.forget(t)
+		if err = p.ensure(t); err != nil {
+			return err
+		}
+		_, err = p.db.Exec(query, args...)
+	}
+	return err
+}
+
+// dropEmpty drops the partitions of the days before the given time which no longer have rows
+func (p *partitionManager) dropEmpty(before time.Time) error {
+	for _, table := range partitionedTables {
+		var names []string
+		rows, err := p.db.Query("SELECT c.relname FROM pg_inherits i "+
+			"JOIN pg_class c ON c.oid = i.inhrelid "+
+			"JOIN pg_class t ON t.oid = i.inhparent "+
+			"WHERE t.relname = $1", table)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var name string
+			if err = rows.Scan(&name); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			names = append(names, name)
+		}
+		if err = rows.Close(); err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			day, err := time.Parse(pgPartitionsLayout, strings.TrimPrefix(name, table+"_p"))
+			if err != nil || !day.Add(24*time.Hour).Before(before) {
+				continue
+			}
+
+			var exists bool
+			if err = p.db.QueryRow("SELECT EXISTS (SELECT 1 FROM " + name + ")").Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			if err = p.dropIfEmpty(name, day); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dropIfEmpty drops the partition of the day if it is still empty once locked.
+// The lock keeps rows from being written to the partition between the check and the drop,
+// the writers waiting on it create the partition again once it is dropped.
+func (p *partitionManager) dropIfEmpty(name string, day time.Time) error {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Another node dropped it already
+	if _, err = tx.Exec("LOCK TABLE " + name + " IN ACCESS EXCLUSIVE MODE"); isPgError(err, pgUndefinedTable) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var exists bool
+	if err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM " + name + ")").Scan(&exists); err != nil || exists {
+		return err
+	}
+
+	glog.Infof("Dropping empty partition %s", name)
+	if _, err = tx.Exec("DROP TABLE " + name); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	p.forget(day)
+	return nil
+}
+
+// Check whether the error was raised by PostgreSQL with the given code
+func isPgError(err error, code string) bool {
+	pgErr, ok := err.(*pq.Error)
+	return ok && string(pgErr.Code) == code
+}
@@ -41,4 +41,9 @@ type ScheduleDao interface {
 	OptimizedEnrichSchedule(schedules []s.Schedule) ([]s.Schedule, error)
 	GetCronSchedulesByApp(appId string, status s.Status) ([]s.Schedule, []string)
 	BulkAction(app s.App, partitionId int, scheduleTimeGroup time.Time, status []s.Status, actionType s.ActionType) error
+	GetSchedulesByLabel(appId string, selectors []string, size int64, pageState []byte) ([]s.Schedule, []byte, error)
+	GetLabels(appId string, scheduleIds []gocql.UUID) (map[gocql.UUID]map[string]string, error)
+	DeleteLabels(schedule s.Schedule) error
+	UpdateUsage(appId string, kind s.UsageKind, delta int64) error
+	GetUsage(appId string) (s.Usage, error)
 }
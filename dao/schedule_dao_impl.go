@@ -94,7 +94,12 @@ func (s *ScheduleDaoImpl) createRecurringSchedule(schedule store.Schedule) (stor
 			"callback_type," +
 			"callback_details," +
 			"cron_expression, " +
-			"status) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			"status," +
+			"expires_after," +
+			"jitter," +
+			"calendar," +
+			"calendar_policy," +
+			"priority) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 
 		"INSERT INTO recurring_schedules_by_partition (" +
 			"app_id," +
@@ -104,7 +109,12 @@ func (s *ScheduleDaoImpl) createRecurringSchedule(schedule store.Schedule) (stor
 			"callback_type," +
 			"callback_details," +
 			"cron_expression, " +
-			"status) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			"status," +
+			"expires_after," +
+			"jitter," +
+			"calendar," +
+			"calendar_policy," +
+			"priority) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
 	} {
 		batch.Query(
 			query,
@@ -115,7 +125,12 @@ func (s *ScheduleDaoImpl) createRecurringSchedule(schedule store.Schedule) (stor
 			schedule.GetCallBackType(),
 			schedule.GetCallbackDetails(),
 			schedule.CronExpression,
-			store.Scheduled)
+			store.Scheduled,
+			schedule.ExpiresAfter,
+			schedule.Jitter,
+			schedule.Calendar,
+			string(schedule.CalendarPolicy),
+			string(schedule.Priority))
 	}
 
 	err := s.Session.ExecuteBatch(batch)
@@ -125,8 +140,12 @@ func (s *ScheduleDaoImpl) createRecurringSchedule(schedule store.Schedule) (stor
 }
 
 // Persist a one time schedule in Cassandra.
+// The schedule and its lookup row are written in a logged batch with the same TTL.
 // Throws error if writing data to schedule fails.
 func (s *ScheduleDaoImpl) createOneTimeSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	batch := gocql.NewBatch(gocql.LoggedBatch)
+	ttl := schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)
+
 	query := "INSERT INTO schedules (" +
 		"app_id," +
 		"partition_id," +
@@ -135,9 +154,12 @@ func (s *ScheduleDaoImpl) createOneTimeSchedule(schedule store.Schedule, app sto
 		"schedule_time," +
 		"payload," +
 		"callback_type," +
-		"callback_details) VALUES (?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?"
+		"callback_details," +
+		"chain," +
+		"deadline," +
+		"priority) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?"
 
-	err := s.Session.Query(
+	batch.Query(
 		query,
 		schedule.AppId,
 		schedule.PartitionId,
@@ -147,24 +169,42 @@ func (s *ScheduleDaoImpl) createOneTimeSchedule(schedule store.Schedule, app sto
 		schedule.Payload,
 		schedule.GetCallBackType(),
 		schedule.GetCallbackDetails(),
-		schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)).Exec()
+		schedule.GetChain(),
+		getDeadline(schedule),
+		string(schedule.Priority),
+		ttl)
+	addScheduleLookup(batch, schedule, ttl)
 
-	return schedule, err
+	return schedule, s.Session.ExecuteBatch(batch)
 }
 
 // Persist the schedule details in cassandra.
 // The tables to which the schedule is written to is determined based on it being a recurring schedule or not.
+// One time schedules having a dedup key are deduplicated against the pending schedules of the app.
+// The labels of the schedule are written to the label lookup table once the schedule is created.
 // Throws error if the writing to the schedule fails.
 func (s *ScheduleDaoImpl) CreateSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	var created store.Schedule
+	var err error
+
 	if schedule.IsRecurring() {
-		return s.profile(func() (store.Schedule, error) {
+		created, err = s.profile(func() (store.Schedule, error) {
 			return s.createRecurringSchedule(schedule)
 		}, constants.CreateRecurringSchedule, schedule.AppId)
 	} else {
-		return s.profile(func() (store.Schedule, error) {
+		created, err = s.profile(func() (store.Schedule, error) {
+			if schedule.DedupKey != "" {
+				return s.createDedupSchedule(schedule, app)
+			}
 			return s.createOneTimeSchedule(schedule, app)
 		}, constants.CreateOneTimeSchedule, schedule.AppId)
 	}
+
+	if err != nil || created.Deduplicated {
+		return created, err
+	}
+
+	return created, s.createLabels(schedule, app)
 }
 
 // Get all recurring schedules with partition id
@@ -178,7 +218,12 @@ func (s *ScheduleDaoImpl) GetRecurringScheduleByPartition(partitionId int) ([]st
 		"app_id," +
 		"partition_id, " +
 		"cron_expression, " +
-		"status " +
+		"status," +
+		"expires_after," +
+		"jitter," +
+		"calendar," +
+		"calendar_policy," +
+		"priority " +
 		"FROM recurring_schedules_by_partition " +
 		"WHERE partition_id = ?"
 
@@ -187,7 +232,7 @@ func (s *ScheduleDaoImpl) GetRecurringScheduleByPartition(partitionId int) ([]st
 
 	_map := make(map[string]interface{})
 	iter := s.Session.Query(query, partitionId).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		Iter()
 
 	for iter.MapScan(_map) {
@@ -219,13 +264,18 @@ func (s *ScheduleDaoImpl) getRecurringSchedule(uuid gocql.UUID) (store.Schedule,
 		"app_id," +
 		"partition_id, " +
 		"cron_expression, " +
-		"status " +
+		"status," +
+		"expires_after," +
+		"jitter," +
+		"calendar," +
+		"calendar_policy," +
+		"priority " +
 		"FROM recurring_schedules_by_id " +
 		"WHERE schedule_id= ? LIMIT 1"
 
 	_map := make(map[string]interface{})
 	err := s.Session.Query(query, uuid).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		MapScan(_map)
 	if err != nil {
 		return store.Schedule{}, err
@@ -237,8 +287,14 @@ func (s *ScheduleDaoImpl) getRecurringSchedule(uuid gocql.UUID) (store.Schedule,
 }
 
 // Find a one time schedule with the supplied id.
+// The partition key of the schedule is resolved from the schedules_by_id lookup table.
 // Returns a non nil error if fetching the details failed or if no row with the id is found.
 func (s *ScheduleDaoImpl) getOneTimeSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	key, err := s.getScheduleKey(uuid)
+	if err != nil {
+		return store.Schedule{}, err
+	}
+
 	query := "SELECT " +
 		"schedule_id," +
 		"payload," +
@@ -247,13 +303,24 @@ func (s *ScheduleDaoImpl) getOneTimeSchedule(uuid gocql.UUID) (store.Schedule, e
 		"callback_type," +
 		"callback_details," +
 		"app_id," +
-		"partition_id " +
-		"FROM view_schedules " +
-		"WHERE schedule_id= ? LIMIT 1"
+		"partition_id," +
+		"chain," +
+		"deadline," +
+		"priority " +
+		"FROM schedules " +
+		"WHERE app_id= ? " +
+		"AND partition_id= ? " +
+		"AND schedule_time_group= ? " +
+		"AND schedule_id= ?"
 
 	_map := make(map[string]interface{})
-	err := s.Session.Query(query, uuid).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+	err = s.Session.Query(
+		query,
+		key.AppId,
+		key.PartitionId,
+		key.ScheduleGroup*constants.SecondsToMillis,
+		uuid).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		MapScan(_map)
 	if err != nil {
 		return store.Schedule{}, err
@@ -279,6 +346,22 @@ func (s *ScheduleDaoImpl) getEnrichedSchedule(uuid gocql.UUID) (store.Schedule,
 	return schedule, nil
 }
 
+// Get the deadline of the schedule to be written to Cassandra, nil if the schedule has no deadline
+func getDeadline(schedule store.Schedule) interface{} {
+	if schedule.Deadline == 0 {
+		return nil
+	}
+	return schedule.Deadline * constants.SecondsToMillis
+}
+
+// Get the nominal time of the run to be written to Cassandra, nil if the schedule is not a run
+func getNominalTime(schedule store.Schedule) interface{} {
+	if schedule.NominalTime == 0 {
+		return nil
+	}
+	return schedule.NominalTime * constants.SecondsToMillis
+}
+
 // Get a single schedule with the supplied id.
 // Attempts to find a one time schedule and falls back to recurring schedules if not found.
 // Returns a non nil error if fetching the details of the schedule fails.
@@ -315,7 +398,7 @@ func (s *ScheduleDaoImpl) EnrichSchedule(schedule *store.Schedule) error {
 
 // Delete a schedule from the recurring schedule tables.
 // These are soft deletes marked with status as Deleted
-// All one time future runs generated from this schedule will also be deleted.
+// All one time future runs generated from this schedule and the label rows of the schedule will also be deleted.
 // Returns a non nil error in case deleting the rows fails.
 func (s *ScheduleDaoImpl) deleteRecurringSchedule(schedule store.Schedule) (store.Schedule, error) {
 	batch := gocql.NewBatch(gocql.LoggedBatch)
@@ -344,6 +427,11 @@ func (s *ScheduleDaoImpl) deleteRecurringSchedule(schedule store.Schedule) (stor
 			run.PartitionId,
 			run.ScheduleGroup*constants.SecondsToMillis,
 			run.ScheduleId)
+		batch.Query(deleteScheduleLookup, run.ScheduleId)
+	}
+
+	if err = s.addLabelDeletes(batch, schedule); err != nil {
+		return schedule, err
 	}
 
 	err = s.Session.ExecuteBatch(batch)
@@ -359,17 +447,23 @@ const deleteFromSchedule string = "DELETE from schedules " +
 	"AND schedule_id = ?"
 
 // Deletes a given schedule from the schedule table.
-// The rows are removed from the Cassandra.
+// The schedule, its lookup row and its label rows are removed from the Cassandra in a logged batch.
 // Return a non nil error in case the delete fails.
 func (s *ScheduleDaoImpl) deleteOneTimeSchedule(schedule store.Schedule) (store.Schedule, error) {
-	err := s.Session.Query(
+	batch := gocql.NewBatch(gocql.LoggedBatch)
+	batch.Query(
 		deleteFromSchedule,
 		schedule.AppId,
 		schedule.PartitionId,
 		schedule.ScheduleGroup*constants.SecondsToMillis,
-		schedule.ScheduleId).Exec()
+		schedule.ScheduleId)
+	batch.Query(deleteScheduleLookup, schedule.ScheduleId)
 
-	return schedule, err
+	if err := s.addLabelDeletes(batch, schedule); err != nil {
+		return schedule, err
+	}
+
+	return schedule, s.Session.ExecuteBatch(batch)
 }
 
 // Delete schedule with the given id
@@ -399,14 +493,18 @@ func (s *ScheduleDaoImpl) getRuns(uuid gocql.UUID, pageState []byte, size int64)
 		"callback_type, " +
 		"callback_details, " +
 		"payload, " +
-		"schedule_time " +
+		"schedule_time, " +
+		"run_number," +
+		"nominal_time," +
+		"deadline," +
+		"priority " +
 		"FROM recurring_schedule_runs " +
 		"WHERE parent_schedule_id = ? "
 
 	return s.Session.Query(query, uuid).
 		PageState(pageState).
 		PageSize(int(size)).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		Iter()
 }
 
@@ -603,11 +701,12 @@ func (s *ScheduleDaoImpl) GetScheduleRuns(uuid gocql.UUID, size int64, when stri
 }
 
 // Create a one time schedule for a recurring schedule.
-// The schedule will be persisted in schedule and runs tables.
+// The schedule will be persisted in schedule, lookup and runs tables.
 // Returns a non nil error in case persisting the data fails.
 func (s *ScheduleDaoImpl) CreateRun(schedule store.Schedule, app store.App) (store.Schedule, error) {
 
 	batch := gocql.NewBatch(gocql.LoggedBatch)
+	ttl := schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)
 
 	for _, query := range []string{"INSERT INTO schedules (" +
 		"app_id," +
@@ -618,7 +717,11 @@ func (s *ScheduleDaoImpl) CreateRun(schedule store.Schedule, app store.App) (sto
 		"payload," +
 		"callback_type," +
 		"callback_details," +
-		"parent_schedule_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?",
+		"parent_schedule_id," +
+		"run_number," +
+		"nominal_time," +
+		"deadline," +
+		"priority) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?",
 
 		"INSERT INTO recurring_schedule_runs (" +
 			"app_id," +
@@ -629,10 +732,14 @@ func (s *ScheduleDaoImpl) CreateRun(schedule store.Schedule, app store.App) (sto
 			"payload," +
 			"callback_type," +
 			"callback_details," +
-			"parent_schedule_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?",
+			"parent_schedule_id," +
+			"run_number," +
+			"nominal_time," +
+			"deadline," +
+			"priority) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?",
 	} {
 		batch.
-			RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+			RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 			Query(
 				query,
 				schedule.AppId,
@@ -644,13 +751,19 @@ func (s *ScheduleDaoImpl) CreateRun(schedule store.Schedule, app store.App) (sto
 				schedule.GetCallBackType(),
 				schedule.GetCallbackDetails(),
 				schedule.ParentScheduleId,
-				schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod))
+				schedule.RunNumber,
+				getNominalTime(schedule),
+				getDeadline(schedule),
+				string(schedule.Priority),
+				ttl)
 	}
+	addScheduleLookup(batch, schedule, ttl)
 
 	return schedule, s.Session.ExecuteBatch(batch)
 }
 
 // updates status in batches
+// the status is partitioned by the schedule time group of the schedule, like the schedule itself
 // set ttl same as buffer ttl as data is added to this table after callback is fired
 func (s *ScheduleDaoImpl) UpdateStatus(schedules []store.Schedule, app store.App) error {
 	//log schedule status update
@@ -658,22 +771,29 @@ func (s *ScheduleDaoImpl) UpdateStatus(schedules []store.Schedule, app store.App
 		glog.Infof("update status for schedule: %s", schedule.ScheduleId.String())
 	}
 
-	const insertStatusQuery string = "INSERT INTO status (" +
+	const insertStatusQuery string = "INSERT INTO status_by_bucket (" +
 		"app_id," +
 		"partition_id," +
 		"schedule_time_group," +
 		"schedule_id," +
 		"schedule_status," +
 		"error_msg," +
-		"reconciliation_history) VALUES (?, ?, ?, ?, ?, ?, ?) USING TTL ?"
+		"reconciliation_history," +
+		"target_status," +
+		"next_schedule_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?"
 
 	batch := gocql.NewBatch(gocql.UnloggedBatch)
 
 	for _, query := range schedules {
 		reconciliationHistory, _ := json.Marshal(query.ReconciliationHistory)
 
+		var targetStatus []byte
+		if len(query.TargetStatuses) > 0 {
+			targetStatus, _ = json.Marshal(query.TargetStatuses)
+		}
+
 		batch.
-			RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+			RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 			Query(
 				insertStatusQuery,
 				query.AppId,
@@ -683,6 +803,8 @@ func (s *ScheduleDaoImpl) UpdateStatus(schedules []store.Schedule, app store.App
 				query.Status,
 				query.ErrorMessage,
 				reconciliationHistory,
+				string(targetStatus),
+				query.NextScheduleId,
 				query.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod))
 	}
 
@@ -722,7 +844,7 @@ func (s *ScheduleDaoImpl) getPaginatedSchedulesByStatus(appId string, partitions
 
 func (s *ScheduleDaoImpl) GetPaginatedSchedules(appId string, partitions int, timeRange Range, size int64, status store.Status, pageState []byte, continuationStartTime time.Time) ([]store.Schedule, []byte, time.Time, error) {
 	switch status {
-	case store.Success, store.Failure, store.Miss, store.Scheduled:
+	case store.Success, store.Failure, store.Partial, store.Expired, store.Miss, store.Scheduled:
 		return s.getPaginatedSchedulesByStatus(appId, partitions, timeRange, size, status, pageState, continuationStartTime)
 	default:
 		return s.getPaginatedSchedulesByStatus(appId, partitions, timeRange, size, "", pageState, continuationStartTime)
@@ -853,7 +975,7 @@ func (s *ScheduleDaoImpl) getSchedules(appId string, partitions []int, timeRange
 		timeStamps(timeRange.StartTime, timeRange.EndTime)).
 		PageState(pageState).
 		PageSize(int(size)).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		Iter()
 
 	return iter
@@ -870,7 +992,11 @@ func (s *ScheduleDaoImpl) GetSchedulesForEntity(appId string, partitionId int, t
 		"callback_details," +
 		"payload," +
 		"schedule_time," +
-		"parent_schedule_id " +
+		"parent_schedule_id," +
+		"run_number," +
+		"chain," +
+		"deadline," +
+		"priority " +
 		"FROM schedules " +
 		"WHERE app_id = ? " +
 		"AND partition_id = ? " +
@@ -885,12 +1011,21 @@ func (s *ScheduleDaoImpl) GetSchedulesForEntity(appId string, partitionId int, t
 		timeBucket).
 		PageState(pageState).
 		PageSize(s.Conf.ScheduleDB.DBConfig.PageSize).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		Iter()
 
 	return iter
 }
 
+const statusColumns string = "schedule_status," +
+	"error_msg," +
+	"reconciliation_history," +
+	"target_status," +
+	"next_schedule_id "
+
+// Status written before statuses were bucketed by time, partitioned by app and partition id only
+const legacyStatusTable string = "status"
+
 // fetch schedule status and error from status table
 // case 1) schedule is not found in status table
 //
@@ -903,16 +1038,15 @@ func (s *ScheduleDaoImpl) GetSchedulesForEntity(appId string, partitionId int, t
 //
 //	-> set status of the schedule from the fetched row
 //
+// The legacy status table is read when the status is not found and LegacyStatusReads is set.
 // return error if there is any other error while fetching data from db
 func (s *ScheduleDaoImpl) setStatus(schedule *store.Schedule) error {
-	query := "SELECT " +
-		"schedule_status," +
-		"error_msg," +
-		"reconciliation_history " +
-		"FROM status " +
+	query := "SELECT " + statusColumns +
+		"FROM status_by_bucket " +
 		"WHERE app_id= ? " +
 		"AND partition_id= ? " +
-		"AND schedule_id= ? LIMIT 1"
+		"AND schedule_time_group= ? " +
+		"AND schedule_id= ?"
 
 	_map := make(map[string]interface{})
 
@@ -920,10 +1054,27 @@ func (s *ScheduleDaoImpl) setStatus(schedule *store.Schedule) error {
 		query,
 		schedule.AppId,
 		schedule.PartitionId,
+		schedule.ScheduleGroup*constants.SecondsToMillis,
 		schedule.ScheduleId).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		MapScan(_map)
 
+	if err == gocql.ErrNotFound && s.Conf.ScheduleDB.LegacyStatusReads {
+		legacyQuery := "SELECT " + statusColumns +
+			"FROM " + legacyStatusTable + " " +
+			"WHERE app_id= ? " +
+			"AND partition_id= ? " +
+			"AND schedule_id= ? LIMIT 1"
+
+		err = s.Session.Query(
+			legacyQuery,
+			schedule.AppId,
+			schedule.PartitionId,
+			schedule.ScheduleId).
+			RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+			MapScan(_map)
+	}
+
 	if err != nil {
 		if err == gocql.ErrNotFound {
 			schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
@@ -939,8 +1090,10 @@ func (s *ScheduleDaoImpl) setStatus(schedule *store.Schedule) error {
 	return nil
 }
 
-// Fetch status data in bulk by appId, partitionId, scheduleIds
-func (s *ScheduleDaoImpl) getBulkStatus(schedules []store.Schedule) db_wrapper.IterInterface {
+// Fetch status data in bulk by appId, partitionId, scheduleTimeGroup, scheduleIds
+// All the schedules are expected to be in the same status partition as the first one.
+// The legacy status table is partitioned by appId and partitionId only.
+func (s *ScheduleDaoImpl) getBulkStatus(schedules []store.Schedule, legacy bool) db_wrapper.IterInterface {
 	var uuids []gocql.UUID
 
 	if len(schedules) == 0 {
@@ -951,29 +1104,73 @@ func (s *ScheduleDaoImpl) getBulkStatus(schedules []store.Schedule) db_wrapper.I
 		uuids = append(uuids, schedule.ScheduleId)
 	}
 
-	query := "SELECT " +
-		"schedule_id," +
-		"schedule_status," +
-		"error_msg," +
-		"reconciliation_history " +
-		"FROM status " +
+	if legacy {
+		query := "SELECT schedule_id," + statusColumns +
+			"FROM " + legacyStatusTable + " " +
+			"WHERE app_id= ? " +
+			"AND partition_id= ? " +
+			"AND schedule_id IN ?"
+
+		return s.Session.Query(
+			query,
+			schedules[0].AppId,
+			schedules[0].PartitionId,
+			uuids).
+			RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+			Iter()
+	}
+
+	query := "SELECT schedule_id," + statusColumns +
+		"FROM status_by_bucket " +
 		"WHERE app_id= ? " +
 		"AND partition_id= ? " +
+		"AND schedule_time_group= ? " +
 		"AND schedule_id IN ?"
 
-	iter := s.Session.Query(
+	return s.Session.Query(
 		query,
 		schedules[0].AppId,
 		schedules[0].PartitionId,
+		schedules[0].ScheduleGroup*constants.SecondsToMillis,
 		uuids).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		Iter()
+}
 
-	return iter
+// Group schedules by the status partition they are written to.
+// The schedule time group is a part of the partition unless the legacy status table is read.
+func groupByStatusPartition(schedules []store.Schedule, legacy bool) [][]store.Schedule {
+	type partition struct {
+		appId         string
+		partitionId   int
+		scheduleGroup int64
+	}
+
+	var keys []partition
+	groups := make(map[partition][]store.Schedule)
+
+	for _, schedule := range schedules {
+		key := partition{appId: schedule.AppId, partitionId: schedule.PartitionId}
+		if !legacy {
+			key.scheduleGroup = schedule.ScheduleGroup
+		}
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], schedule)
+	}
+
+	grouped := make([][]store.Schedule, 0, len(keys))
+	for _, key := range keys {
+		grouped = append(grouped, groups[key])
+	}
+	return grouped
 }
 
-// Fetch status data in bulk by appId, partitionId, scheduleIds
+// Fetch status data in bulk, one query per status partition of the schedules
 // Set status for schedules present in Status table
+// Statuses not found are looked up in the legacy status table if LegacyStatusReads is set
 // Set status for schedules non present in status table
 // Return error if there is any error while fetching data
 func (s *ScheduleDaoImpl) OptimizedEnrichSchedule(schedules []store.Schedule) ([]store.Schedule, error) {
@@ -992,35 +1189,51 @@ func (s *ScheduleDaoImpl) OptimizedEnrichSchedule(schedules []store.Schedule) ([
 
 	glog.Infof("idToSchedule: %+v", idToSchedule)
 
+	tables := []bool{false}
+	if s.Conf.ScheduleDB.LegacyStatusReads {
+		tables = append(tables, true)
+	}
+
 	// Set status of schedules which are present in status table
-	iter := s.getBulkStatus(schedules)
-	for iter.MapScan(_map) {
-		uuid := _map["schedule_id"].(gocql.UUID)
-		schedule := idToSchedule[uuid]
-		found[uuid] = true
+	pending := schedules
+	for _, legacy := range tables {
+		for _, group := range groupByStatusPartition(pending, legacy) {
+			iter := s.getBulkStatus(group, legacy)
+			for iter.MapScan(_map) {
+				uuid := _map["schedule_id"].(gocql.UUID)
+				schedule := idToSchedule[uuid]
+				found[uuid] = true
+
+				if err := schedule.SetStatus(_map); err != nil {
+					return enrichedSchedules, err
+				}
 
-		if err := schedule.SetStatus(_map); err != nil {
-			return enrichedSchedules, err
-		}
+				glog.V(constants.INFO).Infof("Enriched Schedule: %+v", schedule)
 
-		glog.V(constants.INFO).Infof("Enriched Schedule: %+v", schedule)
+				enrichedSchedules = append(enrichedSchedules, schedule)
+				_map = make(map[string]interface{})
+			}
+			if err := iter.Close(); err != nil {
+				glog.Errorf("Error: %s while calling status query for schedules: %+v", err.Error(), group)
+				return enrichedSchedules, err
+			}
+		}
 
-		enrichedSchedules = append(enrichedSchedules, schedule)
-		_map = make(map[string]interface{})
-	}
-	if err := iter.Close(); err != nil {
-		glog.Errorf("Error: %s while calling status query for schedules: %+v", err.Error(), schedules)
-		return enrichedSchedules, err
+		var notFound []store.Schedule
+		for _, schedule := range pending {
+			if !found[schedule.ScheduleId] {
+				notFound = append(notFound, schedule)
+			}
+		}
+		pending = notFound
 	}
 
 	// Set status of schedules which are not present in status table
-	for uuid, schedule := range idToSchedule {
-		if _, ok := found[uuid]; !ok {
-			schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
+	for _, schedule := range pending {
+		schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
 
-			glog.V(constants.INFO).Infof("Enriched Schedule: %+v", schedule)
-			enrichedSchedules = append(enrichedSchedules, schedule)
-		}
+		glog.V(constants.INFO).Infof("Enriched Schedule: %+v", schedule)
+		enrichedSchedules = append(enrichedSchedules, schedule)
 	}
 
 	return enrichedSchedules, nil
@@ -1035,7 +1248,12 @@ func (s *ScheduleDaoImpl) GetCronSchedulesByApp(appId string, status store.Statu
 		"app_id," +
 		"partition_id, " +
 		"cron_expression, " +
-		"status " +
+		"status," +
+		"expires_after," +
+		"jitter," +
+		"calendar," +
+		"calendar_policy," +
+		"priority " +
 		"FROM recurring_schedules_by_id"
 
 	var schedules []store.Schedule
@@ -1043,7 +1261,7 @@ func (s *ScheduleDaoImpl) GetCronSchedulesByApp(appId string, status store.Statu
 
 	_map := make(map[string]interface{})
 	iter := s.Session.Query(query).
-		RetryPolicy(&gocql.SimpleRetryPolicy{NumRetries: s.Conf.ScheduleDB.DBConfig.NumRetry}).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
 		Iter()
 
 	for iter.MapScan(_map) {
@@ -1066,6 +1284,12 @@ func (s *ScheduleDaoImpl) GetCronSchedulesByApp(appId string, status store.Statu
 }
 
 func (s *ScheduleDaoImpl) BulkAction(app store.App, partitionId int, scheduleTimeGroup time.Time, status []store.Status, actionType store.ActionType) error {
+	return bulkAction(s, app, partitionId, scheduleTimeGroup, status, actionType)
+}
+
+// Reconcile or delete the schedules of an app partition in a time bucket whose status is one of the given ones.
+// The schedules are fetched page by page and enriched with status in batches of BatchSize.
+func bulkAction(s ScheduleDao, app store.App, partitionId int, scheduleTimeGroup time.Time, status []store.Status, actionType store.ActionType) error {
 	defer func() {
 		if r := recover(); r != nil {
 			glog.Errorf("Recovered in %s from error %+v with stacktrace %s", string(actionType), r, string(debug.Stack()))
@@ -1092,7 +1316,7 @@ func (s *ScheduleDaoImpl) BulkAction(app store.App, partitionId int, scheduleTim
 		counter++
 
 		if counter == BatchSize {
-			if err := s.actionIfRequired(app, batch, status, actionType); err != nil {
+			if err := actionIfRequired(s, app, batch, status, actionType); err != nil {
 				return err
 			}
 
@@ -1103,7 +1327,7 @@ func (s *ScheduleDaoImpl) BulkAction(app store.App, partitionId int, scheduleTim
 		_sch = store.Schedule{}
 	}
 
-	if err := s.actionIfRequired(app, batch, status, actionType); err != nil {
+	if err := actionIfRequired(s, app, batch, status, actionType); err != nil {
 		return err
 	}
 
@@ -1126,7 +1350,7 @@ func (s *ScheduleDaoImpl) BulkAction(app store.App, partitionId int, scheduleTim
 func contains(status []store.Status, _sch store.Schedule) bool {
 	for _, v := range status {
 		switch v {
-		case store.Success, store.Failure, store.Miss, store.Scheduled:
+		case store.Success, store.Failure, store.Partial, store.Expired, store.Miss, store.Scheduled:
 			if v == _sch.Status {
 				return true
 			}
@@ -1139,7 +1363,7 @@ func contains(status []store.Status, _sch store.Schedule) bool {
 
 // Makes the callback for schedules based on the status
 // Schedules are enriched with status before making a callback
-func (s *ScheduleDaoImpl) actionIfRequired(app store.App, schedules []store.Schedule, status []store.Status, actionType store.ActionType) error {
+func actionIfRequired(s ScheduleDao, app store.App, schedules []store.Schedule, status []store.Status, actionType store.ActionType) error {
 	if len(schedules) == 0 {
 		return nil
 	}
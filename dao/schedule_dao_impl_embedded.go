@@ -0,0 +1,1079 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/db_wrapper"
+	"github.com/myntra/goscheduler/embedded"
+	p "github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	defaultEmbeddedPageSize      = 1000
+	defaultEmbeddedPurgeInterval = 60
+)
+
+// ScheduleDaoImplEmbedded keeps the schedules in an embedded database file.
+// Rows carry the time they expire at, expired rows are skipped by reads and purged periodically,
+// which emulates the TTL Cassandra expires rows with.
+type ScheduleDaoImplEmbedded struct {
+	DB      *bolt.DB
+	Conf    *conf.Configuration
+	Monitor p.Monitor
+}
+
+func GetScheduleDaoImplEmbedded(conf *conf.Configuration, monitor p.Monitor) *ScheduleDaoImplEmbedded {
+	db, err := embedded.GetDB(conf.Storage.Embedded)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Embedded database initialisation failed for path %s with error %s", conf.Storage.Embedded.Path, err.Error()))
+		panic(err)
+	}
+
+	dao := NewScheduleDaoImplEmbedded(db, conf, monitor)
+	go dao.purgeExpired()
+	return dao
+}
+
+// NewScheduleDaoImplEmbedded creates the DAO over an open database, without purging the expired rows periodically.
+func NewScheduleDaoImplEmbedded(db *bolt.DB, conf *conf.Configuration, monitor p.Monitor) *ScheduleDaoImplEmbedded {
+	return &ScheduleDaoImplEmbedded{
+		DB:      db,
+		Conf:    conf,
+		Monitor: monitor,
+	}
+}
+
+// Profile execution of do
+func (s *ScheduleDaoImplEmbedded) profile(do func() (store.Schedule, error), metric, appId string) (store.Schedule, error) {
+	startTime := time.Now()
+
+	result, err := do()
+
+	if s.Monitor != nil {
+		duration := time.Since(startTime)
+		s.Monitor.RecordTiming(metric, map[string]string{"appId": appId}, duration)
+	}
+
+	return result, err
+}
+
+// Number of schedules fetched per page
+func (s *ScheduleDaoImplEmbedded) pageSize() int {
+	if s.Conf.Storage.Embedded.PageSize <= 0 {
+		return defaultEmbeddedPageSize
+	}
+	return s.Conf.Storage.Embedded.PageSize
+}
+
+// Get the row of a one time schedule or a run
+func (s *ScheduleDaoImplEmbedded) scheduleRow(schedule store.Schedule, app store.App) embeddedSchedule {
+	return embeddedSchedule{
+		AppId:            schedule.AppId,
+		PartitionId:      schedule.PartitionId,
+		ScheduleGroup:    schedule.ScheduleGroup,
+		ScheduleId:       schedule.ScheduleId,
+		ScheduleTime:     schedule.ScheduleTime,
+		Payload:          schedule.Payload,
+		CallbackType:     schedule.GetCallBackType(),
+		CallbackDetails:  schedule.GetCallbackDetails(),
+		ParentScheduleId: schedule.ParentScheduleId,
+		RunNumber:        schedule.RunNumber,
+		NominalTime:      schedule.NominalTime,
+		Chain:            schedule.GetChain(),
+		Deadline:         schedule.Deadline,
+		Priority:         string(schedule.Priority),
+		ExpiresAt:        expiresAtUnix(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)),
+	}
+}
+
+// Write the row of a schedule along with its id lookup and, for runs, the lookup of the runs of its parent
+func putSchedule(tx *bolt.Tx, row embeddedSchedule) error {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	key := row.key()
+	if err = tx.Bucket(embedded.SchedulesBucket).Put(key, data); err != nil {
+		return err
+	}
+
+	if err = tx.Bucket(embedded.SchedulesByIdBucket).Put(row.ScheduleId[:], key); err != nil {
+		return err
+	}
+
+	if row.ParentScheduleId != (gocql.UUID{}) {
+		return tx.Bucket(embedded.RunsBucket).Put(row.runKey(), key)
+	}
+	return nil
+}
+
+// Delete the row of a schedule along with its lookups
+func deleteSchedule(tx *bolt.Tx, row embeddedSchedule) error {
+	if err := tx.Bucket(embedded.SchedulesBucket).Delete(row.key()); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(embedded.SchedulesByIdBucket).Delete(row.ScheduleId[:]); err != nil {
+		return err
+	}
+
+	if row.ParentScheduleId != (gocql.UUID{}) {
+		return tx.Bucket(embedded.RunsBucket).Delete(row.runKey())
+	}
+	return nil
+}
+
+// Get the row stored under the key of the schedules bucket, false if there is none or it has expired
+func getScheduleRow(tx *bolt.Tx, key []byte) (embeddedSchedule, bool, error) {
+	var row embeddedSchedule
+
+	data := tx.Bucket(embedded.SchedulesBucket).Get(key)
+	if data == nil {
+		return row, false, nil
+	}
+
+	if err := json.Unmarshal(data, &row); err != nil {
+		return row, false, err
+	}
+	return row, !expired(row.ExpiresAt, time.Now()), nil
+}
+
+// Get the row of the one time schedule with the id, false if there is none
+func getScheduleRowById(tx *bolt.Tx, uuid gocql.UUID) (embeddedSchedule, bool, error) {
+	key := tx.Bucket(embedded.SchedulesByIdBucket).Get(uuid[:])
+	if key == nil {
+		return embeddedSchedule{}, false, nil
+	}
+	return getScheduleRow(tx, key)
+}
+
+// Get the status of the schedule stored under the key, nil if the schedule has not fired yet
+func getStatusRow(tx *bolt.Tx, key []byte) (*embeddedStatus, error) {
+	data := tx.Bucket(embedded.StatusBucket).Get(key)
+	if data == nil {
+		return nil, nil
+	}
+
+	var row embeddedStatus
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, err
+	}
+
+	if expired(row.ExpiresAt, time.Now()) {
+		return nil, nil
+	}
+	return &row, nil
+}
+
+// Get a one time schedule enriched with its status, gocql.ErrNotFound is returned if there is none
+func (s *ScheduleDaoImplEmbedded) getEnrichedSchedule(tx *bolt.Tx, uuid gocql.UUID) (store.Schedule, error) {
+	row, found, err := getScheduleRowById(tx, uuid)
+	switch {
+	case err != nil:
+		return store.Schedule{}, err
+	case !found:
+		return store.Schedule{}, gocql.ErrNotFound
+	}
+
+	status, err := getStatusRow(tx, row.key())
+	if err != nil {
+		return store.Schedule{}, err
+	}
+
+	return enrichedEmbeddedSchedule(row, status, s.Conf.AggregateSchedulesConfig.FlushPeriod)
+}
+
+// Get the recurring schedule with the id, gocql.ErrNotFound is returned if there is none
+func getRecurringSchedule(tx *bolt.Tx, uuid gocql.UUID) (store.Schedule, error) {
+	var schedule store.Schedule
+	var row embeddedRecurringSchedule
+
+	data := tx.Bucket(embedded.RecurringSchedulesBucket).Get(uuid[:])
+	if data == nil {
+		return schedule, gocql.ErrNotFound
+	}
+
+	if err := json.Unmarshal(data, &row); err != nil {
+		return schedule, err
+	}
+
+	err := schedule.CreateScheduleFromCassandraMap(row.toMap())
+	return schedule, err
+}
+
+// Persist a cron schedule with status as Scheduled.
+func (s *ScheduleDaoImplEmbedded) createRecurringSchedule(schedule store.Schedule) (store.Schedule, error) {
+	data, err := json.Marshal(embeddedRecurringSchedule{
+		ScheduleId:      schedule.ScheduleId,
+		AppId:           schedule.AppId,
+		PartitionId:     schedule.PartitionId,
+		Payload:         schedule.Payload,
+		CallbackType:    schedule.GetCallBackType(),
+		CallbackDetails: schedule.GetCallbackDetails(),
+		CronExpression:  schedule.CronExpression,
+		Status:          string(store.Scheduled),
+		ExpiresAfter:    schedule.ExpiresAfter,
+		Jitter:          schedule.Jitter,
+		Calendar:        schedule.Calendar,
+		CalendarPolicy:  string(schedule.CalendarPolicy),
+		Priority:        string(schedule.Priority),
+	})
+	if err != nil {
+		return schedule, err
+	}
+
+	err = s.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(embedded.RecurringSchedulesBucket).Put(schedule.ScheduleId[:], data)
+	})
+
+	schedule.Status = store.Scheduled
+	return schedule, err
+}
+
+// Persist a one time schedule.
+func (s *ScheduleDaoImplEmbedded) createOneTimeSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		return putSchedule(tx, s.scheduleRow(schedule, app))
+	})
+	return schedule, err
+}
+
+// Persist a one time schedule having a dedup key.
+// Writes to the database are serialized, so the key is checked and claimed in a single transaction.
+// If the key is held by a pending schedule, the app's dedup policy decides whether the pending schedule is replaced,
+// kept or the creation is rejected. Expired keys and keys held by schedules which are no longer pending are taken over.
+func (s *ScheduleDaoImplEmbedded) createDedupSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	var replaced *store.Schedule
+	result := schedule
+
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		row := s.scheduleRow(schedule, app)
+		keys := tx.Bucket(embedded.DedupKeysBucket)
+		key := joinKey(schedule.AppId, schedule.DedupKey)
+
+		var holder embeddedExpiring
+		if data := keys.Get(key); data != nil {
+			if err := json.Unmarshal(data, &holder); err != nil {
+				return err
+			}
+		}
+
+		if holder.ScheduleId != (gocql.UUID{}) && !expired(holder.ExpiresAt, time.Now()) {
+			pending, err := s.getEnrichedSchedule(tx, holder.ScheduleId)
+			switch {
+			case err == gocql.ErrNotFound:
+			case err != nil:
+				return err
+			case pending.Status == store.Scheduled:
+				switch app.Configuration.GetDedupPolicy() {
+				case store.DedupReject:
+					return ErrDuplicateSchedule
+				case store.DedupKeepFirst:
+					pending.Deduplicated = true
+					result = pending
+					return nil
+				}
+
+				pendingRow, _, err := getScheduleRowById(tx, pending.ScheduleId)
+				if err != nil {
+					return err
+				}
+				if err = deleteSchedule(tx, pendingRow); err != nil {
+					return err
+				}
+				if err = deleteLabelRows(tx, pending.AppId, pending.ScheduleId); err != nil {
+					return err
+				}
+				replaced = &pending
+			}
+		}
+
+		data, err := json.Marshal(embeddedExpiring{ScheduleId: schedule.ScheduleId, ExpiresAt: row.ExpiresAt})
+		if err != nil {
+			return err
+		}
+		if err = keys.Put(key, data); err != nil {
+			return err
+		}
+
+		return putSchedule(tx, row)
+	})
+	if err != nil {
+		return schedule, err
+	}
+
+	if replaced != nil {
+		if err = s.UpdateUsage(replaced.AppId, store.PendingUsage, -1); err != nil {
+			glog.Errorf("Releasing the usage of replaced schedule %s failed with error %s", replaced.ScheduleId.String(), err.Error())
+		}
+		result.Replaced = replaced
+	}
+
+	return result, nil
+}
+
+// Persist a row per label of the schedule in the label lookup bucket.
+// Rows of one time schedules expire along with the schedule, rows of recurring schedules do not expire.
+func (s *ScheduleDaoImplEmbedded) createLabels(schedule store.Schedule, app store.App) error {
+	if len(schedule.Labels) == 0 {
+		return nil
+	}
+
+	row := embeddedExpiring{ScheduleId: schedule.ScheduleId, Labels: schedule.Labels}
+	if !schedule.IsRecurring() {
+		row.ExpiresAt = expiresAtUnix(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod))
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		labels := tx.Bucket(embedded.LabelsBucket)
+		for _, label := range schedule.GetLabelSelectors() {
+			if err := labels.Put(append(joinKey(schedule.AppId, label, ""), schedule.ScheduleId[:]...), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Persist the schedule details.
+// One time schedules having a dedup key are deduplicated against the pending schedules of the app.
+// The labels of the schedule are written to the label lookup bucket once the schedule is created.
+func (s *ScheduleDaoImplEmbedded) CreateSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	var created store.Schedule
+	var err error
+
+	if schedule.IsRecurring() {
+		created, err = s.profile(func() (store.Schedule, error) {
+			return s.createRecurringSchedule(schedule)
+		}, constants.CreateRecurringSchedule, schedule.AppId)
+	} else {
+		created, err = s.profile(func() (store.Schedule, error) {
+			if schedule.DedupKey != "" {
+				return s.createDedupSchedule(schedule, app)
+			}
+			return s.createOneTimeSchedule(schedule, app)
+		}, constants.CreateOneTimeSchedule, schedule.AppId)
+	}
+
+	if err != nil || created.Deduplicated {
+		return created, err
+	}
+
+	return created, s.createLabels(schedule, app)
+}
+
+// Get the recurring schedules passing the filter
+func (s *ScheduleDaoImplEmbedded) getRecurringSchedules(filter func(row embeddedRecurringSchedule) bool) ([]store.Schedule, []error) {
+	var schedules []store.Schedule
+	var errs []error
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(embedded.RecurringSchedulesBucket).ForEach(func(k, v []byte) error {
+			var row embeddedRecurringSchedule
+			if err := json.Unmarshal(v, &row); err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+
+			if !filter(row) {
+				return nil
+			}
+
+			var schedule store.Schedule
+			if err := schedule.CreateScheduleFromCassandraMap(row.toMap()); err != nil {
+				errs = append(errs, err)
+			} else {
+				schedules = append(schedules, schedule)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return schedules, errs
+}
+
+// Get all recurring schedules with partition id
+func (s *ScheduleDaoImplEmbedded) GetRecurringScheduleByPartition(partitionId int) ([]store.Schedule, []error) {
+	return s.getRecurringSchedules(func(row embeddedRecurringSchedule) bool {
+		return row.PartitionId == partitionId
+	})
+}
+
+// Get a single schedule with the supplied id.
+// Attempts to find a one time schedule and falls back to recurring schedules if not found.
+func (s *ScheduleDaoImplEmbedded) GetSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	var schedule store.Schedule
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		row, found, err := getScheduleRowById(tx, uuid)
+		switch {
+		case err != nil:
+			return err
+		case !found:
+			schedule, err = getRecurringSchedule(tx, uuid)
+			return err
+		default:
+			return schedule.CreateScheduleFromCassandraMap(row.toMap())
+		}
+	})
+
+	return schedule, err
+}
+
+// Get a single schedule enriched with status data
+func (s *ScheduleDaoImplEmbedded) GetEnrichedSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	var schedule store.Schedule
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		var err error
+		if schedule, err = s.getEnrichedSchedule(tx, uuid); err == gocql.ErrNotFound {
+			schedule, err = getRecurringSchedule(tx, uuid)
+		}
+		return err
+	})
+
+	return schedule, err
+}
+
+// Enrich a non-recurring schedule with status data
+func (s *ScheduleDaoImplEmbedded) EnrichSchedule(schedule *store.Schedule) error {
+	return s.DB.View(func(tx *bolt.Tx) error {
+		status, err := getStatusRow(tx, scheduleKey(schedule.AppId, schedule.ScheduleGroup, schedule.PartitionId, schedule.ScheduleId))
+		switch {
+		case err != nil:
+			return err
+		case status == nil:
+			schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
+			return nil
+		default:
+			return schedule.SetStatus(status.toMap())
+		}
+	})
+}
+
+// Delete schedule with the given id
+// For one time schedules, the rows are removed where as for the recurring schedules, the status is marked as Deleted
+// and all one time future runs generated from the schedule are removed.
+func (s *ScheduleDaoImplEmbedded) DeleteSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	var schedule store.Schedule
+
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		row, found, err := getScheduleRowById(tx, uuid)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			if err = schedule.CreateScheduleFromCassandraMap(row.toMap()); err != nil {
+				return err
+			}
+			if err = deleteSchedule(tx, row); err != nil {
+				return err
+			}
+			return deleteLabelRows(tx, schedule.AppId, uuid)
+		}
+
+		if schedule, err = getRecurringSchedule(tx, uuid); err != nil {
+			return err
+		}
+		if err = s.deleteRecurringSchedule(tx, &schedule); err != nil {
+			return err
+		}
+		return deleteLabelRows(tx, schedule.AppId, uuid)
+	})
+
+	return schedule, err
+}
+
+// Mark a recurring schedule as Deleted and remove its future runs
+func (s *ScheduleDaoImplEmbedded) deleteRecurringSchedule(tx *bolt.Tx, schedule *store.Schedule) error {
+	recurring := tx.Bucket(embedded.RecurringSchedulesBucket)
+
+	var row embeddedRecurringSchedule
+	if err := json.Unmarshal(recurring.Get(schedule.ScheduleId[:]), &row); err != nil {
+		return err
+	}
+
+	row.Status = string(store.Deleted)
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if err = recurring.Put(schedule.ScheduleId[:], data); err != nil {
+		return err
+	}
+
+	var runs []embeddedSchedule
+	prefix := appendUint64(append([]byte{}, schedule.ScheduleId[:]...), uint64(time.Now().Unix()+1))
+	c := tx.Bucket(embedded.RunsBucket).Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, schedule.ScheduleId[:]); k, v = c.Next() {
+		run, found, err := getScheduleRow(tx, v)
+		if err != nil {
+			return err
+		}
+		if found {
+			runs = append(runs, run)
+		}
+	}
+
+	for _, run := range runs {
+		if err = deleteSchedule(tx, run); err != nil {
+			return err
+		}
+	}
+
+	schedule.Status = store.Deleted
+	return nil
+}
+
+// Get size number of runs of a recurring schedule, latest first, or all of them if size is negative.
+// Past runs are the ones whose schedule time group is before now, future runs the ones after now.
+// Future runs are the farthest size runs in the future, sorted from the immediate run to the farthest one,
+// like the Cassandra implementation does.
+func (s *ScheduleDaoImplEmbedded) GetScheduleRuns(uuid gocql.UUID, size int64, when string, pageState []byte) ([]store.Schedule, []byte, error) {
+	var schedules []store.Schedule
+	var nextPageState []byte
+	now := time.Now()
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		prefix := uuid[:]
+		c := tx.Bucket(embedded.RunsBucket).Cursor()
+
+		for k, v := seekBefore(c, prefix, pageState); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+			if size >= 0 && len(schedules) == int(size) {
+				break
+			}
+
+			row, found, err := getScheduleRow(tx, v)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+
+			group := time.Unix(row.ScheduleGroup, 0)
+			if (when == "past" && !group.Before(now)) || (when == "future" && !group.After(now)) {
+				continue
+			}
+
+			status, err := getStatusRow(tx, v)
+			if err != nil {
+				return err
+			}
+
+			schedule, err := enrichedEmbeddedSchedule(row, status, s.Conf.AggregateSchedulesConfig.FlushPeriod)
+			if err != nil {
+				return err
+			}
+
+			schedules = append(schedules, schedule)
+			nextPageState = append([]byte{}, k...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if size < 0 || len(schedules) < int(size) {
+		nextPageState = nil
+	}
+
+	if when == "future" {
+		for i, j := 0, len(schedules)-1; i < j; i, j = i+1, j-1 {
+			schedules[i], schedules[j] = schedules[j], schedules[i]
+		}
+	}
+
+	return schedules, nextPageState, nil
+}
+
+// Create a one time schedule for a recurring schedule.
+func (s *ScheduleDaoImplEmbedded) CreateRun(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	err := s.DB.Update(func(tx *bolt.Tx) error {
+		return putSchedule(tx, s.scheduleRow(schedule, app))
+	})
+	return schedule, err
+}
+
+// Write the status of the schedules in a single transaction.
+// The status rows expire along with the schedules.
+func (s *ScheduleDaoImplEmbedded) UpdateStatus(schedules []store.Schedule, app store.App) error {
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		for _, schedule := range schedules {
+			glog.Infof("update status for schedule: %s", schedule.ScheduleId.String())
+
+			reconciliationHistory, _ := json.Marshal(schedule.ReconciliationHistory)
+
+			var targetStatus []byte
+			if len(schedule.TargetStatuses) > 0 {
+				targetStatus, _ = json.Marshal(schedule.TargetStatuses)
+			}
+
+			data, err := json.Marshal(embeddedStatus{
+				Status:                string(schedule.Status),
+				ErrorMsg:              schedule.ErrorMessage,
+				ReconciliationHistory: string(reconciliationHistory),
+				TargetStatus:          string(targetStatus),
+				NextScheduleId:        schedule.NextScheduleId,
+				ExpiresAt:             expiresAtUnix(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)),
+			})
+			if err != nil {
+				return err
+			}
+
+			key := scheduleKey(schedule.AppId, schedule.ScheduleGroup, schedule.PartitionId, schedule.ScheduleId)
+			if err = tx.Bucket(embedded.StatusBucket).Put(key, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get size number of schedules of an app in the time range [startTime, endTime), filtered by status.
+// Schedules are sorted by schedule time group and scanned until size schedules pass the filter.
+// The page state holds the key of the last schedule returned, the continuation start time is its schedule time group.
+func (s *ScheduleDaoImplEmbedded) GetPaginatedSchedules(appId string, partitions int, timeRange Range, size int64, status store.Status, pageState []byte, continuationStartTime time.Time) ([]store.Schedule, []byte, time.Time, error) {
+	switch status {
+	case store.Success, store.Failure, store.Partial, store.Expired, store.Miss, store.Scheduled:
+	default:
+		status = ""
+	}
+
+	startTime := timeRange.StartTime
+	if continuationStartTime.Unix() != 0 && continuationStartTime.After(startTime) {
+		startTime = continuationStartTime
+	}
+
+	var schedules []store.Schedule
+	var nextPageState []byte
+	continuation := startTime
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		end := scheduleGroupPrefix(appId, timeRange.EndTime.Unix())
+		c := tx.Bucket(embedded.SchedulesBucket).Cursor()
+
+		for k, v := seekAfter(c, scheduleGroupPrefix(appId, startTime.Unix()), pageState); k != nil && bytes.Compare(k, end) < 0; k, v = c.Next() {
+			group, partitionId := parseScheduleKey(k)
+			if partitionId >= partitions {
+				continue
+			}
+
+			var row embeddedSchedule
+			if err := json.Unmarshal(v, &row); err != nil {
+				return err
+			}
+			if expired(row.ExpiresAt, time.Now()) {
+				continue
+			}
+
+			statusRow, err := getStatusRow(tx, k)
+			if err != nil {
+				return err
+			}
+
+			schedule, err := enrichedEmbeddedSchedule(row, statusRow, s.Conf.AggregateSchedulesConfig.FlushPeriod)
+			if err != nil {
+				return err
+			}
+
+			continuation = time.Unix(group, 0)
+			if status == "" || schedule.Status == status {
+				schedules = append(schedules, schedule)
+			}
+
+			if len(schedules) == int(size) {
+				nextPageState = append([]byte{}, k...)
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, continuation, err
+	}
+
+	return schedules, nextPageState, continuation, nil
+}
+
+// Fetch a page of the schedules of an app partition in a time bucket.
+// The page state holds the key of the last schedule of the page.
+func (s *ScheduleDaoImplEmbedded) GetSchedulesForEntity(appId string, partitionId int, timeBucket time.Time, pageState []byte) db_wrapper.IterInterface {
+	iter := &rowsIter{columns: entityScheduleColumns}
+
+	iter.err = s.DB.View(func(tx *bolt.Tx) error {
+		prefix := appendUint32(scheduleGroupPrefix(appId, timeBucket.Unix()), uint32(partitionId))
+		c := tx.Bucket(embedded.SchedulesBucket).Cursor()
+
+		for k, v := seekAfter(c, prefix, pageState); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var row embeddedSchedule
+			if err := json.Unmarshal(v, &row); err != nil {
+				return err
+			}
+			if expired(row.ExpiresAt, time.Now()) {
+				continue
+			}
+
+			iter.rows = append(iter.rows, row.toMap())
+			if len(iter.rows) == s.pageSize() {
+				iter.pageState = append([]byte{}, k...)
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return iter
+}
+
+// Fetch the status of the schedules in bulk.
+// Schedules not present in the status bucket get their status from their schedule time.
+func (s *ScheduleDaoImplEmbedded) OptimizedEnrichSchedule(schedules []store.Schedule) ([]store.Schedule, error) {
+	var enrichedSchedules []store.Schedule
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		for _, schedule := range schedules {
+			status, err := getStatusRow(tx, scheduleKey(schedule.AppId, schedule.ScheduleGroup, schedule.PartitionId, schedule.ScheduleId))
+			if err != nil {
+				return err
+			}
+
+			if status == nil {
+				schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
+			} else if err = schedule.SetStatus(status.toMap()); err != nil {
+				return err
+			}
+
+			glog.V(constants.INFO).Infof("Enriched Schedule: %+v", schedule)
+			enrichedSchedules = append(enrichedSchedules, schedule)
+		}
+		return nil
+	})
+
+	return enrichedSchedules, err
+}
+
+// Get the recurring schedules of an app, or of all apps if appId is empty.
+// Only schedules with the given status are returned if it is Scheduled or Deleted.
+func (s *ScheduleDaoImplEmbedded) GetCronSchedulesByApp(appId string, status store.Status) ([]store.Schedule, []string) {
+	schedules, errs := s.getRecurringSchedules(func(row embeddedRecurringSchedule) bool {
+		if row.AppId != appId && appId != "" {
+			return false
+		}
+		return store.Status(row.Status) == status || (status != store.Scheduled && status != store.Deleted)
+	})
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return schedules, messages
+}
+
+func (s *ScheduleDaoImplEmbedded) BulkAction(app store.App, partitionId int, scheduleTimeGroup time.Time, status []store.Status, actionType store.ActionType) error {
+	return bulkAction(s, app, partitionId, scheduleTimeGroup, status, actionType)
+}
+
+// Get the schedules of an app having all the supplied labels.
+// The lookup bucket is scanned with the first selector and the rows are filtered with the remaining ones,
+// so a page may contain less than size schedules.
+func (s *ScheduleDaoImplEmbedded) GetSchedulesByLabel(appId string, selectors []string, size int64, pageState []byte) ([]store.Schedule, []byte, error) {
+	var schedules []store.Schedule
+	var nextPageState []byte
+
+	if len(selectors) == 0 {
+		return schedules, nil, nil
+	}
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		prefix := joinKey(appId, selectors[0], "")
+		c := tx.Bucket(embedded.LabelsBucket).Cursor()
+
+		scanned := 0
+		for k, v := seekAfter(c, prefix, pageState); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if scanned == int(size) {
+				nextPageState = append([]byte{}, pageState...)
+				return nil
+			}
+			scanned++
+			pageState = append([]byte{}, k...)
+
+			var row embeddedExpiring
+			if err := json.Unmarshal(v, &row); err != nil {
+				glog.Errorf("Error unmarshalling labels of %s: %v", string(k), err)
+				continue
+			}
+
+			if expired(row.ExpiresAt, time.Now()) || !store.MatchLabels(row.Labels, selectors[1:]) {
+				continue
+			}
+
+			schedule, err := s.getEnrichedSchedule(tx, row.ScheduleId)
+			if err == gocql.ErrNotFound {
+				schedule, err = getRecurringSchedule(tx, row.ScheduleId)
+			}
+			switch {
+			case err == gocql.ErrNotFound:
+				continue
+			case err != nil:
+				return err
+			}
+
+			schedule.Labels = row.Labels
+			schedules = append(schedules, schedule)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schedules, nextPageState, nil
+}
+
+// Get the labels of the labelled schedules among the supplied ones of an app, by schedule id.
+// The label rows are keyed by app and label, so the label rows of the app are scanned for the ones of the schedules.
+func (s *ScheduleDaoImplEmbedded) GetLabels(appId string, scheduleIds []gocql.UUID) (map[gocql.UUID]map[string]string, error) {
+	labelsById := make(map[gocql.UUID]map[string]string)
+	if len(scheduleIds) == 0 {
+		return labelsById, nil
+	}
+
+	wanted := make(map[gocql.UUID]bool, len(scheduleIds))
+	for _, scheduleId := range scheduleIds {
+		wanted[scheduleId] = true
+	}
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		prefix := joinKey(appId, "")
+		c := tx.Bucket(embedded.LabelsBucket).Cursor()
+
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var scheduleId gocql.UUID
+			if len(k) < len(scheduleId) {
+				continue
+			}
+			if copy(scheduleId[:], k[len(k)-len(scheduleId):]); !wanted[scheduleId] {
+				continue
+			}
+
+			var row embeddedExpiring
+			if err := json.Unmarshal(v, &row); err != nil {
+				glog.Errorf("Error unmarshalling labels of %s: %v", string(k), err)
+				continue
+			}
+
+			if !expired(row.ExpiresAt, time.Now()) {
+				labelsById[row.ScheduleId] = row.Labels
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return labelsById, nil
+}
+
+// Delete the label lookup rows of a schedule, whichever labels it has.
+// The rows are keyed by app and label, so the label rows of the app are scanned for the ones of the schedule.
+func deleteLabelRows(tx *bolt.Tx, appId string, scheduleId gocql.UUID) error {
+	labels := tx.Bucket(embedded.LabelsBucket)
+	prefix := joinKey(appId, "")
+
+	var keys [][]byte
+	c := labels.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		if bytes.HasSuffix(k, scheduleId[:]) {
+			keys = append(keys, append([]byte{}, k...))
+		}
+	}
+
+	for _, key := range keys {
+		if err := labels.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete the label lookup rows of the schedule.
+func (s *ScheduleDaoImplEmbedded) DeleteLabels(schedule store.Schedule) error {
+	if len(schedule.Labels) == 0 {
+		return nil
+	}
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		labels := tx.Bucket(embedded.LabelsBucket)
+		for _, label := range schedule.GetLabelSelectors() {
+			if err := labels.Delete(append(joinKey(schedule.AppId, label, ""), schedule.ScheduleId[:]...)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateUsage adds delta to the number of schedules of the given kind of an app, the usage never goes below zero.
+func (s *ScheduleDaoImplEmbedded) UpdateUsage(appId string, kind store.UsageKind, delta int64) error {
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		usage := tx.Bucket(embedded.UsageBucket)
+		key := joinKey(appId, string(kind))
+
+		var total int64
+		if data := usage.Get(key); data != nil {
+			total = int64(binary.BigEndian.Uint64(data))
+		}
+
+		if total += delta; total < 0 {
+			total = 0
+		}
+		return usage.Put(key, appendUint64(nil, uint64(total)))
+	})
+}
+
+// GetUsage gets the number of schedules of an app counted against its quotas
+func (s *ScheduleDaoImplEmbedded) GetUsage(appId string) (store.Usage, error) {
+	var usage store.Usage
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		prefix := joinKey(appId, "")
+		c := tx.Bucket(embedded.UsageBucket).Cursor()
+
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			total := int64(binary.BigEndian.Uint64(v))
+			switch store.UsageKind(k[len(prefix):]) {
+			case store.PendingUsage:
+				usage.PendingSchedules = total
+			case store.RecurringUsage:
+				usage.RecurringSchedules = total
+			}
+		}
+		return nil
+	})
+
+	return usage, err
+}
+
+// Purge the expired rows periodically
+func (s *ScheduleDaoImplEmbedded) purgeExpired() {
+	interval := s.Conf.Storage.Embedded.PurgeInterval
+	if interval <= 0 {
+		interval = defaultEmbeddedPurgeInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.purge(); err != nil {
+			glog.Errorf("Purging expired rows failed with error %s", err.Error())
+		}
+	}
+}
+
+// Delete the expired rows, along with the lookups of the expired schedules
+func (s *ScheduleDaoImplEmbedded) purge() error {
+	now := time.Now()
+
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		var schedules []embeddedSchedule
+		err := tx.Bucket(embedded.SchedulesBucket).ForEach(func(k, v []byte) error {
+			var row embeddedSchedule
+			if err := json.Unmarshal(v, &row); err != nil {
+				return err
+			}
+			if expired(row.ExpiresAt, now) {
+				schedules = append(schedules, row)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range schedules {
+			if err = deleteSchedule(tx, row); err != nil {
+				return err
+			}
+		}
+
+		for _, bucket := range [][]byte{embedded.StatusBucket, embedded.DedupKeysBucket, embedded.LabelsBucket} {
+			if err = purgeBucket(tx.Bucket(bucket), now); err != nil {
+				return err
+			}
+		}
+
+		glog.V(constants.INFO).Infof("Purged %d expired schedules", len(schedules))
+		return nil
+	})
+}
+
+// Delete the expired rows of a bucket whose rows carry their expiry
+func purgeBucket(bucket *bolt.Bucket, now time.Time) error {
+	var keys [][]byte
+
+	err := bucket.ForEach(func(k, v []byte) error {
+		var row struct {
+			ExpiresAt int64 `json:"expiresAt"`
+		}
+		if err := json.Unmarshal(v, &row); err != nil {
+			return err
+		}
+		if expired(row.ExpiresAt, now) {
+			keys = append(keys, append([]byte{}, k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
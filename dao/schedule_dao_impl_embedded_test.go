@@ -0,0 +1,301 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/embedded"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func setupEmbeddedDB(t *testing.T) (*bolt.DB, *conf.Configuration) {
+	s.Registry[constants.DefaultCallback] = func() s.Callback { return &s.HttpCallback{} }
+
+	config := &conf.Configuration{
+		Storage: conf.StorageConfig{
+			Backend:  conf.EmbeddedBackend,
+			Embedded: conf.EmbeddedConfig{Path: filepath.Join(t.TempDir(), "goscheduler.db"), PageSize: 2},
+		},
+		AggregateSchedulesConfig: conf.AggregateSchedulesConfig{
+			FlushPeriod: 60,
+		},
+		AppLevelConfiguration: conf.AppLevelConfiguration{
+			FiredScheduleRetentionPeriod: 1,
+		},
+	}
+
+	db, err := embedded.GetDB(config.Storage.Embedded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, config
+}
+
+func setupEmbeddedScheduleDao(t *testing.T) *ScheduleDaoImplEmbedded {
+	db, config := setupEmbeddedDB(t)
+	return NewScheduleDaoImplEmbedded(db, config, nil)
+}
+
+func newEmbeddedSchedule(scheduleTime time.Time) s.Schedule {
+	return s.Schedule{
+		ScheduleId:    gocql.TimeUUID(),
+		AppId:         "Test",
+		Payload:       "payload",
+		ScheduleTime:  scheduleTime.Unix(),
+		ScheduleGroup: scheduleTime.Truncate(time.Minute).Unix(),
+		Callback:      &s.HttpCallback{Type: "http", Details: s.Details{Url: "http://example.com/callback", Method: "POST"}},
+	}
+}
+
+func TestScheduleDaoImplEmbedded_CreateSchedule(t *testing.T) {
+	dao := setupEmbeddedScheduleDao(t)
+	app := s.App{AppId: "Test", Partitions: 1}
+
+	schedule := newEmbeddedSchedule(time.Now().Add(time.Hour))
+	_, err := dao.CreateSchedule(schedule, app)
+	assert.Nil(t, err)
+
+	found, err := dao.GetEnrichedSchedule(schedule.ScheduleId)
+	assert.Nil(t, err)
+	assert.Equal(t, schedule.ScheduleId, found.ScheduleId)
+	assert.Equal(t, schedule.ScheduleTime, found.ScheduleTime)
+	assert.Equal(t, "payload", found.Payload)
+	assert.Equal(t, s.Scheduled, found.Status)
+
+	schedule.Status = s.Success
+	assert.Nil(t, dao.UpdateStatus([]s.Schedule{schedule}, app))
+
+	found, err = dao.GetEnrichedSchedule(schedule.ScheduleId)
+	assert.Nil(t, err)
+	assert.Equal(t, s.Success, found.Status)
+
+	deleted, err := dao.DeleteSchedule(schedule.ScheduleId)
+	assert.Nil(t, err)
+	assert.Equal(t, schedule.ScheduleId, deleted.ScheduleId)
+
+	_, err = dao.GetSchedule(schedule.ScheduleId)
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
+
+func TestScheduleDaoImplEmbedded_CreateDedupSchedule(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		policy       s.DedupPolicy
+		fired        bool
+		err          error
+		deduplicated bool
+	}{
+		{name: "pending schedule is rejected", policy: s.DedupReject, err: ErrDuplicateSchedule},
+		{name: "pending schedule is kept", policy: s.DedupKeepFirst, deduplicated: true},
+		{name: "pending schedule is replaced", policy: ""},
+		{name: "fired schedule releases the key", policy: s.DedupReject, fired: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dao := setupEmbeddedScheduleDao(t)
+			app := s.App{AppId: "Test", Partitions: 1, Configuration: s.Configuration{DedupPolicy: test.policy}}
+
+			first := newEmbeddedSchedule(time.Now().Add(time.Hour))
+			first.DedupKey = "cart-1"
+			_, err := dao.CreateSchedule(first, app)
+			assert.Nil(t, err)
+
+			if test.fired {
+				first.Status = s.Success
+				assert.Nil(t, dao.UpdateStatus([]s.Schedule{first}, app))
+			}
+
+			second := newEmbeddedSchedule(time.Now().Add(time.Hour))
+			second.DedupKey = "cart-1"
+			created, err := dao.CreateSchedule(second, app)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.deduplicated, created.Deduplicated)
+
+			_, err = dao.GetSchedule(first.ScheduleId)
+			assert.Equal(t, test.policy == "", err == gocql.ErrNotFound)
+		})
+	}
+}
+
+func TestScheduleDaoImplEmbedded_GetScheduleRuns(t *testing.T) {
+	dao := setupEmbeddedScheduleDao(t)
+	app := s.App{AppId: "Test", Partitions: 1}
+
+	parent := newEmbeddedSchedule(time.Now())
+	parent.CronExpression = "* * * * *"
+	_, err := dao.CreateSchedule(parent, app)
+	assert.Nil(t, err)
+
+	var runs []s.Schedule
+	for _, offset := range []time.Duration{-2 * time.Hour, -time.Hour, time.Hour, 2 * time.Hour, 3 * time.Hour} {
+		run := newEmbeddedSchedule(time.Now().Add(offset))
+		run.ParentScheduleId = parent.ScheduleId
+		_, err = dao.CreateRun(run, app)
+		assert.Nil(t, err)
+		runs = append(runs, run)
+	}
+
+	past, _, err := dao.GetScheduleRuns(parent.ScheduleId, 10, "past", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{runs[1].ScheduleId, runs[0].ScheduleId}, scheduleIds(past))
+
+	future, _, err := dao.GetScheduleRuns(parent.ScheduleId, 2, "future", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{runs[3].ScheduleId, runs[4].ScheduleId}, scheduleIds(future))
+
+	latest, pageState, err := dao.GetScheduleRuns(parent.ScheduleId, 3, "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{runs[4].ScheduleId, runs[3].ScheduleId, runs[2].ScheduleId}, scheduleIds(latest))
+
+	next, _, err := dao.GetScheduleRuns(parent.ScheduleId, 3, "", pageState)
+	assert.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{runs[1].ScheduleId, runs[0].ScheduleId}, scheduleIds(next))
+
+	// Deleting the recurring schedule removes its future runs only
+	deleted, err := dao.DeleteSchedule(parent.ScheduleId)
+	assert.Nil(t, err)
+	assert.Equal(t, s.Deleted, deleted.Status)
+
+	remaining, _, err := dao.GetScheduleRuns(parent.ScheduleId, -1, "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{runs[1].ScheduleId, runs[0].ScheduleId}, scheduleIds(remaining))
+}
+
+func TestScheduleDaoImplEmbedded_GetPaginatedSchedules(t *testing.T) {
+	dao := setupEmbeddedScheduleDao(t)
+	app := s.App{AppId: "Test", Partitions: 1}
+
+	start := time.Now().Add(time.Hour).Truncate(time.Minute)
+	var ids []gocql.UUID
+	for i := 0; i < 3; i++ {
+		schedule := newEmbeddedSchedule(start.Add(time.Duration(i) * time.Minute))
+		_, err := dao.CreateSchedule(schedule, app)
+		assert.Nil(t, err)
+		ids = append(ids, schedule.ScheduleId)
+	}
+
+	other := newEmbeddedSchedule(start)
+	other.AppId = "Other"
+	_, err := dao.CreateSchedule(other, app)
+	assert.Nil(t, err)
+
+	timeRange := Range{StartTime: start, EndTime: start.Add(time.Hour)}
+	page, pageState, continuation, err := dao.GetPaginatedSchedules("Test", 1, timeRange, 2, "", nil, time.Unix(0, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, ids[:2], scheduleIds(page))
+	assert.NotNil(t, pageState)
+
+	page, pageState, _, err = dao.GetPaginatedSchedules("Test", 1, timeRange, 2, "", pageState, continuation)
+	assert.Nil(t, err)
+	assert.Equal(t, ids[2:], scheduleIds(page))
+	assert.Nil(t, pageState)
+}
+
+func TestScheduleDaoImplEmbedded_GetSchedulesForEntity(t *testing.T) {
+	dao := setupEmbeddedScheduleDao(t)
+	app := s.App{AppId: "Test", Partitions: 1}
+
+	scheduleTime := time.Now().Add(time.Hour).Truncate(time.Minute)
+	for i := 0; i < 3; i++ {
+		_, err := dao.CreateSchedule(newEmbeddedSchedule(scheduleTime), app)
+		assert.Nil(t, err)
+	}
+
+	var count int
+	var pageState []byte
+	for pages := 0; pages < 3; pages++ {
+		iter := dao.GetSchedulesForEntity("Test", 0, scheduleTime, pageState)
+
+		row := map[string]interface{}{}
+		for iter.MapScan(row) {
+			var schedule s.Schedule
+			assert.Nil(t, schedule.CreateScheduleFromCassandraMap(row))
+			assert.Equal(t, scheduleTime.Unix(), schedule.ScheduleTime)
+			count++
+			row = map[string]interface{}{}
+		}
+		assert.Nil(t, iter.Close())
+
+		if pageState = iter.PageState(); len(pageState) == 0 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestScheduleDaoImplEmbedded_Purge(t *testing.T) {
+	dao := setupEmbeddedScheduleDao(t)
+	app := s.App{AppId: "Test", Partitions: 1}
+
+	// A schedule fired longer ago than the retention period has expired
+	fired := newEmbeddedSchedule(time.Now().Add(-48 * time.Hour))
+	fired.DedupKey = "cart-1"
+	fired.Labels = map[string]string{"tenant": "acme"}
+	_, err := dao.CreateSchedule(fired, app)
+	assert.Nil(t, err)
+
+	_, err = dao.GetSchedule(fired.ScheduleId)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	pending := newEmbeddedSchedule(time.Now().Add(time.Hour))
+	_, err = dao.CreateSchedule(pending, app)
+	assert.Nil(t, err)
+
+	assert.Nil(t, dao.purge())
+
+	err = dao.DB.View(func(tx *bolt.Tx) error {
+		assert.Equal(t, 1, tx.Bucket(embedded.SchedulesBucket).Stats().KeyN)
+		assert.Equal(t, 1, tx.Bucket(embedded.SchedulesByIdBucket).Stats().KeyN)
+		assert.Equal(t, 0, tx.Bucket(embedded.DedupKeysBucket).Stats().KeyN)
+		assert.Equal(t, 0, tx.Bucket(embedded.LabelsBucket).Stats().KeyN)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	_, err = dao.GetSchedule(pending.ScheduleId)
+	assert.Nil(t, err)
+}
+
+func TestScheduleDaoImplEmbedded_Usage(t *testing.T) {
+	dao := setupEmbeddedScheduleDao(t)
+
+	assert.Nil(t, dao.UpdateUsage("Test", s.PendingUsage, 3))
+	assert.Nil(t, dao.UpdateUsage("Test", s.PendingUsage, -1))
+	assert.Nil(t, dao.UpdateUsage("Test", s.RecurringUsage, 1))
+	assert.Nil(t, dao.UpdateUsage("Other", s.PendingUsage, 5))
+
+	usage, err := dao.GetUsage("Test")
+	assert.Nil(t, err)
+	assert.Equal(t, s.Usage{PendingSchedules: 2, RecurringSchedules: 1}, usage)
+}
+
+func scheduleIds(schedules []s.Schedule) []gocql.UUID {
+	var ids []gocql.UUID
+	for _, schedule := range schedules {
+		ids = append(ids, schedule.ScheduleId)
+	}
+	return ids
+}
@@ -0,0 +1,737 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/db_wrapper"
+	"github.com/myntra/goscheduler/store"
+)
+
+const defaultInMemoryPageSize = 1000
+
+// memoryRow is a row kept by the in-memory DAO, in the shape of the maps scanned from Cassandra
+type memoryRow struct {
+	values    map[string]interface{}
+	expiresAt int64
+}
+
+// memoryLabels is a row of the label lookup of the in-memory DAO
+type memoryLabels struct {
+	labels    map[string]string
+	expiresAt int64
+}
+
+// memoryDedupKey is a row of the dedup key lookup of the in-memory DAO
+type memoryDedupKey struct {
+	scheduleId gocql.UUID
+	expiresAt  int64
+}
+
+// ScheduleDaoImplInMemory keeps the schedules in memory, for tests exercising the flows built on the DAO.
+// Rows are kept in the shape Cassandra returns them in and expire like the Cassandra rows do,
+// so the schedules read back are the ones a real backend returns.
+type ScheduleDaoImplInMemory struct {
+	Conf     *conf.Configuration
+	PageSize int
+
+	lock      sync.RWMutex
+	schedules map[gocql.UUID]memoryRow
+	status    map[gocql.UUID]memoryRow
+	recurring map[gocql.UUID]map[string]interface{}
+	dedupKeys map[string]memoryDedupKey
+	labels    map[string]map[gocql.UUID]memoryLabels
+	usage     map[string]int64
+}
+
+func NewScheduleDaoImplInMemory(conf *conf.Configuration) *ScheduleDaoImplInMemory {
+	return &ScheduleDaoImplInMemory{
+		Conf:      conf,
+		PageSize:  defaultInMemoryPageSize,
+		schedules: make(map[gocql.UUID]memoryRow),
+		status:    make(map[gocql.UUID]memoryRow),
+		recurring: make(map[gocql.UUID]map[string]interface{}),
+		dedupKeys: make(map[string]memoryDedupKey),
+		labels:    make(map[string]map[gocql.UUID]memoryLabels),
+		usage:     make(map[string]int64),
+	}
+}
+
+// Encode the offset of the next row to fetch as a page state
+func offsetPageState(offset int) []byte {
+	return []byte(strconv.Itoa(offset))
+}
+
+// Decode the offset of the next row to fetch from a page state, 0 if it is not set
+func pageOffset(pageState []byte) int {
+	offset, err := strconv.Atoi(string(pageState))
+	if err != nil {
+		return 0
+	}
+	return offset
+}
+
+// Get the row of a one time schedule or a run
+func (s *ScheduleDaoImplInMemory) scheduleRow(schedule store.Schedule, app store.App) memoryRow {
+	var deadline, nominalTime time.Time
+	if schedule.Deadline != 0 {
+		deadline = time.Unix(schedule.Deadline, 0)
+	}
+	if schedule.NominalTime != 0 {
+		nominalTime = time.Unix(schedule.NominalTime, 0)
+	}
+
+	return memoryRow{
+		values: map[string]interface{}{
+			"app_id":              schedule.AppId,
+			"partition_id":        schedule.PartitionId,
+			"schedule_time_group": time.Unix(schedule.ScheduleGroup, 0),
+			"schedule_id":         schedule.ScheduleId,
+			"callback_type":       schedule.GetCallBackType(),
+			"callback_details":    schedule.GetCallbackDetails(),
+			"payload":             schedule.Payload,
+			"schedule_time":       time.Unix(schedule.ScheduleTime, 0),
+			"parent_schedule_id":  schedule.ParentScheduleId,
+			"run_number":          schedule.RunNumber,
+			"nominal_time":        nominalTime,
+			"chain":               schedule.GetChain(),
+			"deadline":            deadline,
+			"priority":            string(schedule.Priority),
+		},
+		expiresAt: expiresAtUnix(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)),
+	}
+}
+
+// Get the schedule of a row, enriched with its status if enrich is set
+func (s *ScheduleDaoImplInMemory) toSchedule(row memoryRow, enrich bool) (store.Schedule, error) {
+	var schedule store.Schedule
+	if err := schedule.CreateScheduleFromCassandraMap(row.values); err != nil {
+		return schedule, err
+	}
+
+	if enrich {
+		return schedule, s.setStatus(&schedule)
+	}
+	return schedule, nil
+}
+
+// Set the status of a one time schedule from the status rows, from its schedule time if it has not fired yet
+func (s *ScheduleDaoImplInMemory) setStatus(schedule *store.Schedule) error {
+	status, found := s.status[schedule.ScheduleId]
+	if !found || expired(status.expiresAt, time.Now()) {
+		schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
+		return nil
+	}
+	return schedule.SetStatus(status.values)
+}
+
+// Get the row of the one time schedule with the id, false if there is none or it has expired
+func (s *ScheduleDaoImplInMemory) getScheduleRow(uuid gocql.UUID) (memoryRow, bool) {
+	row, found := s.schedules[uuid]
+	if !found || expired(row.expiresAt, time.Now()) {
+		return memoryRow{}, false
+	}
+	return row, true
+}
+
+// Get the schedule with the id, falling back to the recurring schedules if there is no such one time schedule.
+// gocql.ErrNotFound is returned if there is none
+func (s *ScheduleDaoImplInMemory) getSchedule(uuid gocql.UUID, enrich bool) (store.Schedule, error) {
+	if row, found := s.getScheduleRow(uuid); found {
+		return s.toSchedule(row, enrich)
+	}
+
+	var schedule store.Schedule
+	values, found := s.recurring[uuid]
+	if !found {
+		return schedule, gocql.ErrNotFound
+	}
+
+	err := schedule.CreateScheduleFromCassandraMap(values)
+	return schedule, err
+}
+
+// Get the rows of the one time schedules passing the filter, sorted by schedule time group, partition and id,
+// or from the latest schedule time group to the oldest if desc is set
+func (s *ScheduleDaoImplInMemory) getScheduleRows(filter func(schedule store.Schedule) bool, desc bool) ([]memoryRow, error) {
+	var rows []memoryRow
+	var schedules []store.Schedule
+	now := time.Now()
+
+	for _, row := range s.schedules {
+		if expired(row.expiresAt, now) {
+			continue
+		}
+
+		schedule, err := s.toSchedule(row, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter(schedule) {
+			rows = append(rows, row)
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	less := func(i, j int) bool {
+		a, b := schedules[i], schedules[j]
+		switch {
+		case a.ScheduleGroup != b.ScheduleGroup:
+			return a.ScheduleGroup < b.ScheduleGroup
+		case a.PartitionId != b.PartitionId:
+			return a.PartitionId < b.PartitionId
+		default:
+			return a.ScheduleId.String() < b.ScheduleId.String()
+		}
+	}
+
+	indexes := make([]int, len(rows))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		if desc {
+			return less(indexes[j], indexes[i])
+		}
+		return less(indexes[i], indexes[j])
+	})
+
+	sorted := make([]memoryRow, len(rows))
+	for i, index := range indexes {
+		sorted[i] = rows[index]
+	}
+	return sorted, nil
+}
+
+func (s *ScheduleDaoImplInMemory) createLabels(schedule store.Schedule, app store.App) {
+	if len(schedule.Labels) == 0 {
+		return
+	}
+
+	row := memoryLabels{labels: schedule.Labels}
+	if !schedule.IsRecurring() {
+		row.expiresAt = expiresAtUnix(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod))
+	}
+
+	for _, label := range schedule.GetLabelSelectors() {
+		key := string(joinKey(schedule.AppId, label))
+		if s.labels[key] == nil {
+			s.labels[key] = make(map[gocql.UUID]memoryLabels)
+		}
+		s.labels[key][schedule.ScheduleId] = row
+	}
+}
+
+// Delete the label lookup rows of a schedule, whichever labels it has
+func (s *ScheduleDaoImplInMemory) deleteLabels(appId string, scheduleId gocql.UUID) {
+	prefix := string(joinKey(appId, ""))
+	for key, rows := range s.labels {
+		if strings.HasPrefix(key, prefix) {
+			delete(rows, scheduleId)
+		}
+	}
+}
+
+// Persist a one time schedule having a dedup key.
+// If the key is held by a pending schedule, the app's dedup policy decides whether the pending schedule is replaced,
+// kept or the creation is rejected. Expired keys and keys held by schedules which are no longer pending are taken over.
+func (s *ScheduleDaoImplInMemory) createDedupSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	key := string(joinKey(schedule.AppId, schedule.DedupKey))
+	row := s.scheduleRow(schedule, app)
+
+	if holder, found := s.dedupKeys[key]; found && !expired(holder.expiresAt, time.Now()) {
+		pending, err := s.getSchedule(holder.scheduleId, true)
+		switch {
+		case err == gocql.ErrNotFound:
+		case err != nil:
+			return schedule, err
+		case pending.Status == store.Scheduled && !pending.IsRecurring():
+			switch app.Configuration.GetDedupPolicy() {
+			case store.DedupReject:
+				return schedule, ErrDuplicateSchedule
+			case store.DedupKeepFirst:
+				pending.Deduplicated = true
+				return pending, nil
+			}
+
+			delete(s.schedules, pending.ScheduleId)
+			s.deleteLabels(pending.AppId, pending.ScheduleId)
+			s.addUsage(pending.AppId, store.PendingUsage, -1)
+			schedule.Replaced = &pending
+		}
+	}
+
+	s.dedupKeys[key] = memoryDedupKey{scheduleId: schedule.ScheduleId, expiresAt: row.expiresAt}
+	s.schedules[schedule.ScheduleId] = row
+	return schedule, nil
+}
+
+// Persist the schedule details.
+// One time schedules having a dedup key are deduplicated against the pending schedules of the app.
+func (s *ScheduleDaoImplInMemory) CreateSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	switch {
+	case schedule.IsRecurring():
+		s.recurring[schedule.ScheduleId] = map[string]interface{}{
+			"schedule_id":      schedule.ScheduleId,
+			"app_id":           schedule.AppId,
+			"partition_id":     schedule.PartitionId,
+			"payload":          schedule.Payload,
+			"callback_type":    schedule.GetCallBackType(),
+			"callback_details": schedule.GetCallbackDetails(),
+			"cron_expression":  schedule.CronExpression,
+			"status":           string(store.Scheduled),
+			"expires_after":    schedule.ExpiresAfter,
+			"jitter":           schedule.Jitter,
+			"calendar":         schedule.Calendar,
+			"calendar_policy":  string(schedule.CalendarPolicy),
+			"priority":         string(schedule.Priority),
+		}
+		schedule.Status = store.Scheduled
+	case schedule.DedupKey != "":
+		created, err := s.createDedupSchedule(schedule, app)
+		if err != nil || created.Deduplicated {
+			return created, err
+		}
+		schedule = created
+	default:
+		s.schedules[schedule.ScheduleId] = s.scheduleRow(schedule, app)
+	}
+
+	s.createLabels(schedule, app)
+	return schedule, nil
+}
+
+// Get the recurring schedules passing the filter
+func (s *ScheduleDaoImplInMemory) getRecurringSchedules(filter func(schedule store.Schedule) bool) ([]store.Schedule, []error) {
+	var schedules []store.Schedule
+	var errs []error
+
+	for _, values := range s.recurring {
+		var schedule store.Schedule
+		if err := schedule.CreateScheduleFromCassandraMap(values); err != nil {
+			errs = append(errs, err)
+		} else if filter(schedule) {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	return schedules, errs
+}
+
+// Get all recurring schedules with partition id
+func (s *ScheduleDaoImplInMemory) GetRecurringScheduleByPartition(partitionId int) ([]store.Schedule, []error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.getRecurringSchedules(func(schedule store.Schedule) bool {
+		return schedule.PartitionId == partitionId
+	})
+}
+
+// Get a single schedule with the supplied id.
+// Attempts to find a one time schedule and falls back to recurring schedules if not found.
+func (s *ScheduleDaoImplInMemory) GetSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.getSchedule(uuid, false)
+}
+
+// Get a single schedule enriched with status data
+func (s *ScheduleDaoImplInMemory) GetEnrichedSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.getSchedule(uuid, true)
+}
+
+// Enrich a non-recurring schedule with status data
+func (s *ScheduleDaoImplInMemory) EnrichSchedule(schedule *store.Schedule) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.setStatus(schedule)
+}
+
+// Delete schedule with the given id
+// For one time schedules, the rows are removed where as for the recurring schedules, the status is marked as Deleted
+// and all one time future runs generated from the schedule are removed.
+func (s *ScheduleDaoImplInMemory) DeleteSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	schedule, err := s.getSchedule(uuid, false)
+	switch {
+	case err != nil:
+		return store.Schedule{}, err
+	case !schedule.IsRecurring():
+		delete(s.schedules, uuid)
+		s.deleteLabels(schedule.AppId, uuid)
+		return schedule, nil
+	}
+
+	s.recurring[uuid]["status"] = string(store.Deleted)
+	s.deleteLabels(schedule.AppId, uuid)
+	schedule.Status = store.Deleted
+
+	now := time.Now()
+	runs, err := s.getScheduleRows(func(run store.Schedule) bool {
+		return run.ParentScheduleId == uuid && time.Unix(run.ScheduleGroup, 0).After(now)
+	}, false)
+	if err != nil {
+		return schedule, err
+	}
+
+	for _, run := range runs {
+		delete(s.schedules, run.values["schedule_id"].(gocql.UUID))
+	}
+	return schedule, nil
+}
+
+// Get size number of runs of a recurring schedule, latest first, or all of them if size is negative.
+// Past runs are the ones whose schedule time group is before now, future runs the ones after now.
+// Future runs are the farthest size runs in the future, sorted from the immediate run to the farthest one,
+// like the Cassandra implementation does.
+// The page state holds the offset of the next run to scan.
+func (s *ScheduleDaoImplInMemory) GetScheduleRuns(uuid gocql.UUID, size int64, when string, pageState []byte) ([]store.Schedule, []byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	rows, err := s.getScheduleRows(func(run store.Schedule) bool {
+		return run.ParentScheduleId == uuid
+	}, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var schedules []store.Schedule
+	var nextPageState []byte
+	now := time.Now()
+
+	offset := pageOffset(pageState)
+	for ; offset < len(rows); offset++ {
+		if size >= 0 && len(schedules) == int(size) {
+			nextPageState = offsetPageState(offset)
+			break
+		}
+
+		schedule, err := s.toSchedule(rows[offset], true)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		group := time.Unix(schedule.ScheduleGroup, 0)
+		if (when == "past" && !group.Before(now)) || (when == "future" && !group.After(now)) {
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	if when == "future" {
+		for i, j := 0, len(schedules)-1; i < j; i, j = i+1, j-1 {
+			schedules[i], schedules[j] = schedules[j], schedules[i]
+		}
+	}
+
+	return schedules, nextPageState, nil
+}
+
+// Create a one time schedule for a recurring schedule.
+func (s *ScheduleDaoImplInMemory) CreateRun(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.schedules[schedule.ScheduleId] = s.scheduleRow(schedule, app)
+	return schedule, nil
+}
+
+// Write the status of the schedules, the status rows expire along with the schedules.
+func (s *ScheduleDaoImplInMemory) UpdateStatus(schedules []store.Schedule, app store.App) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, schedule := range schedules {
+		glog.Infof("update status for schedule: %s", schedule.ScheduleId.String())
+
+		reconciliationHistory, _ := json.Marshal(schedule.ReconciliationHistory)
+
+		var targetStatus []byte
+		if len(schedule.TargetStatuses) > 0 {
+			targetStatus, _ = json.Marshal(schedule.TargetStatuses)
+		}
+
+		s.status[schedule.ScheduleId] = memoryRow{
+			values: map[string]interface{}{
+				"schedule_status":        string(schedule.Status),
+				"error_msg":              schedule.ErrorMessage,
+				"reconciliation_history": string(reconciliationHistory),
+				"target_status":          string(targetStatus),
+				"next_schedule_id":       schedule.NextScheduleId,
+			},
+			expiresAt: expiresAtUnix(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)),
+		}
+	}
+	return nil
+}
+
+// Get size number of schedules of an app in the time range [startTime, endTime), filtered by status.
+// The page state holds the offset of the next schedule to scan in the time range,
+// the continuation start time is the schedule time group of the last schedule scanned.
+func (s *ScheduleDaoImplInMemory) GetPaginatedSchedules(appId string, partitions int, timeRange Range, size int64, status store.Status, pageState []byte, continuationStartTime time.Time) ([]store.Schedule, []byte, time.Time, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	switch status {
+	case store.Success, store.Failure, store.Partial, store.Expired, store.Miss, store.Scheduled:
+	default:
+		status = ""
+	}
+
+	rows, err := s.getScheduleRows(func(schedule store.Schedule) bool {
+		return schedule.AppId == appId &&
+			schedule.PartitionId < partitions &&
+			schedule.ScheduleGroup >= timeRange.StartTime.Unix() &&
+			schedule.ScheduleGroup < timeRange.EndTime.Unix()
+	}, false)
+	if err != nil {
+		return nil, nil, continuationStartTime, err
+	}
+
+	var schedules []store.Schedule
+	var nextPageState []byte
+	continuation := continuationStartTime
+
+	for offset := pageOffset(pageState); offset < len(rows); offset++ {
+		schedule, err := s.toSchedule(rows[offset], true)
+		if err != nil {
+			return nil, nil, continuation, err
+		}
+
+		continuation = time.Unix(schedule.ScheduleGroup, 0)
+		if status == "" || schedule.Status == status {
+			schedules = append(schedules, schedule)
+		}
+
+		if len(schedules) == int(size) {
+			if offset+1 < len(rows) {
+				nextPageState = offsetPageState(offset + 1)
+			}
+			break
+		}
+	}
+
+	return schedules, nextPageState, continuation, nil
+}
+
+// Fetch a page of the schedules of an app partition in a time bucket.
+// The page state holds the offset of the next schedule to fetch.
+func (s *ScheduleDaoImplInMemory) GetSchedulesForEntity(appId string, partitionId int, timeBucket time.Time, pageState []byte) db_wrapper.IterInterface {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	iter := &rowsIter{columns: entityScheduleColumns}
+
+	rows, err := s.getScheduleRows(func(schedule store.Schedule) bool {
+		return schedule.AppId == appId && schedule.PartitionId == partitionId && schedule.ScheduleGroup == timeBucket.Unix()
+	}, false)
+	if err != nil {
+		iter.err = err
+		return iter
+	}
+
+	for offset := pageOffset(pageState); offset < len(rows); offset++ {
+		if len(iter.rows) == s.PageSize {
+			iter.pageState = offsetPageState(offset)
+			break
+		}
+
+		values := make(map[string]interface{}, len(rows[offset].values))
+		for column, value := range rows[offset].values {
+			values[column] = value
+		}
+		iter.rows = append(iter.rows, values)
+	}
+
+	return iter
+}
+
+// Fetch the status of the schedules in bulk.
+// Schedules without status get their status from their schedule time.
+func (s *ScheduleDaoImplInMemory) OptimizedEnrichSchedule(schedules []store.Schedule) ([]store.Schedule, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var enrichedSchedules []store.Schedule
+	for _, schedule := range schedules {
+		if err := s.setStatus(&schedule); err != nil {
+			return nil, err
+		}
+		enrichedSchedules = append(enrichedSchedules, schedule)
+	}
+
+	return enrichedSchedules, nil
+}
+
+// Get the recurring schedules of an app, or of all apps if appId is empty.
+// Only schedules with the given status are returned if it is Scheduled or Deleted.
+func (s *ScheduleDaoImplInMemory) GetCronSchedulesByApp(appId string, status store.Status) ([]store.Schedule, []string) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	schedules, errs := s.getRecurringSchedules(func(schedule store.Schedule) bool {
+		if schedule.AppId != appId && appId != "" {
+			return false
+		}
+		return schedule.Status == status || (status != store.Scheduled && status != store.Deleted)
+	})
+
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return schedules, messages
+}
+
+func (s *ScheduleDaoImplInMemory) BulkAction(app store.App, partitionId int, scheduleTimeGroup time.Time, status []store.Status, actionType store.ActionType) error {
+	return bulkAction(s, app, partitionId, scheduleTimeGroup, status, actionType)
+}
+
+// Get the schedules of an app having all the supplied labels.
+// The schedules labelled with the first selector are scanned and filtered with the remaining ones,
+// so a page may contain less than size schedules.
+func (s *ScheduleDaoImplInMemory) GetSchedulesByLabel(appId string, selectors []string, size int64, pageState []byte) ([]store.Schedule, []byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var schedules []store.Schedule
+	var nextPageState []byte
+
+	if len(selectors) == 0 {
+		return schedules, nil, nil
+	}
+
+	rows := s.labels[string(joinKey(appId, selectors[0]))]
+	ids := make([]gocql.UUID, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+
+	offset := pageOffset(pageState)
+	for scanned := 0; offset < len(ids); offset, scanned = offset+1, scanned+1 {
+		if scanned == int(size) {
+			nextPageState = offsetPageState(offset)
+			break
+		}
+
+		row := rows[ids[offset]]
+		if expired(row.expiresAt, time.Now()) || !store.MatchLabels(row.labels, selectors[1:]) {
+			continue
+		}
+
+		schedule, err := s.getSchedule(ids[offset], true)
+		switch {
+		case err == gocql.ErrNotFound:
+			continue
+		case err != nil:
+			return nil, nil, err
+		}
+
+		schedule.Labels = row.labels
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, nextPageState, nil
+}
+
+// Get the labels of the labelled schedules among the supplied ones of an app, by schedule id.
+func (s *ScheduleDaoImplInMemory) GetLabels(appId string, scheduleIds []gocql.UUID) (map[gocql.UUID]map[string]string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	labelsById := make(map[gocql.UUID]map[string]string)
+
+	prefix := string(joinKey(appId, ""))
+	for key, rows := range s.labels {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		for _, id := range scheduleIds {
+			if row, ok := rows[id]; ok && !expired(row.expiresAt, time.Now()) {
+				labelsById[id] = row.labels
+			}
+		}
+	}
+
+	return labelsById, nil
+}
+
+// Delete the label lookup rows of the schedule.
+func (s *ScheduleDaoImplInMemory) DeleteLabels(schedule store.Schedule) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, label := range schedule.GetLabelSelectors() {
+		delete(s.labels[string(joinKey(schedule.AppId, label))], schedule.ScheduleId)
+	}
+	return nil
+}
+
+// UpdateUsage adds delta to the number of schedules of the given kind of an app, the usage never goes below zero.
+func (s *ScheduleDaoImplInMemory) UpdateUsage(appId string, kind store.UsageKind, delta int64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.addUsage(appId, kind, delta)
+	return nil
+}
+
+// addUsage adds delta to the usage of the given kind of an app without going below zero, the lock must be held
+func (s *ScheduleDaoImplInMemory) addUsage(appId string, kind store.UsageKind, delta int64) {
+	key := string(joinKey(appId, string(kind)))
+	if s.usage[key] += delta; s.usage[key] < 0 {
+		s.usage[key] = 0
+	}
+}
+
+// GetUsage gets the number of schedules of an app counted against its quotas
+func (s *ScheduleDaoImplInMemory) GetUsage(appId string) (store.Usage, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return store.Usage{
+		PendingSchedules:   s.usage[string(joinKey(appId, string(store.PendingUsage)))],
+		RecurringSchedules: s.usage[string(joinKey(appId, string(store.RecurringUsage)))],
+	}, nil
+}
@@ -0,0 +1,1110 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/lib/pq"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/db_wrapper"
+	p "github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/postgres"
+	"github.com/myntra/goscheduler/store"
+	"github.com/myntra/goscheduler/util"
+)
+
+const (
+	defaultPostgresPageSize      = 1000
+	defaultPostgresPurgeInterval = 300
+	purgeBatchSize               = 1000
+)
+
+const pgScheduleColumns string = "s.app_id," +
+	"s.partition_id," +
+	"s.schedule_time_group," +
+	"s.schedule_id," +
+	"s.schedule_time," +
+	"s.payload," +
+	"s.callback_type," +
+	"s.callback_details," +
+	"s.parent_schedule_id," +
+	"s.run_number," +
+	"s.chain," +
+	"s.deadline," +
+	"s.priority," +
+	"s.nominal_time"
+
+const pgStatusColumns string = "schedule_status," +
+	"error_msg," +
+	"reconciliation_history," +
+	"target_status," +
+	"next_schedule_id"
+
+const pgRecurringScheduleColumns string = "schedule_id," +
+	"payload," +
+	"callback_type," +
+	"callback_details," +
+	"app_id," +
+	"partition_id," +
+	"cron_expression," +
+	"status," +
+	"expires_after," +
+	"jitter," +
+	"calendar," +
+	"calendar_policy," +
+	"priority"
+
+// Schedules joined with their status, the status columns are empty if the schedule has not fired yet
+const pgEnrichedSchedules string = "SELECT " + pgScheduleColumns + "," +
+	"COALESCE(st.schedule_status, '') AS schedule_status," +
+	"COALESCE(st.error_msg, '') AS error_msg," +
+	"COALESCE(st.reconciliation_history, '') AS reconciliation_history," +
+	"COALESCE(st.target_status, '') AS target_status," +
+	"COALESCE(st.next_schedule_id, '') AS next_schedule_id " +
+	"FROM schedules s " +
+	"LEFT JOIN status st " +
+	"ON st.app_id = s.app_id " +
+	"AND st.partition_id = s.partition_id " +
+	"AND st.schedule_time_group = s.schedule_time_group " +
+	"AND st.schedule_id = s.schedule_id "
+
+const pgInsertSchedule string = "INSERT INTO schedules (" +
+	"app_id," +
+	"partition_id," +
+	"schedule_time_group," +
+	"schedule_id," +
+	"schedule_time," +
+	"payload," +
+	"callback_type," +
+	"callback_details," +
+	"parent_schedule_id," +
+	"run_number," +
+	"chain," +
+	"deadline," +
+	"priority," +
+	"nominal_time," +
+	"expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)"
+
+const pgInsertRecurringSchedule string = "INSERT INTO recurring_schedules (" +
+	"schedule_id," +
+	"app_id," +
+	"partition_id," +
+	"payload," +
+	"callback_type," +
+	"callback_details," +
+	"cron_expression," +
+	"status," +
+	"expires_after," +
+	"jitter," +
+	"calendar," +
+	"calendar_policy," +
+	"priority) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)"
+
+const pgDeleteSchedule string = "DELETE FROM schedules " +
+	"WHERE app_id = $1 " +
+	"AND partition_id = $2 " +
+	"AND schedule_time_group = $3 " +
+	"AND schedule_id = $4"
+
+const pgDeleteScheduleLabels string = "DELETE FROM schedules_by_label " +
+	"WHERE app_id = $1 " +
+	"AND schedule_id = $2"
+
+const pgUpsertStatus string = "INSERT INTO status (" +
+	"app_id," +
+	"partition_id," +
+	"schedule_time_group," +
+	"schedule_id," +
+	"schedule_status," +
+	"error_msg," +
+	"reconciliation_history," +
+	"target_status," +
+	"next_schedule_id," +
+	"expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) " +
+	"ON CONFLICT (app_id, partition_id, schedule_time_group, schedule_id) DO UPDATE SET " +
+	"schedule_status = EXCLUDED.schedule_status," +
+	"error_msg = EXCLUDED.error_msg," +
+	"reconciliation_history = EXCLUDED.reconciliation_history," +
+	"target_status = EXCLUDED.target_status," +
+	"next_schedule_id = EXCLUDED.next_schedule_id," +
+	"expires_at = EXCLUDED.expires_at"
+
+// Claims the dedup key for the schedule, or locks the row of the schedule holding it until the transaction ends
+const pgClaimDedupKey string = "INSERT INTO schedules_by_dedup_key (" +
+	"app_id," +
+	"dedup_key," +
+	"schedule_id," +
+	"expires_at) VALUES ($1, $2, $3, $4) " +
+	"ON CONFLICT (app_id, dedup_key) DO UPDATE SET dedup_key = EXCLUDED.dedup_key " +
+	"RETURNING schedule_id, expires_at > now()"
+
+const pgSwapDedupKey string = "UPDATE schedules_by_dedup_key " +
+	"SET schedule_id = $1, expires_at = $2 " +
+	"WHERE app_id = $3 " +
+	"AND dedup_key = $4"
+
+const pgUpsertLabel string = "INSERT INTO schedules_by_label (" +
+	"app_id," +
+	"label," +
+	"schedule_id," +
+	"labels," +
+	"expires_at) VALUES ($1, $2, $3, $4, $5) " +
+	"ON CONFLICT (app_id, label, schedule_id) DO UPDATE SET labels = EXCLUDED.labels, expires_at = EXCLUDED.expires_at"
+
+const pgUpdateUsage string = "INSERT INTO app_usage (app_id, kind, total) VALUES ($1, $2, GREATEST($3::bigint, 0)) " +
+	"ON CONFLICT (app_id, kind) DO UPDATE SET total = GREATEST(app_usage.total + $3::bigint, 0)"
+
+// Deletes a batch of expired rows, skipping the rows locked by writers so that purging never blocks them
+const pgPurgeExpired string = "DELETE FROM %s WHERE (%s) IN (" +
+	"SELECT %s FROM %s WHERE expires_at < now() LIMIT %d FOR UPDATE SKIP LOCKED)"
+
+// Tables whose rows expire, along with the columns identifying a row
+var expiringTables = []struct {
+	name string
+	key  string
+}{
+	{"schedules", "app_id, partition_id, schedule_time_group, schedule_id"},
+	{"status", "app_id, partition_id, schedule_time_group, schedule_id"},
+	{"schedules_by_dedup_key", "app_id, dedup_key"},
+	{"schedules_by_label", "app_id, label, schedule_id"},
+}
+
+// ScheduleDaoImplPostgres keeps the schedules in PostgreSQL.
+// One time schedules and their status are partitioned by the day of the schedule time group,
+// rows are deleted once they expire instead of relying on a TTL.
+type ScheduleDaoImplPostgres struct {
+	DB         *sql.DB
+	Conf       *conf.Configuration
+	Monitor    p.Monitor
+	partitions *partitionManager
+}
+
+func GetScheduleDaoImplPostgres(conf *conf.Configuration, monitor p.Monitor) *ScheduleDaoImplPostgres {
+	db, err := postgres.GetDB(conf.Storage.Postgres)
+	if err != nil {
+		err = errors.New(fmt.Sprintf("Postgres initialisation failed with error %s", err.Error()))
+		panic(err)
+	}
+
+	dao := NewScheduleDaoImplPostgres(db, conf, monitor)
+	go dao.purgeExpired()
+	return dao
+}
+
+// NewScheduleDaoImplPostgres creates the DAO over an open database, without purging the expired rows periodically.
+func NewScheduleDaoImplPostgres(db *sql.DB, conf *conf.Configuration, monitor p.Monitor) *ScheduleDaoImplPostgres {
+	return &ScheduleDaoImplPostgres{
+		DB:         db,
+		Conf:       conf,
+		Monitor:    monitor,
+		partitions: newPartitionManager(db),
+	}
+}
+
+// Profile execution of do
+func (s *ScheduleDaoImplPostgres) profile(do func() (store.Schedule, error), metric, appId string) (store.Schedule, error) {
+	startTime := time.Now()
+
+	result, err := do()
+
+	if s.Monitor != nil {
+		duration := time.Since(startTime)
+		s.Monitor.RecordTiming(metric, map[string]string{"appId": appId}, duration)
+	}
+
+	return result, err
+}
+
+// Number of schedules fetched per page
+func (s *ScheduleDaoImplPostgres) pageSize() int {
+	if s.Conf.Storage.Postgres.PageSize <= 0 {
+		return defaultPostgresPageSize
+	}
+	return s.Conf.Storage.Postgres.PageSize
+}
+
+// Get the values of the columns of the schedules table for the schedule, in the order of pgInsertSchedule
+func (s *ScheduleDaoImplPostgres) scheduleArgs(schedule store.Schedule, app store.App) []interface{} {
+	return []interface{}{
+		schedule.AppId,
+		schedule.PartitionId,
+		time.Unix(schedule.ScheduleGroup, 0).UTC(),
+		schedule.ScheduleId.String(),
+		time.Unix(schedule.ScheduleTime, 0).UTC(),
+		schedule.Payload,
+		schedule.GetCallBackType(),
+		schedule.GetCallbackDetails(),
+		uuidValue(schedule.ParentScheduleId),
+		schedule.RunNumber,
+		schedule.GetChain(),
+		timeValue(schedule.Deadline),
+		string(schedule.Priority),
+		timeValue(schedule.NominalTime),
+		expiresAt(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)),
+	}
+}
+
+// Build a schedule from a row of pgEnrichedSchedules
+func (s *ScheduleDaoImplPostgres) enrichedSchedule(m map[string]interface{}) (store.Schedule, error) {
+	var schedule store.Schedule
+	if err := schedule.CreateScheduleFromCassandraMap(m); err != nil {
+		return schedule, err
+	}
+
+	if m["schedule_status"] == "" {
+		schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
+		return schedule, nil
+	}
+
+	return schedule, schedule.SetStatus(m)
+}
+
+// Persist a cron schedule with status as Scheduled.
+func (s *ScheduleDaoImplPostgres) createRecurringSchedule(schedule store.Schedule) (store.Schedule, error) {
+	_, err := s.DB.Exec(
+		pgInsertRecurringSchedule,
+		schedule.ScheduleId.String(),
+		schedule.AppId,
+		schedule.PartitionId,
+		schedule.Payload,
+		schedule.GetCallBackType(),
+		schedule.GetCallbackDetails(),
+		schedule.CronExpression,
+		string(store.Scheduled),
+		schedule.ExpiresAfter,
+		schedule.Jitter,
+		schedule.Calendar,
+		string(schedule.CalendarPolicy),
+		string(schedule.Priority))
+
+	schedule.Status = store.Scheduled
+	return schedule, err
+}
+
+// Persist a one time schedule in the partition of its day.
+func (s *ScheduleDaoImplPostgres) createOneTimeSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	err := s.partitions.exec(time.Unix(schedule.ScheduleGroup, 0), pgInsertSchedule, s.scheduleArgs(schedule, app)...)
+	return schedule, err
+}
+
+// Persist a one time schedule having a dedup key.
+// The row of the dedup key is locked for the duration of the transaction, so concurrent creations are serialized.
+// If the key is held by a pending schedule, the app's dedup policy decides whether the pending schedule is replaced,
+// kept or the creation is rejected. Expired keys and keys held by schedules which are no longer pending are taken over.
+func (s *ScheduleDaoImplPostgres) createDedupSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	if err := s.partitions.ensure(time.Unix(schedule.ScheduleGroup, 0)); err != nil {
+		return schedule, err
+	}
+
+	expiry := expiresAt(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod))
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return schedule, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var holder string
+	var live bool
+	if err = tx.QueryRow(pgClaimDedupKey, schedule.AppId, schedule.DedupKey, schedule.ScheduleId.String(), expiry).Scan(&holder, &live); err != nil {
+		return schedule, err
+	}
+
+	var pending store.Schedule
+	var found bool
+	if holder != schedule.ScheduleId.String() {
+		if live {
+			holderId, err := gocql.ParseUUID(holder)
+			if err != nil {
+				return schedule, err
+			}
+			if pending, found, err = s.getPendingSchedule(holderId); err != nil {
+				return schedule, err
+			}
+		}
+
+		if found {
+			switch app.Configuration.GetDedupPolicy() {
+			case store.DedupReject:
+				return schedule, ErrDuplicateSchedule
+			case store.DedupKeepFirst:
+				pending.Deduplicated = true
+				return pending, nil
+			}
+		}
+
+		if _, err = tx.Exec(pgSwapDedupKey, schedule.ScheduleId.String(), expiry, schedule.AppId, schedule.DedupKey); err != nil {
+			return schedule, err
+		}
+
+		if found {
+			if _, err = tx.Exec(pgDeleteSchedule, pending.AppId, pending.PartitionId, time.Unix(pending.ScheduleGroup, 0).UTC(), pending.ScheduleId.String()); err != nil {
+				return schedule, err
+			}
+			if _, err = tx.Exec(pgDeleteScheduleLabels, pending.AppId, pending.ScheduleId.String()); err != nil {
+				return schedule, err
+			}
+		}
+	}
+
+	if _, err = tx.Exec(pgInsertSchedule, s.scheduleArgs(schedule, app)...); err != nil {
+		return schedule, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return schedule, err
+	}
+
+	if found {
+		if err = s.UpdateUsage(pending.AppId, store.PendingUsage, -1); err != nil {
+			glog.Errorf("Releasing the usage of replaced schedule %s failed with error %s", pending.ScheduleId.String(), err.Error())
+		}
+		schedule.Replaced = &pending
+	}
+
+	return schedule, nil
+}
+
+// Get the schedule holding a dedup key if it has not fired yet.
+// Returns false if the schedule does not exist anymore or is no longer pending.
+func (s *ScheduleDaoImplPostgres) getPendingSchedule(uuid gocql.UUID) (store.Schedule, bool, error) {
+	if util.IsZeroUUID(uuid) {
+		return store.Schedule{}, false, nil
+	}
+
+	schedule, err := s.getEnrichedSchedule(uuid)
+	switch {
+	case err == gocql.ErrNotFound:
+		return store.Schedule{}, false, nil
+	case err != nil:
+		return store.Schedule{}, false, err
+	}
+
+	return schedule, schedule.Status == store.Scheduled, nil
+}
+
+// Persist a row per label of the schedule in the label lookup table.
+// Rows of one time schedules expire along with the schedule, rows of recurring schedules do not expire.
+func (s *ScheduleDaoImplPostgres) createLabels(schedule store.Schedule, app store.App) error {
+	if len(schedule.Labels) == 0 {
+		return nil
+	}
+
+	labels, err := json.Marshal(schedule.Labels)
+	if err != nil {
+		return err
+	}
+
+	var expiry interface{}
+	if !schedule.IsRecurring() {
+		expiry = expiresAt(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod))
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, label := range schedule.GetLabelSelectors() {
+		if _, err = tx.Exec(pgUpsertLabel, schedule.AppId, label, schedule.ScheduleId.String(), string(labels), expiry); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Persist the schedule details.
+// One time schedules having a dedup key are deduplicated against the pending schedules of the app.
+// The labels of the schedule are written to the label lookup table once the schedule is created.
+func (s *ScheduleDaoImplPostgres) CreateSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	var created store.Schedule
+	var err error
+
+	if schedule.IsRecurring() {
+		created, err = s.profile(func() (store.Schedule, error) {
+			return s.createRecurringSchedule(schedule)
+		}, constants.CreateRecurringSchedule, schedule.AppId)
+	} else {
+		created, err = s.profile(func() (store.Schedule, error) {
+			if schedule.DedupKey != "" {
+				return s.createDedupSchedule(schedule, app)
+			}
+			return s.createOneTimeSchedule(schedule, app)
+		}, constants.CreateOneTimeSchedule, schedule.AppId)
+	}
+
+	if err != nil || created.Deduplicated {
+		return created, err
+	}
+
+	return created, s.createLabels(schedule, app)
+}
+
+// Get all recurring schedules with partition id
+func (s *ScheduleDaoImplPostgres) GetRecurringScheduleByPartition(partitionId int) ([]store.Schedule, []error) {
+	query := "SELECT " + pgRecurringScheduleColumns + " FROM recurring_schedules WHERE partition_id = $1"
+
+	var schedules []store.Schedule
+	var errs []error
+
+	maps, err := queryMaps(s.DB, query, partitionId)
+	if err != nil {
+		return schedules, []error{err}
+	}
+
+	for _, m := range maps {
+		var schedule store.Schedule
+		if err := schedule.CreateScheduleFromCassandraMap(m); err != nil {
+			errs = append(errs, err)
+		} else {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	return schedules, errs
+}
+
+// Find a recurring schedule with the supplied id, gocql.ErrNotFound is returned if there is none
+func (s *ScheduleDaoImplPostgres) getRecurringSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	query := "SELECT " + pgRecurringScheduleColumns + " FROM recurring_schedules WHERE schedule_id = $1"
+
+	m, err := queryMap(s.DB, query, uuid.String())
+	if err != nil {
+		return store.Schedule{}, err
+	}
+
+	var schedule store.Schedule
+	err = schedule.CreateScheduleFromCassandraMap(m)
+	return schedule, err
+}
+
+// Find a one time schedule with the supplied id, gocql.ErrNotFound is returned if there is none
+func (s *ScheduleDaoImplPostgres) getOneTimeSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	query := "SELECT " + pgScheduleColumns + " FROM schedules s WHERE s.schedule_id = $1 LIMIT 1"
+
+	m, err := queryMap(s.DB, query, uuid.String())
+	if err != nil {
+		return store.Schedule{}, err
+	}
+
+	var schedule store.Schedule
+	err = schedule.CreateScheduleFromCassandraMap(m)
+	return schedule, err
+}
+
+// Get a single one time schedule with status data enriched, gocql.ErrNotFound is returned if there is none
+func (s *ScheduleDaoImplPostgres) getEnrichedSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	m, err := queryMap(s.DB, pgEnrichedSchedules+"WHERE s.schedule_id = $1 LIMIT 1", uuid.String())
+	if err != nil {
+		return store.Schedule{}, err
+	}
+
+	return s.enrichedSchedule(m)
+}
+
+// Get a single schedule with the supplied id.
+// Attempts to find a one time schedule and falls back to recurring schedules if not found.
+func (s *ScheduleDaoImplPostgres) GetSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	schedule, err := s.getOneTimeSchedule(uuid)
+	if err == gocql.ErrNotFound {
+		return s.getRecurringSchedule(uuid)
+	}
+
+	return schedule, err
+}
+
+// Get a single schedule enriched with status data
+func (s *ScheduleDaoImplPostgres) GetEnrichedSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	schedule, err := s.getEnrichedSchedule(uuid)
+	if err == gocql.ErrNotFound {
+		return s.getRecurringSchedule(uuid)
+	}
+
+	return schedule, err
+}
+
+// Enrich a non-recurring schedule with status data
+func (s *ScheduleDaoImplPostgres) EnrichSchedule(schedule *store.Schedule) error {
+	query := "SELECT " + pgStatusColumns + " FROM status " +
+		"WHERE app_id = $1 " +
+		"AND partition_id = $2 " +
+		"AND schedule_time_group = $3 " +
+		"AND schedule_id = $4"
+
+	m, err := queryMap(s.DB, query, schedule.AppId, schedule.PartitionId, time.Unix(schedule.ScheduleGroup, 0).UTC(), schedule.ScheduleId.String())
+	switch {
+	case err == gocql.ErrNotFound:
+		schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
+		return nil
+	case err != nil:
+		return err
+	}
+
+	return schedule.SetStatus(m)
+}
+
+// Soft delete a recurring schedule by marking its status as Deleted.
+// All one time future runs generated from this schedule are deleted in the same transaction.
+func (s *ScheduleDaoImplPostgres) deleteRecurringSchedule(schedule store.Schedule) (store.Schedule, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return schedule, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec("UPDATE recurring_schedules SET status = $1 WHERE schedule_id = $2", string(store.Deleted), schedule.ScheduleId.String()); err != nil {
+		return schedule, err
+	}
+
+	if _, err = tx.Exec("DELETE FROM schedules WHERE parent_schedule_id = $1 AND schedule_time_group > $2", schedule.ScheduleId.String(), time.Now().UTC()); err != nil {
+		return schedule, err
+	}
+
+	if _, err = tx.Exec(pgDeleteScheduleLabels, schedule.AppId, schedule.ScheduleId.String()); err != nil {
+		return schedule, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return schedule, err
+	}
+
+	schedule.Status = store.Deleted
+	return schedule, nil
+}
+
+// Delete a one time schedule along with its label rows
+func (s *ScheduleDaoImplPostgres) deleteOneTimeSchedule(schedule store.Schedule) (store.Schedule, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return schedule, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(
+		pgDeleteSchedule,
+		schedule.AppId,
+		schedule.PartitionId,
+		time.Unix(schedule.ScheduleGroup, 0).UTC(),
+		schedule.ScheduleId.String())
+	if err != nil {
+		return schedule, err
+	}
+
+	if _, err = tx.Exec(pgDeleteScheduleLabels, schedule.AppId, schedule.ScheduleId.String()); err != nil {
+		return schedule, err
+	}
+
+	return schedule, tx.Commit()
+}
+
+// Delete schedule with the given id
+// For one time schedules, the rows are removed from the schedule table
+// where as for the recurring schedules, the status is marked accordingly.
+func (s *ScheduleDaoImplPostgres) DeleteSchedule(uuid gocql.UUID) (store.Schedule, error) {
+	switch schedule, err := s.GetSchedule(uuid); {
+	case err != nil:
+		return store.Schedule{}, err
+	case schedule.IsRecurring():
+		return s.deleteRecurringSchedule(schedule)
+	default:
+		return s.deleteOneTimeSchedule(schedule)
+	}
+}
+
+// Get size number of runs of a recurring schedule, latest first, or all of them if size is negative.
+// Past runs are the ones whose schedule time group is before now, future runs the ones after now.
+// Future runs are the farthest size runs in the future, sorted from the immediate run to the farthest one,
+// like the Cassandra implementation does.
+func (s *ScheduleDaoImplPostgres) GetScheduleRuns(uuid gocql.UUID, size int64, when string, pageState []byte) ([]store.Schedule, []byte, error) {
+	key, resume, err := decodePageKey(pageState)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := pgEnrichedSchedules + "WHERE s.parent_schedule_id = $1"
+	args := []interface{}{uuid.String()}
+
+	switch when {
+	case "past":
+		args = append(args, time.Now().UTC())
+		query += fmt.Sprintf(" AND s.schedule_time_group < $%d", len(args))
+	case "future":
+		args = append(args, time.Now().UTC())
+		query += fmt.Sprintf(" AND s.schedule_time_group > $%d", len(args))
+	}
+
+	if resume {
+		args = append(args, key.Group, key.Id)
+		query += fmt.Sprintf(" AND (s.schedule_time_group, s.schedule_id) < ($%d, $%d::uuid)", len(args)-1, len(args))
+	}
+
+	query += " ORDER BY s.schedule_time_group DESC, s.schedule_id DESC"
+	if size >= 0 {
+		query += fmt.Sprintf(" LIMIT %d", size)
+	}
+
+	maps, err := queryMaps(s.DB, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var schedules []store.Schedule
+	for _, m := range maps {
+		schedule, err := s.enrichedSchedule(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	var nextPageState []byte
+	if size > 0 && len(maps) == int(size) {
+		last := maps[len(maps)-1]
+		nextPageState = encodePageKey(last["schedule_time_group"].(time.Time), last["schedule_id"].(gocql.UUID), true)
+	}
+
+	if when == "future" {
+		for i, j := 0, len(schedules)-1; i < j; i, j = i+1, j-1 {
+			schedules[i], schedules[j] = schedules[j], schedules[i]
+		}
+	}
+
+	return schedules, nextPageState, nil
+}
+
+// Create a one time schedule for a recurring schedule.
+func (s *ScheduleDaoImplPostgres) CreateRun(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	err := s.partitions.exec(time.Unix(schedule.ScheduleGroup, 0), pgInsertSchedule, s.scheduleArgs(schedule, app)...)
+	return schedule, err
+}
+
+// Upsert the status of the schedules in a single transaction.
+// The status rows expire along with the schedules.
+func (s *ScheduleDaoImplPostgres) UpdateStatus(schedules []store.Schedule, app store.App) error {
+	for _, schedule := range schedules {
+		glog.Infof("update status for schedule: %s", schedule.ScheduleId.String())
+
+		if err := s.partitions.ensure(time.Unix(schedule.ScheduleGroup, 0)); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, schedule := range schedules {
+		reconciliationHistory, _ := json.Marshal(schedule.ReconciliationHistory)
+
+		var targetStatus []byte
+		if len(schedule.TargetStatuses) > 0 {
+			targetStatus, _ = json.Marshal(schedule.TargetStatuses)
+		}
+
+		if _, err = tx.Exec(
+			pgUpsertStatus,
+			schedule.AppId,
+			schedule.PartitionId,
+			time.Unix(schedule.ScheduleGroup, 0).UTC(),
+			schedule.ScheduleId.String(),
+			string(schedule.Status),
+			schedule.ErrorMessage,
+			string(reconciliationHistory),
+			string(targetStatus),
+			schedule.NextScheduleId,
+			expiresAt(schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod))); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Get size number of schedules of an app in the time range [startTime, endTime), filtered by status.
+// Schedules are sorted by schedule time group and fetched page by page until size schedules pass the filter.
+// The page state holds the last schedule examined, the continuation start time is its schedule time group.
+func (s *ScheduleDaoImplPostgres) GetPaginatedSchedules(appId string, partitions int, timeRange Range, size int64, status store.Status, pageState []byte, continuationStartTime time.Time) ([]store.Schedule, []byte, time.Time, error) {
+	switch status {
+	case store.Success, store.Failure, store.Partial, store.Expired, store.Miss, store.Scheduled:
+	default:
+		status = ""
+	}
+
+	key, resume, err := decodePageKey(pageState)
+	if err != nil {
+		return nil, nil, continuationStartTime, err
+	}
+
+	startTime := timeRange.StartTime
+	if continuationStartTime.Unix() != 0 && continuationStartTime.After(startTime) {
+		startTime = continuationStartTime
+	}
+
+	var schedules []store.Schedule
+	continuation := startTime
+	for {
+		query := pgEnrichedSchedules +
+			"WHERE s.app_id = $1 " +
+			"AND s.partition_id < $2 " +
+			"AND s.schedule_time_group >= $3 " +
+			"AND s.schedule_time_group < $4"
+		args := []interface{}{appId, partitions, startTime.UTC(), timeRange.EndTime.UTC()}
+
+		if resume {
+			args = append(args, key.Group, key.Id)
+			query += " AND (s.schedule_time_group, s.schedule_id) > ($5, $6::uuid)"
+		}
+		query += fmt.Sprintf(" ORDER BY s.schedule_time_group, s.schedule_id LIMIT %d", s.pageSize())
+
+		maps, err := queryMaps(s.DB, query, args...)
+		if err != nil {
+			return nil, nil, continuation, err
+		}
+
+		for _, m := range maps {
+			schedule, err := s.enrichedSchedule(m)
+			if err != nil {
+				return nil, nil, continuation, err
+			}
+
+			key = pageKey{Group: m["schedule_time_group"].(time.Time), Id: schedule.ScheduleId.String()}
+			resume = true
+			continuation = key.Group
+
+			if status == "" || schedule.Status == status {
+				schedules = append(schedules, schedule)
+			}
+
+			if len(schedules) == int(size) {
+				return schedules, encodePageKey(key.Group, schedule.ScheduleId, true), continuation, nil
+			}
+		}
+
+		if len(maps) < s.pageSize() {
+			return schedules, nil, continuation, nil
+		}
+	}
+}
+
+// Fetch a page of the schedules of an app partition in a time bucket.
+// Pages are sorted by schedule id and the page state holds the id of the last schedule of the page.
+// The rows are read without locking them: a partition is polled by the single node owning it in the ring, and skipping
+// the rows locked by a writer would drop those schedules from the bucket, which is read only once.
+func (s *ScheduleDaoImplPostgres) GetSchedulesForEntity(appId string, partitionId int, timeBucket time.Time, pageState []byte) db_wrapper.IterInterface {
+	key, resume, err := decodePageKey(pageState)
+	if err != nil {
+		return &rowsIter{err: err}
+	}
+
+	query := "SELECT " + pgScheduleColumns + " FROM schedules s " +
+		"WHERE s.app_id = $1 " +
+		"AND s.partition_id = $2 " +
+		"AND s.schedule_time_group = $3"
+	args := []interface{}{appId, partitionId, timeBucket.UTC()}
+
+	if resume {
+		args = append(args, key.Id)
+		query += " AND s.schedule_id > $4::uuid"
+	}
+	query += fmt.Sprintf(" ORDER BY s.schedule_id LIMIT %d", s.pageSize())
+
+	columns, maps, err := queryRows(s.DB, query, args...)
+	if err != nil {
+		return &rowsIter{err: err}
+	}
+
+	iter := &rowsIter{rows: maps, columns: columns}
+	if len(maps) == s.pageSize() {
+		iter.pageState = encodePageKey(time.Time{}, maps[len(maps)-1]["schedule_id"].(gocql.UUID), true)
+	}
+	return iter
+}
+
+// Fetch the status of the schedules in bulk.
+// Schedules not present in the status table get their status from their schedule time.
+func (s *ScheduleDaoImplPostgres) OptimizedEnrichSchedule(schedules []store.Schedule) ([]store.Schedule, error) {
+	var enrichedSchedules []store.Schedule
+	idToSchedule := make(map[gocql.UUID]store.Schedule)
+	found := make(map[gocql.UUID]bool)
+
+	if len(schedules) == 0 {
+		return enrichedSchedules, nil
+	}
+
+	var ids []string
+	for _, schedule := range schedules {
+		idToSchedule[schedule.ScheduleId] = schedule
+		ids = append(ids, schedule.ScheduleId.String())
+	}
+
+	query := "SELECT schedule_id," + pgStatusColumns + " FROM status " +
+		"WHERE app_id = $1 " +
+		"AND partition_id = $2 " +
+		"AND schedule_id = ANY($3::uuid[])"
+
+	maps, err := queryMaps(s.DB, query, schedules[0].AppId, schedules[0].PartitionId, pq.Array(ids))
+	if err != nil {
+		glog.Errorf("Error: %s while calling status query for schedules: %+v", err.Error(), schedules)
+		return enrichedSchedules, err
+	}
+
+	for _, m := range maps {
+		uuid := m["schedule_id"].(gocql.UUID)
+		schedule, ok := idToSchedule[uuid]
+		if !ok || found[uuid] {
+			continue
+		}
+		found[uuid] = true
+
+		if err := schedule.SetStatus(m); err != nil {
+			return enrichedSchedules, err
+		}
+		enrichedSchedules = append(enrichedSchedules, schedule)
+	}
+
+	for uuid, schedule := range idToSchedule {
+		if !found[uuid] {
+			schedule.SetUnknownStatus(s.Conf.AggregateSchedulesConfig.FlushPeriod)
+			enrichedSchedules = append(enrichedSchedules, schedule)
+		}
+	}
+
+	return enrichedSchedules, nil
+}
+
+// Get the recurring schedules of an app, or of all apps if appId is empty.
+// Only schedules with the given status are returned if it is Scheduled or Deleted.
+func (s *ScheduleDaoImplPostgres) GetCronSchedulesByApp(appId string, status store.Status) ([]store.Schedule, []string) {
+	query := "SELECT " + pgRecurringScheduleColumns + " FROM recurring_schedules WHERE ($1::text = '' OR app_id = $1)"
+
+	var schedules []store.Schedule
+	var errs []string
+
+	maps, err := queryMaps(s.DB, query, appId)
+	if err != nil {
+		return schedules, []string{err.Error()}
+	}
+
+	for _, m := range maps {
+		var schedule store.Schedule
+		if err := schedule.CreateScheduleFromCassandraMap(m); err != nil {
+			errs = append(errs, err.Error())
+		} else if schedule.Status == status || (status != store.Scheduled && status != store.Deleted) {
+			schedules = append(schedules, schedule)
+		}
+	}
+
+	return schedules, errs
+}
+
+func (s *ScheduleDaoImplPostgres) BulkAction(app store.App, partitionId int, scheduleTimeGroup time.Time, status []store.Status, actionType store.ActionType) error {
+	return bulkAction(s, app, partitionId, scheduleTimeGroup, status, actionType)
+}
+
+// Get the schedules of an app having all the supplied labels.
+// The lookup table is queried with the first selector and the rows are filtered with the remaining ones,
+// so a page may contain less than size schedules.
+func (s *ScheduleDaoImplPostgres) GetSchedulesByLabel(appId string, selectors []string, size int64, pageState []byte) ([]store.Schedule, []byte, error) {
+	var schedules []store.Schedule
+
+	if len(selectors) == 0 {
+		return schedules, nil, nil
+	}
+
+	key, resume, err := decodePageKey(pageState)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := "SELECT schedule_id, labels FROM schedules_by_label " +
+		"WHERE app_id = $1 " +
+		"AND label = $2"
+	args := []interface{}{appId, selectors[0]}
+
+	if resume {
+		args = append(args, key.Id)
+		query += " AND schedule_id > $3::uuid"
+	}
+	query += fmt.Sprintf(" ORDER BY schedule_id LIMIT %d", size)
+
+	maps, err := queryMaps(s.DB, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, m := range maps {
+		scheduleId := m["schedule_id"].(gocql.UUID)
+
+		var labels map[string]string
+		if err := json.Unmarshal([]byte(m["labels"].(string)), &labels); err != nil {
+			glog.Errorf("Error unmarshalling labels of schedule %s: %v", scheduleId, err)
+			continue
+		}
+
+		if !store.MatchLabels(labels, selectors[1:]) {
+			continue
+		}
+
+		schedule, err := s.GetEnrichedSchedule(scheduleId)
+		switch {
+		case err == gocql.ErrNotFound:
+			continue
+		case err != nil:
+			return nil, nil, err
+		}
+
+		schedule.Labels = labels
+		schedules = append(schedules, schedule)
+	}
+
+	var nextPageState []byte
+	if len(maps) > 0 && len(maps) == int(size) {
+		nextPageState = encodePageKey(time.Time{}, maps[len(maps)-1]["schedule_id"].(gocql.UUID), true)
+	}
+
+	return schedules, nextPageState, nil
+}
+
+// Get the labels of the labelled schedules among the supplied ones of an app, by schedule id.
+func (s *ScheduleDaoImplPostgres) GetLabels(appId string, scheduleIds []gocql.UUID) (map[gocql.UUID]map[string]string, error) {
+	ids := make([]string, 0, len(scheduleIds))
+	for _, scheduleId := range scheduleIds {
+		ids = append(ids, scheduleId.String())
+	}
+
+	maps, err := queryMaps(s.DB, "SELECT DISTINCT schedule_id, labels FROM schedules_by_label WHERE app_id = $1 AND schedule_id = ANY($2::uuid[])", appId, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	labelsById := make(map[gocql.UUID]map[string]string)
+	for _, m := range maps {
+		scheduleId := m["schedule_id"].(gocql.UUID)
+
+		var labels map[string]string
+		if err := json.Unmarshal([]byte(m["labels"].(string)), &labels); err != nil {
+			glog.Errorf("Error unmarshalling labels of schedule %s: %v", scheduleId, err)
+			continue
+		}
+		labelsById[scheduleId] = labels
+	}
+
+	return labelsById, nil
+}
+
+// Delete the label lookup rows of the schedule.
+func (s *ScheduleDaoImplPostgres) DeleteLabels(schedule store.Schedule) error {
+	if len(schedule.Labels) == 0 {
+		return nil
+	}
+
+	_, err := s.DB.Exec("DELETE FROM schedules_by_label WHERE app_id = $1 AND schedule_id = $2 AND label = ANY($3)",
+		schedule.AppId,
+		schedule.ScheduleId.String(),
+		pq.Array(schedule.GetLabelSelectors()))
+	return err
+}
+
+// UpdateUsage adds delta to the number of schedules of the given kind of an app, the usage never goes below zero.
+func (s *ScheduleDaoImplPostgres) UpdateUsage(appId string, kind store.UsageKind, delta int64) error {
+	_, err := s.DB.Exec(pgUpdateUsage, appId, string(kind), delta)
+	return err
+}
+
+// GetUsage gets the number of schedules of an app counted against its quotas
+func (s *ScheduleDaoImplPostgres) GetUsage(appId string) (store.Usage, error) {
+	var kind string
+	var total int64
+	var usage store.Usage
+
+	rows, err := s.DB.Query("SELECT kind, total FROM app_usage WHERE app_id = $1", appId)
+	if err != nil {
+		return usage, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err = rows.Scan(&kind, &total); err != nil {
+			return usage, err
+		}
+		switch store.UsageKind(kind) {
+		case store.PendingUsage:
+			usage.PendingSchedules = total
+		case store.RecurringUsage:
+			usage.RecurringSchedules = total
+		}
+	}
+
+	return usage, rows.Err()
+}
+
+// Purge the expired rows periodically, in place of the TTL Cassandra expires rows with
+func (s *ScheduleDaoImplPostgres) purgeExpired() {
+	interval := s.Conf.Storage.Postgres.PurgeInterval
+	if interval <= 0 {
+		interval = defaultPostgresPurgeInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := s.purge(); err != nil {
+			glog.Errorf("Purging expired rows failed with error %s", err.Error())
+		}
+	}
+}
+
+// Delete the expired rows in batches and drop the partitions of past days left empty.
+// Rows locked by writers are skipped and picked up by a later purge, so several nodes can purge at once.
+func (s *ScheduleDaoImplPostgres) purge() error {
+	for _, table := range expiringTables {
+		query := fmt.Sprintf(pgPurgeExpired, table.name, table.key, table.key, table.name, purgeBatchSize)
+		for {
+			result, err := s.DB.Exec(query)
+			if err != nil {
+				return err
+			}
+
+			deleted, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			glog.V(constants.INFO).Infof("Purged %d expired rows from %s", deleted, table.name)
+			if deleted < purgeBatchSize {
+				break
+			}
+		}
+	}
+
+	return s.partitions.dropEmpty(time.Now())
+}
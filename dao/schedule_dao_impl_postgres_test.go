@@ -0,0 +1,339 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package dao
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gocql/gocql"
+	"github.com/lib/pq"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+)
+
+const pgCallbackDetails = `{"url":"http://example.com/callback","method":"POST"}`
+
+func setupPostgresMocks(t *testing.T) (*ScheduleDaoImplPostgres, sqlmock.Sqlmock) {
+	s.Registry[constants.DefaultCallback] = func() s.Callback { return &s.HttpCallback{} }
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	dao := NewScheduleDaoImplPostgres(db, &conf.Configuration{
+		Storage: conf.StorageConfig{
+			Backend:  conf.PostgresBackend,
+			Postgres: conf.PostgresConfig{PageSize: 2},
+		},
+		AggregateSchedulesConfig: conf.AggregateSchedulesConfig{
+			FlushPeriod: 60,
+		},
+	}, nil)
+
+	return dao, mock
+}
+
+func expectPartitions(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schedules_p").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS status_p").WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func pgScheduleRows(scheduleTime time.Time, ids ...gocql.UUID) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"app_id", "partition_id", "schedule_time_group", "schedule_id", "schedule_time", "payload",
+		"callback_type", "callback_details", "parent_schedule_id", "run_number", "chain", "deadline", "priority",
+		"schedule_status", "error_msg", "reconciliation_history", "target_status", "next_schedule_id"})
+	for _, id := range ids {
+		rows.AddRow("Test", 0, scheduleTime, id.String(), scheduleTime, "payload", "http", pgCallbackDetails,
+			nil, 0, "", nil, "", "", "", "", "", "")
+	}
+	return rows
+}
+
+func TestScheduleDaoImplPostgres_CreateSchedule(t *testing.T) {
+	dao, mock := setupPostgresMocks(t)
+
+	scheduleTime := time.Now().Add(time.Hour).Truncate(time.Minute)
+	schedule := s.Schedule{
+		ScheduleId:    gocql.TimeUUID(),
+		AppId:         "Test",
+		Payload:       "payload",
+		ScheduleTime:  scheduleTime.Unix(),
+		ScheduleGroup: scheduleTime.Unix(),
+		Callback:      &s.HttpCallback{Type: "http", Details: s.Details{Url: "http://example.com/callback", Method: "POST"}},
+	}
+
+	// The partitions are created on the first write to a day, a dropped partition is created again,
+	// losing the race to create it to another node is not an error
+	expectPartitions(mock)
+	mock.ExpectExec(regexp.QuoteMeta(pgInsertSchedule)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(pgInsertSchedule)).WillReturnError(&pq.Error{Code: pgNoPartition})
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schedules_p").WillReturnError(&pq.Error{Code: pgUniqueViolation})
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS status_p").WillReturnError(&pq.Error{Code: pgDuplicateTable})
+	mock.ExpectExec(regexp.QuoteMeta(pgInsertSchedule)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	app := s.App{AppId: "Test", Partitions: 1}
+	for i := 0; i < 2; i++ {
+		created, err := dao.CreateSchedule(schedule, app)
+		assert.Nil(t, err)
+		assert.Equal(t, schedule.ScheduleId, created.ScheduleId)
+	}
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduleDaoImplPostgres_CreateDedupSchedule(t *testing.T) {
+	pendingId := gocql.TimeUUID()
+	scheduleTime := time.Now().Add(time.Hour).Truncate(time.Minute)
+
+	for _, test := range []struct {
+		name         string
+		policy       s.DedupPolicy
+		claimed      bool
+		live         bool
+		err          error
+		deduplicated bool
+	}{
+		{name: "key is free", policy: s.DedupReject, claimed: true},
+		{name: "pending schedule is rejected", policy: s.DedupReject, live: true, err: ErrDuplicateSchedule},
+		{name: "pending schedule is kept", policy: s.DedupKeepFirst, live: true, deduplicated: true},
+		{name: "pending schedule is replaced", policy: "", live: true},
+		{name: "expired key is taken over", policy: s.DedupReject},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dao, mock := setupPostgresMocks(t)
+
+			schedule := s.Schedule{
+				ScheduleId:    gocql.TimeUUID(),
+				AppId:         "Test",
+				Payload:       "second",
+				ScheduleTime:  scheduleTime.Unix(),
+				ScheduleGroup: scheduleTime.Unix(),
+				DedupKey:      "cart-1",
+				Callback:      &s.HttpCallback{Type: "http", Details: s.Details{Url: "http://example.com/callback", Method: "POST"}},
+			}
+
+			holder := pendingId
+			if test.claimed {
+				holder = schedule.ScheduleId
+			}
+
+			expectPartitions(mock)
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(pgClaimDedupKey)).
+				WillReturnRows(sqlmock.NewRows([]string{"schedule_id", "live"}).AddRow(holder.String(), test.live || test.claimed))
+
+			if test.live {
+				mock.ExpectQuery(regexp.QuoteMeta(pgEnrichedSchedules)).WillReturnRows(pgScheduleRows(scheduleTime, pendingId))
+			}
+
+			switch {
+			case test.err != nil || test.deduplicated:
+				mock.ExpectRollback()
+			default:
+				if !test.claimed {
+					mock.ExpectExec(regexp.QuoteMeta(pgSwapDedupKey)).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				if test.live {
+					mock.ExpectExec(regexp.QuoteMeta(pgDeleteSchedule)).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(regexp.QuoteMeta(pgDeleteScheduleLabels)).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectExec(regexp.QuoteMeta(pgInsertSchedule)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				if test.live {
+					mock.ExpectExec(regexp.QuoteMeta(pgUpdateUsage)).WithArgs("Test", string(s.PendingUsage), int64(-1)).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+			}
+
+			app := s.App{AppId: "Test", Partitions: 1, Configuration: s.Configuration{DedupPolicy: test.policy}}
+			created, err := dao.CreateSchedule(schedule, app)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.deduplicated, created.Deduplicated)
+			if test.deduplicated {
+				assert.Equal(t, pendingId, created.ScheduleId)
+			}
+			assert.Nil(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestScheduleDaoImplPostgres_GetEnrichedSchedule(t *testing.T) {
+	dao, mock := setupPostgresMocks(t)
+
+	id := gocql.TimeUUID()
+	scheduleTime := time.Now().Add(-time.Hour).Truncate(time.Minute)
+
+	rows := pgScheduleRows(scheduleTime)
+	rows.AddRow("Test", 0, scheduleTime, id.String(), scheduleTime, "payload", "http", pgCallbackDetails,
+		nil, 0, "", nil, "", string(s.Success), "", "", "", "")
+	mock.ExpectQuery(regexp.QuoteMeta(pgEnrichedSchedules)).WithArgs(id.String()).WillReturnRows(rows)
+
+	schedule, err := dao.GetEnrichedSchedule(id)
+	assert.Nil(t, err)
+	assert.Equal(t, id, schedule.ScheduleId)
+	assert.Equal(t, s.Success, schedule.Status)
+	assert.Equal(t, scheduleTime.Unix(), schedule.ScheduleTime)
+
+	// Unknown ids fall back to the recurring schedules and are not found
+	mock.ExpectQuery(regexp.QuoteMeta(pgEnrichedSchedules)).WillReturnRows(pgScheduleRows(scheduleTime))
+	mock.ExpectQuery("FROM recurring_schedules").WillReturnRows(sqlmock.NewRows([]string{"schedule_id"}))
+
+	_, err = dao.GetEnrichedSchedule(gocql.TimeUUID())
+	assert.Equal(t, gocql.ErrNotFound, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduleDaoImplPostgres_GetScheduleRuns(t *testing.T) {
+	dao, mock := setupPostgresMocks(t)
+
+	parentId := gocql.TimeUUID()
+	farthest, nearest := gocql.TimeUUID(), gocql.TimeUUID()
+	scheduleTime := time.Now().Add(time.Hour).Truncate(time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE s.parent_schedule_id = $1 AND s.schedule_time_group > $2 " +
+		"ORDER BY s.schedule_time_group DESC, s.schedule_id DESC LIMIT 2")).
+		WillReturnRows(pgScheduleRows(scheduleTime, farthest, nearest))
+
+	runs, pageState, err := dao.GetScheduleRuns(parentId, 2, "future", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{nearest, farthest}, []gocql.UUID{runs[0].ScheduleId, runs[1].ScheduleId})
+	assert.Equal(t, s.Scheduled, runs[0].Status)
+
+	// The next page starts after the last run fetched
+	key, resume, err := decodePageKey(pageState)
+	assert.Nil(t, err)
+	assert.True(t, resume)
+	assert.Equal(t, nearest.String(), key.Id)
+
+	mock.ExpectQuery(regexp.QuoteMeta("AND (s.schedule_time_group, s.schedule_id) < ($3, $4::uuid)")).
+		WithArgs(parentId.String(), sqlmock.AnyArg(), sqlmock.AnyArg(), nearest.String()).
+		WillReturnRows(pgScheduleRows(scheduleTime))
+
+	runs, pageState, err = dao.GetScheduleRuns(parentId, 2, "future", pageState)
+	assert.Nil(t, err)
+	assert.Empty(t, runs)
+	assert.Nil(t, pageState)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduleDaoImplPostgres_GetPaginatedSchedules(t *testing.T) {
+	dao, mock := setupPostgresMocks(t)
+
+	first, second, third := gocql.TimeUUID(), gocql.TimeUUID(), gocql.TimeUUID()
+	scheduleTime := time.Now().Add(time.Hour).Truncate(time.Minute)
+
+	success := pgScheduleRows(scheduleTime, first)
+	success.AddRow("Test", 0, scheduleTime, second.String(), scheduleTime, "payload", "http", pgCallbackDetails,
+		nil, 0, "", nil, "", string(s.Success), "", "", "", "")
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY s.schedule_time_group, s.schedule_id LIMIT 2")).WillReturnRows(success)
+	mock.ExpectQuery(regexp.QuoteMeta("AND (s.schedule_time_group, s.schedule_id) > ($5, $6::uuid)")).
+		WillReturnRows(pgScheduleRows(scheduleTime, third))
+
+	timeRange := Range{StartTime: scheduleTime.Add(-time.Minute), EndTime: scheduleTime.Add(time.Minute)}
+	schedules, pageState, continuation, err := dao.GetPaginatedSchedules("Test", 1, timeRange, 2, s.Scheduled, nil, time.Unix(0, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, []gocql.UUID{first, third}, []gocql.UUID{schedules[0].ScheduleId, schedules[1].ScheduleId})
+	assert.Equal(t, scheduleTime.Unix(), continuation.Unix())
+
+	key, _, _ := decodePageKey(pageState)
+	assert.Equal(t, third.String(), key.Id)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduleDaoImplPostgres_GetSchedulesForEntity(t *testing.T) {
+	dao, mock := setupPostgresMocks(t)
+
+	first, second := gocql.TimeUUID(), gocql.TimeUUID()
+	scheduleTime := time.Now().Truncate(time.Minute)
+
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY s.schedule_id LIMIT 2")).WillReturnRows(pgScheduleRows(scheduleTime, first, second))
+
+	iter := dao.GetSchedulesForEntity("Test", 0, scheduleTime, nil)
+
+	var schedules []s.Schedule
+	m := make(map[string]interface{})
+	for iter.MapScan(m) {
+		var schedule s.Schedule
+		assert.Nil(t, schedule.CreateScheduleFromCassandraMap(m))
+		schedules = append(schedules, schedule)
+		m = make(map[string]interface{})
+	}
+	assert.Nil(t, iter.Close())
+	assert.Len(t, schedules, 2)
+	assert.NotNil(t, iter.PageState())
+
+	mock.ExpectQuery(regexp.QuoteMeta("AND s.schedule_id > $4::uuid")).
+		WithArgs("Test", 0, sqlmock.AnyArg(), second.String()).
+		WillReturnRows(pgScheduleRows(scheduleTime, first))
+
+	iter = dao.GetSchedulesForEntity("Test", 0, scheduleTime, iter.PageState())
+
+	var appId string
+	var partitionId int
+	assert.True(t, iter.Scan(&appId, &partitionId))
+	assert.Equal(t, "Test", appId)
+	assert.False(t, iter.Scan(&appId, &partitionId))
+	assert.Nil(t, iter.PageState())
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestScheduleDaoImplPostgres_Purge(t *testing.T) {
+	dao, mock := setupPostgresMocks(t)
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schedules WHERE")).WillReturnResult(sqlmock.NewResult(0, purgeBatchSize))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schedules WHERE")).WillReturnResult(sqlmock.NewResult(0, 10))
+	for _, table := range []string{"status", "schedules_by_dedup_key", "schedules_by_label"} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM " + table + " WHERE")).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	// Only the empty partitions of past days are dropped, checked again once locked against concurrent writes
+	yesterday := time.Now().UTC().Add(-48 * time.Hour)
+	mock.ExpectQuery("FROM pg_inherits").WithArgs("schedules").
+		WillReturnRows(sqlmock.NewRows([]string{"relname"}).
+			AddRow(partitionName("schedules", yesterday)).
+			AddRow(partitionName("schedules", yesterday.Add(-24*time.Hour))).
+			AddRow(partitionName("schedules", yesterday.Add(-48*time.Hour))).
+			AddRow(partitionName("schedules", time.Now())))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT EXISTS (SELECT 1 FROM " + partitionName("schedules", yesterday) + ")")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("LOCK TABLE " + partitionName("schedules", yesterday) + " IN ACCESS EXCLUSIVE MODE")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("DROP TABLE " + partitionName("schedules", yesterday))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	// A row written before the lock was taken keeps the partition
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec("LOCK TABLE").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("FROM pg_inherits").WithArgs("status").WillReturnRows(sqlmock.NewRows([]string{"relname"}))
+
+	assert.Nil(t, dao.purge())
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
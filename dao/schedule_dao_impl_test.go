@@ -25,6 +25,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/myntra/goscheduler/conf"
 	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/db_wrapper"
 	"github.com/myntra/goscheduler/mocks"
 	s "github.com/myntra/goscheduler/store"
 	"github.com/stretchr/testify/assert"
@@ -36,9 +37,11 @@ func setupMocks(t *testing.T) (*ScheduleDaoImpl, *mocks.MockSessionInterface, *m
 	s.Registry[constants.DefaultCallback] = func() s.Callback { return &s.HttpCallback{} }
 	dao := &ScheduleDaoImpl{
 		Conf: &conf.Configuration{
-			Cluster: conf.ClusterConfig{
-				PageSize: 10,
-				NumRetry: 2,
+			ScheduleDB: conf.ScheduleDBConfig{
+				DBConfig: conf.CassandraConfig{
+					PageSize: 10,
+					NumRetry: 2,
+				},
 			},
 			AggregateSchedulesConfig: conf.AggregateSchedulesConfig{
 				FlushPeriod: 60,
@@ -137,6 +140,7 @@ func TestScheduleDaoImpl_GetSchedule(t *testing.T) {
 
 	m.EXPECT().Query(gomock.Any(), gomock.Any()).Return(mq).AnyTimes()
 	mq.EXPECT().RetryPolicy(gomock.Any()).Return(mq).AnyTimes()
+	mq.EXPECT().Scan(gomock.Any()).Return(nil).AnyTimes()
 	mq.EXPECT().MapScan(gomock.Any()).Return(nil).Times(1)
 
 	_, err := dao.GetSchedule(gocql.TimeUUID())
@@ -158,6 +162,7 @@ func TestScheduleDaoImpl_GetEnrichedSchedule(t *testing.T) {
 
 	m.EXPECT().Query(gomock.Any(), gomock.Any()).Return(mq).AnyTimes()
 	mq.EXPECT().RetryPolicy(gomock.Any()).Return(mq).AnyTimes()
+	mq.EXPECT().Scan(gomock.Any()).Return(nil).AnyTimes()
 	mq.EXPECT().MapScan(gomock.Any()).Return(nil).Times(2)
 
 	_, err := dao.GetEnrichedSchedule(gocql.TimeUUID())
@@ -207,10 +212,28 @@ func TestScheduleDaoImpl_DeleteSchedule(t *testing.T) {
 	defer ctrl.Finish()
 
 	m.EXPECT().Query(gomock.Any(), gomock.Any()).Return(mq).AnyTimes()
-	mq.EXPECT().Exec().Return(nil).AnyTimes()
 	mq.EXPECT().RetryPolicy(gomock.Any()).Return(mq).AnyTimes()
 	mq.EXPECT().MapScan(gomock.Any()).Return(nil).Times(1)
 
+	// The schedule key is found first, then the labels of the schedule
+	gomock.InOrder(
+		mq.EXPECT().Scan(gomock.Any()).Return(nil),
+		mq.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+			*dest[0].(*string) = `{"tier":"gold"}`
+			return nil
+		}),
+	)
+
+	m.EXPECT().ExecuteBatch(gomock.Any()).DoAndReturn(func(batch *gocql.Batch) error {
+		var stmts []string
+		for _, entry := range batch.Entries {
+			stmts = append(stmts, entry.Stmt)
+		}
+		assert.Equal(t, []string{deleteFromSchedule, deleteScheduleLookup, deleteLabel, deleteLabelLookup}, stmts)
+		assert.Equal(t, "tier:gold", batch.Entries[2].Args[1])
+		return nil
+	})
+
 	_, err := dao.DeleteSchedule(gocql.TimeUUID())
 	if err != nil {
 		t.Errorf("Expected no error, got %s", err)
@@ -414,3 +437,122 @@ func TestScheduleDaoImpl_GetCronSchedulesByApp(t *testing.T) {
 		t.Errorf("Expected 0 errors, got %d", len(errs))
 	}
 }
+
+func TestScheduleDaoImpl_OptimizedEnrichScheduleByBucket(t *testing.T) {
+	dao, m, _, _, ctrl := setupMocks(t)
+	defer ctrl.Finish()
+	dao.Conf.ScheduleDB.LegacyStatusReads = true
+
+	group := time.Now().Add(-time.Hour).Truncate(time.Minute).Unix()
+	success, legacy, unknown := gocql.TimeUUID(), gocql.TimeUUID(), gocql.TimeUUID()
+	schedules := []s.Schedule{
+		{ScheduleId: success, AppId: "app1", PartitionId: 1, ScheduleGroup: group, ScheduleTime: group},
+		{ScheduleId: legacy, AppId: "app1", PartitionId: 1, ScheduleGroup: group + 60, ScheduleTime: group + 60},
+		{ScheduleId: unknown, AppId: "app1", PartitionId: 1, ScheduleGroup: group + 60, ScheduleTime: group + 60},
+	}
+
+	statusOf := func(ids map[gocql.UUID]bool) *mocks.MockQueryInterface {
+		query := mocks.NewMockQueryInterface(ctrl)
+		iter := mocks.NewMockIterInterface(ctrl)
+		query.EXPECT().RetryPolicy(gomock.Any()).Return(query)
+		query.EXPECT().Iter().Return(iter)
+		iter.EXPECT().MapScan(gomock.Any()).DoAndReturn(func(row map[string]interface{}) bool {
+			for id := range ids {
+				delete(ids, id)
+				row["schedule_id"] = id
+				row["schedule_status"] = string(s.Success)
+				row["error_msg"] = ""
+				row["reconciliation_history"] = ""
+				return true
+			}
+			return false
+		}).AnyTimes()
+		iter.EXPECT().Close().Return(nil)
+		return query
+	}
+
+	gomock.InOrder(
+		m.EXPECT().Query(gomock.Any(), "app1", 1, group*1000, []gocql.UUID{success}).
+			Return(statusOf(map[gocql.UUID]bool{success: true})),
+		m.EXPECT().Query(gomock.Any(), "app1", 1, (group+60)*1000, []gocql.UUID{legacy, unknown}).
+			Return(statusOf(map[gocql.UUID]bool{})),
+		m.EXPECT().Query(gomock.Any(), "app1", 1, []gocql.UUID{legacy, unknown}).
+			Return(statusOf(map[gocql.UUID]bool{legacy: true})),
+	)
+
+	enriched, err := dao.OptimizedEnrichSchedule(schedules)
+	assert.NoError(t, err)
+
+	statuses := make(map[gocql.UUID]s.Status)
+	for _, schedule := range enriched {
+		statuses[schedule.ScheduleId] = schedule.Status
+	}
+	assert.Equal(t, map[gocql.UUID]s.Status{success: s.Success, legacy: s.Success, unknown: s.Miss}, statuses)
+}
+
+func TestScheduleDaoImpl_EnrichScheduleFromLegacyStatus(t *testing.T) {
+	dao, m, mq, _, ctrl := setupMocks(t)
+	defer ctrl.Finish()
+
+	m.EXPECT().Query(gomock.Any(), gomock.Any()).Return(mq).AnyTimes()
+	mq.EXPECT().RetryPolicy(gomock.Any()).Return(mq).AnyTimes()
+
+	group := time.Now().Add(-time.Hour).Truncate(time.Minute).Unix()
+	schedule := s.Schedule{ScheduleId: gocql.TimeUUID(), AppId: "app1", PartitionId: 1, ScheduleGroup: group, ScheduleTime: group}
+
+	for _, test := range []struct {
+		name     string
+		legacy   bool
+		expected s.Status
+	}{
+		{name: "legacy reads", legacy: true, expected: s.Failure},
+		{name: "bucketed reads only", legacy: false, expected: s.Miss},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dao.Conf.ScheduleDB.LegacyStatusReads = test.legacy
+
+			calls := []*gomock.Call{mq.EXPECT().MapScan(gomock.Any()).Return(gocql.ErrNotFound)}
+			if test.legacy {
+				calls = append(calls, mq.EXPECT().MapScan(gomock.Any()).DoAndReturn(func(row map[string]interface{}) error {
+					row["schedule_status"] = string(s.Failure)
+					row["error_msg"] = "timeout"
+					row["reconciliation_history"] = ""
+					return nil
+				}))
+			}
+			gomock.InOrder(calls...)
+
+			enriched := schedule
+			assert.NoError(t, dao.EnrichSchedule(&enriched))
+			assert.Equal(t, test.expected, enriched.Status)
+		})
+	}
+}
+
+func TestScheduleDaoImpl_GetLabels(t *testing.T) {
+	dao, m, _, _, ctrl := setupMocks(t)
+	defer ctrl.Finish()
+
+	gold, unlabelled, missing := gocql.TimeUUID(), gocql.TimeUUID(), gocql.TimeUUID()
+
+	m.EXPECT().Query(selectLabelLookup, gomock.Any()).Times(3).DoAndReturn(func(_ string, args ...interface{}) db_wrapper.QueryInterface {
+		query := mocks.NewMockQueryInterface(ctrl)
+		query.EXPECT().RetryPolicy(gomock.Any()).Return(query)
+		query.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+			switch args[0].(gocql.UUID) {
+			case gold:
+				*dest[0].(*string) = `{"tier":"gold","region":"eu"}`
+			case missing:
+				return gocql.ErrNotFound
+			}
+			return nil
+		})
+		return query
+	})
+
+	labels, err := dao.GetLabels("Test", []gocql.UUID{gold, unlabelled, missing})
+	assert.Nil(t, err)
+	assert.Equal(t, map[gocql.UUID]map[string]string{
+		gold: {"tier": "gold", "region": "eu"},
+	}, labels)
+}
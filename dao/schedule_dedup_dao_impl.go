@@ -0,0 +1,129 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"errors"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/store"
+	"github.com/myntra/goscheduler/util"
+)
+
+var (
+	ErrDuplicateSchedule = errors.New("a pending schedule with the same dedup key exists")
+	ErrDedupContention   = errors.New("dedup key is being updated concurrently, try again")
+)
+
+const maxDedupAttempts = 3
+
+const insertDedupKey string = "INSERT INTO schedules_by_dedup_key (" +
+	"app_id," +
+	"dedup_key," +
+	"schedule_id) VALUES (?, ?, ?) IF NOT EXISTS USING TTL ?"
+
+const swapDedupKey string = "UPDATE schedules_by_dedup_key USING TTL ? " +
+	"SET schedule_id = ? " +
+	"WHERE app_id = ? " +
+	"AND dedup_key = ? " +
+	"IF schedule_id = ?"
+
+// Persist a one time schedule having a dedup key.
+// The dedup key is claimed with a lightweight transaction. If it is held by a pending schedule,
+// the app's dedup policy decides whether the pending schedule is replaced, kept or the creation is rejected.
+// Keys held by schedules which are no longer pending are taken over.
+// Returns the pending schedule marked as deduplicated if it is kept, the created schedule along with the one it replaced otherwise,
+// ErrDuplicateSchedule if the creation is rejected and ErrDedupContention if the key keeps changing underneath.
+func (s *ScheduleDaoImpl) createDedupSchedule(schedule store.Schedule, app store.App) (store.Schedule, error) {
+	ttl := schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)
+
+	for attempt := 0; attempt < maxDedupAttempts; attempt++ {
+		existing := make(map[string]interface{})
+		applied, err := s.Session.Query(insertDedupKey, schedule.AppId, schedule.DedupKey, schedule.ScheduleId, ttl).
+			MapScanCAS(existing)
+		if err != nil {
+			return schedule, err
+		}
+
+		if applied {
+			return s.createOneTimeSchedule(schedule, app)
+		}
+
+		existingId, _ := existing["schedule_id"].(gocql.UUID)
+		pending, found, err := s.getPendingSchedule(existingId)
+		if err != nil {
+			return schedule, err
+		}
+
+		if found {
+			switch app.Configuration.GetDedupPolicy() {
+			case store.DedupReject:
+				return schedule, ErrDuplicateSchedule
+			case store.DedupKeepFirst:
+				pending.Deduplicated = true
+				return pending, nil
+			}
+		}
+
+		swapped, err := s.Session.Query(swapDedupKey, ttl, schedule.ScheduleId, schedule.AppId, schedule.DedupKey, existingId).
+			MapScanCAS(make(map[string]interface{}))
+		if err != nil {
+			return schedule, err
+		}
+
+		if !swapped {
+			glog.Infof("Dedup key %s of app %s changed concurrently, retrying", schedule.DedupKey, schedule.AppId)
+			continue
+		}
+
+		if found {
+			if _, err = s.deleteOneTimeSchedule(pending); err != nil {
+				return schedule, err
+			}
+			if err = s.UpdateUsage(pending.AppId, store.PendingUsage, -1); err != nil {
+				glog.Errorf("Releasing the usage of replaced schedule %s failed with error %s", pending.ScheduleId.String(), err.Error())
+			}
+			schedule.Replaced = &pending
+		}
+
+		return s.createOneTimeSchedule(schedule, app)
+	}
+
+	return schedule, ErrDedupContention
+}
+
+// Get the schedule holding a dedup key if it has not fired yet.
+// Returns false if the schedule does not exist anymore or is no longer pending.
+func (s *ScheduleDaoImpl) getPendingSchedule(uuid gocql.UUID) (store.Schedule, bool, error) {
+	if util.IsZeroUUID(uuid) {
+		return store.Schedule{}, false, nil
+	}
+
+	schedule, err := s.getEnrichedSchedule(uuid)
+	switch {
+	case err == gocql.ErrNotFound:
+		return store.Schedule{}, false, nil
+	case err != nil:
+		return store.Schedule{}, false, err
+	}
+
+	return schedule, schedule.Status == store.Scheduled, nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestScheduleDaoImpl_CreateDedupSchedule(t *testing.T) {
+	pendingId := gocql.TimeUUID()
+	scheduleTime := time.Now().Add(time.Hour).Truncate(time.Minute)
+
+	pendingRow := func(m map[string]interface{}) error {
+		m["app_id"] = "Test"
+		m["partition_id"] = 0
+		m["schedule_id"] = pendingId
+		m["payload"] = "first"
+		m["callback_type"] = "http"
+		m["callback_details"] = `{"url":"http://example.com/callback","method":"POST"}`
+		m["schedule_time_group"] = scheduleTime
+		m["schedule_time"] = scheduleTime
+		return nil
+	}
+
+	for _, test := range []struct {
+		name         string
+		policy       s.DedupPolicy
+		claimed      bool
+		pending      bool
+		execs        int
+		err          error
+		deduplicated bool
+	}{
+		{name: "key is free", policy: s.DedupReject, claimed: true},
+		{name: "pending schedule is rejected", policy: s.DedupReject, pending: true, err: ErrDuplicateSchedule},
+		{name: "pending schedule is kept", policy: s.DedupKeepFirst, pending: true, deduplicated: true},
+		{name: "pending schedule is replaced", policy: "", pending: true, execs: 1},
+		{name: "fired schedule is taken over", policy: s.DedupReject},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dao, m, mq, _, ctrl := setupMocks(t)
+			defer ctrl.Finish()
+
+			m.EXPECT().Query(gomock.Any(), gomock.Any()).Return(mq).AnyTimes()
+			m.EXPECT().ExecuteBatch(gomock.Any()).Return(nil).AnyTimes()
+			mq.EXPECT().RetryPolicy(gomock.Any()).Return(mq).AnyTimes()
+			mq.EXPECT().Consistency(gomock.Any()).Return(mq).AnyTimes()
+			// The usage of the replaced schedule is read before it is released
+			mq.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+				if total, ok := dest[0].(*int64); ok {
+					*total = 1
+				}
+				return nil
+			}).AnyTimes()
+			mq.EXPECT().Exec().Return(nil).Times(test.execs)
+
+			claim := mq.EXPECT().MapScanCAS(gomock.Any()).DoAndReturn(func(m map[string]interface{}) (bool, error) {
+				m["schedule_id"] = pendingId
+				return test.claimed, nil
+			})
+
+			if !test.claimed {
+				if test.pending {
+					gomock.InOrder(
+						mq.EXPECT().MapScan(gomock.Any()).DoAndReturn(pendingRow),
+						mq.EXPECT().MapScan(gomock.Any()).Return(gocql.ErrNotFound),
+					)
+				} else {
+					mq.EXPECT().MapScan(gomock.Any()).Return(gocql.ErrNotFound)
+				}
+
+				if test.err == nil && !test.deduplicated {
+					mq.EXPECT().MapScanCAS(gomock.Any()).Return(true, nil).After(claim)
+				}
+			}
+
+			app := s.App{AppId: "Test", Partitions: 1, Configuration: s.Configuration{DedupPolicy: test.policy}}
+			schedule := s.Schedule{
+				ScheduleId:   gocql.TimeUUID(),
+				AppId:        "Test",
+				Payload:      "second",
+				ScheduleTime: scheduleTime.Unix(),
+				DedupKey:     "cart-1",
+				Callback:     &s.HttpCallback{Type: "http", Details: s.Details{Url: "http://example.com/callback", Method: "POST"}},
+			}
+
+			created, err := dao.CreateSchedule(schedule, app)
+			assert.Equal(t, test.err, err)
+			assert.Equal(t, test.deduplicated, created.Deduplicated)
+			if test.deduplicated {
+				assert.Equal(t, pendingId, created.ScheduleId)
+			}
+		})
+	}
+}
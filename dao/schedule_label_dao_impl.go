@@ -0,0 +1,215 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"encoding/json"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/cassandra"
+	"github.com/myntra/goscheduler/store"
+)
+
+const insertLabel string = "INSERT INTO schedules_by_label (" +
+	"app_id," +
+	"label," +
+	"schedule_id," +
+	"labels) VALUES (?, ?, ?, ?) USING TTL ?"
+
+const deleteLabel string = "DELETE FROM schedules_by_label " +
+	"WHERE app_id = ? " +
+	"AND label = ? " +
+	"AND schedule_id = ?"
+
+const insertLabelLookup string = "INSERT INTO labels_by_schedule (" +
+	"schedule_id," +
+	"app_id," +
+	"labels) VALUES (?, ?, ?) USING TTL ?"
+
+const selectLabelLookup string = "SELECT labels " +
+	"FROM labels_by_schedule " +
+	"WHERE schedule_id = ?"
+
+const deleteLabelLookup string = "DELETE FROM labels_by_schedule WHERE schedule_id = ?"
+
+// Persist a row per label of the schedule in the label lookup table, along with the labels_by_schedule row
+// through which the label rows of the schedule are found when it is deleted.
+// Rows of one time schedules expire along with the schedule, rows of recurring schedules do not expire.
+// Returns a non nil error if writing the rows fails.
+func (s *ScheduleDaoImpl) createLabels(schedule store.Schedule, app store.App) error {
+	if len(schedule.Labels) == 0 {
+		return nil
+	}
+
+	labels, err := json.Marshal(schedule.Labels)
+	if err != nil {
+		return err
+	}
+
+	ttl := 0
+	if !schedule.IsRecurring() {
+		ttl = schedule.GetTTL(app, s.Conf.AppLevelConfiguration.FiredScheduleRetentionPeriod)
+	}
+
+	batch := gocql.NewBatch(gocql.LoggedBatch)
+	for _, label := range schedule.GetLabelSelectors() {
+		batch.Query(insertLabel, schedule.AppId, label, schedule.ScheduleId, string(labels), ttl)
+	}
+	batch.Query(insertLabelLookup, schedule.ScheduleId, schedule.AppId, string(labels), ttl)
+
+	return s.Session.ExecuteBatch(batch)
+}
+
+// Get the labels of a schedule from the labels_by_schedule lookup table.
+// Returns nil labels if the schedule has none.
+func (s *ScheduleDaoImpl) getLabels(uuid gocql.UUID) (map[string]string, error) {
+	var rawLabels string
+
+	err := s.Session.Query(selectLabelLookup, uuid).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+		Scan(&rawLabels)
+	switch {
+	case err == gocql.ErrNotFound:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var labels map[string]string
+	if rawLabels == "" {
+		return nil, nil
+	}
+	if err = json.Unmarshal([]byte(rawLabels), &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// Add the deletes of the label rows of a schedule to a batch deleting the schedule.
+// The labels are read from the labels_by_schedule lookup table.
+// Returns a non nil error if reading the labels fails.
+func (s *ScheduleDaoImpl) addLabelDeletes(batch *gocql.Batch, schedule store.Schedule) error {
+	labels, err := s.getLabels(schedule.ScheduleId)
+	if err != nil || len(labels) == 0 {
+		return err
+	}
+
+	schedule.Labels = labels
+	for _, label := range schedule.GetLabelSelectors() {
+		batch.Query(deleteLabel, schedule.AppId, label, schedule.ScheduleId)
+	}
+	batch.Query(deleteLabelLookup, schedule.ScheduleId)
+	return nil
+}
+
+// Get the schedules of an app having all the supplied labels.
+// The lookup table is queried with the first selector and the rows are filtered with the remaining ones,
+// so a page may contain less than size schedules.
+// Rows whose schedule no longer exists are skipped.
+// Returns a non nil error if fetching the rows or the schedules fails.
+func (s *ScheduleDaoImpl) GetSchedulesByLabel(appId string, selectors []string, size int64, pageState []byte) ([]store.Schedule, []byte, error) {
+	var schedules []store.Schedule
+
+	if len(selectors) == 0 {
+		return schedules, nil, nil
+	}
+
+	query := "SELECT " +
+		"schedule_id," +
+		"labels " +
+		"FROM schedules_by_label " +
+		"WHERE app_id = ? " +
+		"AND label = ?"
+
+	iter := s.Session.Query(query, appId, selectors[0]).
+		PageState(pageState).
+		PageSize(int(size)).
+		RetryPolicy(cassandra.RetryPolicy(s.Conf.ScheduleDB.DBConfig)).
+		Iter()
+
+	var scheduleId gocql.UUID
+	var rawLabels string
+	for iter.Scan(&scheduleId, &rawLabels) {
+		var labels map[string]string
+		if err := json.Unmarshal([]byte(rawLabels), &labels); err != nil {
+			glog.Errorf("Error unmarshalling labels of schedule %s: %v", scheduleId, err)
+			continue
+		}
+
+		if !store.MatchLabels(labels, selectors[1:]) {
+			continue
+		}
+
+		schedule, err := s.GetEnrichedSchedule(scheduleId)
+		switch {
+		case err == gocql.ErrNotFound:
+			continue
+		case err != nil:
+			_ = iter.Close()
+			return nil, nil, err
+		}
+
+		schedule.Labels = labels
+		schedules = append(schedules, schedule)
+	}
+
+	nextPageState := iter.PageState()
+	if err := iter.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	return schedules, nextPageState, nil
+}
+
+// Get the labels of the labelled schedules among the supplied ones of an app, by schedule id.
+// The labels of each schedule are read from the labels_by_schedule lookup table.
+// Returns a non nil error if fetching the labels fails.
+func (s *ScheduleDaoImpl) GetLabels(appId string, scheduleIds []gocql.UUID) (map[gocql.UUID]map[string]string, error) {
+	labelsById := make(map[gocql.UUID]map[string]string)
+
+	for _, scheduleId := range scheduleIds {
+		labels, err := s.getLabels(scheduleId)
+		if err != nil {
+			return nil, err
+		}
+		if len(labels) != 0 {
+			labelsById[scheduleId] = labels
+		}
+	}
+
+	return labelsById, nil
+}
+
+// Delete the label lookup rows of the schedule.
+// Returns a non nil error if deleting the rows fails.
+func (s *ScheduleDaoImpl) DeleteLabels(schedule store.Schedule) error {
+	if len(schedule.Labels) == 0 {
+		return nil
+	}
+
+	batch := gocql.NewBatch(gocql.LoggedBatch)
+	for _, label := range schedule.GetLabelSelectors() {
+		batch.Query(deleteLabel, schedule.AppId, label, schedule.ScheduleId)
+	}
+	batch.Query(deleteLabelLookup, schedule.ScheduleId)
+
+	return s.Session.ExecuteBatch(batch)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/store"
+)
+
+var (
+	KeyUsageTable    = "app_usage"
+	QueryUpdateUsage = "UPDATE " + KeyUsageTable + " SET total = total + ? WHERE app_id = ? AND kind = ?"
+	KeyUsageOfApp    = "SELECT kind, total FROM " + KeyUsageTable + " WHERE app_id = ?"
+	KeyUsageOfKind   = "SELECT total FROM " + KeyUsageTable + " WHERE app_id = ? AND kind = ?"
+)
+
+// UpdateUsage adds delta to the number of schedules of the given kind of an app.
+// Counter updates are not idempotent, so they are never retried.
+// Counters cannot be bounded, so a decrement is first limited to the current total to keep the usage from going below zero.
+// Concurrent decrements can still overshoot it by the number of schedules they release, which the usage recount corrects.
+func (s *ScheduleDaoImpl) UpdateUsage(appId string, kind store.UsageKind, delta int64) error {
+	if delta < 0 {
+		var total int64
+		err := s.Session.Query(KeyUsageOfKind, appId, string(kind)).
+			Consistency(s.Conf.ScheduleDB.DBConfig.Consistency).
+			Scan(&total)
+		switch {
+		case err == gocql.ErrNotFound:
+			return nil
+		case err != nil:
+			return err
+		case total <= 0:
+			return nil
+		case total+delta < 0:
+			delta = -total
+		}
+	}
+
+	return s.Session.Query(QueryUpdateUsage, delta, appId, string(kind)).Exec()
+}
+
+// GetUsage gets the number of schedules of an app counted against its quotas
+func (s *ScheduleDaoImpl) GetUsage(appId string) (store.Usage, error) {
+	var kind string
+	var total int64
+	var usage store.Usage
+
+	iter := s.Session.Query(KeyUsageOfApp, appId).
+		Consistency(s.Conf.ScheduleDB.DBConfig.Consistency).
+		Iter()
+	for iter.Scan(&kind, &total) {
+		switch store.UsageKind(kind) {
+		case store.PendingUsage:
+			usage.PendingSchedules = total
+		case store.RecurringUsage:
+			usage.RecurringSchedules = total
+		}
+	}
+
+	return usage, iter.Close()
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package dao
+
+import (
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	s "github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestScheduleDaoImpl_UpdateUsage(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		delta   int64
+		total   int64
+		readErr error
+		applied []int64
+	}{
+		{name: "increment is not read", delta: 2, applied: []int64{2}},
+		{name: "decrement within the total", delta: -1, total: 3, applied: []int64{-1}},
+		{name: "decrement past the total stops at zero", delta: -3, total: 1, applied: []int64{-1}},
+		{name: "decrement of a zero total is skipped", delta: -1},
+		{name: "decrement of a missing total is skipped", delta: -1, readErr: gocql.ErrNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dao, m, mq, _, ctrl := setupMocks(t)
+			defer ctrl.Finish()
+
+			if test.delta < 0 {
+				m.EXPECT().Query(KeyUsageOfKind, "Test", string(s.PendingUsage)).Return(mq)
+				mq.EXPECT().Consistency(gomock.Any()).Return(mq)
+				mq.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...interface{}) error {
+					*dest[0].(*int64) = test.total
+					return test.readErr
+				})
+			}
+			for _, delta := range test.applied {
+				m.EXPECT().Query(QueryUpdateUsage, delta, "Test", string(s.PendingUsage)).Return(mq)
+				mq.EXPECT().Exec().Return(nil)
+			}
+
+			assert.Nil(t, dao.UpdateUsage("Test", s.PendingUsage, test.delta))
+		})
+	}
+}
@@ -43,6 +43,7 @@ type QueryInterface interface {
 	Iter() IterInterface
 	Scan(...interface{}) error
 	MapScan(m map[string]interface{}) error
+	MapScanCAS(m map[string]interface{}) (bool, error)
 	Consistency(c gocql.Consistency) QueryInterface
 	PageState(state []byte) QueryInterface
 	PageSize(n int) QueryInterface
@@ -133,6 +134,11 @@ func (q *Query) MapScan(m map[string]interface{}) error {
 	return q.query.MapScan(m)
 }
 
+// MapScanCAS wraps the query's MapScanCAS method
+func (q *Query) MapScanCAS(m map[string]interface{}) (bool, error) {
+	return q.query.MapScanCAS(m)
+}
+
 // Consistency wraps the query's Consistency method
 func (q *Query) Consistency(c gocql.Consistency) QueryInterface {
 	return NewQuery(q.query.Consistency(c))
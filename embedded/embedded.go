@@ -0,0 +1,99 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package embedded
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/conf"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets of the embedded database, one per table of the Cassandra schema
+var (
+	AppsBucket                 = []byte("apps")
+	EntitiesBucket             = []byte("entity")
+	ConfigurationHistoryBucket = []byte("app_configuration_history")
+	CalendarsBucket            = []byte("calendars")
+	ApiKeysBucket              = []byte("api_keys")
+	SchedulesBucket            = []byte("schedules")
+	SchedulesByIdBucket        = []byte("schedules_by_id")
+	RunsBucket                 = []byte("recurring_schedule_runs")
+	StatusBucket               = []byte("status")
+	RecurringSchedulesBucket   = []byte("recurring_schedules")
+	DedupKeysBucket            = []byte("schedules_by_dedup_key")
+	LabelsBucket               = []byte("schedules_by_label")
+	UsageBucket                = []byte("app_usage")
+)
+
+var buckets = [][]byte{
+	AppsBucket,
+	EntitiesBucket,
+	ConfigurationHistoryBucket,
+	CalendarsBucket,
+	ApiKeysBucket,
+	SchedulesBucket,
+	SchedulesByIdBucket,
+	RunsBucket,
+	StatusBucket,
+	RecurringSchedulesBucket,
+	DedupKeysBucket,
+	LabelsBucket,
+	UsageBucket,
+}
+
+var (
+	lock sync.Mutex
+	dbs  = make(map[string]*bolt.DB)
+)
+
+// GetDB opens the database file with the given configuration and creates its buckets if they do not exist.
+// The file is locked by the process holding it open, so the cluster and schedule DAOs share one handle per path.
+func GetDB(config conf.EmbeddedConfig) (*bolt.DB, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if db, ok := dbs[config.Path]; ok {
+		return db, nil
+	}
+
+	db, err := bolt.Open(config.Path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		glog.Error("ERROR OPENING EMBEDDED DATABASE", err)
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	dbs[config.Path] = db
+	return db, nil
+}
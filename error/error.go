@@ -48,6 +48,9 @@ const (
 	ActivatedApp           = 4003
 	BulkActionPushFailure  = 4004
 	InvalidBulkActionType  = 4005
+	DuplicateSchedule      = 4006
+	Unauthenticated        = 4007
+	Forbidden              = 4008
 	UnmarshalErrorCode     = 5001
 	ValidationFailCode     = 5003
 	DataPersistenceFailure = 5004
@@ -85,6 +88,12 @@ func Handle(w http.ResponseWriter, r *http.Request, err AppError) {
 		w.WriteHeader(http.StatusBadRequest)
 	case TooManyRequests:
 		w.WriteHeader(http.StatusTooManyRequests)
+	case DuplicateSchedule:
+		w.WriteHeader(http.StatusConflict)
+	case Unauthenticated:
+		w.WriteHeader(http.StatusUnauthorized)
+	case Forbidden:
+		w.WriteHeader(http.StatusForbidden)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
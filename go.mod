@@ -3,13 +3,14 @@ module github.com/myntra/goscheduler
 go 1.17
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/cactus/go-statsd-client/statsd v0.0.0-20191106001114-12b4e2b38748
 	github.com/gocql/gocql v1.2.1
 	github.com/golang/glog v0.0.0-20160126235308-23def4e6c14b
 	github.com/golang/mock v1.6.0
 	github.com/gorilla/mux v1.8.1-0.20200912192056-d07530f46e1e
-	github.com/imdario/mergo v0.3.12
 	github.com/jinzhu/configor v0.0.0-20171024081003-6ecfe629230f
+	github.com/lib/pq v1.10.9
 	github.com/orcaman/concurrent-map v1.0.0
 	github.com/prometheus/client_golang v1.15.1
 	github.com/sirupsen/logrus v1.8.2-0.20210422133436-b50299cfaaa1
@@ -17,6 +18,7 @@ require (
 	github.com/uber-common/bark v1.3.0
 	github.com/uber/ringpop-go v0.8.5
 	github.com/uber/tchannel-go v1.8.1
+	go.etcd.io/bbolt v1.3.7
 	golang.org/x/net v0.7.0
 	gopkg.in/alexcesaro/statsd.v2 v2.0.0
 )
@@ -0,0 +1,307 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package migration applies the numbered schema migrations of a storage backend and tracks the applied ones.
+// Migrations are files named <version>_<name>.<extension>, applied in the order of their versions.
+// Each applied migration is recorded with the checksum of its file, so edits to a migration
+// which has already been applied are detected instead of silently diverging schemas.
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+var (
+	ErrSchemaBehind     = errors.New("schema is behind the binary")
+	ErrChecksumMismatch = errors.New("applied migration differs from the one in the binary")
+	ErrLockTimeout      = errors.New("timed out waiting for the migration lock")
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.[a-z]+$`)
+
+// Migration is a numbered change to the schema
+type Migration struct {
+	Version  int
+	Name     string
+	Script   string
+	Checksum string
+	// Checksums of earlier revisions of the migration, which are accepted as applied
+	Replaces []string
+}
+
+// Check if the checksum of an applied migration is the one of the migration or of an earlier revision of it
+func (m Migration) matches(checksum string) bool {
+	if checksum == m.Checksum {
+		return true
+	}
+	for _, replaced := range m.Replaces {
+		if checksum == replaced {
+			return true
+		}
+	}
+	return false
+}
+
+// Record is a migration applied to the schema
+type Record struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// State of a migration in the status of the schema
+type State string
+
+const (
+	Applied  State = "applied"
+	Pending  State = "pending"
+	Modified State = "modified"
+	Unknown  State = "unknown"
+	Held     State = "held"
+)
+
+// Status is the state of a migration, either known to the binary or applied to the schema
+type Status struct {
+	Version   int
+	Name      string
+	State     State
+	AppliedAt time.Time
+	// Why a held migration cannot be applied yet
+	Reason string
+}
+
+// Store keeps the schema and the records of the migrations applied to it
+type Store interface {
+	// Lock blocks until the caller holds the migration lock shared by all the nodes, and returns the function releasing it
+	Lock() (func(), error)
+	// Applied gets the records of the applied migrations
+	Applied() ([]Record, error)
+	// Apply runs the script of the migration and records it
+	Apply(migration Migration) error
+}
+
+// Gate is implemented by the stores holding migrations back until the data they depend on is ready,
+// such as a migration dropping tables which are still read until a backfill is done
+type Gate interface {
+	// Held returns why the migration cannot be applied yet, empty if it can
+	Held(migration Migration) (string, error)
+}
+
+// Get why the migration is held back by the store, empty if it is not
+func held(store Store, migration Migration) (string, error) {
+	gate, ok := store.(Gate)
+	if !ok {
+		return "", nil
+	}
+	return gate.Held(migration)
+}
+
+// Get the pending migrations which can be applied, up to the first one held back by the store.
+// The migrations following a held one wait for it, so that migrations are always applied in order.
+func applicable(store Store, pending []Migration) ([]Migration, error) {
+	for i, migration := range pending {
+		reason, err := held(store, migration)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			glog.Infof("Migration %d (%s) and the following ones are held back: %s", migration.Version, migration.Name, reason)
+			return pending[:i], nil
+		}
+	}
+	return pending, nil
+}
+
+// Load gets the migrations of the files in dir with the given extension, sorted by version
+func Load(fsys fs.FS, dir string, extension string) ([]Migration, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*"+extension))
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	versions := make(map[int]string)
+
+	for _, file := range files {
+		matches := fileNamePattern.FindStringSubmatch(path.Base(file))
+		if matches == nil {
+			return nil, fmt.Errorf("migration file %s is not named <version>_<name>%s", file, extension)
+		}
+
+		version, _ := strconv.Atoi(matches[1])
+		if other, found := versions[version]; found {
+			return nil, fmt.Errorf("migrations %s and %s have the same version %d", other, file, version)
+		}
+		versions[version] = file
+
+		script, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(script)
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     matches[2],
+			Script:   string(script),
+			Checksum: hex.EncodeToString(sum[:]),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Get the applied migrations by version
+func appliedByVersion(store Store) (map[int]Record, error) {
+	records, err := store.Applied()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]Record, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Get the migrations not applied yet, ErrChecksumMismatch is returned if an applied migration has been modified since
+func pending(migrations []Migration, applied map[int]Record) ([]Migration, error) {
+	var pending []Migration
+	for _, migration := range migrations {
+		record, found := applied[migration.Version]
+		switch {
+		case !found:
+			pending = append(pending, migration)
+		case !migration.matches(record.Checksum):
+			return nil, fmt.Errorf("%w: version %d (%s)", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations in the order of their versions while holding the migration lock,
+// so nodes migrating at the same time apply every migration once.
+// Migrations held back by the store are left pending along with the ones following them.
+// Returns the migrations applied.
+func Up(store Store, migrations []Migration) ([]Migration, error) {
+	unlock, err := store.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := appliedByVersion(store)
+	if err != nil {
+		return nil, err
+	}
+
+	toApply, err := pending(migrations, applied)
+	if err != nil {
+		return nil, err
+	}
+
+	if toApply, err = applicable(store, toApply); err != nil {
+		return nil, err
+	}
+
+	for i, migration := range toApply {
+		glog.Infof("Applying migration %d (%s)", migration.Version, migration.Name)
+		if err = store.Apply(migration); err != nil {
+			return toApply[:i], fmt.Errorf("migration %d (%s) failed with error %s", migration.Version, migration.Name, err.Error())
+		}
+	}
+	return toApply, nil
+}
+
+// Check returns ErrSchemaBehind if migrations of the binary have not been applied
+// and ErrChecksumMismatch if applied ones have been modified since.
+// Migrations applied to the schema but unknown to the binary are left to the newer binaries which applied them,
+// and migrations held back by the store do not count as pending.
+func Check(store Store, migrations []Migration) error {
+	applied, err := appliedByVersion(store)
+	if err != nil {
+		return err
+	}
+
+	toApply, err := pending(migrations, applied)
+	if err != nil {
+		return err
+	}
+
+	if toApply, err = applicable(store, toApply); err != nil {
+		return err
+	}
+
+	if len(toApply) > 0 {
+		return fmt.Errorf("%w: %d migrations from version %d (%s) are pending, run migrate up",
+			ErrSchemaBehind, len(toApply), toApply[0].Version, toApply[0].Name)
+	}
+	return nil
+}
+
+// GetStatus gets the state of the migrations of the binary and of the ones applied to the schema, sorted by version
+func GetStatus(store Store, migrations []Migration) ([]Status, error) {
+	applied, err := appliedByVersion(store)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	for _, migration := range migrations {
+		status := Status{Version: migration.Version, Name: migration.Name, State: Pending}
+		if record, found := applied[migration.Version]; found {
+			status.AppliedAt = record.AppliedAt
+			status.State = Applied
+			if !migration.matches(record.Checksum) {
+				status.State = Modified
+			}
+			delete(applied, migration.Version)
+		} else if status.Reason, err = held(store, migration); err != nil {
+			return nil, err
+		} else if status.Reason != "" {
+			status.State = Held
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, record := range applied {
+		statuses = append(statuses, Status{Version: record.Version, Name: record.Name, State: Unknown, AppliedAt: record.AppliedAt})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, nil
+}
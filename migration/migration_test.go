@@ -0,0 +1,197 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package migration
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	records []Record
+	scripts []string
+	locked  bool
+	fail    int
+	holds   map[int]string
+}
+
+func (f *fakeStore) Lock() (func(), error) {
+	if f.locked {
+		return nil, ErrLockTimeout
+	}
+	f.locked = true
+	return func() { f.locked = false }, nil
+}
+
+func (f *fakeStore) Applied() ([]Record, error) {
+	return f.records, nil
+}
+
+func (f *fakeStore) Apply(migration Migration) error {
+	if migration.Version == f.fail {
+		return errors.New("syntax error")
+	}
+	f.scripts = append(f.scripts, migration.Script)
+	f.records = append(f.records, Record{Version: migration.Version, Name: migration.Name, Checksum: migration.Checksum, AppliedAt: time.Now()})
+	return nil
+}
+
+func (f *fakeStore) Held(migration Migration) (string, error) {
+	return f.holds[migration.Version], nil
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/0002_add_index.cql": {Data: []byte("CREATE INDEX;")},
+		"migrations/0001_initial.cql":   {Data: []byte("CREATE TABLE;")},
+		"migrations/README.md":          {Data: []byte("not a migration")},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testFS(), "migrations", ".cql")
+	require.Nil(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "initial", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE;", migrations[0].Script)
+	assert.Len(t, migrations[0].Checksum, 64)
+	assert.Equal(t, "add_index", migrations[1].Name)
+
+	fsys := testFS()
+	fsys["migrations/01_duplicate.cql"] = &fstest.MapFile{Data: []byte("")}
+	_, err = Load(fsys, "migrations", ".cql")
+	assert.NotNil(t, err)
+
+	fsys = testFS()
+	fsys["migrations/initial.cql"] = &fstest.MapFile{Data: []byte("")}
+	_, err = Load(fsys, "migrations", ".cql")
+	assert.NotNil(t, err)
+}
+
+func TestUp(t *testing.T) {
+	migrations, err := Load(testFS(), "migrations", ".cql")
+	require.Nil(t, err)
+
+	store := &fakeStore{}
+	assert.ErrorIs(t, Check(store, migrations), ErrSchemaBehind)
+
+	applied, err := Up(store, migrations)
+	require.Nil(t, err)
+	assert.Len(t, applied, 2)
+	assert.Equal(t, []string{"CREATE TABLE;", "CREATE INDEX;"}, store.scripts)
+	assert.False(t, store.locked)
+	assert.Nil(t, Check(store, migrations))
+
+	// Applied migrations are not applied again
+	applied, err = Up(store, migrations)
+	require.Nil(t, err)
+	assert.Empty(t, applied)
+	assert.Len(t, store.scripts, 2)
+}
+
+func TestUp_Failure(t *testing.T) {
+	migrations, err := Load(testFS(), "migrations", ".cql")
+	require.Nil(t, err)
+
+	store := &fakeStore{fail: 2}
+	applied, err := Up(store, migrations)
+	assert.NotNil(t, err)
+	assert.Len(t, applied, 1)
+	assert.False(t, store.locked)
+
+	store.locked = true
+	_, err = Up(store, migrations)
+	assert.ErrorIs(t, err, ErrLockTimeout)
+}
+
+func TestCheck_ChecksumMismatch(t *testing.T) {
+	migrations, err := Load(testFS(), "migrations", ".cql")
+	require.Nil(t, err)
+
+	store := &fakeStore{}
+	_, err = Up(store, migrations)
+	require.Nil(t, err)
+
+	fsys := testFS()
+	fsys["migrations/0001_initial.cql"] = &fstest.MapFile{Data: []byte("CREATE TABLE IF NOT EXISTS;")}
+	modified, err := Load(fsys, "migrations", ".cql")
+	require.Nil(t, err)
+
+	assert.ErrorIs(t, Check(store, modified), ErrChecksumMismatch)
+	_, err = Up(store, modified)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// The checksums of earlier revisions of a migration are accepted
+	modified[0].Replaces = []string{migrations[0].Checksum}
+	assert.Nil(t, Check(store, modified))
+	statuses, err := GetStatus(store, modified)
+	require.Nil(t, err)
+	assert.Equal(t, Applied, statuses[0].State)
+}
+
+func TestUp_Held(t *testing.T) {
+	migrations, err := Load(testFS(), "migrations", ".cql")
+	require.Nil(t, err)
+
+	store := &fakeStore{holds: map[int]string{2: "backfill pending"}}
+	applied, err := Up(store, migrations)
+	require.Nil(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, []string{"CREATE TABLE;"}, store.scripts)
+
+	// Held migrations do not keep the scheduler from starting
+	assert.Nil(t, Check(store, migrations))
+	statuses, err := GetStatus(store, migrations)
+	require.Nil(t, err)
+	assert.Equal(t, Held, statuses[1].State)
+	assert.Equal(t, "backfill pending", statuses[1].Reason)
+
+	delete(store.holds, 2)
+	assert.ErrorIs(t, Check(store, migrations), ErrSchemaBehind)
+	applied, err = Up(store, migrations)
+	require.Nil(t, err)
+	assert.Len(t, applied, 1)
+	assert.Equal(t, "add_index", applied[0].Name)
+}
+
+func TestGetStatus(t *testing.T) {
+	migrations, err := Load(testFS(), "migrations", ".cql")
+	require.Nil(t, err)
+
+	store := &fakeStore{}
+	_, err = Up(store, migrations[:1])
+	require.Nil(t, err)
+	store.records = append(store.records, Record{Version: 3, Name: "newer"})
+
+	statuses, err := GetStatus(store, migrations)
+	require.Nil(t, err)
+
+	var states []State
+	for _, status := range statuses {
+		states = append(states, status.State)
+	}
+	assert.Equal(t, []State{Applied, Pending, Unknown}, states)
+	assert.Equal(t, "newer", statuses[2].Name)
+}
@@ -222,18 +222,18 @@ func (mr *MockClusterDaoMockRecorder) UpdateAppActiveStatus(appName, activeStatu
 }
 
 // UpdateConfiguration mocks base method.
-func (m *MockClusterDao) UpdateConfiguration(appId string, configuration store.Configuration) (store.Configuration, error) {
+func (m *MockClusterDao) UpdateConfiguration(appId string, patch store.ConfigurationPatch) (store.Configuration, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateConfiguration", appId, configuration)
+	ret := m.ctrl.Call(m, "UpdateConfiguration", appId, patch)
 	ret0, _ := ret[0].(store.Configuration)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateConfiguration indicates an expected call of UpdateConfiguration.
-func (mr *MockClusterDaoMockRecorder) UpdateConfiguration(appId, configuration interface{}) *gomock.Call {
+func (mr *MockClusterDaoMockRecorder) UpdateConfiguration(appId, patch interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfiguration", reflect.TypeOf((*MockClusterDao)(nil).UpdateConfiguration), appId, configuration)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConfiguration", reflect.TypeOf((*MockClusterDao)(nil).UpdateConfiguration), appId, patch)
 }
 
 // UpdateEntityStatus mocks base method.
@@ -177,6 +177,21 @@ func (mr *MockQueryInterfaceMockRecorder) MapScan(m interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MapScan", reflect.TypeOf((*MockQueryInterface)(nil).MapScan), m)
 }
 
+// MapScanCAS mocks base method.
+func (m_2 *MockQueryInterface) MapScanCAS(m map[string]interface{}) (bool, error) {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "MapScanCAS", m)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MapScanCAS indicates an expected call of MapScanCAS.
+func (mr *MockQueryInterfaceMockRecorder) MapScanCAS(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MapScanCAS", reflect.TypeOf((*MockQueryInterface)(nil).MapScanCAS), m)
+}
+
 // PageSize mocks base method.
 func (m *MockQueryInterface) PageSize(n int) db_wrapper.QueryInterface {
 	m.ctrl.T.Helper()
@@ -45,6 +45,10 @@ func initStatsd(conf *conf.StatsdConfig) (*statsd.Client, error) {
 }
 
 func GetRingPopStatsD(conf *conf.StatsdConfig) bark.StatsReporter {
+	if conf == nil {
+		return nil
+	}
+
 	RingPopStatsDClient, err := gstatsd.New(conf.Address, conf.Prefix)
 	if err != nil {
 		glog.Errorf("Error while creating statsd client %+v", err)
@@ -0,0 +1,217 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package payload offloads the payloads of schedules above a size threshold to a blob store,
+// keeping only a reference to the object in the schedule.
+package payload
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/blob"
+)
+
+const (
+	// ReferencePrefix starts the payload of a schedule whose payload is offloaded, followed by the key of the object
+	ReferencePrefix = "goscheduler-payload:"
+	// Prefix of the keys of the offloaded payloads
+	keyPrefix = "payloads/"
+	// Expiry element of the keys of the payloads which are kept until their schedule is deleted,
+	// sorting after the expiry dates of the other payloads
+	neverExpires = "never"
+	dayLayout    = "2006-01-02"
+)
+
+var (
+	// ErrInvalidReference is returned for references which do not point to an offloaded payload.
+	ErrInvalidReference = errors.New("invalid payload reference")
+	// ErrDisabled is returned when resolving a reference while payloads are not offloaded.
+	ErrDisabled = errors.New("payload store is disabled")
+)
+
+// Store offloads the payloads larger than the threshold to the blob store.
+// The payload of a schedule is kept under a key holding the time it expires at,
+// "payloads/<expiry date>/<expiry unix time>-<schedule id>", so expired payloads are found by listing the keys alone.
+// Payloads which never expire are kept under "payloads/never/<schedule id>".
+type Store struct {
+	blobs     blob.Store
+	threshold int
+	now       func() time.Time
+}
+
+// NewStore creates a store offloading the payloads larger than threshold bytes to the blob store.
+func NewStore(blobs blob.Store, threshold int) *Store {
+	return &Store{
+		blobs:     blobs,
+		threshold: threshold,
+		now:       time.Now,
+	}
+}
+
+// IsReference checks if the payload is a reference to an offloaded payload.
+func IsReference(payload string) bool {
+	return strings.HasPrefix(payload, ReferencePrefix)
+}
+
+// Offload writes the payload of the schedule to the blob store if it is larger than the threshold and returns the reference to it.
+// The payload is returned as is otherwise. A zero expiresAt keeps the payload until the schedule is deleted.
+func (s *Store) Offload(scheduleId gocql.UUID, payload string, expiresAt time.Time) (string, bool, error) {
+	if len(payload) <= s.threshold {
+		return payload, false, nil
+	}
+
+	key := payloadKey(scheduleId, expiresAt)
+	if err := s.blobs.Put(key, []byte(payload)); err != nil {
+		return payload, false, err
+	}
+	return ReferencePrefix + key, true, nil
+}
+
+// Load reads the payload the reference points to.
+func (s *Store) Load(reference string) (string, error) {
+	key, err := referenceKey(reference)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := s.blobs.Get(key)
+	if err != nil {
+		return "", fmt.Errorf("loading payload %s failed: %w", key, err)
+	}
+	return string(data), nil
+}
+
+// Resolve returns the payload the reference points to, or the payload itself if it is not a reference.
+// Resolving a reference with a nil store fails with ErrDisabled, e.g. after the payload store is disabled.
+func (s *Store) Resolve(payload string) (string, error) {
+	switch {
+	case !IsReference(payload):
+		return payload, nil
+	case s == nil:
+		return "", ErrDisabled
+	default:
+		return s.Load(payload)
+	}
+}
+
+// Delete removes the payload the reference points to if it was offloaded for the schedule.
+// The runs of a recurring schedule reference the payload of their recurring schedule, which is left alone when a run is deleted.
+func (s *Store) Delete(scheduleId gocql.UUID, reference string) error {
+	key, err := referenceKey(reference)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(key, scheduleId.String()) {
+		return nil
+	}
+	return s.blobs.Delete(key)
+}
+
+// Collect deletes the payloads which expired and returns the number of payloads deleted.
+func (s *Store) Collect() (int, error) {
+	keys, err := s.blobs.List(keyPrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now().Unix()
+	deleted := 0
+	for _, key := range keys {
+		expiresAt, expires := keyExpiry(key)
+		if !expires {
+			continue
+		}
+		// Keys are sorted by expiry, the remaining ones have not expired yet
+		if expiresAt > now {
+			break
+		}
+
+		if err = s.blobs.Delete(key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// Start deleting the expired payloads every interval in the background.
+func (s *Store) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			deleted, err := s.Collect()
+			if err != nil {
+				glog.Errorf("Deleting expired payloads failed with error %s", err.Error())
+			}
+			if deleted > 0 {
+				glog.Infof("Deleted %d expired payloads", deleted)
+			}
+		}
+	}()
+}
+
+// payloadKey gets the key of the payload of a schedule expiring at expiresAt, or never if it is zero.
+func payloadKey(scheduleId gocql.UUID, expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return keyPrefix + neverExpires + "/" + scheduleId.String()
+	}
+
+	expiresAt = expiresAt.UTC()
+	return fmt.Sprintf("%s%s/%010d-%s", keyPrefix, expiresAt.Format(dayLayout), expiresAt.Unix(), scheduleId.String())
+}
+
+// referenceKey gets the key of the payload a reference points to.
+// References to objects other than payloads are rejected so that a payload never exposes other objects of the blob store.
+func referenceKey(reference string) (string, error) {
+	key := strings.TrimPrefix(reference, ReferencePrefix)
+	if key == reference || !strings.HasPrefix(key, keyPrefix) || strings.Contains(key, "..") {
+		return "", ErrInvalidReference
+	}
+	return key, nil
+}
+
+// keyExpiry gets the unix time the payload of the key expires at, false if it never does.
+// Keys which are not payload keys never expire, they are left alone.
+func keyExpiry(key string) (int64, bool) {
+	elements := strings.Split(strings.TrimPrefix(key, keyPrefix), "/")
+	if len(elements) != 2 || elements[0] == neverExpires {
+		return 0, false
+	}
+
+	i := strings.Index(elements[1], "-")
+	if i < 0 {
+		return 0, false
+	}
+
+	expiresAt, err := strconv.ParseInt(elements[1][:i], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return expiresAt, true
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package payload
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/blob"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setup(t *testing.T) (*Store, blob.Store) {
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	return NewStore(blobs, 8), blobs
+}
+
+func TestStore_Offload(t *testing.T) {
+	store, blobs := setup(t)
+	scheduleId := gocql.TimeUUID()
+	expiresAt := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	reference, offloaded, err := store.Offload(scheduleId, "small", expiresAt)
+	require.NoError(t, err)
+	assert.False(t, offloaded)
+	assert.Equal(t, "small", reference)
+
+	large := strings.Repeat("x", 100)
+	reference, offloaded, err = store.Offload(scheduleId, large, expiresAt)
+	require.NoError(t, err)
+	assert.True(t, offloaded)
+	assert.True(t, IsReference(reference))
+	assert.Equal(t, ReferencePrefix+"payloads/2023-01-02/1672628645-"+scheduleId.String(), reference)
+
+	loaded, err := store.Resolve(reference)
+	require.NoError(t, err)
+	assert.Equal(t, large, loaded)
+
+	loaded, err = store.Resolve("inline")
+	require.NoError(t, err)
+	assert.Equal(t, "inline", loaded)
+
+	var disabled *Store
+	_, err = disabled.Resolve(reference)
+	assert.Equal(t, ErrDisabled, err)
+
+	// References never point outside of the payloads
+	require.NoError(t, blobs.Put("archive/secret", []byte("secret")))
+	_, err = store.Load(ReferencePrefix + "archive/secret")
+	assert.Equal(t, ErrInvalidReference, err)
+	_, err = store.Load(ReferencePrefix + "payloads/../archive/secret")
+	assert.Equal(t, ErrInvalidReference, err)
+
+	// The payload of a recurring schedule is not deleted along with its runs
+	require.NoError(t, store.Delete(gocql.TimeUUID(), reference))
+	_, err = store.Load(reference)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Delete(scheduleId, reference))
+	_, err = store.Load(reference)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+func TestStore_Collect(t *testing.T) {
+	store, blobs := setup(t)
+	now := time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	large := strings.Repeat("x", 100)
+	expired, _, err := store.Offload(gocql.TimeUUID(), large, now.Add(-25*time.Hour))
+	require.NoError(t, err)
+	expiring, _, err := store.Offload(gocql.TimeUUID(), large, now.Add(-time.Second))
+	require.NoError(t, err)
+	live, _, err := store.Offload(gocql.TimeUUID(), large, now.Add(time.Hour))
+	require.NoError(t, err)
+	recurring, _, err := store.Offload(gocql.TimeUUID(), large, time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, blobs.Put("archive/other", []byte("other")))
+
+	deleted, err := store.Collect()
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	for _, reference := range []string{expired, expiring} {
+		_, err = store.Load(reference)
+		assert.ErrorIs(t, err, blob.ErrNotFound)
+	}
+	for _, reference := range []string{live, recurring} {
+		_, err = store.Load(reference)
+		assert.NoError(t, err)
+	}
+	_, err = blobs.Get("archive/other")
+	assert.NoError(t, err)
+}
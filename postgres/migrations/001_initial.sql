@@ -0,0 +1,126 @@
+-- Cluster data
+
+CREATE TABLE IF NOT EXISTS apps (
+    id            text PRIMARY KEY,
+    partitions    integer NOT NULL,
+    active        boolean NOT NULL DEFAULT false,
+    configuration text    NOT NULL DEFAULT '{}'
+);
+
+CREATE TABLE IF NOT EXISTS entity (
+    id       text PRIMARY KEY,
+    nodename text    NOT NULL DEFAULT '',
+    status   integer NOT NULL DEFAULT 0,
+    history  text    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS entity_by_nodename ON entity (nodename);
+
+CREATE TABLE IF NOT EXISTS app_configuration_history (
+    app_id        text        NOT NULL,
+    version       integer     NOT NULL,
+    operation     text        NOT NULL,
+    configuration text        NOT NULL,
+    updated_at    timestamptz NOT NULL,
+    PRIMARY KEY (app_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS calendars (
+    name     text PRIMARY KEY,
+    calendar text NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id      text PRIMARY KEY,
+    api_key text NOT NULL
+);
+
+-- Schedule data
+
+-- One time schedules and the runs of recurring schedules, partitioned by day of their schedule time group.
+-- Partitions are created by the scheduler as schedules are written and dropped once they are empty.
+CREATE TABLE IF NOT EXISTS schedules (
+    app_id              text        NOT NULL,
+    partition_id        integer     NOT NULL,
+    schedule_time_group timestamptz NOT NULL,
+    schedule_id         uuid        NOT NULL,
+    schedule_time       timestamptz NOT NULL,
+    payload             text        NOT NULL DEFAULT '',
+    callback_type       text        NOT NULL,
+    callback_details    text        NOT NULL,
+    parent_schedule_id  uuid,
+    run_number          integer     NOT NULL DEFAULT 0,
+    chain               text        NOT NULL DEFAULT '',
+    deadline            timestamptz,
+    priority            text        NOT NULL DEFAULT '',
+    expires_at          timestamptz NOT NULL,
+    PRIMARY KEY (app_id, partition_id, schedule_time_group, schedule_id)
+) PARTITION BY RANGE (schedule_time_group);
+
+CREATE INDEX IF NOT EXISTS schedules_by_id ON schedules (schedule_id);
+CREATE INDEX IF NOT EXISTS schedules_by_parent ON schedules (parent_schedule_id, schedule_time_group) WHERE parent_schedule_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS schedules_by_expiry ON schedules (expires_at);
+
+-- Outcome of fired schedules, partitioned like the schedules
+CREATE TABLE IF NOT EXISTS status (
+    app_id                 text        NOT NULL,
+    partition_id           integer     NOT NULL,
+    schedule_time_group    timestamptz NOT NULL,
+    schedule_id            uuid        NOT NULL,
+    schedule_status        text        NOT NULL,
+    error_msg              text        NOT NULL DEFAULT '',
+    reconciliation_history text        NOT NULL DEFAULT '',
+    target_status          text        NOT NULL DEFAULT '',
+    next_schedule_id       text        NOT NULL DEFAULT '',
+    expires_at             timestamptz NOT NULL,
+    PRIMARY KEY (app_id, partition_id, schedule_time_group, schedule_id)
+) PARTITION BY RANGE (schedule_time_group);
+
+CREATE INDEX IF NOT EXISTS status_by_expiry ON status (expires_at);
+
+CREATE TABLE IF NOT EXISTS recurring_schedules (
+    schedule_id      uuid PRIMARY KEY,
+    app_id           text    NOT NULL,
+    partition_id     integer NOT NULL,
+    payload          text    NOT NULL DEFAULT '',
+    callback_type    text    NOT NULL,
+    callback_details text    NOT NULL,
+    cron_expression  text    NOT NULL,
+    status           text    NOT NULL,
+    expires_after    text    NOT NULL DEFAULT '',
+    jitter           text    NOT NULL DEFAULT '',
+    calendar         text    NOT NULL DEFAULT '',
+    calendar_policy  text    NOT NULL DEFAULT '',
+    priority         text    NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS recurring_schedules_by_partition ON recurring_schedules (partition_id);
+CREATE INDEX IF NOT EXISTS recurring_schedules_by_app ON recurring_schedules (app_id);
+
+CREATE TABLE IF NOT EXISTS schedules_by_dedup_key (
+    app_id      text        NOT NULL,
+    dedup_key   text        NOT NULL,
+    schedule_id uuid        NOT NULL,
+    expires_at  timestamptz NOT NULL,
+    PRIMARY KEY (app_id, dedup_key)
+);
+
+CREATE INDEX IF NOT EXISTS schedules_by_dedup_key_by_expiry ON schedules_by_dedup_key (expires_at);
+
+CREATE TABLE IF NOT EXISTS schedules_by_label (
+    app_id      text NOT NULL,
+    label       text NOT NULL,
+    schedule_id uuid NOT NULL,
+    labels      text NOT NULL,
+    expires_at  timestamptz,
+    PRIMARY KEY (app_id, label, schedule_id)
+);
+
+CREATE INDEX IF NOT EXISTS schedules_by_label_by_expiry ON schedules_by_label (expires_at) WHERE expires_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS app_usage (
+    app_id text   NOT NULL,
+    kind   text   NOT NULL,
+    total  bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (app_id, kind)
+);
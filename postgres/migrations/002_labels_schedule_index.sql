@@ -0,0 +1,2 @@
+-- Label rows are deleted by schedule along with the schedules
+CREATE INDEX IF NOT EXISTS schedules_by_label_by_schedule ON schedules_by_label (schedule_id);
@@ -0,0 +1,2 @@
+-- The cron time of a run before its calendar shift and jitter, which the runs are numbered from
+ALTER TABLE schedules ADD COLUMN IF NOT EXISTS nominal_time timestamptz;
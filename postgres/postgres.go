@@ -0,0 +1,187 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/lib/pq"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/migration"
+)
+
+// Key of the advisory lock held while the migrations are applied, so that nodes starting together apply them once
+const migrationLockKey = 0x676f7363686564
+
+// SQLSTATE of queries on tables which do not exist
+const undefinedTable = "42P01"
+
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    integer PRIMARY KEY,
+    name       text        NOT NULL,
+    checksum   text        NOT NULL,
+    applied_at timestamptz NOT NULL
+)`
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// GetDB opens a connection pool to the PostgreSQL database with the given configuration and checks that it is reachable.
+func GetDB(config conf.PostgresConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", config.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(config.MaxOpenConnections)
+	db.SetMaxIdleConns(config.MaxIdleConnections)
+	db.SetConnMaxLifetime(time.Duration(config.ConnectionMaxLifetime) * time.Second)
+
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		glog.Error("ERROR CONNECTING TO POSTGRES", err)
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrations gets the migrations of the PostgreSQL schema embedded in the binary
+func Migrations() ([]migration.Migration, error) {
+	return migration.Load(migrations, "migrations", ".sql")
+}
+
+// MigrationStore records the migrations applied to the PostgreSQL schema in the schema_migrations table.
+// The migration lock is an advisory lock, released by the database if its holder dies.
+// Each migration is applied in a transaction along with its record.
+type MigrationStore struct {
+	DB *sql.DB
+}
+
+func NewMigrationStore(db *sql.DB) *MigrationStore {
+	return &MigrationStore{DB: db}
+}
+
+// GetMigrationStore connects to the database and returns the migration store along with the function closing the connections.
+func GetMigrationStore(config conf.PostgresConfig) (*MigrationStore, func(), error) {
+	db, err := GetDB(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewMigrationStore(db), func() { _ = db.Close() }, nil
+}
+
+// Lock creates the table tracking the migrations if needed and waits until the migration lock is taken.
+func (m *MigrationStore) Lock() (func(), error) {
+	// Advisory locks belong to a session, so the lock is taken and released on a connection of its own
+	ctx := context.Background()
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = conn.ExecContext(ctx, createMigrationsTable); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		_, _ = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey)
+		_ = conn.Close()
+	}, nil
+}
+
+// Applied gets the records of the applied migrations, none if the table tracking them does not exist yet
+func (m *MigrationStore) Applied() ([]migration.Record, error) {
+	rows, err := m.DB.Query("SELECT version, name, checksum, applied_at FROM schema_migrations")
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == undefinedTable {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []migration.Record
+	for rows.Next() {
+		var record migration.Record
+		if err = rows.Scan(&record.Version, &record.Name, &record.Checksum, &record.AppliedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// Apply runs the script of the migration and records it in a single transaction
+func (m *MigrationStore) Apply(migration migration.Migration) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = tx.Exec(migration.Script); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, now())",
+		migration.Version, migration.Name, migration.Checksum); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Migrate applies the pending migrations embedded in the binary in the order of their versions.
+func Migrate(db *sql.DB) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+
+	_, err = migration.Up(NewMigrationStore(db), migrations)
+	return err
+}
+
+// PostgresInit connects to the database and applies the migrations, panicking if either fails.
+func PostgresInit(config conf.PostgresConfig) {
+	db, err := GetDB(config)
+	if err != nil {
+		panic(fmt.Errorf("GetDB failed with error %s", err.Error()))
+	}
+	defer db.Close()
+
+	if err = Migrate(db); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package postgres
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/myntra/goscheduler/migration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, migrations)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "initial", migrations[0].Name)
+}
+
+func TestMigrationStore_Applied(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewMigrationStore(db)
+
+	// Migrations are not tracked before the first one is applied
+	mock.ExpectQuery("SELECT version, name, checksum, applied_at FROM schema_migrations").
+		WillReturnError(&pq.Error{Code: undefinedTable})
+	records, err := store.Applied()
+	assert.Nil(t, err)
+	assert.Empty(t, records)
+
+	appliedAt := time.Now()
+	mock.ExpectQuery("SELECT version, name, checksum, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "checksum", "applied_at"}).AddRow(1, "initial", "abc", appliedAt))
+	records, err = store.Applied()
+	assert.Nil(t, err)
+	assert.Equal(t, []migration.Record{{Version: 1, Name: "initial", Checksum: "abc", AppliedAt: appliedAt}}, records)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrationStore_Apply(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store := NewMigrationStore(db)
+	m := migration.Migration{Version: 2, Name: "add_column", Script: "ALTER TABLE apps ADD COLUMN owner text", Checksum: "abc"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE apps ADD COLUMN owner text").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(2, "add_column", "abc").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.Nil(t, store.Apply(m))
+
+	// A failed migration is rolled back without being recorded
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE apps ADD COLUMN owner text").WillReturnError(errors.New("column exists"))
+	mock.ExpectRollback()
+	assert.NotNil(t, store.Apply(m))
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -24,6 +24,7 @@ import (
 	c "github.com/myntra/goscheduler/conf"
 	"github.com/myntra/goscheduler/dao"
 	p "github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/payload"
 	"github.com/myntra/goscheduler/retrieveriface"
 )
 
@@ -42,10 +43,10 @@ func (retriever Retrievers) Get(app string) retrieveriface.Retriever {
 	return retriever[_default]
 }
 
-func InitRetrievers(conf *c.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor p.Monitor) Retrievers {
+func InitRetrievers(conf *c.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor p.Monitor, payloads *payload.Store) Retrievers {
 	cronApp := conf.CronConfig.App
 	return Retrievers{
-		_default: ScheduleRetriever{config: &conf.Poller, clusterDao: clusterDao, scheduleDao: scheduleDao, monitor: monitor},
+		_default: ScheduleRetriever{config: &conf.Poller, clusterDao: clusterDao, scheduleDao: scheduleDao, monitor: monitor, payloads: payloads},
 		cronApp:  CronRetriever{scheduleDao: scheduleDao, cronConfig: &conf.CronConfig, monitor: monitor},
 	}
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/myntra/goscheduler/constants"
 	"github.com/myntra/goscheduler/dao"
 	p "github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/payload"
 	"github.com/myntra/goscheduler/store"
 )
 
@@ -39,6 +40,7 @@ type ScheduleRetriever struct {
 	scheduleDao dao.ScheduleDao
 	monitor     p.Monitor
 	config      *conf.PollerConfig
+	payloads    *payload.Store
 }
 
 func (s ScheduleRetriever) GetSchedules(appName string, partitionId int, timeBucket time.Time) (err error) {
@@ -60,39 +62,52 @@ func (s ScheduleRetriever) GetSchedules(appName string, partitionId int, timeBuc
 		return err
 	}
 
+	// Every page is handed over to the connectors as soon as it is read, higher priority schedules of the page first.
+	// Across pages the schedules go to the queue of their priority, from which the http workers take the higher ones first.
+	return s.forEachPage(appName, partitionId, timeBucket, func(schedules []store.Schedule) {
+		store.SortByPriority(schedules, app)
+		for _, sch := range schedules {
+			sch.Callback.Invoke(store.ScheduleWrapper{Schedule: sch, App: app, IsReconciliation: false})
+		}
+	})
+}
+
+// Reads the pages of the schedules of an app partition in a time bucket, up to the max query limit, and calls dispatch with each of them.
+// The schedules of a page read before an error are still dispatched.
+func (s ScheduleRetriever) forEachPage(appName string, partitionId int, timeBucket time.Time, dispatch func([]store.Schedule)) error {
 	pageState := []byte(nil)
 	queryCount := 0
-	totalSchedules := 0
 
 	for {
+		var schedules []store.Schedule
 		sch := store.Schedule{}
 		_map := make(map[string]interface{})
 		iter := s.scheduleDao.GetSchedulesForEntity(appName, partitionId, timeBucket, pageState)
-		scheduleCount := 0
 
 		for iter.MapScan(_map) {
 			if err := sch.CreateScheduleFromCassandraMap(_map); err != nil {
 				glog.Infof("Error while forming schedule from cassandra map: %+v, error: %s", _map, err.Error())
 				iter.Close()
+				dispatch(schedules)
 				return err
 			}
 
 			glog.V(constants.INFO).Infof("Got schedule: %+v, pageState: %+v", sch, iter.PageState())
-			sch.Callback.Invoke(store.ScheduleWrapper{Schedule: sch, App: app, IsReconciliation: false})
+			schedules = append(schedules, sch)
 
 			_map = make(map[string]interface{})
 			sch = store.Schedule{}
-			scheduleCount++
-			totalSchedules++
 		}
 
 		pageState = iter.PageState()
 		queryCount++
 
-		if err = iter.Close(); err != nil {
+		if err := iter.Close(); err != nil {
 			glog.Errorf("Error: %s while fetching schedules for app: %s, partitionId: %d, timeBucket: %v", err.Error(), appName, partitionId, timeBucket)
+			dispatch(schedules)
 			return err
 		}
+		dispatch(schedules)
 
 		if len(pageState) == 0 || queryCount > s.config.MaxQueryLimit {
 			if queryCount > s.config.MaxQueryLimit && s.monitor != nil {
@@ -102,11 +117,9 @@ func (s ScheduleRetriever) GetSchedules(appName string, partitionId int, timeBuc
 				}, 1)
 				glog.Errorf("Query count exceeded for app: %s, partitionId: %d, timeBucket: %v, with max Query limit: %v", appName, partitionId, timeBucket, s.config.MaxQueryLimit)
 			}
-			break
+			return nil
 		}
 	}
-
-	return nil
 }
 
 // Fetches data from DB for a given appId, partitionId, scheduleTimeGroup in paginated way
@@ -187,7 +200,7 @@ func (s ScheduleRetriever) BulkAction(app store.App, partitionId int, scheduleTi
 func contains(status []store.Status, sch store.Schedule) bool {
 	for _, v := range status {
 		switch v {
-		case store.Success, store.Failure, store.Miss, store.Scheduled:
+		case store.Success, store.Failure, store.Partial, store.Expired, store.Miss, store.Scheduled:
 			if v == sch.Status {
 				return true
 			}
@@ -220,7 +233,16 @@ func (s ScheduleRetriever) actionIfRequired(app store.App, schedules []store.Sch
 			case store.Reconcile:
 				_ = sch.Callback.Invoke(store.ScheduleWrapper{Schedule: sch, App: app, IsReconciliation: true})
 			case store.Delete:
-				_, _ = s.scheduleDao.DeleteSchedule(sch.ScheduleId)
+				_, err := s.scheduleDao.DeleteSchedule(sch.ScheduleId)
+				// pending usage is released when a schedule is fired, only release it for the ones yet to fire
+				if kind, counted := sch.UsageKind(); err == nil && counted && sch.Status == store.Scheduled && sch.ScheduleTime > time.Now().Unix() {
+					_ = s.scheduleDao.UpdateUsage(sch.AppId, kind, -1)
+				}
+				if err == nil && s.payloads != nil && payload.IsReference(sch.Payload) {
+					if err = s.payloads.Delete(sch.ScheduleId, sch.Payload); err != nil {
+						glog.Errorf("Deleting payload of schedule id %s failed with error %s", sch.ScheduleId.String(), err.Error())
+					}
+				}
 			}
 
 		}
@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package retrievers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/blob"
+	"github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/dao"
+	"github.com/myntra/goscheduler/payload"
+	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleRetriever_GetSchedulesByPriority(t *testing.T) {
+	store.Registry[constants.DefaultCallback] = func() store.Callback { return &store.HttpCallback{} }
+
+	config := conf.NewConfig()
+	clusterDao := dao.NewClusterDaoImplInMemory(config)
+	scheduleDao := dao.NewScheduleDaoImplInMemory(config)
+	scheduleDao.PageSize = 2
+
+	app := store.App{AppId: "priority", Partitions: 1, Active: true}
+	require.Nil(t, clusterDao.InsertApp(app))
+
+	// The pages are [low, high], [low, low] and [high]
+	bucket := time.Now().Add(time.Hour).Truncate(time.Minute)
+	priorities := []store.Priority{store.Low, store.High, store.Low, store.Low, store.High}
+	for _, priority := range priorities {
+		_, err := scheduleDao.CreateSchedule(store.Schedule{
+			ScheduleId:    gocql.TimeUUID(),
+			AppId:         app.AppId,
+			Payload:       "{}",
+			ScheduleTime:  bucket.Unix(),
+			ScheduleGroup: bucket.Unix(),
+			Priority:      priority,
+			Callback:      &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}},
+		}, app)
+		require.Nil(t, err)
+	}
+
+	queues := []*chan store.ScheduleWrapper{&store.HighPriorityHttpTaskQueue, &store.HttpTaskQueue, &store.LowPriorityHttpTaskQueue}
+	previous := make([]chan store.ScheduleWrapper, len(queues))
+	fired := make(chan store.ScheduleWrapper, len(priorities))
+	for i, queue := range queues {
+		previous[i] = *queue
+		*queue = fired
+	}
+	defer func() {
+		for i, queue := range queues {
+			*queue = previous[i]
+		}
+	}()
+
+	retriever := ScheduleRetriever{config: &config.Poller, clusterDao: clusterDao, scheduleDao: scheduleDao}
+	require.Nil(t, retriever.GetSchedules(app.AppId, 0, bucket))
+	close(fired)
+
+	var got []store.Priority
+	for wrapper := range fired {
+		got = append(got, wrapper.Schedule.Priority)
+	}
+	// Every page is dispatched as soon as it is read with its higher priority schedules first,
+	// the whole bucket is not held back to order it
+	assert.Equal(t, []store.Priority{store.High, store.Low, store.Low, store.Low, store.High}, got)
+}
+
+func TestScheduleRetriever_BulkDeletePayload(t *testing.T) {
+	store.Registry[constants.DefaultCallback] = func() store.Callback { return &store.HttpCallback{} }
+
+	config := conf.NewConfig()
+	clusterDao := dao.NewClusterDaoImplInMemory(config)
+	scheduleDao := dao.NewScheduleDaoImplInMemory(config)
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.Nil(t, err)
+	payloads := payload.NewStore(blobs, 16)
+
+	app := store.App{AppId: "bulk", Partitions: 1, Active: true}
+	require.Nil(t, clusterDao.InsertApp(app))
+
+	bucket := time.Now().Add(time.Hour).Truncate(time.Minute)
+	schedule := store.Schedule{
+		ScheduleId:    gocql.TimeUUID(),
+		AppId:         app.AppId,
+		ScheduleTime:  bucket.Unix(),
+		ScheduleGroup: bucket.Unix(),
+		Callback:      &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}},
+	}
+	schedule.Payload, _, err = payloads.Offload(schedule.ScheduleId, `{"large": "payload"}`, bucket.Add(time.Hour))
+	require.Nil(t, err)
+	_, err = scheduleDao.CreateSchedule(schedule, app)
+	require.Nil(t, err)
+
+	retriever := ScheduleRetriever{config: &config.Poller, clusterDao: clusterDao, scheduleDao: scheduleDao, payloads: payloads}
+	require.Nil(t, retriever.BulkAction(app, 0, bucket, []store.Status{store.Scheduled}, store.Delete))
+
+	_, err = scheduleDao.GetSchedule(schedule.ScheduleId)
+	assert.Equal(t, gocql.ErrNotFound, err)
+	_, err = payloads.Load(schedule.Payload)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
@@ -22,6 +22,8 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
+
 	"github.com/myntra/goscheduler/conf"
 	"github.com/myntra/goscheduler/scheduler"
 	"github.com/myntra/goscheduler/store"
@@ -34,6 +36,52 @@ func main() {
 	_ = flag.Set("stderrthreshold", "INFO")
 
 	config := conf.InitConfig(confFile, port, host)
+
+	// goscheduler [flags] migrate up|status
+	if flag.Arg(0) == "migrate" {
+		if err := scheduler.Migrate(config, flag.Arg(1), os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// goscheduler [flags] lookups backfill|check
+	if flag.Arg(0) == "lookups" {
+		if err := scheduler.Lookups(config, flag.Arg(1), os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// goscheduler [flags] usage recount [--app <app>]
+	if flag.Arg(0) == "usage" {
+		if err := scheduler.Usage(config, flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// goscheduler [flags] export --app <app> [--out <file>]
+	if flag.Arg(0) == "export" {
+		if err := scheduler.Export(config, flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// goscheduler [flags] import --in <file> [--app <app>] [--keep-ids] [--skip-past-due]
+	if flag.Arg(0) == "import" {
+		if err := scheduler.Import(config, flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	s := scheduler.New(config, map[string]store.Factory{})
 	s.Supervisor.WaitForTermination()
 }
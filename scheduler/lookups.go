@@ -0,0 +1,98 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/myntra/goscheduler/cassandra"
+	c "github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/dao"
+)
+
+// ErrLookupsInconsistent is returned by the lookup check if any lookup table has missing or stale rows.
+var ErrLookupsInconsistent = errors.New("lookup tables are inconsistent, run the backfill")
+
+// Lookups runs a command against the lookup tables of the Cassandra backend and writes the outcome to w.
+// The commands are "backfill", writing the lookup rows of the existing schedules and entities,
+// and "check", reporting the rows missing from or stale in the lookup tables and recording that they passed if none are.
+func Lookups(conf *c.Configuration, command string, w io.Writer) error {
+	if command != "backfill" && command != "check" {
+		return errors.New(fmt.Sprintf("unknown lookups command %q, expected backfill or check", command))
+	}
+	if conf.Storage.Backend != c.CassandraBackend {
+		return errors.New(fmt.Sprintf("the %s backend has no lookup tables", conf.Storage.Backend))
+	}
+
+	scheduleSession, err := cassandra.GetSessionInterface(conf.ScheduleDB.DBConfig, conf.ScheduleDB.ScheduleKeySpace)
+	if err != nil {
+		return err
+	}
+	defer scheduleSession.Close()
+
+	clusterSession, err := cassandra.GetSessionInterface(conf.ClusterDB.DBConfig, conf.ClusterDB.ClusterKeySpace)
+	if err != nil {
+		return err
+	}
+	defer clusterSession.Close()
+
+	scheduleDao := &dao.ScheduleDaoImpl{Session: scheduleSession, Conf: conf}
+	clusterDao := &dao.ClusterDaoImplCassandra{Session: clusterSession, Conf: conf}
+
+	runs := []func() (dao.LookupReport, error){scheduleDao.CheckScheduleLookups, clusterDao.CheckNodeLookups}
+	if command == "backfill" {
+		runs = []func() (dao.LookupReport, error){scheduleDao.BackfillScheduleLookups, scheduleDao.BackfillLabelLookups, clusterDao.BackfillNodeLookups}
+	}
+
+	consistent := true
+	for _, run := range runs {
+		report, err := run()
+		writeLookupReport(w, command, report)
+		if err != nil {
+			return err
+		}
+		consistent = consistent && report.Consistent()
+	}
+
+	if command == "check" && !consistent {
+		return ErrLookupsInconsistent
+	}
+	if command == "check" {
+		// Releases the migration dropping the materialized views replaced by the lookup tables
+		return cassandra.RecordLookupsCheck(clusterSession)
+	}
+	return nil
+}
+
+// writeLookupReport writes a line summarising the report followed by the sampled ids.
+func writeLookupReport(w io.Writer, command string, report dao.LookupReport) {
+	if command == "backfill" {
+		_, _ = fmt.Fprintf(w, "%s: read %d rows, wrote %d lookups\n", report.Table, report.Checked, report.Written)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "%s: read %d rows, %d missing, %d stale\n", report.Table, report.Checked, report.Missing, report.Stale)
+	if len(report.Samples) > 0 {
+		_, _ = fmt.Fprintf(w, "  sample ids: %s\n", strings.Join(report.Samples, ", "))
+	}
+}
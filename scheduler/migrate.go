@@ -0,0 +1,142 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/myntra/goscheduler/cassandra"
+	c "github.com/myntra/goscheduler/conf"
+	"github.com/myntra/goscheduler/migration"
+	"github.com/myntra/goscheduler/postgres"
+)
+
+// getMigrationStore gets the migration store and the migrations of the configured storage backend,
+// along with the function closing the store. The embedded backend has no migrations.
+func getMigrationStore(conf *c.Configuration) (migration.Store, []migration.Migration, func(), error) {
+	var store migration.Store
+	var migrations []migration.Migration
+	var closer func()
+	var err error
+
+	switch conf.Storage.Backend {
+	case c.EmbeddedBackend:
+		return nil, nil, nil, nil
+	case c.PostgresBackend:
+		if migrations, err = postgres.Migrations(); err != nil {
+			return nil, nil, nil, err
+		}
+		store, closer, err = postgres.GetMigrationStore(conf.Storage.Postgres)
+	default:
+		if migrations, err = cassandra.Migrations(); err != nil {
+			return nil, nil, nil, err
+		}
+		store, closer, err = cassandra.GetMigrationStore(conf.ClusterDB.DBConfig)
+	}
+
+	return store, migrations, closer, err
+}
+
+// initSchema applies the pending migrations of the storage backend if migrate is set,
+// and refuses to start otherwise if the schema is behind the binary.
+func initSchema(conf *c.Configuration, migrate bool) {
+	store, migrations, closer, err := getMigrationStore(conf)
+	if err != nil {
+		glog.Fatalf("Connecting to the storage for migrations failed with error %s", err.Error())
+	}
+	if store == nil {
+		return
+	}
+	defer closer()
+
+	if migrate {
+		if _, err = migration.Up(store, migrations); err != nil {
+			glog.Fatalf("Applying the schema migrations failed with error %s", err.Error())
+		}
+		return
+	}
+
+	if err = migration.Check(store, migrations); err != nil {
+		glog.Fatalf("Refusing to start: %s", err.Error())
+	}
+}
+
+// Migrate runs a migration command against the storage backend of the configuration and writes its outcome to w.
+// The commands are "up", applying the pending migrations, and "status", listing the state of every migration.
+func Migrate(conf *c.Configuration, command string, w io.Writer) error {
+	if command != "up" && command != "status" {
+		return errors.New(fmt.Sprintf("unknown migrate command %q, expected up or status", command))
+	}
+
+	store, migrations, closer, err := getMigrationStore(conf)
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return errors.New(fmt.Sprintf("the %s backend has no migrations", conf.Storage.Backend))
+	}
+	defer closer()
+
+	if command == "up" {
+		applied, err := migration.Up(store, migrations)
+		for _, m := range applied {
+			_, _ = fmt.Fprintf(w, "applied %d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		statuses, err := migration.GetStatus(store, migrations)
+		if err != nil {
+			return err
+		}
+		held := false
+		for _, status := range statuses {
+			if status.State == migration.Held {
+				_, _ = fmt.Fprintf(w, "held %d %s: %s\n", status.Version, status.Name, status.Reason)
+				held = true
+			}
+		}
+		if len(applied) == 0 && !held {
+			_, _ = fmt.Fprintln(w, "schema is up to date")
+		}
+		return nil
+	}
+
+	statuses, err := migration.GetStatus(store, migrations)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "VERSION\tNAME\tSTATE\tAPPLIED AT\tREASON")
+	for _, status := range statuses {
+		appliedAt := ""
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", status.Version, status.Name, status.State, appliedAt, status.Reason)
+	}
+	return tw.Flush()
+}
@@ -20,20 +20,27 @@
 package scheduler
 
 import (
-	"os"
-
+	"github.com/golang/glog"
 	"github.com/gorilla/mux"
-	"github.com/myntra/goscheduler/cassandra"
+	"github.com/myntra/goscheduler/archive"
+	"github.com/myntra/goscheduler/blob"
 	"github.com/myntra/goscheduler/cluster"
 	c "github.com/myntra/goscheduler/conf"
 	conn "github.com/myntra/goscheduler/connectors"
 	"github.com/myntra/goscheduler/dao"
 	m "github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/payload"
 	"github.com/myntra/goscheduler/poller"
 	r "github.com/myntra/goscheduler/retrievers"
 	"github.com/myntra/goscheduler/server"
 	s "github.com/myntra/goscheduler/service"
 	st "github.com/myntra/goscheduler/store"
+	"time"
+)
+
+const (
+	defaultPayloadThreshold  = 4096
+	defaultPayloadGCInterval = 3600
 )
 
 // Scheduler is a struct that holds pointers to various components of the scheduler.
@@ -47,23 +54,31 @@ type Scheduler struct {
 	Monitor    m.Monitor
 }
 
-// initCassandra initializes the Cassandra database with the given configuration and schema.
-func initCassandra(conf *c.Configuration, createSchema bool) {
+// initStorage initializes the schema of the configured storage backend, unless createSchema is unset.
+// The pending migrations are applied if the configuration allows migrating on start,
+// otherwise the scheduler refuses to start if the schema is behind the binary.
+func initStorage(conf *c.Configuration, createSchema bool) {
 	if createSchema {
-		cassandra.CassandraInit(conf.ClusterDB.DBConfig, os.Getenv("GOPATH")+conf.SchemaPath)
+		initSchema(conf, conf.Storage.MigrateOnStart)
 	}
 }
 
-// initDAOs creates and returns the implementation objects for the Cluster and Schedule data access objects.
+// initDAOs creates and returns the implementation objects for the Cluster and Schedule data access objects
+// of the configured storage backend.
 func initDAOs(conf *c.Configuration, monitor m.Monitor) (dao.ClusterDao, dao.ScheduleDao) {
-	clusterDao := dao.GetClusterDaoImpl(conf, monitor)
-	scheduleDao := dao.GetScheduleDaoImpl(conf, monitor)
-	return clusterDao, scheduleDao
+	switch conf.Storage.Backend {
+	case c.PostgresBackend:
+		return dao.GetClusterDaoImplPostgres(conf, monitor), dao.GetScheduleDaoImplPostgres(conf, monitor)
+	case c.EmbeddedBackend:
+		return dao.GetClusterDaoImplEmbedded(conf, monitor), dao.GetScheduleDaoImplEmbedded(conf, monitor)
+	default:
+		return dao.GetClusterDaoImpl(conf, monitor), dao.GetScheduleDaoImpl(conf, monitor)
+	}
 }
 
 // initRetrievers initializes the retrievers for the schedules and clusters using the configuration provided.
-func initRetrievers(conf *c.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor m.Monitor) r.Retrievers {
-	return r.InitRetrievers(conf, clusterDao, scheduleDao, monitor)
+func initRetrievers(conf *c.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor m.Monitor, payloads *payload.Store) r.Retrievers {
+	return r.InitRetrievers(conf, clusterDao, scheduleDao, monitor, payloads)
 }
 
 // initSupervisor creates a new Supervisor object that manages the cluster of nodes running the scheduler.
@@ -88,14 +103,65 @@ func initSupervisor(conf *c.Configuration, retrievers r.Retrievers, clusterDao d
 }
 
 // initConnectors creates the connector object used to communicate with the cluster nodes.
-func initConnectors(conf *c.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor m.Monitor, callbackWorkers bool) *conn.Connector {
+func initConnectors(conf *c.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor m.Monitor, payloads *payload.Store, callbackWorkers bool) *conn.Connector {
 	t := &st.Task{Conf: conf}
 	t.InitTaskQueues()
 	connector := conn.NewConnector(conf, clusterDao, scheduleDao, monitor)
+	connector.Payloads = payloads
 	connector.InitConnectors(callbackWorkers)
 	return connector
 }
 
+// initArchive starts the archiver of fired schedules if archival is enabled and returns the reader
+// serving the archived runs, or nil if archival is disabled.
+func initArchive(conf *c.Configuration, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, payloads *payload.Store) *archive.Reader {
+	if !conf.Archive.Enabled {
+		return nil
+	}
+
+	store, err := blob.NewFileStore(conf.Archive.Path)
+	if err != nil {
+		glog.Fatalf("Failed to open archive store at %s: %s", conf.Archive.Path, err.Error())
+	}
+
+	archive.NewArchiver(conf, clusterDao, scheduleDao, store, payloads).Start()
+	return archive.NewReader(store)
+}
+
+// openPayloadStore opens the store of the offloaded payloads if offloading is enabled, or returns nil if it is disabled.
+func openPayloadStore(conf *c.Configuration) *payload.Store {
+	if !conf.PayloadStore.Enabled {
+		return nil
+	}
+
+	blobs, err := blob.NewFileStore(conf.PayloadStore.Path)
+	if err != nil {
+		glog.Fatalf("Failed to open payload store at %s: %s", conf.PayloadStore.Path, err.Error())
+	}
+
+	threshold := conf.PayloadStore.Threshold
+	if threshold <= 0 {
+		threshold = defaultPayloadThreshold
+	}
+	return payload.NewStore(blobs, threshold)
+}
+
+// initPayloadStore opens the store of the offloaded payloads and starts deleting the expired payloads,
+// or returns nil if offloading is disabled.
+func initPayloadStore(conf *c.Configuration) *payload.Store {
+	payloads := openPayloadStore(conf)
+	if payloads == nil {
+		return nil
+	}
+
+	interval := conf.PayloadStore.GCInterval
+	if interval <= 0 {
+		interval = defaultPayloadGCInterval
+	}
+	payloads.Start(time.Duration(interval) * time.Second)
+	return payloads
+}
+
 // initService creates a new Service object that handles the scheduling logic and communication with the cluster nodes.
 func initService(conf *c.Configuration, supervisor cluster.SupervisorHandler, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor m.Monitor) *s.Service {
 	return s.NewService(conf, supervisor, clusterDao, scheduleDao, monitor)
@@ -115,17 +181,28 @@ func initCallbackRegistry(registry map[string]st.Factory) {
 	st.InitializeCallbackRegistry(registry)
 }
 
+// initCallbackNetworks sets the networks http callbacks are denied from reaching.
+func initCallbackNetworks(conf *c.Configuration) {
+	if err := st.InitializeCallbackNetworks(conf.HttpConnector.AllowPrivateNetworks, conf.HttpConnector.DeniedNetworks); err != nil {
+		glog.Fatalf("Invalid callback network configuration: %s", err.Error())
+	}
+}
+
 // New creates a new Scheduler instance with a given configuration and callback factories.
 // This is a base constructor that uses configuration and callback factory objects directly.
 func New(conf *c.Configuration, callbackFactories map[string]st.Factory) *Scheduler {
-	initCassandra(conf, true)
+	initStorage(conf, true)
 	initCallbackRegistry(callbackFactories)
+	initCallbackNetworks(conf)
 	monitor := initMonitoring()
 	clusterDao, schedulerDao := initDAOs(conf, monitor)
-	retrievers := initRetrievers(conf, clusterDao, schedulerDao, monitor)
+	payloads := initPayloadStore(conf)
+	retrievers := initRetrievers(conf, clusterDao, schedulerDao, monitor, payloads)
 	supervisor := initSupervisor(conf, retrievers, clusterDao, monitor)
-	connectors := initConnectors(conf, clusterDao, schedulerDao, monitor, true)
+	connectors := initConnectors(conf, clusterDao, schedulerDao, monitor, payloads, true)
 	service := initService(conf, supervisor, clusterDao, schedulerDao, monitor)
+	service.Archive = initArchive(conf, clusterDao, schedulerDao, payloads)
+	service.Payloads = payloads
 	router := mux.NewRouter().StrictSlash(true)
 	svr := initServer(conf, router, service)
 	go svr.StartServer()
@@ -143,12 +220,16 @@ func New(conf *c.Configuration, callbackFactories map[string]st.Factory) *Schedu
 // TODO: Reformat this constructor
 // NewScheduler creates a new Scheduler instance with a given params.
 func NewScheduler(conf *c.Configuration, callbackFactories map[string]st.Factory, clusterDao dao.ClusterDao, scheduleDao dao.ScheduleDao, monitor m.Monitor, createSchema bool, callbackWorkers bool) *Scheduler {
-	initCassandra(conf, createSchema)
+	initStorage(conf, createSchema)
 	initCallbackRegistry(callbackFactories)
-	retrievers := initRetrievers(conf, clusterDao, scheduleDao, monitor)
+	initCallbackNetworks(conf)
+	payloads := initPayloadStore(conf)
+	retrievers := initRetrievers(conf, clusterDao, scheduleDao, monitor, payloads)
 	supervisor := initSupervisor(conf, retrievers, clusterDao, monitor)
-	connectors := initConnectors(conf, clusterDao, scheduleDao, monitor, callbackWorkers)
+	connectors := initConnectors(conf, clusterDao, scheduleDao, monitor, payloads, callbackWorkers)
 	service := initService(conf, supervisor, clusterDao, scheduleDao, monitor)
+	service.Archive = initArchive(conf, clusterDao, scheduleDao, payloads)
+	service.Payloads = payloads
 	router := mux.NewRouter().StrictSlash(true)
 	initServer(conf, router, service)
 	return &Scheduler{
@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	c "github.com/myntra/goscheduler/conf"
+	s "github.com/myntra/goscheduler/service"
+	st "github.com/myntra/goscheduler/store"
+)
+
+// initTransferService creates the service used by the export and import commands, without joining the cluster.
+// Expired payloads are left to the garbage collection of the scheduler nodes.
+func initTransferService(conf *c.Configuration) *s.Service {
+	initCallbackRegistry(map[string]st.Factory{})
+	initCallbackNetworks(conf)
+	clusterDao, scheduleDao := initDAOs(conf, nil)
+	service := initService(conf, nil, clusterDao, scheduleDao, nil)
+	service.Payloads = openPayloadStore(conf)
+	return service
+}
+
+// Export writes the pending schedules of an app to an export file and writes the outcome to w.
+// The arguments are --app, the app to export, and --out, the path of the file, <app>.jsonl by default.
+func Export(conf *c.Configuration, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	appId := flags.String("app", "", "app whose schedules are exported")
+	out := flags.String("out", "", "path of the export file, <app>.jsonl by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *appId == "" {
+		return errors.New("missing --app")
+	}
+	if *out == "" {
+		*out = *appId + ".jsonl"
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+
+	// An interrupted export stops reading and keeps the schedules written so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	count, err := initTransferService(conf).ExportSchedules(ctx, *appId, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "exported %d schedules of %s to %s\n", count, *appId, *out)
+	return nil
+}
+
+// Import creates the schedules of an export file and writes the outcome to w.
+// The arguments are --in, the path of the file, --app, the app the schedules are created in, the app of the export by default,
+// and the --keep-ids and --skip-past-due options.
+// Returns an error if some schedules could not be created.
+func Import(conf *c.Configuration, args []string, w io.Writer) error {
+	var options s.ImportOptions
+
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	in := flags.String("in", "", "path of the export file")
+	appId := flags.String("app", "", "app the schedules are created in, the app of the export by default")
+	flags.BoolVar(&options.KeepIds, "keep-ids", false, "create the schedules with their exported ids")
+	flags.BoolVar(&options.SkipPastDue, "skip-past-due", false, "skip the one time schedules whose schedule time has passed")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		return errors.New("missing --in")
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := initTransferService(conf).ImportSchedules(*appId, file, options)
+	_, _ = fmt.Fprintf(w, "imported %d schedules, skipped %d, failed %d\n", report.Imported, report.Skipped, report.Failed)
+	for _, message := range report.Errors {
+		_, _ = fmt.Fprintf(w, "  %s\n", message)
+	}
+
+	switch {
+	case err != nil:
+		return err
+	case report.Failed > 0:
+		return errors.New(fmt.Sprintf("%d schedules could not be imported", report.Failed))
+	default:
+		return nil
+	}
+}
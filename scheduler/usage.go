@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	c "github.com/myntra/goscheduler/conf"
+)
+
+// Usage runs a command against the usage of the apps counted against their quotas and writes the outcome to w.
+// The command is the first argument, the only one is "recount", counting the schedules of an app, or of every app if --app is not given,
+// and correcting their stored usage. It is run once after upgrading to a version tracking the usage,
+// and can be run again at any time to repair a drifted usage.
+func Usage(conf *c.Configuration, args []string, w io.Writer) error {
+	if len(args) == 0 || args[0] != "recount" {
+		return errors.New("unknown usage command, expected recount")
+	}
+
+	flags := flag.NewFlagSet("usage recount", flag.ContinueOnError)
+	appId := flags.String("app", "", "app whose usage is recounted, every app by default")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	service := initTransferService(conf)
+	apps, err := service.ClusterDao.GetApps(*appId)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, app := range apps {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		recount, err := service.RecountUsage(ctx, app.AppId)
+		if err != nil {
+			failed++
+			_, _ = fmt.Fprintf(w, "%s: recount failed with error %s\n", app.AppId, err.Error())
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s: pending %d -> %d, recurring %d -> %d\n", app.AppId,
+			recount.Stored.PendingSchedules, recount.Counted.PendingSchedules,
+			recount.Stored.RecurringSchedules, recount.Counted.RecurringSchedules)
+	}
+
+	if failed > 0 {
+		return errors.New(fmt.Sprintf("the usage of %d apps could not be recounted", failed))
+	}
+	return nil
+}
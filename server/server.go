@@ -23,6 +23,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/myntra/goscheduler/constants"
 	"github.com/myntra/goscheduler/service"
+	"github.com/myntra/goscheduler/store"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"log"
 	"net/http"
@@ -72,71 +73,185 @@ func (s *Server) registerHTTPHandlers() {
 	s.router.HandleFunc("/goscheduler/healthcheck", service.HealthCheck)
 
 	s.router.HandleFunc("/goscheduler/schedules",
-		s.monitoringMiddleware(constants.CreateSchedule, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.CreateSchedule, s.service.Authenticate(store.AppWriter, func(w http.ResponseWriter, r *http.Request) {
 			s.service.Post(w, r)
-		}),
+		})),
 	).Methods("POST")
 
 	s.router.HandleFunc("/goscheduler/schedules/{scheduleId}",
-		s.monitoringMiddleware(constants.GetSchedule, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.GetSchedule, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
 			s.service.Get(w, r)
-		}),
+		})),
 	).Methods("GET")
 
 	s.router.HandleFunc("/goscheduler/schedules/{scheduleId}/runs",
-		s.monitoringMiddleware(constants.GetScheduleRuns, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.GetScheduleRuns, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
 			s.service.GetRuns(w, r)
-		}),
+		})),
 	).Methods("GET")
 
 	s.router.HandleFunc("/goscheduler/apps/{appId}/schedules",
-		s.monitoringMiddleware(constants.GetAppSchedule, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.GetLabelSchedules, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetLabelSchedules(w, r)
+		})),
+	).Methods("GET").Queries("label", "{label}")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/schedules",
+		s.monitoringMiddleware(constants.DeleteLabelSchedules, s.service.Authenticate(store.AppWriter, func(w http.ResponseWriter, r *http.Request) {
+			s.service.DeleteLabelSchedules(w, r)
+		})),
+	).Methods("DELETE")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/schedules",
+		s.monitoringMiddleware(constants.GetAppSchedule, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
 			s.service.GetAppSchedules(w, r)
-		}),
+		})),
 	).Methods("GET")
 
 	s.router.HandleFunc("/goscheduler/schedules/{scheduleId}",
-		s.monitoringMiddleware(constants.DeleteSchedule, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.DeleteSchedule, s.service.Authenticate(store.AppWriter, func(w http.ResponseWriter, r *http.Request) {
 			s.service.CancelSchedule(w, r)
-		}),
+		})),
 	).Methods("DELETE")
 
 	s.router.HandleFunc("/goscheduler/apps",
-		s.monitoringMiddleware(constants.RegisterApp, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.RegisterApp, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
 			s.service.Register(w, r)
-		}),
+		})),
 	).Methods("POST")
 
 	s.router.HandleFunc("/goscheduler/apps/{appId}/deactivate",
-		s.monitoringMiddleware(constants.DeactivateApp, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.DeactivateApp, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
 			s.service.Deactivate(w, r)
-		}),
+		})),
 	).Methods("POST")
 
 	s.router.HandleFunc("/goscheduler/apps/{appId}/activate",
-		s.monitoringMiddleware(constants.ActivateApp, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.ActivateApp, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
 			s.service.Activate(w, r)
-		}),
+		})),
 	).Methods("POST")
 
 	s.router.HandleFunc("/goscheduler/apps/{appId}/bulk-action/{action}",
-		s.monitoringMiddleware(constants.BulkAction, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.BulkAction, s.service.Authenticate(store.AppWriter, func(w http.ResponseWriter, r *http.Request) {
 			s.service.BulkAction(w, r)
-		}),
+		})),
+	).Methods("POST")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/configuration",
+		s.monitoringMiddleware(constants.CreateConfiguration, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.CreateConfiguration(w, r)
+		})),
+	).Methods("POST")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/configuration",
+		s.monitoringMiddleware(constants.GetConfiguration, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetConfiguration(w, r)
+		})),
+	).Methods("GET")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/configuration",
+		s.monitoringMiddleware(constants.UpdateConfiguration, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.UpdateConfiguration(w, r)
+		})),
+	).Methods("PATCH")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/configuration",
+		s.monitoringMiddleware(constants.DeleteConfiguration, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.DeleteConfiguration(w, r)
+		})),
+	).Methods("DELETE")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/configuration/history",
+		s.monitoringMiddleware(constants.GetConfigurationHistory, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetConfigurationHistory(w, r)
+		})),
+	).Methods("GET")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/archive/schedules",
+		s.monitoringMiddleware(constants.GetArchivedSchedules, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetArchivedSchedules(w, r)
+		})),
+	).Methods("GET")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/archive/schedules/{scheduleId}",
+		s.monitoringMiddleware(constants.GetArchivedSchedule, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetArchivedSchedule(w, r)
+		})),
+	).Methods("GET")
+
+	s.router.HandleFunc("/goscheduler/apps/{appId}/import",
+		s.monitoringMiddleware(constants.ImportSchedules, s.service.Authenticate(store.AppWriter, func(w http.ResponseWriter, r *http.Request) {
+			s.service.ImportAppSchedules(w, r)
+		})),
 	).Methods("POST")
 
+	s.router.HandleFunc("/goscheduler/apps/{appId}/usage",
+		s.monitoringMiddleware(constants.GetAppUsage, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetAppUsage(w, r)
+		})),
+	).Methods("GET")
+
 	s.router.HandleFunc("/goscheduler/apps",
-		s.monitoringMiddleware(constants.GetApps, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.GetApps, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
 			s.service.GetApps(w, r)
-		}),
+		})),
 	).Methods("GET")
 
 	s.router.HandleFunc("/goscheduler/crons/schedules",
-		s.monitoringMiddleware(constants.GetCronSchedule, func(w http.ResponseWriter, r *http.Request) {
+		s.monitoringMiddleware(constants.GetCronSchedule, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
 			s.service.GetCronSchedules(w, r)
-		}),
+		})),
+	).Methods("GET")
+
+	s.router.HandleFunc("/goscheduler/calendars",
+		s.monitoringMiddleware(constants.CreateCalendar, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.CreateCalendar(w, r)
+		})),
+	).Methods("POST")
+
+	s.router.HandleFunc("/goscheduler/calendars",
+		s.monitoringMiddleware(constants.GetCalendars, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetCalendars(w, r)
+		})),
 	).Methods("GET")
 
+	s.router.HandleFunc("/goscheduler/calendars/{name}",
+		s.monitoringMiddleware(constants.GetCalendar, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.GetCalendar(w, r)
+		})),
+	).Methods("GET")
+
+	s.router.HandleFunc("/goscheduler/calendars/{name}",
+		s.monitoringMiddleware(constants.DeleteCalendar, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.DeleteCalendar(w, r)
+		})),
+	).Methods("DELETE")
+
+	s.router.HandleFunc("/goscheduler/calendars/{name}/ical",
+		s.monitoringMiddleware(constants.ImportCalendar, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.ImportCalendar(w, r)
+		})),
+	).Methods("POST")
+
+	s.router.HandleFunc("/goscheduler/apikeys",
+		s.monitoringMiddleware(constants.CreateApiKey, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.CreateApiKey(w, r)
+		})),
+	).Methods("POST")
+
+	s.router.HandleFunc("/goscheduler/apikeys/{keyId}/rotate",
+		s.monitoringMiddleware(constants.RotateApiKey, s.service.Authenticate(store.AppReader, func(w http.ResponseWriter, r *http.Request) {
+			s.service.RotateApiKey(w, r)
+		})),
+	).Methods("POST")
+
+	s.router.HandleFunc("/goscheduler/apikeys/{keyId}",
+		s.monitoringMiddleware(constants.DeleteApiKey, s.service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) {
+			s.service.DeleteApiKey(w, r)
+		})),
+	).Methods("DELETE")
+
 	s.router.Handle("/metrics", promhttp.Handler())
 }
 
@@ -0,0 +1,160 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	er "github.com/myntra/goscheduler/error"
+	sch "github.com/myntra/goscheduler/store"
+)
+
+// ApiKeyRequest is the body of an api key issuance
+type ApiKeyRequest struct {
+	Apps []string `json:"apps"`
+	Role sch.Role `json:"role"`
+}
+
+// CreateApiKey issues a new api key for the given apps and role, the token is only ever returned in this response
+func (s *Service) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	var input ApiKeyRequest
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.recordRequestStatus(constants.CreateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.UnmarshalErrorCode, err))
+		return
+	}
+
+	if err = json.Unmarshal(b, &input); err != nil {
+		s.recordRequestStatus(constants.CreateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.UnmarshalErrorCode, err))
+		return
+	}
+
+	key, token, err := sch.NewApiKey(input.Apps, input.Role)
+	if err != nil {
+		s.recordRequestStatus(constants.CreateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+		return
+	}
+
+	if err = key.Validate(); err != nil {
+		s.recordRequestStatus(constants.CreateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, err))
+		return
+	}
+
+	for _, appId := range key.Apps {
+		if _, err = s.getApp(appId); err != nil {
+			s.recordRequestStatus(constants.CreateApiKey, constants.Fail)
+			er.Handle(w, r, err.(er.AppError))
+			return
+		}
+	}
+
+	s.saveApiKey(w, r, key, token, constants.CreateApiKey)
+}
+
+// RotateApiKey replaces the secret of an api key, the old token stops working right away.
+// Keys can be rotated by admins and by their own holders.
+func (s *Service) RotateApiKey(w http.ResponseWriter, r *http.Request) {
+	keyId := mux.Vars(r)["keyId"]
+
+	if key, ok := caller(r); ok && key.Role != sch.Admin && key.Id != keyId {
+		s.recordRequestStatus(constants.RotateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.Forbidden, fmt.Errorf("api key %s is not allowed to rotate api key %s", key.Id, keyId)))
+		return
+	}
+
+	key, err := s.ClusterDao.GetApiKey(keyId)
+	switch {
+	case err == gocql.ErrNotFound:
+		s.recordRequestStatus(constants.RotateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataNotFound, errors.New(fmt.Sprintf("api key %s not found", keyId))))
+		return
+	case err != nil:
+		s.recordRequestStatus(constants.RotateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataFetchFailure, err))
+		return
+	}
+
+	token, err := key.Rotate()
+	if err != nil {
+		s.recordRequestStatus(constants.RotateApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+		return
+	}
+
+	s.saveApiKey(w, r, key, token, constants.RotateApiKey)
+}
+
+func (s *Service) saveApiKey(w http.ResponseWriter, r *http.Request, key sch.ApiKey, token string, operation string) {
+	if err := s.ClusterDao.InsertApiKey(key); err != nil {
+		s.recordRequestStatus(operation, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+		return
+	}
+
+	s.recordRequestStatus(operation, constants.Success)
+	status := Status{StatusCode: constants.SuccessCode201, StatusMessage: constants.Success, StatusType: constants.Success, TotalCount: 1}
+	_ = json.NewEncoder(w).Encode(ApiKeyResponse{Status: status, Data: newApiKeyData(key, token)})
+}
+
+// DeleteApiKey revokes an api key
+func (s *Service) DeleteApiKey(w http.ResponseWriter, r *http.Request) {
+	keyId := mux.Vars(r)["keyId"]
+
+	if err := s.ClusterDao.DeleteApiKey(keyId); err != nil {
+		s.recordRequestStatus(constants.DeleteApiKey, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+		return
+	}
+
+	s.recordRequestStatus(constants.DeleteApiKey, constants.Success)
+	status := Status{StatusCode: constants.SuccessCode200, StatusMessage: constants.Success, StatusType: constants.Success, TotalCount: 1}
+	_ = json.NewEncoder(w).Encode(ApiKeyResponse{Status: status, Data: ApiKeyData{Id: keyId}})
+}
+
+func newApiKeyData(key sch.ApiKey, token string) ApiKeyData {
+	return ApiKeyData{
+		Id:        key.Id,
+		Apps:      key.Apps,
+		Role:      key.Role,
+		CreatedAt: timeOrNil(key.CreatedAt),
+		RotatedAt: timeOrNil(key.RotatedAt),
+		Key:       token,
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/store"
+)
+
+func TestService_CreateApiKey(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		Body   string
+		Status int
+	}{
+		{`{"apps": ["test"], "role": "app-writer"}`, http.StatusOK},
+		{`{"role": "admin"}`, http.StatusOK},
+		{`{"apps": ["test"], "role": "owner"}`, http.StatusBadRequest},
+		{`{"role": "app-reader"}`, http.StatusBadRequest},
+		{`{"apps": ["testGetAppErrorNotFound"], "role": "app-reader"}`, http.StatusBadRequest},
+		{`{"apps": ["apiKeyInsertFailure"], "role": "app-reader"}`, http.StatusInternalServerError},
+		{`{"apps": `, http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest("POST", "/goscheduler/apikeys", bytes.NewBufferString(test.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.CreateApiKey)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v", test.Body, status, test.Status)
+		}
+
+		if rr.Code == http.StatusOK {
+			var response ApiKeyResponse
+			if err = json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if id, _, err := store.ParseApiKey(response.Data.Key); err != nil || id != response.Data.Id {
+				t.Errorf("issued key %s does not belong to key id %s", response.Data.Key, response.Data.Id)
+			}
+		}
+	}
+}
+
+func TestService_RotateApiKey(t *testing.T) {
+	service := setupAuthMocks()
+
+	for _, test := range []struct {
+		Caller string
+		KeyId  string
+		Status int
+	}{
+		{"admin.secret", "app-writer", http.StatusOK},
+		{"app-writer.secret", "app-writer", http.StatusOK},
+		{"app-writer.secret", "app-reader", http.StatusForbidden},
+		{"admin.secret", "apiKeyNotFound", http.StatusNotFound},
+		{"admin.secret", "apiKeyFetchFailure", http.StatusInternalServerError},
+	} {
+		req, err := http.NewRequest("POST", "/goscheduler/apikeys/:keyId/rotate", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(constants.ApiKeyHeader, test.Caller)
+		req = mux.SetURLVars(req, map[string]string{"keyId": test.KeyId})
+
+		rr := httptest.NewRecorder()
+		service.Authenticate(store.AppReader, service.RotateApiKey).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s rotating %s: got %v want %v", test.Caller, test.KeyId, status, test.Status)
+		}
+	}
+}
+
+func TestService_DeleteApiKey(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		KeyId  string
+		Status int
+	}{
+		{"app-writer", http.StatusOK},
+		{"apiKeyDeleteFailure", http.StatusInternalServerError},
+	} {
+		req, err := http.NewRequest("DELETE", "/goscheduler/apikeys/:keyId", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"keyId": test.KeyId})
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.DeleteApiKey)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v", test.KeyId, status, test.Status)
+		}
+	}
+}
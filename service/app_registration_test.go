@@ -48,6 +48,7 @@ func setupMocks() *Service {
 				PayloadSize:                  1024,
 				HttpRetries:                  2,
 				HttpTimeout:                  500,
+				PastScheduleTolerance:        60,
 			},
 		},
 		Supervisor:  new(cluster.DummySupervisor),
@@ -0,0 +1,141 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/archive"
+	"github.com/myntra/goscheduler/constants"
+	er "github.com/myntra/goscheduler/error"
+)
+
+var errArchiveDisabled = errors.New("archival of schedules is not enabled")
+
+// GetArchivedSchedule returns the archived run of a schedule of an app
+func (s *Service) GetArchivedSchedule(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	appId := vars["appId"]
+
+	run, err := s.FetchArchivedSchedule(appId, vars["scheduleId"])
+	if err != nil {
+		s.recordRequestAppStatus(constants.GetArchivedSchedule, appId, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
+	s.recordRequestAppStatus(constants.GetArchivedSchedule, appId, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    1,
+	}
+	_ = json.NewEncoder(w).Encode(
+		GetArchivedScheduleResponse{
+			Status: status,
+			Data:   GetArchivedScheduleData{Run: run},
+		})
+}
+
+func (s *Service) FetchArchivedSchedule(appId string, uuid string) (archive.Record, error) {
+	if s.Archive == nil {
+		return archive.Record{}, er.NewError(er.DataNotFound, errArchiveDisabled)
+	}
+
+	scheduleId, err := gocql.ParseUUID(uuid)
+	if err != nil {
+		return archive.Record{}, er.NewError(er.InvalidDataCode, err)
+	}
+
+	switch run, err := s.Archive.Get(appId, scheduleId); err {
+	case archive.ErrNotFound:
+		return archive.Record{}, er.NewError(er.DataNotFound, err)
+	case nil:
+		return run, nil
+	default:
+		return archive.Record{}, er.NewError(er.DataFetchFailure, err)
+	}
+}
+
+// GetArchivedSchedules returns the archived runs of the schedules of an app based on time range
+func (s *Service) GetArchivedSchedules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	appId := vars["appId"]
+
+	size, _, timeRange, cursor, _, err := parse(r)
+	switch {
+	case err != nil:
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, err))
+		return
+	case size <= 0:
+		er.Handle(w, r, er.NewError(er.InvalidDataCode,
+			errors.New(fmt.Sprintf("Size provided(%d) should be greater than 0", size))))
+		return
+	case timeRange.EndTime.Before(timeRange.StartTime):
+		er.Handle(w, r, er.NewError(er.InvalidDataCode,
+			errors.New(fmt.Sprintf("End time: %s cannot be before start time: %s", timeRange.EndTime, timeRange.StartTime))))
+		return
+	case timeRange.EndTime.Sub(timeRange.StartTime).Seconds() > float64(defaultDays*24*60*60):
+		er.Handle(w, r, er.NewError(er.InvalidDataCode,
+			errors.New(fmt.Sprintf("Time range of more than %d days is not allowed", defaultDays))))
+		return
+	}
+
+	if s.Archive == nil {
+		s.recordRequestAppStatus(constants.GetArchivedSchedules, appId, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataNotFound, errArchiveDisabled))
+		return
+	}
+
+	runs, next, err := s.Archive.Query(appId, timeRange, int(size), string(cursor))
+	switch {
+	case err == archive.ErrInvalidCursor:
+		s.recordRequestAppStatus(constants.GetArchivedSchedules, appId, constants.Fail)
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, err))
+		return
+	case err != nil:
+		s.recordRequestAppStatus(constants.GetArchivedSchedules, appId, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataFetchFailure, err))
+		return
+	}
+
+	s.recordRequestAppStatus(constants.GetArchivedSchedules, appId, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    len(runs),
+	}
+	_ = json.NewEncoder(w).Encode(
+		GetArchivedSchedulesResponse{
+			Status: status,
+			Data: GetArchivedSchedulesData{
+				Runs:              runs,
+				ContinuationToken: hex.EncodeToString([]byte(next)),
+			},
+		})
+}
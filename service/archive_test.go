@@ -0,0 +1,164 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/archive"
+	"github.com/myntra/goscheduler/blob"
+	"github.com/myntra/goscheduler/store"
+)
+
+// setupArchive writes an archive object with a run of scheduleId of app test fired at 2023-01-10 10:05:00 IST
+func setupArchive(t *testing.T, scheduleId gocql.UUID) *archive.Reader {
+	blobs, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data bytes.Buffer
+	writer := gzip.NewWriter(&data)
+	if err := json.NewEncoder(writer).Encode(archive.Record{
+		Schedule: store.Schedule{
+			ScheduleId:   scheduleId,
+			AppId:        "test",
+			Payload:      "{}",
+			ScheduleTime: 1673325300,
+			Status:       store.Success,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := blobs.Put("schedules/test/2023-01-10/1673325000-1673328600-0.jsonl.gz", data.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	return archive.NewReader(blobs)
+}
+
+func TestService_GetArchivedSchedule(t *testing.T) {
+	scheduleId := gocql.UUIDFromTime(time.Unix(1673325000, 0))
+	service := setupMocks()
+
+	for _, test := range []struct {
+		Name       string
+		ScheduleId string
+		Archive    *archive.Reader
+		Status     int
+	}{
+		{"disabled", scheduleId.String(), nil, http.StatusNotFound},
+		{"invalid id", "invalid", setupArchive(t, scheduleId), http.StatusBadRequest},
+		{"not archived", gocql.TimeUUID().String(), setupArchive(t, scheduleId), http.StatusNotFound},
+		{"archived", scheduleId.String(), setupArchive(t, scheduleId), http.StatusOK},
+	} {
+		service.Archive = test.Archive
+
+		req, err := http.NewRequest("GET", "/goscheduler/apps/test/archive/schedules/"+test.ScheduleId, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"appId": "test", "scheduleId": test.ScheduleId})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.GetArchivedSchedule).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", test.Name, status, test.Status)
+		}
+	}
+}
+
+func TestService_GetArchivedSchedules(t *testing.T) {
+	scheduleId := gocql.UUIDFromTime(time.Unix(1673325000, 0))
+	service := setupMocks()
+	service.Archive = setupArchive(t, scheduleId)
+
+	for _, test := range []struct {
+		Name   string
+		Query  map[string]string
+		Status int
+		Count  int
+	}{
+		{"in range", map[string]string{"start_time": "2023-01-10 10:00:00", "end_time": "2023-01-10 11:00:00"}, http.StatusOK, 1},
+		{"out of range", map[string]string{"start_time": "2023-01-10 11:00:00", "end_time": "2023-01-10 12:00:00"}, http.StatusOK, 0},
+		{"invalid size", map[string]string{"size": "0"}, http.StatusBadRequest, 0},
+		{"invalid range", map[string]string{"start_time": "2023-01-10 11:00:00", "end_time": "2023-01-10 10:00:00"}, http.StatusBadRequest, 0},
+		{"invalid token", map[string]string{"continuation_token": "abcd"}, http.StatusBadRequest, 0},
+	} {
+		req, err := http.NewRequest("GET", "/goscheduler/apps/test/archive/schedules", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := req.URL.Query()
+		for key, value := range test.Query {
+			q.Add(key, value)
+		}
+		req.URL.RawQuery = q.Encode()
+		req = mux.SetURLVars(req, map[string]string{"appId": "test"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.GetArchivedSchedules).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", test.Name, status, test.Status)
+			continue
+		}
+		if test.Status != http.StatusOK {
+			continue
+		}
+
+		var response GetArchivedSchedulesResponse
+		if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if len(response.Data.Runs) != test.Count {
+			t.Errorf("%s: got %d runs want %d", test.Name, len(response.Data.Runs), test.Count)
+		}
+	}
+}
+
+func TestService_GetArchivedSchedulesDisabled(t *testing.T) {
+	service := setupMocks()
+
+	req, err := http.NewRequest("GET", "/goscheduler/apps/test/archive/schedules", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = mux.SetURLVars(req, map[string]string{"appId": "test"})
+
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.GetArchivedSchedules).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	er "github.com/myntra/goscheduler/error"
+	sch "github.com/myntra/goscheduler/store"
+)
+
+// Id of the key of callers authenticated with a bootstrap admin key
+const bootstrapKeyId = "bootstrap"
+
+type callerKey struct{}
+
+// Authenticate only lets callers with an api key of at least the given role through to the handler.
+// If the route has an appId, the key also needs to be scoped to that app.
+// The key is kept in the request context so that handlers can authorize apps only known after parsing the request.
+func (s *Service) Authenticate(role sch.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.Config.Auth.Enabled {
+			next(w, r)
+			return
+		}
+
+		key, err := s.authenticate(r)
+		if err != nil {
+			er.Handle(w, r, err.(er.AppError))
+			return
+		}
+
+		if !key.Role.Allows(role) {
+			er.Handle(w, r, er.NewError(er.Forbidden, fmt.Errorf("api key %s with role %s is not allowed to perform %s operations", key.Id, key.Role, role)))
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), callerKey{}, key))
+		if appId, ok := mux.Vars(r)["appId"]; ok {
+			if err = authorizeApp(r, appId, role); err != nil {
+				er.Handle(w, r, err.(er.AppError))
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+// authenticate resolves the api key of the request
+func (s *Service) authenticate(r *http.Request) (sch.ApiKey, error) {
+	token := r.Header.Get(constants.ApiKeyHeader)
+	if token == "" {
+		return sch.ApiKey{}, er.NewError(er.Unauthenticated, errors.New("missing api key"))
+	}
+
+	hash := sch.HashSecret(token)
+	for _, adminHash := range s.Config.Auth.AdminKeyHashes {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(adminHash)) == 1 {
+			return sch.ApiKey{Id: bootstrapKeyId, Role: sch.Admin}, nil
+		}
+	}
+
+	id, secret, err := sch.ParseApiKey(token)
+	if err != nil {
+		return sch.ApiKey{}, er.NewError(er.Unauthenticated, err)
+	}
+
+	key, err := s.ClusterDao.GetApiKey(id)
+	switch {
+	case err == gocql.ErrNotFound:
+		return sch.ApiKey{}, er.NewError(er.Unauthenticated, errors.New("invalid api key"))
+	case err != nil:
+		return sch.ApiKey{}, er.NewError(er.DataFetchFailure, err)
+	case !key.Matches(secret):
+		return sch.ApiKey{}, er.NewError(er.Unauthenticated, errors.New("invalid api key"))
+	}
+
+	return key, nil
+}
+
+// caller returns the api key of the request, false is returned if authentication is disabled
+func caller(r *http.Request) (sch.ApiKey, bool) {
+	key, ok := r.Context().Value(callerKey{}).(sch.ApiKey)
+	return key, ok
+}
+
+// authorizeSchedule checks if the caller of the request is allowed to perform operations requiring the given role on the app of the schedule.
+// The schedule is only fetched when authentication is enabled.
+func (s *Service) authorizeSchedule(r *http.Request, uuid string, role sch.Role) error {
+	if _, ok := caller(r); !ok {
+		return nil
+	}
+
+	schedule, err := s.GetSchedule(uuid)
+	if err != nil {
+		return err
+	}
+	return authorizeApp(r, schedule.AppId, role)
+}
+
+// authorizeApp checks if the caller of the request is allowed to perform operations requiring the given role on the app
+func authorizeApp(r *http.Request, appId string, role sch.Role) error {
+	key, ok := caller(r)
+	if !ok || key.CanAccess(appId, role) {
+		return nil
+	}
+
+	if appId == "" {
+		return er.NewError(er.Forbidden, fmt.Errorf("api key %s is only allowed to access its apps", key.Id))
+	}
+	return er.NewError(er.Forbidden, fmt.Errorf("api key %s is not allowed to perform %s operations on app %s", key.Id, role, appId))
+}
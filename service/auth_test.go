@@ -0,0 +1,161 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/store"
+)
+
+func setupAuthMocks() *Service {
+	service := setupMocks()
+	service.Config.Auth.Enabled = true
+	service.Config.Auth.AdminKeyHashes = []string{store.HashSecret("bootstrap-admin-key")}
+	return service
+}
+
+func TestService_Authenticate(t *testing.T) {
+	service := setupAuthMocks()
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	for _, test := range []struct {
+		Name   string
+		Key    string
+		Role   store.Role
+		AppId  string
+		Status int
+	}{
+		{"missing key", "", store.AppReader, "test", http.StatusUnauthorized},
+		{"malformed key", "secret", store.AppReader, "test", http.StatusUnauthorized},
+		{"unknown key", "apiKeyNotFound.secret", store.AppReader, "test", http.StatusUnauthorized},
+		{"wrong secret", "app-writer.wrong", store.AppReader, "test", http.StatusUnauthorized},
+		{"key fetch failure", "apiKeyFetchFailure.secret", store.AppReader, "test", http.StatusInternalServerError},
+		{"insufficient role", "app-reader.secret", store.AppWriter, "test", http.StatusForbidden},
+		{"other app", "app-writer.secret", store.AppWriter, "other", http.StatusForbidden},
+		{"own app", "app-writer.secret", store.AppWriter, "test", http.StatusOK},
+		{"admin", "admin.secret", store.Admin, "other", http.StatusOK},
+		{"bootstrap admin", "bootstrap-admin-key", store.Admin, "", http.StatusOK},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/goscheduler/apps/:appId/schedules", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.Key != "" {
+				req.Header.Set(constants.ApiKeyHeader, test.Key)
+			}
+			if test.AppId != "" {
+				req = mux.SetURLVars(req, map[string]string{"appId": test.AppId})
+			}
+
+			rr := httptest.NewRecorder()
+			service.Authenticate(test.Role, ok).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.Status {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.Status)
+			}
+		})
+	}
+}
+
+func TestService_AuthenticateDisabled(t *testing.T) {
+	service := setupMocks()
+
+	req, err := http.NewRequest("POST", "/goscheduler/apps", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	rr := httptest.NewRecorder()
+	service.Authenticate(store.Admin, func(w http.ResponseWriter, r *http.Request) { called = true }).ServeHTTP(rr, req)
+
+	if !called {
+		t.Errorf("handler was not called with authentication disabled")
+	}
+}
+
+func TestService_PostAuthorization(t *testing.T) {
+	service := setupAuthMocks()
+
+	for _, test := range []struct {
+		Key       string
+		AppId     string
+		Forbidden bool
+	}{
+		{"app-writer.secret", "other", true},
+		{"app-reader.secret", "test", true},
+		{"app-writer.secret", "test", false},
+		{"admin.secret", "other", false},
+	} {
+		body := `{"appId": "` + test.AppId + `", "payload": "{}", "scheduleTime": 2687947561, "callback": {"type": "http", "details": {"url": "https://dummy.url/test", "method": "GET"}}}`
+		req, err := http.NewRequest("POST", "/goscheduler/schedules", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(constants.ApiKeyHeader, test.Key)
+
+		rr := httptest.NewRecorder()
+		service.Authenticate(store.AppWriter, service.Post).ServeHTTP(rr, req)
+
+		if forbidden := rr.Code == http.StatusForbidden; forbidden != test.Forbidden {
+			t.Errorf("handler returned status code %v for %s on app %s, want forbidden %t", rr.Code, test.Key, test.AppId, test.Forbidden)
+		}
+	}
+}
+
+func TestService_GetAppsAuthorization(t *testing.T) {
+	service := setupAuthMocks()
+
+	for _, test := range []struct {
+		Key    string
+		Query  string
+		Status int
+	}{
+		{"app-reader.secret", "", http.StatusForbidden},
+		{"app-reader.secret", "other", http.StatusForbidden},
+		{"app-reader.secret", "test", http.StatusOK},
+		{"admin.secret", "", http.StatusOK},
+	} {
+		req, err := http.NewRequest("GET", "/goscheduler/apps", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := req.URL.Query()
+		if test.Query != "" {
+			q.Add("app_id", test.Query)
+		}
+		req.URL.RawQuery = q.Encode()
+		req.Header.Set(constants.ApiKeyHeader, test.Key)
+
+		rr := httptest.NewRecorder()
+		service.Authenticate(store.AppReader, service.GetApps).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s on app %q: got %v want %v", test.Key, test.Query, status, test.Status)
+		}
+	}
+}
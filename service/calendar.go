@@ -0,0 +1,181 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	er "github.com/myntra/goscheduler/error"
+	sch "github.com/myntra/goscheduler/store"
+)
+
+// CreateCalendar creates or replaces a calendar from its JSON representation
+func (s *Service) CreateCalendar(w http.ResponseWriter, r *http.Request) {
+	var calendar sch.Calendar
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.recordRequestStatus(constants.CreateCalendar, constants.Fail)
+		er.Handle(w, r, er.NewError(er.UnmarshalErrorCode, err))
+		return
+	}
+
+	if err = json.Unmarshal(b, &calendar); err != nil {
+		s.recordRequestStatus(constants.CreateCalendar, constants.Fail)
+		er.Handle(w, r, er.NewError(er.UnmarshalErrorCode, err))
+		return
+	}
+
+	s.saveCalendar(w, r, calendar, constants.CreateCalendar)
+}
+
+// ImportCalendar creates or replaces the calendar with the name in the path from an iCalendar document
+func (s *Service) ImportCalendar(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		s.recordRequestStatus(constants.ImportCalendar, constants.Fail)
+		er.Handle(w, r, er.NewError(er.UnmarshalErrorCode, err))
+		return
+	}
+
+	calendar, err := sch.ParseICal(name, bytes.NewReader(b))
+	if err != nil {
+		s.recordRequestStatus(constants.ImportCalendar, constants.Fail)
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, err))
+		return
+	}
+
+	s.saveCalendar(w, r, calendar, constants.ImportCalendar)
+}
+
+func (s *Service) saveCalendar(w http.ResponseWriter, r *http.Request, calendar sch.Calendar, operation string) {
+	if errs := calendar.Validate(); len(errs) != 0 {
+		s.recordRequestStatus(operation, constants.Fail)
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, errors.New(strings.Join(errs, ","))))
+		return
+	}
+
+	if err := s.ClusterDao.InsertCalendar(calendar); err != nil {
+		s.recordRequestStatus(operation, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+		return
+	}
+
+	s.recordRequestStatus(operation, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode201,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    1,
+	}
+	_ = json.NewEncoder(w).Encode(CalendarResponse{Status: status, Data: calendar})
+}
+
+// GetCalendar gets the calendar with the name in the path
+func (s *Service) GetCalendar(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	calendar, err := s.FetchCalendar(name)
+	if err != nil {
+		s.recordRequestStatus(constants.GetCalendar, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
+	s.recordRequestStatus(constants.GetCalendar, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    1,
+	}
+	_ = json.NewEncoder(w).Encode(CalendarResponse{Status: status, Data: calendar})
+}
+
+// FetchCalendar gets the calendar with the given name
+func (s *Service) FetchCalendar(name string) (sch.Calendar, error) {
+	calendar, err := s.ClusterDao.GetCalendar(name)
+	switch {
+	case err == gocql.ErrNotFound:
+		return sch.Calendar{}, er.NewError(er.DataNotFound, errors.New(fmt.Sprintf("calendar %s not found", name)))
+	case err != nil:
+		return sch.Calendar{}, er.NewError(er.DataFetchFailure, err)
+	default:
+		return calendar, nil
+	}
+}
+
+// GetCalendars gets all the calendars
+func (s *Service) GetCalendars(w http.ResponseWriter, r *http.Request) {
+	calendars, err := s.ClusterDao.GetCalendars()
+	if err != nil {
+		s.recordRequestStatus(constants.GetCalendars, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataFetchFailure, err))
+		return
+	}
+
+	s.recordRequestStatus(constants.GetCalendars, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    len(calendars),
+	}
+	_ = json.NewEncoder(w).Encode(GetCalendarsResponse{Status: status, Data: calendars})
+}
+
+// DeleteCalendar deletes the calendar with the name in the path.
+// Recurring schedules referring to a deleted calendar create all their runs.
+func (s *Service) DeleteCalendar(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	calendar, err := s.FetchCalendar(name)
+	if err != nil {
+		s.recordRequestStatus(constants.DeleteCalendar, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
+	if err = s.ClusterDao.DeleteCalendar(name); err != nil {
+		s.recordRequestStatus(constants.DeleteCalendar, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+		return
+	}
+
+	s.recordRequestStatus(constants.DeleteCalendar, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    1,
+	}
+	_ = json.NewEncoder(w).Encode(CalendarResponse{Status: status, Data: calendar})
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"bytes"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestService_CreateCalendar(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		Body   string
+		Status int
+	}{
+		{`{"name": "holidays", "dates": ["2023-12-25"], "weekend": ["Saturday", "Sunday"]}`, http.StatusOK},
+		{`{"name": "holidays", "dates": ["25-12-2023"]}`, http.StatusBadRequest},
+		{`{"name": "calendarInsertFailure"}`, http.StatusInternalServerError},
+		{`{"name": `, http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest("POST", "/goscheduler/calendars", bytes.NewBufferString(test.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.CreateCalendar)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v", test.Body, status, test.Status)
+		}
+	}
+}
+
+func TestService_ImportCalendar(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		Body   string
+		Status int
+	}{
+		{"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20231225\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n", http.StatusOK},
+		{"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:no start\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n", http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest("POST", "/goscheduler/calendars/:name/ical", bytes.NewBufferString(test.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"name": "holidays"})
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.ImportCalendar)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, test.Status)
+		}
+	}
+}
+
+func TestService_GetCalendar(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		Name   string
+		Status int
+	}{
+		{"holidays", http.StatusOK},
+		{"calendarNotFound", http.StatusNotFound},
+		{"calendarFetchFailure", http.StatusInternalServerError},
+	} {
+		req, err := http.NewRequest("GET", "/goscheduler/calendars/:name", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"name": test.Name})
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.GetCalendar)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v", test.Name, status, test.Status)
+		}
+	}
+}
+
+func TestService_DeleteCalendar(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		Name   string
+		Status int
+	}{
+		{"holidays", http.StatusOK},
+		{"calendarNotFound", http.StatusNotFound},
+		{"calendarDeleteFailure", http.StatusInternalServerError},
+	} {
+		req, err := http.NewRequest("DELETE", "/goscheduler/calendars/:name", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"name": test.Name})
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.DeleteCalendar)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v", test.Name, status, test.Status)
+		}
+	}
+}
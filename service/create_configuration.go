@@ -7,6 +7,7 @@ import (
 	"github.com/gocql/gocql"
 	"github.com/gorilla/mux"
 	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/dao"
 	er "github.com/myntra/goscheduler/error"
 	sch "github.com/myntra/goscheduler/store"
 	"io/ioutil"
@@ -20,7 +21,7 @@ func (s *Service) CreateConfiguration(w http.ResponseWriter, r *http.Request) {
 	var app sch.App
 
 	vars := mux.Vars(r)
-	appId := vars["app_id"]
+	appId := vars["appId"]
 	b, _ := ioutil.ReadAll(r.Body)
 	err = json.Unmarshal(b, &input)
 
@@ -43,11 +44,18 @@ func (s *Service) CreateConfiguration(w http.ResponseWriter, r *http.Request) {
 
 	default:
 		if config, err = s.ClusterDao.CreateConfigurations(app.AppId, input); err != nil {
-			er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+			code := er.DataPersistenceFailure
+			if errors.Is(err, dao.ErrInvalidConfiguration) {
+				code = er.ValidationFailCode
+			}
+			er.Handle(w, r, er.NewError(code, err))
 			s.recordRequestStatus(constants.CreateConfiguration, constants.Fail)
 			return
 		}
 
+		// Every node caches the app, including its configuration
+		s.Supervisor.AppDetailsUpdated(app.AppId)
+
 		s.recordRequestStatus(constants.CreateConfiguration, constants.Success)
 		status := Status{
 			StatusCode:    constants.SuccessCode201,
@@ -27,12 +27,19 @@ import (
 	er "github.com/myntra/goscheduler/error"
 	sch "github.com/myntra/goscheduler/store"
 	"net/http"
+	"time"
 )
 
 func (s *Service) CancelSchedule(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	uuid := vars["scheduleId"]
 
+	if err := s.authorizeSchedule(r, uuid, sch.AppWriter); err != nil {
+		s.recordRequestStatus(constants.DeleteSchedule, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
 	schedule, err := s.DeleteSchedule(uuid)
 	if err != nil {
 		s.recordRequestStatus(constants.DeleteSchedule, constants.Fail)
@@ -64,7 +71,7 @@ func (s *Service) DeleteSchedule(uuid string) (sch.Schedule, error) {
 		return sch.Schedule{}, er.NewError(er.InvalidDataCode, err)
 	}
 
-	switch schedule, err := s.ScheduleDao.DeleteSchedule(scheduleId); err {
+	switch schedule, err := s.deleteSchedule(scheduleId); err {
 	case gocql.ErrNotFound:
 		return sch.Schedule{}, er.NewError(er.DataNotFound, err)
 	case nil:
@@ -73,3 +80,19 @@ func (s *Service) DeleteSchedule(uuid string) (sch.Schedule, error) {
 		return sch.Schedule{}, er.NewError(er.DataFetchFailure, err)
 	}
 }
+
+// deleteSchedule deletes the schedule and releases its usage and offloaded payload.
+// Every delete of a single schedule goes through it so that the quotas of the app stay accurate.
+func (s *Service) deleteSchedule(scheduleId gocql.UUID) (sch.Schedule, error) {
+	schedule, err := s.ScheduleDao.DeleteSchedule(scheduleId)
+	if err != nil {
+		return schedule, err
+	}
+
+	// Usage of one time schedules which are due has already been released when they were fired
+	if schedule.IsRecurring() || schedule.ScheduleTime > time.Now().Unix() {
+		s.updateUsage(schedule, -1)
+	}
+	s.deletePayload(schedule)
+	return schedule, nil
+}
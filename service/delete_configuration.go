@@ -18,13 +18,13 @@ func (s *Service) DeleteConfiguration(w http.ResponseWriter, r *http.Request) {
 	var config sch.Configuration
 
 	vars := mux.Vars(r)
-	appId := vars["app_id"]
+	appId := vars["appId"]
 
 	app, err = s.ClusterDao.GetApp(appId)
 
 	switch {
 	case err == gocql.ErrNotFound:
-		er.Handle(w, r, er.NewError(er.InvalidAppId, errors.New(fmt.Sprintf("app %+v is not registered", app))))
+		er.Handle(w, r, er.NewError(er.InvalidAppId, errors.New(fmt.Sprintf("app id %s is not registered", appId))))
 		s.recordRequestStatus(constants.DeleteConfiguration, constants.Fail)
 
 	case err != nil:
@@ -38,6 +38,9 @@ func (s *Service) DeleteConfiguration(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		// Every node caches the app, including its configuration
+		s.Supervisor.AppDetailsUpdated(app.AppId)
+
 		s.recordRequestStatus(constants.DeleteConfiguration, constants.Success)
 		status := Status{
 			StatusCode:    constants.SuccessCode201,
@@ -30,13 +30,13 @@ func TestService_DeleteConfiguration(t *testing.T) {
 			http.StatusOK,
 		},
 	} {
-		req, err := http.NewRequest("DELETE", "/myss/app/:app_id/configuration", nil)
+		req, err := http.NewRequest("DELETE", "/goscheduler/apps/:appId/configuration", nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		vars := map[string]string{
-			"app_id": test.App,
+			"appId": test.App,
 		}
 
 		req = mux.SetURLVars(req, vars)
@@ -0,0 +1,218 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	er "github.com/myntra/goscheduler/error"
+	sch "github.com/myntra/goscheduler/store"
+	"github.com/myntra/goscheduler/util"
+)
+
+const (
+	// exportVersion is the version of the format of the export files
+	exportVersion = 1
+	// exportHorizonMargin extends the export past the future schedule creation period of the app,
+	// to cover the jitter applied to the schedule time of the schedules created near the end of the period
+	exportHorizonMargin = 24 * time.Hour
+	// exportReadConcurrency is the number of schedule time groups read at a time by the export and the usage recount
+	exportReadConcurrency = 16
+)
+
+// ExportHeader is the first line of an export file, the exported schedules follow one per line.
+type ExportHeader struct {
+	Version    int    `json:"version"`
+	AppId      string `json:"appId"`
+	ExportedAt int64  `json:"exportedAt"`
+}
+
+// ExportSchedules writes the pending schedules of an app to w, as JSON lines following an ExportHeader.
+// The active recurring schedules are written first, then the one time schedules which are not yet fired, in the order of
+// their schedule time group. The runs of the recurring schedules are not written, they are created again from the
+// recurring schedules once imported. Schedules are written with their callbacks, labels and status.
+// The export reads every schedule time group of the future schedule creation period, see forEachPendingGroup,
+// and stops once ctx is done.
+// Returns the number of schedules written, a non nil error if fetching or writing the schedules fails.
+func (s *Service) ExportSchedules(ctx context.Context, appId string, w io.Writer) (int, error) {
+	app, err := s.ClusterDao.GetApp(appId)
+	switch {
+	case err == gocql.ErrNotFound || (err == nil && len(app.AppId) == 0):
+		return 0, er.NewError(er.InvalidAppId, errors.New(fmt.Sprintf("app Id %s is not registered", appId)))
+	case err != nil:
+		return 0, er.NewError(er.DataFetchFailure, err)
+	}
+
+	recurring, errs := s.ScheduleDao.GetCronSchedulesByApp(appId, sch.Scheduled)
+	if len(errs) != 0 {
+		return 0, er.NewError(er.DataFetchFailure, errors.New(strings.Join(errs, ",")))
+	}
+
+	now := time.Now()
+	encoder := json.NewEncoder(w)
+	if err = encoder.Encode(ExportHeader{Version: exportVersion, AppId: appId, ExportedAt: now.Unix()}); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	// writeAll reads the labels of a group of schedules and writes them
+	writeAll := func(schedules []sch.Schedule) error {
+		if len(schedules) == 0 {
+			return nil
+		}
+
+		ids := make([]gocql.UUID, 0, len(schedules))
+		for _, schedule := range schedules {
+			ids = append(ids, schedule.ScheduleId)
+		}
+		labels, err := s.ScheduleDao.GetLabels(appId, ids)
+		if err != nil {
+			return er.NewError(er.DataFetchFailure, err)
+		}
+
+		for _, schedule := range schedules {
+			// Offloaded payloads are exported inline, the import offloads them again on the target
+			resolved, err := s.Payloads.Resolve(schedule.Payload)
+			if err != nil {
+				return err
+			}
+			schedule.Payload = resolved
+			schedule.Labels = labels[schedule.ScheduleId]
+			count++
+			if err = encoder.Encode(schedule); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err = writeAll(recurring); err != nil {
+		return count, err
+	}
+
+	err = s.forEachPendingGroup(ctx, app, now, writeAll)
+	return count, err
+}
+
+// forEachPendingGroup calls fn with the one time schedules of the app which are not yet fired, a schedule time group
+// of a partition at a time, in the order of the schedule time groups, from the group of now to the end of the future
+// schedule creation period of the app. Every group of every partition is read, populated or not, as the groups of a
+// partition are not listed anywhere: a 30 day period is about 44,600 groups, and as many queries, per partition.
+// They are read exportReadConcurrency groups at a time, and reading stops between them once ctx is done.
+// Stops at the first error of fn, which is returned as is.
+func (s *Service) forEachPendingGroup(ctx context.Context, app sch.App, now time.Time, fn func([]sch.Schedule) error) error {
+	start := now.Truncate(time.Minute)
+	end := now.Add(time.Duration(app.GetMaxTTL(s.Config.AppLevelConfiguration.FutureScheduleCreationPeriod))*time.Second + exportHorizonMargin)
+	partitions := int(app.Partitions)
+	total := (int(end.Sub(start)/time.Minute) + 1) * partitions
+	logged := 0
+
+	for first := 0; first < total; first += exportReadConcurrency {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		groups := make([][]sch.Schedule, exportReadConcurrency)
+		errs := make([]error, exportReadConcurrency)
+		var wg sync.WaitGroup
+		for i := 0; i < exportReadConcurrency && first+i < total; i++ {
+			bucket := start.Add(time.Duration((first+i)/partitions) * time.Minute)
+			partitionId := (first + i) % partitions
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				groups[i], errs[i] = s.fetchScheduleGroup(app.AppId, partitionId, bucket)
+			}(i)
+		}
+		wg.Wait()
+
+		for i := 0; i < exportReadConcurrency && first+i < total; i++ {
+			if errs[i] != nil {
+				return er.NewError(er.DataFetchFailure, errs[i])
+			}
+
+			pending := groups[i][:0]
+			for _, schedule := range groups[i] {
+				if !util.IsZeroUUID(schedule.ParentScheduleId) || (schedule.Status != "" && schedule.Status != sch.Scheduled) {
+					continue
+				}
+				pending = append(pending, schedule)
+			}
+			if err := fn(pending); err != nil {
+				return err
+			}
+		}
+
+		if read := first + exportReadConcurrency; read*10/total > logged && read < total {
+			logged = read * 10 / total
+			glog.Infof("Read %d of %d schedule time groups of app %s", read, total, app.AppId)
+		}
+	}
+
+	return nil
+}
+
+// fetchScheduleGroup gets the schedules of a partition of an app in a schedule time group, enriched with their status.
+func (s *Service) fetchScheduleGroup(appId string, partitionId int, bucket time.Time) ([]sch.Schedule, error) {
+	var schedules []sch.Schedule
+	var pageState []byte
+
+	for {
+		var page []sch.Schedule
+		_map := make(map[string]interface{})
+		iter := s.ScheduleDao.GetSchedulesForEntity(appId, partitionId, bucket, pageState)
+
+		for iter.MapScan(_map) {
+			var schedule sch.Schedule
+			if err := schedule.CreateScheduleFromCassandraMap(_map); err != nil {
+				_ = iter.Close()
+				return nil, err
+			}
+			page = append(page, schedule)
+			_map = make(map[string]interface{})
+		}
+
+		pageState = iter.PageState()
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+
+		if len(page) > 0 {
+			enriched, err := s.ScheduleDao.OptimizedEnrichSchedule(page)
+			if err != nil {
+				return nil, err
+			}
+			schedules = append(schedules, enriched...)
+		}
+
+		if len(pageState) == 0 {
+			return schedules, nil
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/dao"
+	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupInMemory gets a service over the in-memory DAOs, so a flow sees the writes of its earlier steps
+func setupInMemory() *Service {
+	service := setupMocks()
+	service.Config.Poller.DefaultCount = 1
+	service.ClusterDao = dao.NewClusterDaoImplInMemory(service.Config)
+	service.ScheduleDao = dao.NewScheduleDaoImplInMemory(service.Config)
+	return service
+}
+
+func serveSchedule(service *Service, handler http.HandlerFunc, method string, scheduleId string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/goscheduler/schedules/"+scheduleId, nil)
+	req = mux.SetURLVars(req, map[string]string{"scheduleId": scheduleId})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestService_ScheduleFlow(t *testing.T) {
+	service := setupInMemory()
+
+	_, err := service.RegisterApp(store.App{AppId: "flow", Partitions: 2, Active: true})
+	require.Nil(t, err)
+
+	scheduleTime := time.Now().Add(time.Hour)
+	schedule, err := service.CreateSchedule(store.Schedule{
+		AppId:        "flow",
+		Payload:      "{}",
+		ScheduleTime: scheduleTime.Unix(),
+		Callback:     &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}},
+	})
+	require.Nil(t, err)
+
+	rr := serveSchedule(service, service.Get, "GET", schedule.ScheduleId.String())
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response GetScheduleResponse
+	require.Nil(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, schedule.ScheduleId, response.Data.Schedule.ScheduleId)
+	assert.Equal(t, store.Scheduled, response.Data.Schedule.Status)
+
+	schedules, _, _, err := service.FetchAppSchedules("flow", dao.Range{StartTime: scheduleTime.Add(-time.Minute), EndTime: scheduleTime.Add(time.Minute)}, 10, "", nil, time.Unix(0, 0))
+	require.Nil(t, err)
+	require.Len(t, schedules, 1)
+	assert.Equal(t, schedule.ScheduleId, schedules[0].ScheduleId)
+
+	rr = serveSchedule(service, service.CancelSchedule, "DELETE", schedule.ScheduleId.String())
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = serveSchedule(service, service.Get, "GET", schedule.ScheduleId.String())
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
@@ -34,6 +34,12 @@ func (s *Service) Get(w http.ResponseWriter, r *http.Request) {
 	uuid := vars["scheduleId"]
 
 	schedule, err := s.GetSchedule(uuid)
+	if err == nil {
+		err = authorizeApp(r, schedule.AppId, sch.AppReader)
+	}
+	if err == nil {
+		err = s.resolvePayload(&schedule)
+	}
 	if err != nil {
 		s.recordRequestStatus(constants.GetSchedule, constants.Fail)
 		er.Handle(w, r, err.(er.AppError))
@@ -37,6 +37,12 @@ func parseAppQueryParams(r *http.Request) string {
 func (s *Service) GetApps(w http.ResponseWriter, r *http.Request) {
 	appId := parseAppQueryParams(r)
 
+	if err := authorizeApp(r, appId, store.AppReader); err != nil {
+		s.recordRequestStatus(constants.GetApps, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
 	apps, err := s.FetchApps(appId)
 	if err != nil {
 		s.recordRequestStatus(constants.GetApps, constants.Fail)
@@ -16,7 +16,7 @@ func (s *Service) GetConfiguration(w http.ResponseWriter, r *http.Request) {
 	var configuration sch.Configuration
 
 	vars := mux.Vars(r)
-	appId := vars["app_id"]
+	appId := vars["appId"]
 
 	app, err := s.ClusterDao.GetApp(appId)
 
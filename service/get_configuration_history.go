@@ -0,0 +1,73 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	er "github.com/myntra/goscheduler/error"
+)
+
+// GetConfigurationHistory returns the versioned changes to the configuration of an app, latest first
+func (s *Service) GetConfigurationHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	appId := vars["appId"]
+
+	app, err := s.ClusterDao.GetApp(appId)
+
+	switch {
+	case err == gocql.ErrNotFound:
+		er.Handle(w, r, er.NewError(er.InvalidAppId, errors.New(fmt.Sprintf("app id %s is not registered", appId))))
+		s.recordRequestStatus(constants.GetConfigurationHistory, constants.Fail)
+
+	case err != nil:
+		er.Handle(w, r, er.NewError(er.DataFetchFailure, err))
+		s.recordRequestStatus(constants.GetConfigurationHistory, constants.Fail)
+
+	default:
+		history, err := s.ClusterDao.GetConfigurationHistory(app.AppId)
+		if err != nil {
+			er.Handle(w, r, er.NewError(er.DataFetchFailure, err))
+			s.recordRequestStatus(constants.GetConfigurationHistory, constants.Fail)
+			return
+		}
+
+		s.recordRequestStatus(constants.GetConfigurationHistory, constants.Success)
+		status := Status{
+			StatusCode:    constants.SuccessCode200,
+			StatusMessage: constants.Success,
+			StatusType:    constants.Success,
+			TotalCount:    len(history),
+		}
+		_ = json.NewEncoder(w).Encode(GetConfigurationHistoryResponse{
+			Status: status,
+			Data: GetConfigurationHistoryData{
+				AppId:   app.AppId,
+				History: history,
+			},
+		})
+	}
+}
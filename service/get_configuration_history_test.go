@@ -0,0 +1,53 @@
+package service
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestService_GetConfigurationHistory(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		App    string
+		Status int
+	}{
+		{
+			"testGetAppErrorNotFound",
+			http.StatusBadRequest,
+		},
+		{
+			"testGetAppError",
+			http.StatusInternalServerError,
+		},
+		{
+			"testGetConfigurationHistoryError",
+			http.StatusInternalServerError,
+		},
+		{
+			"test",
+			http.StatusOK,
+		},
+	} {
+		req, err := http.NewRequest("GET", "/goscheduler/apps/:appId/configuration/history", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vars := map[string]string{
+			"appId": test.App,
+		}
+
+		req = mux.SetURLVars(req, vars)
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.GetConfigurationHistory)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, test.Status)
+		}
+	}
+}
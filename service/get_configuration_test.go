@@ -31,13 +31,13 @@ func TestService_GetConfiguration(t *testing.T) {
 			http.StatusOK,
 		},
 	} {
-		req, err := http.NewRequest("GET", "/myss/app/:app_id/configuration", nil)
+		req, err := http.NewRequest("GET", "/goscheduler/apps/:appId/configuration", nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		vars := map[string]string{
-			"app_id": test.App,
+			"appId": test.App,
 		}
 
 		req = mux.SetURLVars(req, vars)
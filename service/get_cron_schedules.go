@@ -44,6 +44,12 @@ func parseCron(r *http.Request) (string, sch.Status, error) {
 func (s *Service) GetCronSchedules(w http.ResponseWriter, r *http.Request) {
 	appId, status, _ := parseCron(r)
 
+	if err := authorizeApp(r, appId, sch.AppReader); err != nil {
+		s.recordRequestAppStatus(constants.GetCronSchedule, appId, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
 	cronSchedules, err := s.FetchCronSchedules(appId, status)
 	if err != nil {
 		s.recordRequestAppStatus(constants.GetCronSchedule, appId, constants.Fail)
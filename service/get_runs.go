@@ -83,6 +83,12 @@ func (s *Service) GetRuns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = s.authorizeSchedule(r, scheduleId, sch.AppReader); err != nil {
+		s.recordRequestStatus(constants.GetScheduleRuns, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
 	schedules, pageState, err := s.FetchCronRuns(scheduleId, size, when, pageState)
 	if err != nil {
 		s.recordRequestStatus(constants.GetScheduleRuns, constants.Fail)
@@ -0,0 +1,243 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/dao"
+	er "github.com/myntra/goscheduler/error"
+	"github.com/myntra/goscheduler/payload"
+	sch "github.com/myntra/goscheduler/store"
+)
+
+// maxImportErrors caps the errors listed in an import report
+const maxImportErrors = 100
+
+// ImportOptions controls how the schedules of an export file are created again.
+type ImportOptions struct {
+	// KeepIds creates the schedules with their exported ids, schedules whose id already exists are skipped.
+	// Otherwise new ids are generated.
+	KeepIds bool
+	// SkipPastDue skips the one time schedules whose schedule time has passed.
+	// Otherwise they are created to fire in the next minute.
+	SkipPastDue bool
+}
+
+// ImportReport counts the outcome of the schedules of an export file.
+type ImportReport struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+func (r *ImportReport) fail(schedule sch.Schedule, err error) {
+	r.Failed++
+	if len(r.Errors) < maxImportErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s: %s", schedule.ScheduleId, err.Error()))
+	}
+}
+
+// parseImportOptions parses the keep_ids and skip_past_due query params, both default to false
+func parseImportOptions(r *http.Request) (ImportOptions, error) {
+	var options ImportOptions
+	var err error
+
+	query := r.URL.Query()
+	for param, option := range map[string]*bool{"keep_ids": &options.KeepIds, "skip_past_due": &options.SkipPastDue} {
+		if value := query.Get(param); value != "" {
+			if *option, err = strconv.ParseBool(value); err != nil {
+				return options, errors.New(fmt.Sprintf("Invalid %s query parameter %s", param, value))
+			}
+		}
+	}
+
+	return options, nil
+}
+
+// ImportAppSchedules creates the schedules of the export file in the request body in an app
+func (s *Service) ImportAppSchedules(w http.ResponseWriter, r *http.Request) {
+	appId := mux.Vars(r)["appId"]
+
+	options, err := parseImportOptions(r)
+	if err != nil {
+		s.recordRequestAppStatus(constants.ImportSchedules, appId, constants.Fail)
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, err))
+		return
+	}
+
+	report, err := s.ImportSchedules(appId, r.Body, options)
+	if err != nil {
+		s.recordRequestAppStatus(constants.ImportSchedules, appId, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
+	s.recordRequestAppStatus(constants.ImportSchedules, appId, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    report.Imported,
+	}
+	_ = json.NewEncoder(w).Encode(
+		ImportSchedulesResponse{
+			Status: status,
+			Data:   report,
+		})
+}
+
+// ImportSchedules creates the schedules of an export file read from r in an app, the app of the export if appId is empty.
+// Schedules are validated against the app like new schedules, except for the rate limit of the app, and
+// their schedule time is kept as exported. A schedule which cannot be created is counted as failed and
+// the import goes on with the next one.
+// Returns a non nil error if the app cannot be fetched or the export file cannot be read.
+func (s *Service) ImportSchedules(appId string, r io.Reader, options ImportOptions) (ImportReport, error) {
+	var report ImportReport
+
+	decoder := json.NewDecoder(r)
+
+	var header ExportHeader
+	if err := decoder.Decode(&header); err != nil {
+		return report, er.NewError(er.InvalidDataCode, errors.New(fmt.Sprintf("invalid export header: %s", err.Error())))
+	}
+	if header.Version != exportVersion {
+		return report, er.NewError(er.InvalidDataCode, errors.New(fmt.Sprintf("unsupported export version %d", header.Version)))
+	}
+
+	if appId == "" {
+		appId = header.AppId
+	}
+	app, err := s.getApp(appId)
+	if err != nil {
+		return report, err
+	}
+
+	for {
+		var schedule sch.Schedule
+		switch err := decoder.Decode(&schedule); {
+		case err == io.EOF:
+			return report, nil
+		case err != nil:
+			return report, er.NewError(er.InvalidDataCode, errors.New(fmt.Sprintf("invalid schedule after %d schedules: %s", report.Imported+report.Skipped+report.Failed, err.Error())))
+		}
+
+		switch skipped, err := s.importSchedule(app, schedule, options, time.Now()); {
+		case err != nil:
+			report.fail(schedule, err)
+		case skipped:
+			report.Skipped++
+		default:
+			report.Imported++
+		}
+	}
+}
+
+// importSchedule creates an exported schedule in the app.
+// Returns whether the schedule was skipped, a non nil error if it could not be created.
+func (s *Service) importSchedule(app sch.App, input sch.Schedule, options ImportOptions, now time.Time) (bool, error) {
+	input.AppId = app.AppId
+	input.Deduplicated = false
+
+	if !input.IsRecurring() && input.ScheduleTime <= now.Unix() {
+		nextMinute := 60 * (now.Unix()/60 + 1)
+		if options.SkipPastDue || (input.Deadline != 0 && input.Deadline < nextMinute) {
+			return true, nil
+		}
+		input.ScheduleTime = nextMinute
+	}
+
+	if input.Callback == nil {
+		return false, errors.New("missing callback")
+	}
+
+	if errs := input.ValidateSchedule(app, s.Config.AppLevelConfiguration); len(errs) > 0 {
+		return false, errors.New(strings.Join(errs, ","))
+	}
+
+	if payload.IsReference(input.Payload) {
+		return false, errPayloadReference
+	}
+
+	if input.Calendar != "" {
+		if _, err := s.FetchCalendar(input.Calendar); err != nil {
+			return false, err
+		}
+	}
+
+	if err := s.checkQuota(app, input); err != nil {
+		return false, err
+	}
+
+	if input.IsRecurring() {
+		cronApp, err := s.getApp(s.Config.CronConfig.App)
+		if err != nil {
+			return false, err
+		}
+		app = cronApp
+	}
+
+	if options.KeepIds {
+		switch _, err := s.ScheduleDao.GetSchedule(input.ScheduleId); {
+		case err == nil:
+			return true, nil
+		case err != gocql.ErrNotFound:
+			return false, err
+		}
+		input.SetPartition(app)
+	} else {
+		input.SetFields(app)
+	}
+
+	offloaded, err := s.offloadPayload(&input, app)
+	if err != nil {
+		return false, err
+	}
+
+	schedule, err := s.ScheduleDao.CreateSchedule(input, app)
+	// A schedule imported with a duplicate id shares the payload key of the existing schedule
+	if offloaded && err != dao.ErrDuplicateSchedule && (err != nil || schedule.Deduplicated) {
+		s.deletePayload(input)
+	}
+
+	switch {
+	case err == dao.ErrDuplicateSchedule || (err == nil && schedule.Deduplicated):
+		return true, nil
+	case err != nil:
+		return false, err
+	}
+
+	s.updateUsage(schedule, 1)
+	if schedule.Replaced != nil {
+		s.deletePayload(*schedule.Replaced)
+	}
+	return false, nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	er "github.com/myntra/goscheduler/error"
+	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTransfer gets a service over the in-memory DAOs with the app and the cron app registered
+func setupTransfer(t *testing.T, appId string, partitions uint32) *Service {
+	service := setupInMemory()
+	for _, app := range []store.App{{AppId: appId, Partitions: partitions, Active: true}, {AppId: service.Config.CronConfig.App, Partitions: 1, Active: true}} {
+		_, err := service.RegisterApp(app)
+		require.Nil(t, err)
+	}
+	return service
+}
+
+func transferSchedule(appId string, scheduleTime time.Time) store.Schedule {
+	callback := &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}}
+	raw, _ := json.Marshal(callback)
+	return store.Schedule{
+		AppId:        appId,
+		Payload:      "{}",
+		ScheduleTime: scheduleTime.Unix(),
+		Callback:     callback,
+		CallbackRaw:  raw,
+	}
+}
+
+func TestService_ExportImportSchedules(t *testing.T) {
+	source := setupTransfer(t, "transfer", 2)
+	now := time.Now()
+
+	labelled := transferSchedule("transfer", now.Add(time.Hour))
+	labelled.Labels = map[string]string{"tier": "gold"}
+	labelled, err := source.CreateSchedule(labelled)
+	require.Nil(t, err)
+
+	pending, err := source.CreateSchedule(transferSchedule("transfer", now.Add(2*time.Hour)))
+	require.Nil(t, err)
+
+	recurring := transferSchedule("transfer", time.Unix(0, 0))
+	recurring.CronExpression = "*/5 * * * *"
+	recurring, err = source.CreateSchedule(recurring)
+	require.Nil(t, err)
+
+	fired, err := source.CreateSchedule(transferSchedule("transfer", now.Add(3*time.Hour)))
+	require.Nil(t, err)
+	fired.Status = store.Success
+	require.Nil(t, source.ScheduleDao.UpdateStatus([]store.Schedule{fired}, store.App{AppId: "transfer"}))
+
+	var export bytes.Buffer
+	count, err := source.ExportSchedules(context.Background(), "transfer", &export)
+	require.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	_, err = source.ExportSchedules(context.Background(), "unknown", &bytes.Buffer{})
+	assert.Equal(t, er.InvalidAppId, err.(er.AppError).Code)
+
+	target := setupTransfer(t, "transfer", 4)
+
+	report, err := target.ImportSchedules("", bytes.NewReader(export.Bytes()), ImportOptions{KeepIds: true})
+	require.Nil(t, err)
+	assert.Equal(t, ImportReport{Imported: 3}, report)
+
+	for _, schedule := range []store.Schedule{labelled, pending, recurring} {
+		imported, err := target.ScheduleDao.GetSchedule(schedule.ScheduleId)
+		require.Nil(t, err)
+		assert.Equal(t, schedule.ScheduleTime, imported.ScheduleTime)
+		assert.Equal(t, schedule.CronExpression, imported.CronExpression)
+	}
+	_, err = target.ScheduleDao.GetSchedule(fired.ScheduleId)
+	assert.Equal(t, gocql.ErrNotFound, err)
+
+	labels, err := target.ScheduleDao.GetLabels("transfer", []gocql.UUID{labelled.ScheduleId, pending.ScheduleId})
+	require.Nil(t, err)
+	assert.Equal(t, labelled.Labels, labels[labelled.ScheduleId])
+
+	// Schedules whose id exists are skipped, so an import can be run again
+	report, err = target.ImportSchedules("transfer", bytes.NewReader(export.Bytes()), ImportOptions{KeepIds: true})
+	require.Nil(t, err)
+	assert.Equal(t, ImportReport{Skipped: 3}, report)
+
+	report, err = target.ImportSchedules("transfer", bytes.NewReader(export.Bytes()), ImportOptions{})
+	require.Nil(t, err)
+	assert.Equal(t, ImportReport{Imported: 3}, report)
+
+	usage, err := target.ScheduleDao.GetUsage("transfer")
+	require.Nil(t, err)
+	assert.Equal(t, int64(4), usage.Get(store.PendingUsage))
+}
+
+func TestService_ExportOrder(t *testing.T) {
+	service := setupTransfer(t, "transfer", 3)
+	now := time.Now()
+
+	var created []int64
+	for _, offset := range []time.Duration{4 * time.Hour, time.Hour, 3 * time.Hour, 2 * time.Hour, 5 * time.Hour} {
+		schedule, err := service.CreateSchedule(transferSchedule("transfer", now.Add(offset)))
+		require.Nil(t, err)
+		created = append(created, schedule.ScheduleTime)
+	}
+
+	var export bytes.Buffer
+	count, err := service.ExportSchedules(context.Background(), "transfer", &export)
+	require.Nil(t, err)
+	assert.Equal(t, 5, count)
+
+	// The groups of all the partitions are written in the order of their schedule time group
+	decoder := json.NewDecoder(&export)
+	require.Nil(t, decoder.Decode(&ExportHeader{}))
+	var exported []int64
+	for decoder.More() {
+		var schedule store.Schedule
+		require.Nil(t, decoder.Decode(&schedule))
+		exported = append(exported, schedule.ScheduleTime)
+	}
+	sort.Slice(created, func(i, j int) bool { return created[i] < created[j] })
+	assert.Equal(t, created, exported)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = service.ExportSchedules(ctx, "transfer", &bytes.Buffer{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestService_ImportPastDueSchedules(t *testing.T) {
+	now := time.Now()
+	pastDue := transferSchedule("transfer", now.Add(-10*time.Minute))
+	pastDue.ScheduleId = gocql.TimeUUID()
+	expired := transferSchedule("transfer", now.Add(-10*time.Minute))
+	expired.ScheduleId = gocql.TimeUUID()
+	expired.Deadline = now.Add(-5 * time.Minute).Unix()
+
+	var export bytes.Buffer
+	encoder := json.NewEncoder(&export)
+	for _, line := range []interface{}{ExportHeader{Version: exportVersion, AppId: "transfer", ExportedAt: now.Unix()}, pastDue, expired} {
+		require.Nil(t, encoder.Encode(line))
+	}
+
+	service := setupTransfer(t, "transfer", 1)
+
+	report, err := service.ImportSchedules("transfer", bytes.NewReader(export.Bytes()), ImportOptions{KeepIds: true, SkipPastDue: true})
+	require.Nil(t, err)
+	assert.Equal(t, ImportReport{Skipped: 2}, report)
+
+	report, err = service.ImportSchedules("transfer", bytes.NewReader(export.Bytes()), ImportOptions{KeepIds: true})
+	require.Nil(t, err)
+	assert.Equal(t, ImportReport{Imported: 1, Skipped: 1}, report)
+
+	imported, err := service.ScheduleDao.GetSchedule(pastDue.ScheduleId)
+	require.Nil(t, err)
+	assert.True(t, imported.ScheduleTime > now.Unix())
+	assert.Equal(t, int64(0), imported.ScheduleTime%60)
+}
+
+func TestService_ImportAppSchedules(t *testing.T) {
+	service := setupTransfer(t, "transfer", 1)
+	header := fmt.Sprintf(`{"version":%d,"appId":"transfer"}`, exportVersion)
+	invalid := transferSchedule("transfer", time.Now().Add(time.Hour))
+	invalid.Payload = ""
+	line, err := json.Marshal(invalid)
+	require.Nil(t, err)
+
+	for _, test := range []struct {
+		Name   string
+		Query  string
+		Body   string
+		Status int
+		Report ImportReport
+	}{
+		{"invalid option", "keep_ids=maybe", header, http.StatusBadRequest, ImportReport{}},
+		{"invalid header", "", "{", http.StatusBadRequest, ImportReport{}},
+		{"unsupported version", "", `{"version":0}`, http.StatusBadRequest, ImportReport{}},
+		{"invalid schedule", "", header + "\n" + string(line), http.StatusOK, ImportReport{Failed: 1}},
+		{"empty", "keep_ids=true&skip_past_due=true", header, http.StatusOK, ImportReport{}},
+	} {
+		req := httptest.NewRequest("POST", "/goscheduler/apps/transfer/import?"+test.Query, strings.NewReader(test.Body))
+		req = mux.SetURLVars(req, map[string]string{"appId": "transfer"})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.ImportAppSchedules).ServeHTTP(rr, req)
+
+		require.Equal(t, test.Status, rr.Code, test.Name)
+		if test.Status != http.StatusOK {
+			continue
+		}
+
+		var response ImportSchedulesResponse
+		require.Nil(t, json.NewDecoder(rr.Body).Decode(&response))
+		assert.Equal(t, test.Report.Failed, response.Data.Failed, test.Name)
+		assert.Len(t, response.Data.Errors, test.Report.Failed, test.Name)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gocql/gocql"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	er "github.com/myntra/goscheduler/error"
+	sch "github.com/myntra/goscheduler/store"
+)
+
+const defaultLabelPageSize int64 = 15
+
+// parse the label selectors, size and continuation token query params
+// return error if any of them cannot be parsed
+func parseLabelQuery(r *http.Request) ([]string, int64, []byte, error) {
+	query := r.URL.Query()
+
+	selectors := query["label"]
+	if len(selectors) == 0 {
+		return nil, 0, nil, errors.New("at least one label selector is required")
+	}
+	for _, selector := range selectors {
+		if _, _, err := sch.ParseLabelSelector(selector); err != nil {
+			return nil, 0, nil, err
+		}
+	}
+
+	size := defaultLabelPageSize
+	if sizeParam := query.Get("size"); len(sizeParam) > 0 {
+		var err error
+		if size, err = strconv.ParseInt(sizeParam, 10, 64); err != nil {
+			return nil, 0, nil, err
+		}
+		if size <= 0 {
+			return nil, 0, nil, errors.New(fmt.Sprintf("Size provided(%d) should be greater than 0", size))
+		}
+	}
+
+	var pageState []byte
+	if continuationToken := query.Get("continuation_token"); continuationToken != "" {
+		var err error
+		if pageState, err = hex.DecodeString(continuationToken); err != nil {
+			return nil, 0, nil, errors.New(fmt.Sprintf("Invalid page token: %s", continuationToken))
+		}
+	}
+
+	return selectors, size, pageState, nil
+}
+
+// get the schedules of an app having all the labels in the label query params
+func (s *Service) GetLabelSchedules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	appId := vars["appId"]
+
+	selectors, size, pageState, err := parseLabelQuery(r)
+	if err != nil {
+		s.recordRequestAppStatus(constants.GetLabelSchedules, appId, constants.Fail)
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, err))
+		return
+	}
+
+	schedules, pageState, err := s.FetchLabelSchedules(appId, selectors, size, pageState)
+	if err != nil {
+		s.recordRequestAppStatus(constants.GetLabelSchedules, appId, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
+	s.recordRequestAppStatus(constants.GetLabelSchedules, appId, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    len(schedules),
+	}
+	data := GetPaginatedRunSchedulesData{
+		Schedules:         schedules,
+		ContinuationToken: hex.EncodeToString(pageState),
+	}
+	_ = json.NewEncoder(w).Encode(
+		GetPaginatedRunSchedulesResponse{
+			Status: status,
+			Data:   data,
+		})
+}
+
+func (s *Service) FetchLabelSchedules(appId string, selectors []string, size int64, pageState []byte) ([]sch.Schedule, []byte, error) {
+	if _, err := s.getActiveOrInactiveApp(appId); err != nil {
+		return []sch.Schedule{}, nil, err
+	}
+
+	schedules, pageState, err := s.ScheduleDao.GetSchedulesByLabel(appId, selectors, size, pageState)
+	if err != nil {
+		return []sch.Schedule{}, nil, er.NewError(er.DataFetchFailure, err)
+	}
+
+	return schedules, pageState, nil
+}
+
+// delete all the schedules of an app having all the labels in the label query params
+func (s *Service) DeleteLabelSchedules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	appId := vars["appId"]
+
+	selectors, _, _, err := parseLabelQuery(r)
+	if err != nil {
+		s.recordRequestAppStatus(constants.DeleteLabelSchedules, appId, constants.Fail)
+		er.Handle(w, r, er.NewError(er.InvalidDataCode, err))
+		return
+	}
+
+	deleted, failures, err := s.DeleteSchedulesByLabel(appId, selectors)
+	if err != nil {
+		s.recordRequestAppStatus(constants.DeleteLabelSchedules, appId, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
+	s.recordRequestAppStatus(constants.DeleteLabelSchedules, appId, constants.Success)
+	status := Status{
+		StatusCode:    constants.SuccessCode200,
+		StatusMessage: constants.Success,
+		StatusType:    constants.Success,
+		TotalCount:    len(deleted),
+	}
+	data := DeleteLabelSchedulesData{
+		Schedules: deleted,
+		Failures:  failures,
+	}
+	_ = json.NewEncoder(w).Encode(
+		DeleteLabelSchedulesResponse{
+			Status: status,
+			Data:   data,
+		})
+}
+
+// Delete all the schedules of an app matching the selectors along with their label lookup rows.
+// Returns the deleted schedules and the ids of the schedules that could not be deleted mapped to the error.
+func (s *Service) DeleteSchedulesByLabel(appId string, selectors []string) ([]sch.Schedule, map[string]string, error) {
+	if _, err := s.getActiveOrInactiveApp(appId); err != nil {
+		return nil, nil, err
+	}
+
+	var matched []sch.Schedule
+	var pageState []byte
+	for {
+		schedules, nextPageState, err := s.ScheduleDao.GetSchedulesByLabel(appId, selectors, defaultLabelPageSize, pageState)
+		if err != nil {
+			return nil, nil, er.NewError(er.DataFetchFailure, err)
+		}
+
+		matched = append(matched, schedules...)
+		if len(nextPageState) == 0 {
+			break
+		}
+		pageState = nextPageState
+	}
+
+	deleted := []sch.Schedule{}
+	failures := make(map[string]string)
+	for _, schedule := range matched {
+		// Label rows are deleted along with the schedule, only the ones of a schedule which is gone are left
+		result, err := s.deleteSchedule(schedule.ScheduleId)
+		if err == gocql.ErrNotFound {
+			err = s.ScheduleDao.DeleteLabels(schedule)
+		}
+		if err != nil {
+			failures[schedule.ScheduleId.String()] = err.Error()
+			continue
+		}
+
+		result.ScheduleId = schedule.ScheduleId
+		result.Labels = schedule.Labels
+		deleted = append(deleted, result)
+	}
+
+	return deleted, failures, nil
+}
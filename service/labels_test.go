@@ -0,0 +1,150 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestService_GetLabelSchedules(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		AppId  string
+		Query  string
+		Status int
+		Count  int
+	}{
+		{"labelApp", "", http.StatusBadRequest, 0},
+		{"labelApp", "?label=tenant", http.StatusBadRequest, 0},
+		{"labelApp", "?label=tenant:acme&size=-1", http.StatusBadRequest, 0},
+		{"labelApp", "?label=tenant:acme&continuation_token=xyz", http.StatusBadRequest, 0},
+		{"testGetAppErrorNotFound", "?label=tenant:acme", http.StatusBadRequest, 0},
+		{"labelFetchFailureApp", "?label=tenant:acme", http.StatusInternalServerError, 0},
+		{"labelApp", "?label=tenant:acme&label=env:prod", http.StatusOK, 2},
+	} {
+		req, err := http.NewRequest("GET", "/goscheduler/apps/:appId/schedules"+test.Query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"appId": test.AppId})
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.GetLabelSchedules)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s%s: got %v want %v", test.AppId, test.Query, status, test.Status)
+			continue
+		}
+
+		if test.Status == http.StatusOK {
+			var response GetPaginatedRunSchedulesResponse
+			if err = json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Status.TotalCount != test.Count {
+				t.Errorf("handler returned wrong count: got %v want %v", response.Status.TotalCount, test.Count)
+			}
+		}
+	}
+}
+
+func TestService_DeleteLabelSchedules(t *testing.T) {
+	service := setupMocks()
+
+	for _, test := range []struct {
+		AppId    string
+		Query    string
+		Status   int
+		Deleted  int
+		Failures int
+	}{
+		{"labelApp", "?label=:acme", http.StatusBadRequest, 0, 0},
+		{"labelFetchFailureApp", "?label=tenant:acme", http.StatusInternalServerError, 0, 0},
+		{"labelDeleteFailureApp", "?label=tenant:acme", http.StatusOK, 0, 1},
+		{"labelApp", "?label=tenant:acme", http.StatusOK, 2, 0},
+	} {
+		req, err := http.NewRequest("DELETE", "/goscheduler/apps/:appId/schedules"+test.Query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"appId": test.AppId})
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(service.DeleteLabelSchedules)
+		handler.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s%s: got %v want %v", test.AppId, test.Query, status, test.Status)
+			continue
+		}
+
+		if test.Status == http.StatusOK {
+			var response DeleteLabelSchedulesResponse
+			if err = json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Data.Schedules) != test.Deleted || len(response.Data.Failures) != test.Failures {
+				t.Errorf("handler returned deleted %d, failures %d: want %d, %d",
+					len(response.Data.Schedules), len(response.Data.Failures), test.Deleted, test.Failures)
+			}
+		}
+	}
+}
+
+func TestService_DeleteSchedulesByLabelReleasesUsage(t *testing.T) {
+	service := setupInMemory()
+
+	_, err := service.RegisterApp(store.App{AppId: "labelUsage", Partitions: 1, Active: true})
+	require.Nil(t, err)
+
+	callback := &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}}
+	for _, schedule := range []store.Schedule{
+		{AppId: "labelUsage", Payload: "{}", ScheduleTime: time.Now().Add(time.Hour).Unix(), Labels: map[string]string{"tenant": "acme"}},
+		{AppId: "labelUsage", Payload: "{}", CronExpression: "*/5 * * * *", Labels: map[string]string{"tenant": "acme"}},
+		{AppId: "labelUsage", Payload: "{}", ScheduleTime: time.Now().Add(time.Hour).Unix(), Labels: map[string]string{"tenant": "other"}},
+	} {
+		schedule.Callback = callback
+		_, err = service.CreateSchedule(schedule)
+		require.Nil(t, err)
+	}
+
+	usage, err := service.ScheduleDao.GetUsage("labelUsage")
+	require.Nil(t, err)
+	require.Equal(t, store.Usage{PendingSchedules: 2, RecurringSchedules: 1}, usage)
+
+	deleted, failures, err := service.DeleteSchedulesByLabel("labelUsage", []string{"tenant:acme"})
+	require.Nil(t, err)
+	assert.Len(t, deleted, 2)
+	assert.Empty(t, failures)
+
+	usage, err = service.ScheduleDao.GetUsage("labelUsage")
+	require.Nil(t, err)
+	assert.Equal(t, store.Usage{PendingSchedules: 1}, usage)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/glog"
+	er "github.com/myntra/goscheduler/error"
+	"github.com/myntra/goscheduler/payload"
+	sch "github.com/myntra/goscheduler/store"
+)
+
+var errPayloadReference = errors.New("payload cannot start with " + payload.ReferencePrefix)
+
+// offloadPayload replaces the payload of the schedule with a reference to the payload store if it is above the threshold.
+// The payload of a one time schedule expires along with its row. The payload of a recurring schedule is referenced by its runs,
+// it is kept until the recurring schedule is deleted.
+func (s *Service) offloadPayload(schedule *sch.Schedule, app sch.App) (bool, error) {
+	if s.Payloads == nil {
+		return false, nil
+	}
+
+	var expiresAt time.Time
+	if !schedule.IsRecurring() {
+		bufferTTL := app.GetBufferTTL(s.Config.AppLevelConfiguration.FiredScheduleRetentionPeriod)
+		expiresAt = time.Unix(schedule.ScheduleTime, 0).Add(time.Duration(bufferTTL) * time.Second)
+	}
+
+	reference, offloaded, err := s.Payloads.Offload(schedule.ScheduleId, schedule.Payload, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	schedule.Payload = reference
+	return offloaded, nil
+}
+
+// resolvePayload replaces the reference to an offloaded payload of the schedule with the payload.
+func (s *Service) resolvePayload(schedule *sch.Schedule) error {
+	resolved, err := s.Payloads.Resolve(schedule.Payload)
+	if err != nil {
+		return er.NewError(er.DataFetchFailure, err)
+	}
+
+	schedule.Payload = resolved
+	return nil
+}
+
+// deletePayload removes the offloaded payload of a deleted schedule.
+// Failures are only logged, the payload is deleted anyway once it expires.
+func (s *Service) deletePayload(schedule sch.Schedule) {
+	if s.Payloads == nil || !payload.IsReference(schedule.Payload) {
+		return
+	}
+
+	if err := s.Payloads.Delete(schedule.ScheduleId, schedule.Payload); err != nil {
+		glog.Errorf("Deleting payload of schedule id %s failed with error %s", schedule.ScheduleId.String(), err.Error())
+	}
+}
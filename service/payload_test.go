@@ -0,0 +1,130 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/myntra/goscheduler/blob"
+	"github.com/myntra/goscheduler/payload"
+	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_OffloadPayload(t *testing.T) {
+	service := setupInMemory()
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.Nil(t, err)
+	service.Payloads = payload.NewStore(blobs, 16)
+
+	_, err = service.RegisterApp(store.App{AppId: "offload", Partitions: 1, Active: true})
+	require.Nil(t, err)
+
+	large := `{"data":"` + strings.Repeat("x", 100) + `"}`
+	scheduleTime := time.Now().Add(time.Hour)
+	schedule, err := service.CreateSchedule(store.Schedule{
+		AppId:        "offload",
+		Payload:      large,
+		ScheduleTime: scheduleTime.Unix(),
+		Callback:     &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, large, schedule.Payload)
+
+	// Only the reference is kept in the row, the payload expires along with it
+	stored, err := service.ScheduleDao.GetSchedule(schedule.ScheduleId)
+	require.Nil(t, err)
+	require.True(t, payload.IsReference(stored.Payload))
+	expiresAt := scheduleTime.Unix() + int64(store.App{}.GetBufferTTL(service.Config.AppLevelConfiguration.FiredScheduleRetentionPeriod))
+	assert.Contains(t, stored.Payload, time.Unix(expiresAt, 0).UTC().Format("2006-01-02"))
+
+	rr := serveSchedule(service, service.Get, "GET", schedule.ScheduleId.String())
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var response GetScheduleResponse
+	require.Nil(t, json.NewDecoder(rr.Body).Decode(&response))
+	assert.Equal(t, large, response.Data.Schedule.Payload)
+
+	// Payloads below the threshold stay inline
+	small, err := service.CreateSchedule(store.Schedule{
+		AppId:        "offload",
+		Payload:      "{}",
+		ScheduleTime: scheduleTime.Unix(),
+		Callback:     &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}},
+	})
+	require.Nil(t, err)
+	stored, err = service.ScheduleDao.GetSchedule(small.ScheduleId)
+	require.Nil(t, err)
+	assert.Equal(t, "{}", stored.Payload)
+
+	// Deleting the schedule deletes its payload
+	deleted, err := service.DeleteSchedule(schedule.ScheduleId.String())
+	require.Nil(t, err)
+	_, err = service.Payloads.Load(deleted.Payload)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+
+	// References cannot be smuggled in as payloads
+	_, err = service.CreateSchedule(store.Schedule{
+		AppId:        "offload",
+		Payload:      deleted.Payload,
+		ScheduleTime: scheduleTime.Unix(),
+		Callback:     &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}},
+	})
+	assert.NotNil(t, err)
+}
+
+func TestService_ReplacedSchedulePayload(t *testing.T) {
+	service := setupInMemory()
+	blobs, err := blob.NewFileStore(t.TempDir())
+	require.Nil(t, err)
+	service.Payloads = payload.NewStore(blobs, 16)
+
+	_, err = service.RegisterApp(store.App{AppId: "replace", Partitions: 1, Active: true, Configuration: store.Configuration{DedupPolicy: store.DedupReplace}})
+	require.Nil(t, err)
+
+	create := func() store.Schedule {
+		schedule, err := service.CreateSchedule(store.Schedule{
+			AppId:        "replace",
+			Payload:      `{"data":"` + strings.Repeat("x", 100) + `"}`,
+			ScheduleTime: time.Now().Add(time.Hour).Unix(),
+			DedupKey:     "order-1",
+			Callback:     &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}},
+		})
+		require.Nil(t, err)
+
+		stored, err := service.ScheduleDao.GetSchedule(schedule.ScheduleId)
+		require.Nil(t, err)
+		require.True(t, payload.IsReference(stored.Payload))
+		return stored
+	}
+
+	// The payload of the replaced schedule is deleted along with it
+	replaced := create()
+	replacement := create()
+	_, err = service.Payloads.Load(replaced.Payload)
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	_, err = service.Payloads.Load(replacement.Payload)
+	assert.Nil(t, err)
+}
@@ -26,11 +26,14 @@ import (
 	"github.com/gocql/gocql"
 	"github.com/golang/glog"
 	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/dao"
 	er "github.com/myntra/goscheduler/error"
+	"github.com/myntra/goscheduler/payload"
 	sch "github.com/myntra/goscheduler/store"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 func (s *Service) Post(w http.ResponseWriter, r *http.Request) {
@@ -50,9 +53,16 @@ func (s *Service) Post(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = authorizeApp(r, input.AppId, sch.AppWriter); err != nil {
+		s.recordRequestAppStatus(constants.CreateSchedule, getAppId(sch.Schedule{}), constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
 	schedule, err := s.CreateSchedule(input)
 	if err != nil {
 		s.recordRequestAppStatus(constants.CreateSchedule, getAppId(sch.Schedule{}), constants.Fail)
+		setRetryAfter(w, err.(er.AppError))
 		er.Handle(w, r, err.(er.AppError))
 	} else {
 		s.recordRequestAppStatus(constants.CreateSchedule, getAppId(schedule), constants.Success)
@@ -70,11 +80,41 @@ func (s *Service) CreateSchedule(input sch.Schedule) (sch.Schedule, error) {
 		return sch.Schedule{}, err
 	}
 
+	tolerance := time.Duration(s.Config.AppLevelConfiguration.PastScheduleTolerance) * time.Second
+	if err = input.ResolveScheduleTime(time.Now(), tolerance); err != nil {
+		return sch.Schedule{}, er.NewError(er.InvalidDataCode, err)
+	}
+
+	if err = input.ResolveDeadline(); err != nil {
+		return sch.Schedule{}, er.NewError(er.InvalidDataCode, err)
+	}
+
+	// The schedule time is spread within its jitter window before it is validated against the max TTL
+	input.ScheduleId = gocql.TimeUUID()
+	input.ApplyJitter(app, s.Config.AppLevelConfiguration.DefaultJitter)
+
 	errs := input.ValidateSchedule(app, s.Config.AppLevelConfiguration)
 	if errs != nil && len(errs) > 0 {
 		return sch.Schedule{}, er.NewError(er.InvalidDataCode, errors.New(strings.Join(errs, ",")))
 	}
 
+	if payload.IsReference(input.Payload) {
+		return sch.Schedule{}, er.NewError(er.InvalidDataCode, errPayloadReference)
+	}
+
+	if input.Calendar != "" {
+		if _, err = s.FetchCalendar(input.Calendar); err != nil {
+			if appErr := err.(er.AppError); appErr.Code == er.DataNotFound {
+				return sch.Schedule{}, er.NewError(er.InvalidDataCode, appErr.Err)
+			}
+			return sch.Schedule{}, err
+		}
+	}
+
+	if err = s.admit(app, input); err != nil {
+		return sch.Schedule{}, err
+	}
+
 	if input.IsRecurring() {
 		cronApp, err := s.getApp(s.Config.CronConfig.App)
 		if err != nil {
@@ -83,13 +123,35 @@ func (s *Service) CreateSchedule(input sch.Schedule) (sch.Schedule, error) {
 		app = cronApp
 	}
 
-	input.SetFields(app)
+	input.SetPartition(app)
 
-	schedule, err := s.ScheduleDao.CreateSchedule(input, app)
+	original := input.Payload
+	offloaded, err := s.offloadPayload(&input, app)
 	if err != nil {
 		return sch.Schedule{}, er.NewError(er.DataPersistenceFailure, err)
 	}
 
+	schedule, err := s.ScheduleDao.CreateSchedule(input, app)
+	if offloaded && (err != nil || schedule.Deduplicated) {
+		s.deletePayload(input)
+	}
+
+	switch {
+	case err == dao.ErrDuplicateSchedule:
+		return sch.Schedule{}, er.NewError(er.DuplicateSchedule, err)
+	case err != nil:
+		return sch.Schedule{}, er.NewError(er.DataPersistenceFailure, err)
+	}
+
+	if !schedule.Deduplicated {
+		schedule.Payload = original
+		s.updateUsage(schedule, 1)
+	}
+	// The usage of a schedule replaced through its dedup key is released by the DAO, its payload is deleted here
+	if schedule.Replaced != nil {
+		s.deletePayload(*schedule.Replaced)
+	}
+
 	return schedule, nil
 }
 
@@ -69,6 +69,66 @@ func TestService_Post(t *testing.T) {
 			[]byte(fmt.Sprintf(`{"AppId": "createScheduleFailureApp", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "ScheduleTime":%d, "Payload":"{}"}`, time.Now().Add(90000000000).Unix())),
 			http.StatusInternalServerError,
 		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "CronExpression": "0 9 * * *", "calendar": "calendarNotFound", "Payload":"{}"}`),
+			http.StatusBadRequest,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "CronExpression": "0 9 * * *", "calendar": "calendarFetchFailure", "Payload":"{}"}`),
+			http.StatusInternalServerError,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "duplicateScheduleApp", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "delay": "15m", "dedupKey": "cart-1", "Payload":"{}"}`),
+			http.StatusConflict,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "CronExpression": "*/5 * * * *", "dedupKey": "cart-1", "Payload":"{}"}`),
+			http.StatusBadRequest,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "delay": "15m", "Payload":"{}"}`),
+			http.StatusOK,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "delay": "PT2H", "Payload":"{}"}`),
+			http.StatusOK,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(fmt.Sprintf(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "scheduleAt": "%s", "Payload":"{}"}`, time.Now().Add(time.Hour).In(time.FixedZone("IST", 19800)).Format(time.RFC3339))),
+			http.StatusOK,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(fmt.Sprintf(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "delay": "15m", "ScheduleTime":%d, "Payload":"{}"}`, time.Now().Add(90000000000).Unix())),
+			http.StatusBadRequest,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(fmt.Sprintf(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "ScheduleTime":%d, "Payload":"{}"}`, time.Now().Add(-10*time.Second).Unix())),
+			http.StatusOK,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(fmt.Sprintf(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "ScheduleTime":%d, "Payload":"{}"}`, time.Now().Add(-time.Hour).Unix())),
+			http.StatusBadRequest,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "delay": "24000h", "Payload":"{}"}`),
+			http.StatusOK,
+		},
+		{
+			gocql.TimeUUID().String(),
+			[]byte(`{"AppId": "test", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST", "headers": {"header": "value"}}}, "delay": "24000h", "jitter": "24h", "Payload":"{}"}`),
+			http.StatusBadRequest,
+		},
 	} {
 
 		req, err := http.NewRequest("POST", "/goscheduler/schedules", bytes.NewBuffer(test.body))
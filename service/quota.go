@@ -0,0 +1,259 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/glog"
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/constants"
+	er "github.com/myntra/goscheduler/error"
+	sch "github.com/myntra/goscheduler/store"
+)
+
+// quotaError is returned when an app exceeds its rate limit or quotas, along with when the request can be retried
+type quotaError struct {
+	retryAfter time.Duration
+	message    string
+}
+
+func (q quotaError) Error() string {
+	return q.message
+}
+
+// rateWindow counts the schedules created by an app in the fixed window starting at start
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// rateLimiter enforces the rate limits of the apps with fixed windows.
+// Every node enforces the rate limits on its own. The zero value is ready to use.
+type rateLimiter struct {
+	lock    sync.Mutex
+	windows map[string]rateWindow
+}
+
+// window returns the current window of the app, starting a new one if the last one is over
+func (l *rateLimiter) window(appId string, period time.Duration, now time.Time) rateWindow {
+	if l.windows == nil {
+		l.windows = make(map[string]rateWindow)
+	}
+
+	w, ok := l.windows[appId]
+	if !ok || !now.Before(w.start.Add(period)) {
+		w = rateWindow{start: now.Truncate(period)}
+		l.windows[appId] = w
+	}
+	return w
+}
+
+// allow takes a request of the app from its rate limit, the time after which it can be retried is returned if the limit is exhausted
+func (l *rateLimiter) allow(appId string, limit sch.RateLimit, now time.Time) (bool, time.Duration) {
+	period, err := sch.ParseDelay(limit.Period)
+	if err != nil || period == 0 {
+		return true, 0
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	w := l.window(appId, period, now)
+	if w.count >= limit.Requests {
+		return false, w.start.Add(period).Sub(now)
+	}
+
+	w.count++
+	l.windows[appId] = w
+	return true, 0
+}
+
+// remaining returns the requests left in the current window of the app
+func (l *rateLimiter) remaining(appId string, limit sch.RateLimit, now time.Time) int {
+	period, err := sch.ParseDelay(limit.Period)
+	if err != nil || period == 0 {
+		return limit.Requests
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return limit.Requests - l.window(appId, period, now).count
+}
+
+// admit checks the rate limit and the quota of the app before a schedule is created
+func (s *Service) admit(app sch.App, schedule sch.Schedule) error {
+	if limit := app.Configuration.RateLimit; limit != nil {
+		if ok, retryAfter := s.limiter.allow(app.AppId, *limit, time.Now()); !ok {
+			return er.NewError(er.TooManyRequests, quotaError{
+				retryAfter: retryAfter,
+				message:    fmt.Sprintf("app %s exceeded its rate limit of %d schedules per %s", app.AppId, limit.Requests, limit.Period),
+			})
+		}
+	}
+
+	return s.checkQuota(app, schedule)
+}
+
+// checkQuota rejects the schedule if the app reached the quota of the kind of schedule.
+func (s *Service) checkQuota(app sch.App, schedule sch.Schedule) error {
+	kind, counted := schedule.UsageKind()
+	quota := app.Configuration.Quotas.Limit(kind)
+	if !counted || quota == 0 {
+		return nil
+	}
+
+	usage, err := s.ScheduleDao.GetUsage(app.AppId)
+	if err != nil {
+		return er.NewError(er.DataFetchFailure, err)
+	}
+
+	if usage.Get(kind) >= quota {
+		return er.NewError(er.TooManyRequests, quotaError{
+			retryAfter: time.Duration(s.Config.Poller.Interval) * time.Second,
+			message:    fmt.Sprintf("app %s reached its quota of %d %s schedules", app.AppId, quota, kind),
+		})
+	}
+
+	return nil
+}
+
+// updateUsage adds delta to the usage of the quota the schedule is counted against.
+// Usage is best effort, failures are only logged.
+func (s *Service) updateUsage(schedule sch.Schedule, delta int64) {
+	kind, counted := schedule.UsageKind()
+	if !counted {
+		return
+	}
+
+	if err := s.ScheduleDao.UpdateUsage(schedule.AppId, kind, delta); err != nil {
+		glog.Errorf("Updating %s usage of app %s by %d failed with error %s", kind, schedule.AppId, delta, err.Error())
+	}
+}
+
+// UsageRecount is the usage of an app as stored before a recount and as counted from its schedules
+type UsageRecount struct {
+	AppId   string
+	Stored  sch.Usage
+	Counted sch.Usage
+}
+
+// RecountUsage counts the active recurring schedules and the one time schedules not yet fired of an app,
+// and corrects its stored usage to match. It repairs the usage of the schedules created before the quotas were tracked,
+// whose callbacks and deletions were released from a usage they were never added to.
+// Schedules created or fired while the recount runs can leave the usage off by as many, running it again corrects them.
+func (s *Service) RecountUsage(ctx context.Context, appId string) (UsageRecount, error) {
+	recount := UsageRecount{AppId: appId}
+
+	// Deactivated apps are recounted as well, their schedules are still counted once they are activated again
+	app, err := s.ClusterDao.GetApp(appId)
+	switch {
+	case err == gocql.ErrNotFound || (err == nil && len(app.AppId) == 0):
+		return recount, er.NewError(er.InvalidAppId, errors.New(fmt.Sprintf("app Id %s is not registered", appId)))
+	case err != nil:
+		return recount, er.NewError(er.DataFetchFailure, err)
+	}
+
+	recurring, errs := s.ScheduleDao.GetCronSchedulesByApp(appId, sch.Scheduled)
+	if len(errs) != 0 {
+		return recount, er.NewError(er.DataFetchFailure, errors.New(strings.Join(errs, ",")))
+	}
+	recount.Counted.RecurringSchedules = int64(len(recurring))
+
+	err = s.forEachPendingGroup(ctx, app, time.Now(), func(schedules []sch.Schedule) error {
+		recount.Counted.PendingSchedules += int64(len(schedules))
+		return nil
+	})
+	if err != nil {
+		return recount, err
+	}
+
+	if recount.Stored, err = s.ScheduleDao.GetUsage(appId); err != nil {
+		return recount, er.NewError(er.DataFetchFailure, err)
+	}
+
+	deltas := map[sch.UsageKind]int64{
+		sch.PendingUsage:   recount.Counted.PendingSchedules - recount.Stored.PendingSchedules,
+		sch.RecurringUsage: recount.Counted.RecurringSchedules - recount.Stored.RecurringSchedules,
+	}
+	for kind, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		if err = s.ScheduleDao.UpdateUsage(appId, kind, delta); err != nil {
+			return recount, er.NewError(er.DataPersistenceFailure, err)
+		}
+	}
+
+	return recount, nil
+}
+
+// setRetryAfter sets the Retry-After header if the error is caused by a rate limit or quota
+func setRetryAfter(w http.ResponseWriter, err er.AppError) {
+	if q, ok := err.Err.(quotaError); ok {
+		w.Header().Set(constants.RetryAfter, strconv.Itoa(int(math.Ceil(q.retryAfter.Seconds()))))
+	}
+}
+
+// GetAppUsage returns the number of schedules an app has against its quotas and what is left of its rate limit on this node
+func (s *Service) GetAppUsage(w http.ResponseWriter, r *http.Request) {
+	appId := mux.Vars(r)["appId"]
+
+	app, err := s.getApp(appId)
+	if err != nil {
+		s.recordRequestAppStatus(constants.GetAppUsage, appId, constants.Fail)
+		er.Handle(w, r, err.(er.AppError))
+		return
+	}
+
+	usage, err := s.ScheduleDao.GetUsage(app.AppId)
+	if err != nil {
+		s.recordRequestAppStatus(constants.GetAppUsage, appId, constants.Fail)
+		er.Handle(w, r, er.NewError(er.DataFetchFailure, err))
+		return
+	}
+
+	data := AppUsageData{
+		AppId:              app.AppId,
+		PendingSchedules:   usage.Get(sch.PendingUsage),
+		RecurringSchedules: usage.Get(sch.RecurringUsage),
+		Quotas:             app.Configuration.Quotas,
+		RateLimit:          app.Configuration.RateLimit,
+	}
+	if limit := app.Configuration.RateLimit; limit != nil {
+		remaining := s.limiter.remaining(app.AppId, *limit, time.Now())
+		data.RemainingRequests = &remaining
+	}
+
+	s.recordRequestAppStatus(constants.GetAppUsage, appId, constants.Success)
+	status := Status{StatusCode: constants.SuccessCode200, StatusMessage: constants.Success, StatusType: constants.Success, TotalCount: 1}
+	_ = json.NewEncoder(w).Encode(AppUsageResponse{Status: status, Data: data})
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/myntra/goscheduler/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postSchedule(service *Service, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/goscheduler/schedules", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(service.Post).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestService_PostRateLimit(t *testing.T) {
+	service := setupMocks()
+	body := fmt.Sprintf(`{"appId": "rateLimitedApp", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST"}}, "scheduleTime":%d, "payload":"{}"}`, time.Now().Add(time.Hour).Unix())
+
+	if rr := postSchedule(service, body); rr.Code != http.StatusOK {
+		t.Fatalf("first schedule returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	rr := postSchedule(service, body)
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("second schedule returned wrong status code: got %v want %v", rr.Code, http.StatusTooManyRequests)
+	}
+	if rr.Header().Get("Retry-After") == "" {
+		t.Errorf("second schedule returned no Retry-After header")
+	}
+}
+
+func TestService_PostQuota(t *testing.T) {
+	service := setupMocks()
+	for _, test := range []struct {
+		body   string
+		Status int
+	}{
+		{
+			fmt.Sprintf(`{"appId": "quotaExceededApp", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST"}}, "scheduleTime":%d, "payload":"{}"}`, time.Now().Add(time.Hour).Unix()),
+			http.StatusTooManyRequests,
+		},
+		{
+			`{"appId": "quotaExceededApp", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST"}}, "cronExpression": "*/5 * * * *", "payload":"{}"}`,
+			http.StatusTooManyRequests,
+		},
+		{
+			fmt.Sprintf(`{"appId": "usageFetchFailureApp", "callback": {"type": "http", "details": {"url": "https://dummy.url", "method": "POST"}}, "scheduleTime":%d, "payload":"{}"}`, time.Now().Add(time.Hour).Unix()),
+			http.StatusInternalServerError,
+		},
+	} {
+		if rr := postSchedule(service, test.body); rr.Code != test.Status {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, test.Status)
+		}
+	}
+}
+
+func TestService_GetAppUsage(t *testing.T) {
+	service := setupMocks()
+	for _, test := range []struct {
+		App    string
+		Status int
+	}{
+		{"testAppNotFound", http.StatusBadRequest},
+		{"usageFetchFailureApp", http.StatusInternalServerError},
+		{"rateLimitedApp", http.StatusOK},
+		{"test", http.StatusOK},
+	} {
+		req, err := http.NewRequest("GET", "/goscheduler/apps/:appId/usage", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = mux.SetURLVars(req, map[string]string{"appId": test.App})
+
+		rr := httptest.NewRecorder()
+		http.HandlerFunc(service.GetAppUsage).ServeHTTP(rr, req)
+
+		if status := rr.Code; status != test.Status {
+			t.Errorf("handler returned wrong status code for %s: got %v want %v", test.App, status, test.Status)
+		}
+	}
+}
+
+func TestService_RecountUsage(t *testing.T) {
+	service := setupInMemory()
+	_, err := service.RegisterApp(store.App{AppId: "recount", Partitions: 2, Active: true})
+	require.Nil(t, err)
+
+	callback := &store.HttpCallback{Type: "http", Details: store.Details{Url: "https://dummy.url", Method: "POST"}}
+	for _, schedule := range []store.Schedule{
+		{AppId: "recount", Payload: "{}", ScheduleTime: time.Now().Add(time.Hour).Unix(), Callback: callback},
+		{AppId: "recount", Payload: "{}", ScheduleTime: time.Now().Add(48 * time.Hour).Unix(), Callback: callback},
+		{AppId: "recount", Payload: "{}", CronExpression: "*/5 * * * *", Callback: callback},
+	} {
+		_, err = service.CreateSchedule(schedule)
+		require.Nil(t, err)
+	}
+
+	// Releases the schedules created before the usage was tracked, which were never added to it
+	require.Nil(t, service.ScheduleDao.UpdateUsage("recount", store.PendingUsage, -5))
+	require.Nil(t, service.ScheduleDao.UpdateUsage("recount", store.RecurringUsage, 3))
+
+	recount, err := service.RecountUsage(context.Background(), "recount")
+	require.Nil(t, err)
+	assert.Equal(t, store.Usage{RecurringSchedules: 4}, recount.Stored)
+	assert.Equal(t, store.Usage{PendingSchedules: 2, RecurringSchedules: 1}, recount.Counted)
+
+	usage, err := service.ScheduleDao.GetUsage("recount")
+	require.Nil(t, err)
+	assert.Equal(t, recount.Counted, usage)
+
+	_, err = service.RecountUsage(context.Background(), "unknown")
+	assert.NotNil(t, err)
+}
+
+func TestRateLimiter(t *testing.T) {
+	var limiter rateLimiter
+	limit := store.RateLimit{Requests: 2, Period: "1m"}
+	now := time.Now().Truncate(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow("app", limit, now); !ok {
+			t.Fatalf("request %d was not allowed", i+1)
+		}
+	}
+
+	ok, retryAfter := limiter.allow("app", limit, now.Add(10*time.Second))
+	if ok {
+		t.Errorf("request over the limit was allowed")
+	}
+	if retryAfter <= 0 || retryAfter > time.Minute {
+		t.Errorf("retry after = %s, want within the window", retryAfter)
+	}
+
+	if got := limiter.remaining("other", limit, now); got != 2 {
+		t.Errorf("remaining() of another app = %d, want 2", got)
+	}
+
+	if ok, _ := limiter.allow("app", limit, now.Add(time.Minute)); !ok {
+		t.Errorf("request in the next window was not allowed")
+	}
+}
@@ -20,7 +20,10 @@
 package service
 
 import (
+	"time"
+
 	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/archive"
 	s "github.com/myntra/goscheduler/store"
 )
 
@@ -65,6 +68,16 @@ type DeleteScheduleData struct {
 	Schedule s.Schedule `json:"schedule"`
 }
 
+type DeleteLabelSchedulesResponse struct {
+	Status Status                   `json:"status"`
+	Data   DeleteLabelSchedulesData `json:"data"`
+}
+
+type DeleteLabelSchedulesData struct {
+	Schedules []s.Schedule      `json:"schedules"`
+	Failures  map[string]string `json:"failures,omitempty"`
+}
+
 type DeleteConfigurationData struct {
 	AppId         string          `json:"appId"`
 	Configuration s.Configuration `json:"configuration"`
@@ -127,6 +140,30 @@ type GetPaginatedAppSchedulesData struct {
 	ContinuationStartTime int64        `json:"continuationStartTime"`
 }
 
+type GetArchivedScheduleResponse struct {
+	Status Status                  `json:"status"`
+	Data   GetArchivedScheduleData `json:"data"`
+}
+
+type GetArchivedScheduleData struct {
+	Run archive.Record `json:"run"`
+}
+
+type GetArchivedSchedulesResponse struct {
+	Status Status                   `json:"status"`
+	Data   GetArchivedSchedulesData `json:"data"`
+}
+
+type GetArchivedSchedulesData struct {
+	Runs              []archive.Record `json:"runs"`
+	ContinuationToken string           `json:"continuationToken"`
+}
+
+type ImportSchedulesResponse struct {
+	Status Status       `json:"status"`
+	Data   ImportReport `json:"data"`
+}
+
 type GetConfigurationData struct {
 	AppId         string          `json:"appId"`
 	Configuration s.Configuration `json:"configuration"`
@@ -141,6 +178,44 @@ type GetConfigurationResponse struct {
 	Data   GetConfigurationData `json:"data"`
 }
 
+type AppUsageData struct {
+	AppId              string       `json:"appId"`
+	PendingSchedules   int64        `json:"pendingSchedules"`
+	RecurringSchedules int64        `json:"recurringSchedules"`
+	Quotas             *s.Quotas    `json:"quotas,omitempty"`
+	RateLimit          *s.RateLimit `json:"rateLimit,omitempty"`
+	RemainingRequests  *int         `json:"remainingRequests,omitempty"`
+}
+
+type AppUsageResponse struct {
+	Status Status       `json:"status"`
+	Data   AppUsageData `json:"data"`
+}
+
+type ApiKeyData struct {
+	Id        string     `json:"id"`
+	Apps      []string   `json:"apps,omitempty"`
+	Role      s.Role     `json:"role,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+	Key       string     `json:"key,omitempty"`
+}
+
+type ApiKeyResponse struct {
+	Status Status     `json:"status"`
+	Data   ApiKeyData `json:"data"`
+}
+
+type GetConfigurationHistoryData struct {
+	AppId   string                   `json:"appId"`
+	History []s.ConfigurationVersion `json:"history"`
+}
+
+type GetConfigurationHistoryResponse struct {
+	Status Status                      `json:"status"`
+	Data   GetConfigurationHistoryData `json:"data"`
+}
+
 type CreateScheduleResponse struct {
 	Status Status             `json:"status"`
 	Data   CreateScheduleData `json:"data"`
@@ -188,3 +263,13 @@ type GetAppsResponse struct {
 	Status Status      `json:"status"`
 	Data   GetAppsData `json:"data"`
 }
+
+type CalendarResponse struct {
+	Status Status     `json:"status"`
+	Data   s.Calendar `json:"data"`
+}
+
+type GetCalendarsResponse struct {
+	Status Status       `json:"status"`
+	Data   []s.Calendar `json:"data"`
+}
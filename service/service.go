@@ -20,11 +20,13 @@
 package service
 
 import (
+	"github.com/myntra/goscheduler/archive"
 	"github.com/myntra/goscheduler/cluster"
 	c "github.com/myntra/goscheduler/conf"
 	"github.com/myntra/goscheduler/constants"
 	"github.com/myntra/goscheduler/dao"
 	"github.com/myntra/goscheduler/monitoring"
+	"github.com/myntra/goscheduler/payload"
 )
 
 type Service struct {
@@ -33,6 +35,9 @@ type Service struct {
 	ClusterDao  dao.ClusterDao
 	ScheduleDao dao.ScheduleDao
 	Monitor     monitoring.Monitor
+	Archive     *archive.Reader
+	Payloads    *payload.Store
+	limiter     rateLimiter
 }
 
 func NewService(config *c.Configuration, supervisor cluster.SupervisorHandler, clusterDao dao.ClusterDao, scheduleDAO dao.ScheduleDao, monitor monitoring.Monitor) *Service {
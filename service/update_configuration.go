@@ -7,6 +7,7 @@ import (
 	"github.com/gocql/gocql"
 	"github.com/gorilla/mux"
 	"github.com/myntra/goscheduler/constants"
+	"github.com/myntra/goscheduler/dao"
 	er "github.com/myntra/goscheduler/error"
 	sch "github.com/myntra/goscheduler/store"
 	"io/ioutil"
@@ -14,15 +15,15 @@ import (
 )
 
 func (s *Service) UpdateConfiguration(w http.ResponseWriter, r *http.Request) {
-	var input sch.Configuration
 	var config sch.Configuration
 	var err error
 	var app sch.App
 
 	vars := mux.Vars(r)
-	appId := vars["app_id"]
+	appId := vars["appId"]
 	b, _ := ioutil.ReadAll(r.Body)
-	err = json.Unmarshal(b, &input)
+	patch := sch.ConfigurationPatch(b)
+	err = patch.Validate()
 
 	if err != nil {
 		er.Handle(w, r, er.NewError(er.UnmarshalErrorCode, err))
@@ -34,7 +35,7 @@ func (s *Service) UpdateConfiguration(w http.ResponseWriter, r *http.Request) {
 
 	switch {
 	case err == gocql.ErrNotFound:
-		er.Handle(w, r, er.NewError(er.InvalidAppId, errors.New(fmt.Sprintf("app %+v is not registered", app))))
+		er.Handle(w, r, er.NewError(er.InvalidAppId, errors.New(fmt.Sprintf("app id %s is not registered", appId))))
 		s.recordRequestStatus(constants.UpdateConfiguration, constants.Fail)
 
 	case err != nil:
@@ -42,12 +43,19 @@ func (s *Service) UpdateConfiguration(w http.ResponseWriter, r *http.Request) {
 		s.recordRequestStatus(constants.UpdateConfiguration, constants.Fail)
 
 	default:
-		if config, err = s.ClusterDao.UpdateConfiguration(app.AppId, input); err != nil {
-			er.Handle(w, r, er.NewError(er.DataPersistenceFailure, err))
+		if config, err = s.ClusterDao.UpdateConfiguration(app.AppId, patch); err != nil {
+			code := er.DataPersistenceFailure
+			if errors.Is(err, dao.ErrInvalidConfiguration) {
+				code = er.ValidationFailCode
+			}
+			er.Handle(w, r, er.NewError(code, err))
 			s.recordRequestStatus(constants.UpdateConfiguration, constants.Fail)
 			return
 		}
 
+		// Every node caches the app, including its configuration
+		s.Supervisor.AppDetailsUpdated(app.AppId)
+
 		s.recordRequestStatus(constants.UpdateConfiguration, constants.Success)
 		status := Status{
 			StatusCode:    constants.SuccessCode201,
@@ -59,6 +59,15 @@ func TestService_UpdateConfiguration(t *testing.T) {
 			}`),
 			http.StatusInternalServerError,
 		},
+		{
+			"testInvalidConfiguration",
+			[]byte(`{
+			   "retryPolicy": {
+				   "maxAttempts": 0
+			   }
+			}`),
+			http.StatusBadRequest,
+		},
 		{
 			"test",
 			[]byte(`{
@@ -73,13 +82,13 @@ func TestService_UpdateConfiguration(t *testing.T) {
 			http.StatusOK,
 		},
 	} {
-		req, err := http.NewRequest("PUT", "/myss/app/:app_id/configuration", bytes.NewBuffer(test.Byte))
+		req, err := http.NewRequest("PATCH", "/goscheduler/apps/:appId/configuration", bytes.NewBuffer(test.Byte))
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		vars := map[string]string{
-			"app_id": test.App,
+			"appId": test.App,
 		}
 
 		req = mux.SetURLVars(req, vars)
@@ -0,0 +1,162 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Role decides the operations an api key is allowed to perform
+type Role string
+
+const (
+	// Admin keys can perform every operation on every app, including registering apps and issuing keys
+	Admin Role = "admin"
+	// AppWriter keys can create, update and delete the schedules of their apps
+	AppWriter Role = "app-writer"
+	// AppReader keys can only read the schedules and configuration of their apps
+	AppReader Role = "app-reader"
+)
+
+// Rank of the roles, a role is allowed everything a lower ranked role is
+var roleRanks = map[Role]int{AppReader: 0, AppWriter: 1, Admin: 2}
+
+// Validate checks if the role is a known one
+func (r Role) Validate() error {
+	if _, ok := roleRanks[r]; !ok {
+		return fmt.Errorf("invalid role %s, allowed values are %s, %s and %s", r, Admin, AppWriter, AppReader)
+	}
+	return nil
+}
+
+// Allows checks if the role is allowed to perform operations requiring the given role
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// ApiKey authenticates the callers of the API. Only the hash of its secret is stored.
+type ApiKey struct {
+	Id        string    `json:"id"`
+	Apps      []string  `json:"apps,omitempty"`
+	Role      Role      `json:"role"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"createdAt"`
+	RotatedAt time.Time `json:"rotatedAt"`
+}
+
+// Validate checks the role of the key and that keys other than admin ones are scoped to apps
+func (k ApiKey) Validate() error {
+	if err := k.Role.Validate(); err != nil {
+		return err
+	}
+	if k.Role != Admin && len(k.Apps) == 0 {
+		return errors.New("apps are mandatory for keys other than admin keys")
+	}
+	for _, app := range k.Apps {
+		if strings.TrimSpace(app) == "" {
+			return errors.New("app ids of a key cannot be empty")
+		}
+	}
+	return nil
+}
+
+// CanAccess checks if the key is allowed to perform operations requiring the given role on the app
+func (k ApiKey) CanAccess(appId string, required Role) bool {
+	if !k.Role.Allows(required) {
+		return false
+	}
+	if k.Role == Admin {
+		return true
+	}
+	for _, app := range k.Apps {
+		if app == appId {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches checks the secret against the stored hash in constant time
+func (k ApiKey) Matches(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(k.Hash)) == 1
+}
+
+// NewApiKey creates a key for the apps with the given role, the token to be handed over to the caller is returned along with it
+func NewApiKey(apps []string, role Role) (ApiKey, string, error) {
+	id, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return ApiKey{}, "", err
+	}
+
+	key := ApiKey{Id: id, Apps: apps, Role: role, CreatedAt: time.Now()}
+	token, err := key.newSecret()
+	return key, token, err
+}
+
+// Rotate replaces the secret of the key, the token with the new secret is returned
+func (k *ApiKey) Rotate() (string, error) {
+	token, err := k.newSecret()
+	if err == nil {
+		k.RotatedAt = time.Now()
+	}
+	return token, err
+}
+
+func (k *ApiKey) newSecret() (string, error) {
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", err
+	}
+
+	k.Hash = HashSecret(secret)
+	return k.Id + "." + secret, nil
+}
+
+// ParseApiKey splits a token into the id of its key and its secret
+func ParseApiKey(token string) (string, string, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.New("malformed api key")
+	}
+	return parts[0], parts[1], nil
+}
+
+// HashSecret returns the hex encoded SHA-256 hash of a secret
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(size int, encode func([]byte) string) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
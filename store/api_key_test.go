@@ -0,0 +1,129 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoleAllows(t *testing.T) {
+	for _, test := range []struct {
+		role     Role
+		required Role
+		want     bool
+	}{
+		{Admin, Admin, true},
+		{Admin, AppReader, true},
+		{AppWriter, AppWriter, true},
+		{AppWriter, AppReader, true},
+		{AppWriter, Admin, false},
+		{AppReader, AppWriter, false},
+		{Role("owner"), AppReader, false},
+	} {
+		if got := test.role.Allows(test.required); got != test.want {
+			t.Errorf("%s.Allows(%s) = %t, want %t", test.role, test.required, got, test.want)
+		}
+	}
+}
+
+func TestApiKeyValidate(t *testing.T) {
+	for _, test := range []struct {
+		key   ApiKey
+		valid bool
+	}{
+		{ApiKey{Role: Admin}, true},
+		{ApiKey{Role: AppWriter, Apps: []string{"test"}}, true},
+		{ApiKey{Role: AppReader}, false},
+		{ApiKey{Role: AppReader, Apps: []string{" "}}, false},
+		{ApiKey{Role: "owner", Apps: []string{"test"}}, false},
+	} {
+		if err := test.key.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %t", test.key, err, test.valid)
+		}
+	}
+}
+
+func TestApiKeyCanAccess(t *testing.T) {
+	writer := ApiKey{Role: AppWriter, Apps: []string{"test"}}
+	admin := ApiKey{Role: Admin}
+
+	for _, test := range []struct {
+		key      ApiKey
+		appId    string
+		required Role
+		want     bool
+	}{
+		{writer, "test", AppWriter, true},
+		{writer, "test", AppReader, true},
+		{writer, "other", AppReader, false},
+		{writer, "", AppReader, false},
+		{writer, "test", Admin, false},
+		{admin, "other", AppWriter, true},
+		{admin, "", AppReader, true},
+	} {
+		if got := test.key.CanAccess(test.appId, test.required); got != test.want {
+			t.Errorf("CanAccess(%q, %s) of %s key = %t, want %t", test.appId, test.required, test.key.Role, got, test.want)
+		}
+	}
+}
+
+func TestNewApiKey(t *testing.T) {
+	key, token, err := NewApiKey([]string{"test"}, AppReader)
+	if err != nil {
+		t.Fatalf("NewApiKey() error = %v", err)
+	}
+
+	id, secret, err := ParseApiKey(token)
+	if err != nil {
+		t.Fatalf("ParseApiKey(%s) error = %v", token, err)
+	}
+	if id != key.Id {
+		t.Errorf("ParseApiKey() id = %s, want %s", id, key.Id)
+	}
+	if !key.Matches(secret) {
+		t.Errorf("Matches() = false for the issued secret")
+	}
+	if strings.Contains(key.Hash, secret) {
+		t.Errorf("hash %s contains the secret", key.Hash)
+	}
+
+	rotated, err := key.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if key.Matches(secret) {
+		t.Errorf("Matches() = true for the secret from before the rotation")
+	}
+	if _, newSecret, _ := ParseApiKey(rotated); !key.Matches(newSecret) {
+		t.Errorf("Matches() = false for the rotated secret")
+	}
+	if key.RotatedAt.IsZero() {
+		t.Errorf("Rotate() did not set the rotation time")
+	}
+}
+
+func TestParseApiKey(t *testing.T) {
+	for _, token := range []string{"", "abc", ".secret", "abc."} {
+		if _, _, err := ParseApiKey(token); err == nil {
+			t.Errorf("ParseApiKey(%q) expected an error", token)
+		}
+	}
+}
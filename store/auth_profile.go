@@ -0,0 +1,124 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Supported callback auth profile types
+const (
+	AuthTypeOAuth2 = "oauth2"
+	AuthTypeMTLS   = "mtls"
+	AuthTypeBasic  = "basic"
+)
+
+// Prefixes of supported secret references
+const (
+	envSecretPrefix  = "env:"
+	fileSecretPrefix = "file:"
+)
+
+// AuthProfile is a named, per-app callback authentication profile. Http callbacks refer to a profile by name
+// so that credentials are never stored inline with schedules. Secrets are never stored either, only references
+// to them in the form "env:NAME" or "file:/path/to/secret".
+type AuthProfile struct {
+	Type string `json:"type"`
+
+	// OAuth2 client-credentials
+	TokenUrl        string   `json:"tokenUrl,omitempty"`
+	ClientId        string   `json:"clientId,omitempty"`
+	ClientSecretRef string   `json:"clientSecretRef,omitempty"`
+	Scopes          []string `json:"scopes,omitempty"`
+
+	// mTLS client certificates
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	CAFile   string `json:"caFile,omitempty"`
+
+	// Basic auth
+	Username    string `json:"username,omitempty"`
+	PasswordRef string `json:"passwordRef,omitempty"`
+}
+
+// Validate checks that all the fields required by the profile type are present
+func (a AuthProfile) Validate() error {
+	switch a.Type {
+	case AuthTypeOAuth2:
+		if a.TokenUrl == "" {
+			return errors.New("tokenUrl cannot be empty for oauth2 auth profile")
+		}
+		if _, err := url.ParseRequestURI(a.TokenUrl); err != nil {
+			return errors.New("invalid tokenUrl for oauth2 auth profile")
+		}
+		if a.ClientId == "" {
+			return errors.New("clientId cannot be empty for oauth2 auth profile")
+		}
+		return validateSecretRef(a.ClientSecretRef, "clientSecretRef")
+	case AuthTypeMTLS:
+		if a.CertFile == "" || a.KeyFile == "" {
+			return errors.New("certFile and keyFile cannot be empty for mtls auth profile")
+		}
+		return nil
+	case AuthTypeBasic:
+		if a.Username == "" {
+			return errors.New("username cannot be empty for basic auth profile")
+		}
+		return validateSecretRef(a.PasswordRef, "passwordRef")
+	default:
+		return fmt.Errorf("invalid auth profile type %s", a.Type)
+	}
+}
+
+// ResolveSecret returns the secret value a reference points to
+func ResolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, envSecretPrefix):
+		name := strings.TrimPrefix(ref, envSecretPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret env variable %s is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, fileSecretPrefix):
+		data, err := ioutil.ReadFile(strings.TrimPrefix(ref, fileSecretPrefix))
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("invalid secret reference %s", ref)
+	}
+}
+
+func validateSecretRef(ref string, field string) error {
+	if ref == "" {
+		return fmt.Errorf("%s cannot be empty", field)
+	}
+	if !strings.HasPrefix(ref, envSecretPrefix) && !strings.HasPrefix(ref, fileSecretPrefix) {
+		return fmt.Errorf("%s must be a secret reference starting with %s or %s", field, envSecretPrefix, fileSecretPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile AuthProfile
+		wantErr bool
+	}{
+		{"valid oauth2", AuthProfile{Type: AuthTypeOAuth2, TokenUrl: "https://auth.example.com/token", ClientId: "id", ClientSecretRef: "env:SECRET"}, false},
+		{"oauth2 without token url", AuthProfile{Type: AuthTypeOAuth2, ClientId: "id", ClientSecretRef: "env:SECRET"}, true},
+		{"oauth2 with inline secret", AuthProfile{Type: AuthTypeOAuth2, TokenUrl: "https://auth.example.com/token", ClientId: "id", ClientSecretRef: "secret"}, true},
+		{"valid mtls", AuthProfile{Type: AuthTypeMTLS, CertFile: "/certs/client.pem", KeyFile: "/certs/client.key"}, false},
+		{"mtls without key", AuthProfile{Type: AuthTypeMTLS, CertFile: "/certs/client.pem"}, true},
+		{"valid basic", AuthProfile{Type: AuthTypeBasic, Username: "user", PasswordRef: "file:/secrets/password"}, false},
+		{"basic without password", AuthProfile{Type: AuthTypeBasic, Username: "user"}, true},
+		{"unknown type", AuthProfile{Type: "digest"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.profile.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	os.Setenv("GOSCHEDULER_TEST_SECRET", "from-env")
+	defer os.Unsetenv("GOSCHEDULER_TEST_SECRET")
+
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "secret")
+	if err = ioutil.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := ResolveSecret("env:GOSCHEDULER_TEST_SECRET"); err != nil || got != "from-env" {
+		t.Errorf("ResolveSecret() = %s, %v, expected from-env", got, err)
+	}
+
+	if got, err := ResolveSecret("file:" + path); err != nil || got != "from-file" {
+		t.Errorf("ResolveSecret() = %s, %v, expected from-file", got, err)
+	}
+
+	if _, err := ResolveSecret("env:GOSCHEDULER_TEST_MISSING"); err == nil {
+		t.Errorf("ResolveSecret() expected error for missing env variable")
+	}
+
+	if _, err := ResolveSecret("plain"); err == nil {
+		t.Errorf("ResolveSecret() expected error for invalid reference")
+	}
+}
+
+func TestValidateAuthProfile(t *testing.T) {
+	app := App{
+		AppId: "test",
+		Configuration: Configuration{
+			AuthProfiles: map[string]AuthProfile{
+				"orders": {Type: AuthTypeBasic, Username: "user", PasswordRef: "env:PASSWORD"},
+			},
+		},
+	}
+
+	callback := &HttpCallback{Type: "http", Details: Details{Url: "http://example.com", Method: "GET", AuthProfile: "orders"}}
+	if errStr := validateAuthProfile(callback, app); errStr != "" {
+		t.Errorf("validateAuthProfile() unexpected error %s", errStr)
+	}
+
+	callback.Details.AuthProfile = "payments"
+	if errStr := validateAuthProfile(callback, app); errStr == "" {
+		t.Errorf("validateAuthProfile() expected error for unknown profile")
+	}
+}
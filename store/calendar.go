@@ -0,0 +1,186 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// CalendarPolicy decides what happens to a run of a recurring schedule falling on a blocked time of its calendar
+type CalendarPolicy string
+
+const (
+	// CalendarSkip does not create the run
+	CalendarSkip CalendarPolicy = "skip"
+	// CalendarShift moves the run to the same time on the next business day
+	CalendarShift CalendarPolicy = "shift"
+)
+
+const (
+	calendarDateLayout = "2006-01-02"
+	// Runs are shifted by at most a year
+	maxShiftDays = 366
+)
+
+// Calendar is a named set of blackout dates and time windows.
+// Weekend days are not business days, runs are never shifted onto them.
+type Calendar struct {
+	Name     string   `json:"name"`
+	Timezone string   `json:"timezone,omitempty"`
+	Dates    []string `json:"dates,omitempty"`
+	Windows  []Window `json:"windows,omitempty"`
+	Weekend  []string `json:"weekend,omitempty"`
+}
+
+// Window is a blackout time window, the start is inclusive and the end exclusive
+type Window struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Validate checks if the calendar has a name, a known timezone, well formed dates and windows and known weekend days
+func (c Calendar) Validate() []string {
+	var errs []string
+
+	if c.Name == "" {
+		errs = append(errs, "calendar name cannot be empty")
+	}
+
+	if _, err := time.LoadLocation(c.Timezone); err != nil {
+		errs = append(errs, fmt.Sprintf("invalid timezone %s", c.Timezone))
+	}
+
+	for _, date := range c.Dates {
+		if _, err := time.Parse(calendarDateLayout, date); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid date %s, expected format %s", date, calendarDateLayout))
+		}
+	}
+
+	for _, window := range c.Windows {
+		if !window.End.After(window.Start) {
+			errs = append(errs, fmt.Sprintf("window end %s should be after its start %s", window.End, window.Start))
+		}
+	}
+
+	for _, day := range c.Weekend {
+		if _, err := parseWeekday(day); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	return errs
+}
+
+// IsBlocked checks if the time falls on a blackout date, a weekend day or within a blackout window of the calendar
+func (c Calendar) IsBlocked(t time.Time) bool {
+	if c.isHoliday(t) {
+		return true
+	}
+
+	for _, window := range c.Windows {
+		if !t.Before(window.Start) && t.Before(window.End) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextBusinessTime finds the same time of the day on the first following day which is not blocked.
+// Returns false if there is no such day within a year.
+func (c Calendar) NextBusinessTime(t time.Time) (time.Time, bool) {
+	local := t.In(c.location())
+
+	for day := 1; day <= maxShiftDays; day++ {
+		next := local.AddDate(0, 0, day)
+		if !c.IsBlocked(next) {
+			return next, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (c Calendar) isHoliday(t time.Time) bool {
+	local := t.In(c.location())
+
+	date := local.Format(calendarDateLayout)
+	for _, holiday := range c.Dates {
+		if holiday == date {
+			return true
+		}
+	}
+
+	for _, day := range c.Weekend {
+		if weekday, err := parseWeekday(day); err == nil && weekday == local.Weekday() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c Calendar) location() *time.Location {
+	if location, err := time.LoadLocation(c.Timezone); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+func parseWeekday(day string) (time.Weekday, error) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if weekday.String() == day {
+			return weekday, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("invalid weekend day %s, expected one of Sunday to Saturday", day)
+}
+
+// GetCalendarPolicy returns the calendar policy of the schedule, defaulting to skip
+func (s Schedule) GetCalendarPolicy() CalendarPolicy {
+	if s.CalendarPolicy == "" {
+		return CalendarSkip
+	}
+	return s.CalendarPolicy
+}
+
+func validateCalendar(s Schedule) []string {
+	var errs []string
+
+	if s.Calendar == "" {
+		if s.CalendarPolicy != "" {
+			errs = append(errs, "calendarPolicy requires a calendar")
+		}
+		return errs
+	}
+
+	if !s.IsRecurring() {
+		errs = append(errs, "calendar is supported only for recurring schedules")
+	}
+
+	switch s.CalendarPolicy {
+	case "", CalendarSkip, CalendarShift:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid calendarPolicy %s, allowed values are %s and %s", s.CalendarPolicy, CalendarSkip, CalendarShift))
+	}
+
+	return errs
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendar_Validate(t *testing.T) {
+	for _, test := range []struct {
+		calendar Calendar
+		errs     int
+	}{
+		{Calendar{Name: "holidays", Dates: []string{"2023-12-25"}, Weekend: []string{"Saturday", "Sunday"}}, 0},
+		{Calendar{Dates: []string{"2023-12-25"}}, 1},
+		{Calendar{Name: "holidays", Timezone: "Mars/Olympus"}, 1},
+		{Calendar{Name: "holidays", Dates: []string{"25-12-2023"}}, 1},
+		{Calendar{Name: "holidays", Weekend: []string{"Sat"}}, 1},
+		{Calendar{Name: "holidays", Windows: []Window{{Start: time.Unix(200, 0), End: time.Unix(100, 0)}}}, 1},
+	} {
+		if errs := test.calendar.Validate(); len(errs) != test.errs {
+			t.Errorf("Validate(%+v) = %v, want %d errors", test.calendar, errs, test.errs)
+		}
+	}
+}
+
+func TestCalendar_IsBlocked(t *testing.T) {
+	calendar := Calendar{
+		Name:     "holidays",
+		Timezone: "Asia/Kolkata",
+		Dates:    []string{"2023-12-25"},
+		Windows:  []Window{{Start: time.Date(2023, 12, 27, 10, 0, 0, 0, time.UTC), End: time.Date(2023, 12, 27, 12, 0, 0, 0, time.UTC)}},
+		Weekend:  []string{"Sunday"},
+	}
+
+	for _, test := range []struct {
+		at      time.Time
+		blocked bool
+	}{
+		{time.Date(2023, 12, 25, 9, 0, 0, 0, time.UTC), true},
+		// 2023-12-24 20:00 UTC is already the 25th in Kolkata
+		{time.Date(2023, 12, 24, 20, 0, 0, 0, time.UTC), true},
+		{time.Date(2023, 12, 24, 10, 0, 0, 0, time.UTC), true},
+		{time.Date(2023, 12, 26, 9, 0, 0, 0, time.UTC), false},
+		{time.Date(2023, 12, 27, 10, 0, 0, 0, time.UTC), true},
+		{time.Date(2023, 12, 27, 12, 0, 0, 0, time.UTC), false},
+	} {
+		if blocked := calendar.IsBlocked(test.at); blocked != test.blocked {
+			t.Errorf("IsBlocked(%s) = %v, want %v", test.at, blocked, test.blocked)
+		}
+	}
+}
+
+func TestCalendar_NextBusinessTime(t *testing.T) {
+	calendar := Calendar{Name: "holidays", Dates: []string{"2023-12-25", "2023-12-26"}, Weekend: []string{"Saturday", "Sunday"}}
+
+	// Friday the 22nd is followed by a weekend and two holidays
+	next, ok := calendar.NextBusinessTime(time.Date(2023, 12, 22, 9, 30, 0, 0, time.UTC))
+	if !ok || !next.Equal(time.Date(2023, 12, 27, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("NextBusinessTime() = %s, %v", next, ok)
+	}
+
+	always := Calendar{Name: "always", Weekend: []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}}
+	if _, ok = always.NextBusinessTime(time.Now()); ok {
+		t.Errorf("NextBusinessTime() should fail without business days")
+	}
+}
+
+func TestValidateCalendar(t *testing.T) {
+	for _, test := range []struct {
+		schedule Schedule
+		errs     int
+	}{
+		{Schedule{}, 0},
+		{Schedule{CronExpression: "0 9 * * *", Calendar: "holidays"}, 0},
+		{Schedule{CronExpression: "0 9 * * *", Calendar: "holidays", CalendarPolicy: CalendarShift}, 0},
+		{Schedule{CronExpression: "0 9 * * *", Calendar: "holidays", CalendarPolicy: "later"}, 1},
+		{Schedule{CronExpression: "0 9 * * *", CalendarPolicy: CalendarShift}, 1},
+		{Schedule{ScheduleTime: 100, Calendar: "holidays"}, 1},
+	} {
+		if errs := validateCalendar(test.schedule); len(errs) != test.errs {
+			t.Errorf("validateCalendar(%+v) = %v, want %d errors", test.schedule, errs, test.errs)
+		}
+	}
+}
+
+func TestParseICal(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"X-WR-TIMEZONE:Asia/Kolkata",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20231225",
+		"DTEND;VALUE=DATE:20231227",
+		"SUMMARY:Christmas",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20231230T100000Z",
+		"DTEND:20231230T",
+		" 120000Z",
+		"SUMMARY:Maintenance",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;TZID=America/New_York:20231231T200000",
+		"DTEND;TZID=America/New_York:20231231T220000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20240101T090000",
+		"DTEND:20240101T100000",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	calendar, err := ParseICal("holidays", strings.NewReader(ics))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(calendar.Dates, []string{"2023-12-25", "2023-12-26"}) {
+		t.Errorf("ParseICal() dates = %v", calendar.Dates)
+	}
+
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	newYork, _ := time.LoadLocation("America/New_York")
+	windows := []Window{
+		{Start: time.Date(2023, 12, 30, 10, 0, 0, 0, time.UTC), End: time.Date(2023, 12, 30, 12, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2023, 12, 31, 20, 0, 0, 0, newYork), End: time.Date(2023, 12, 31, 22, 0, 0, 0, newYork)},
+		{Start: time.Date(2024, 1, 1, 9, 0, 0, 0, kolkata), End: time.Date(2024, 1, 1, 10, 0, 0, 0, kolkata)},
+	}
+	if len(calendar.Windows) != len(windows) {
+		t.Fatalf("ParseICal() windows = %v", calendar.Windows)
+	}
+	for i, window := range windows {
+		if !calendar.Windows[i].Start.Equal(window.Start) || !calendar.Windows[i].End.Equal(window.End) {
+			t.Errorf("ParseICal() window %d = %v, want %v", i, calendar.Windows[i], window)
+		}
+	}
+
+	recurring := "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;VALUE=DATE:20231225\nRRULE:FREQ=YEARLY\nEND:VEVENT\nEND:VCALENDAR"
+	if _, err = ParseICal("holidays", strings.NewReader(recurring)); err == nil {
+		t.Errorf("ParseICal() should reject recurring events")
+	}
+}
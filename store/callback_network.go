@@ -0,0 +1,145 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// linkLocalNetworks are never reached, whatever the configuration.
+// They cover the metadata endpoints of the cloud providers like 169.254.169.254.
+var linkLocalNetworks = []string{
+	"169.254.0.0/16",
+	"fe80::/10",
+}
+
+// privateNetworks are the loopback, private and other special purpose ranges callbacks are denied by default.
+var privateNetworks = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"64:ff9b::/96",
+	"fc00::/7",
+	"ff00::/8",
+}
+
+// NetworkPolicy decides the addresses http callbacks can be made to
+type NetworkPolicy struct {
+	denied []*net.IPNet
+}
+
+// NewNetworkPolicy creates a policy denying the link-local ranges and the given CIDRs,
+// along with the private ranges unless allowPrivate is set
+func NewNetworkPolicy(allowPrivate bool, denied []string) (NetworkPolicy, error) {
+	networks := append([]string{}, linkLocalNetworks...)
+	if !allowPrivate {
+		networks = append(networks, privateNetworks...)
+	}
+	denied = append(networks, denied...)
+
+	var policy NetworkPolicy
+	for _, cidr := range denied {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return NetworkPolicy{}, fmt.Errorf("invalid denied network %q", cidr)
+		}
+		policy.denied = append(policy.denied, network)
+	}
+	return policy, nil
+}
+
+// AllowsIP checks that the address is not in any of the denied networks
+func (p NetworkPolicy) AllowsIP(ip net.IP) bool {
+	for _, network := range p.denied {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsHost checks the host of a callback url before it is resolved.
+// Only addresses and localhost can be checked here, the resolved addresses of other hosts are checked while dialing.
+func (p NetworkPolicy) AllowsHost(host string) bool {
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return p.AllowsIP(ip)
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return p.AllowsIP(net.IPv4(127, 0, 0, 1))
+	}
+	return true
+}
+
+// validateCallbackUrl checks that the url is an http url which does not target a denied network
+func validateCallbackUrl(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("callback url scheme must be http or https, got %q", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return errors.New("callback url must have a host")
+	}
+
+	if !callbackNetworks.AllowsHost(u.Hostname()) {
+		return fmt.Errorf("callback url host %s is in a denied network", u.Hostname())
+	}
+	return nil
+}
+
+var callbackNetworks, _ = NewNetworkPolicy(false, nil)
+
+var linkLocalPolicy, _ = NewNetworkPolicy(true, nil)
+
+// InitializeCallbackNetworks sets the network policy applied to all http callbacks
+func InitializeCallbackNetworks(allowPrivate bool, denied []string) error {
+	policy, err := NewNetworkPolicy(allowPrivate, denied)
+	if err != nil {
+		return err
+	}
+
+	callbackNetworks = policy
+	return nil
+}
+
+// CallbackNetworks returns the network policy applied to all http callbacks
+func CallbackNetworks() NetworkPolicy {
+	return callbackNetworks
+}
+
+// LinkLocalNetworks returns the network policy denying the link-local ranges only,
+// applied to the requests which may reach private networks
+func LinkLocalNetworks() NetworkPolicy {
+	return linkLocalPolicy
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNetworkPolicy(t *testing.T) {
+	policy, err := NewNetworkPolicy(false, []string{"203.0.113.0/24"})
+	if err != nil {
+		t.Fatalf("NewNetworkPolicy() error = %v", err)
+	}
+
+	for address, allowed := range map[string]bool{
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.20.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+		"203.0.113.10":     false,
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+	} {
+		if got := policy.AllowsIP(net.ParseIP(address)); got != allowed {
+			t.Errorf("AllowsIP(%s) = %t, want %t", address, got, allowed)
+		}
+	}
+
+	for host, allowed := range map[string]bool{
+		"localhost":       false,
+		"api.localhost":   false,
+		"[::1]":           false,
+		"api.example.com": true,
+		"93.184.216.34":   true,
+		"169.254.169.254": false,
+	} {
+		if got := policy.AllowsHost(host); got != allowed {
+			t.Errorf("AllowsHost(%s) = %t, want %t", host, got, allowed)
+		}
+	}
+}
+
+func TestNetworkPolicyAllowPrivate(t *testing.T) {
+	policy, err := NewNetworkPolicy(true, nil)
+	if err != nil {
+		t.Fatalf("NewNetworkPolicy() error = %v", err)
+	}
+
+	if !policy.AllowsHost("localhost") || !policy.AllowsIP(net.ParseIP("10.1.2.3")) {
+		t.Errorf("policy allowing private networks denies a private address")
+	}
+
+	// Link-local addresses are denied whatever the configuration
+	for _, address := range []string{"169.254.169.254", "fe80::1", "::ffff:169.254.169.254"} {
+		if policy.AllowsIP(net.ParseIP(address)) {
+			t.Errorf("policy allowing private networks allows %s", address)
+		}
+	}
+
+	if _, err = NewNetworkPolicy(true, []string{"10.0.0.0"}); err == nil {
+		t.Errorf("NewNetworkPolicy() with an invalid CIDR = nil, want error")
+	}
+}
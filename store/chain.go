@@ -0,0 +1,194 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Maximum number of nested templates in a chain
+const maxChainDepth = 10
+
+// ChainTemplate describes a schedule which is created once the previous schedule in the chain has fired.
+// The template to be used is picked based on the outcome of the previous schedule.
+// A template without a payload or callback inherits the one of the previous schedule.
+type ChainTemplate struct {
+	Delay       int64           `json:"delay"`
+	Payload     string          `json:"payload,omitempty"`
+	CallbackRaw json.RawMessage `json:"callback,omitempty"`
+	OnSuccess   *ChainTemplate  `json:"onSuccess,omitempty"`
+	OnFailure   *ChainTemplate  `json:"onFailure,omitempty"`
+}
+
+// chain is the persisted chain information of a schedule
+type chain struct {
+	OnSuccess          *ChainTemplate `json:"onSuccess,omitempty"`
+	OnFailure          *ChainTemplate `json:"onFailure,omitempty"`
+	PreviousScheduleId string         `json:"previousScheduleId,omitempty"`
+}
+
+// HasChain checks if the schedule is part of a chain
+func (s Schedule) HasChain() bool {
+	return s.OnSuccess != nil || s.OnFailure != nil || s.PreviousScheduleId != ""
+}
+
+// GetChain returns the chain information of the schedule to be persisted
+func (s Schedule) GetChain() string {
+	if !s.HasChain() {
+		return ""
+	}
+	data, _ := json.Marshal(chain{OnSuccess: s.OnSuccess, OnFailure: s.OnFailure, PreviousScheduleId: s.PreviousScheduleId})
+	return string(data)
+}
+
+// SetChain sets the persisted chain information on the schedule
+func (s *Schedule) SetChain(data string) error {
+	if data == "" {
+		return nil
+	}
+
+	var c chain
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return err
+	}
+
+	s.OnSuccess = c.OnSuccess
+	s.OnFailure = c.OnFailure
+	s.PreviousScheduleId = c.PreviousScheduleId
+
+	return nil
+}
+
+// NextChainTemplate returns the template of the schedule to be created after the schedule has fired with its current status
+func (s Schedule) NextChainTemplate() *ChainTemplate {
+	switch s.Status {
+	case Success:
+		return s.OnSuccess
+	case Failure, Partial, Expired:
+		return s.OnFailure
+	default:
+		return nil
+	}
+}
+
+// Materialize creates the next schedule of the chain from the template.
+// The schedule is due after the template delay, but not earlier than the next minute so that it is not missed by the pollers.
+// The payload and callback of the previous schedule are inherited unless the template overrides them.
+func (t ChainTemplate) Materialize(previous Schedule, now time.Time) (Schedule, error) {
+	payload, callback, callbackRaw := t.Payload, previous.Callback, previous.CallbackRaw
+	if payload == "" {
+		payload = previous.Payload
+	}
+	if len(t.CallbackRaw) > 0 {
+		var err error
+		if callback, err = parseCallback(t.CallbackRaw); err != nil {
+			return Schedule{}, err
+		}
+		callbackRaw = t.CallbackRaw
+	}
+
+	at := now.Add(time.Duration(t.Delay) * time.Second)
+	if nextMinute := now.Truncate(time.Minute).Add(time.Minute); at.Before(nextMinute) {
+		at = nextMinute
+	}
+
+	return Schedule{
+		AppId:              previous.AppId,
+		Payload:            payload,
+		ScheduleTime:       at.Unix(),
+		Callback:           callback,
+		CallbackRaw:        callbackRaw,
+		OnSuccess:          t.OnSuccess,
+		OnFailure:          t.OnFailure,
+		PreviousScheduleId: previous.ScheduleId.String(),
+	}, nil
+}
+
+func (t *ChainTemplate) validate(name string, depth int) []string {
+	if t == nil {
+		return nil
+	}
+
+	if depth > maxChainDepth {
+		return []string{fmt.Sprintf("chain cannot be deeper than %d schedules", maxChainDepth)}
+	}
+
+	var errs []string
+
+	if t.Delay < 0 {
+		errs = append(errs, fmt.Sprintf("%s delay cannot be negative", name))
+	}
+
+	// An empty payload or callback is inherited from the previous schedule
+	if len(t.CallbackRaw) > 0 {
+		if callback, err := parseCallback(t.CallbackRaw); err != nil {
+			errs = append(errs, fmt.Sprintf("%s callback is invalid: %s", name, err.Error()))
+		} else if err = callback.Validate(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s callback is invalid: %s", name, err.Error()))
+		}
+	}
+
+	errs = append(errs, t.OnSuccess.validate(name+".onSuccess", depth+1)...)
+	errs = append(errs, t.OnFailure.validate(name+".onFailure", depth+1)...)
+
+	return errs
+}
+
+func validateChain(s Schedule) []string {
+	if s.OnSuccess == nil && s.OnFailure == nil {
+		return nil
+	}
+
+	if s.IsRecurring() {
+		return []string{"onSuccess and onFailure are not supported for recurring schedules"}
+	}
+
+	return append(s.OnSuccess.validate("onSuccess", 1), s.OnFailure.validate("onFailure", 1)...)
+}
+
+// parseCallback creates a callback of the registered type from its json
+func parseCallback(raw json.RawMessage) (Callback, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("callback cannot be empty")
+	}
+
+	var callbackData struct {
+		Type string `json:"type"`
+	}
+
+	if err := json.Unmarshal(raw, &callbackData); err != nil {
+		return nil, err
+	}
+
+	factoryFunc, ok := Registry[callbackData.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown callback type: %s", callbackData.Type)
+	}
+
+	callback := factoryFunc()
+	if err := json.Unmarshal(raw, callback); err != nil {
+		return nil, err
+	}
+
+	return callback, nil
+}
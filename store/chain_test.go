@@ -0,0 +1,163 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+func chainCallback() json.RawMessage {
+	return json.RawMessage(`{"type":"http","details":{"url":"http://example.com/next","method":"POST"}}`)
+}
+
+func TestScheduleChainRoundTrip(t *testing.T) {
+	schedule := Schedule{
+		OnSuccess:          &ChainTemplate{Delay: 60, Payload: "{}", CallbackRaw: chainCallback()},
+		PreviousScheduleId: gocql.TimeUUID().String(),
+	}
+
+	var got Schedule
+	if err := got.SetChain(schedule.GetChain()); err != nil {
+		t.Fatalf("SetChain() failed with error: %s", err.Error())
+	}
+
+	if got.OnSuccess == nil || got.OnSuccess.Delay != 60 || got.OnFailure != nil || got.PreviousScheduleId != schedule.PreviousScheduleId {
+		t.Errorf("SetChain() got %+v, expected %+v", got, schedule)
+	}
+
+	if (Schedule{}).GetChain() != "" {
+		t.Errorf("GetChain() expected empty chain for schedule without chain")
+	}
+}
+
+func TestNextChainTemplate(t *testing.T) {
+	onSuccess := &ChainTemplate{Payload: "success"}
+	onFailure := &ChainTemplate{Payload: "failure"}
+	schedule := Schedule{OnSuccess: onSuccess, OnFailure: onFailure}
+
+	for status, expected := range map[Status]*ChainTemplate{
+		Success:   onSuccess,
+		Failure:   onFailure,
+		Partial:   onFailure,
+		Scheduled: nil,
+	} {
+		schedule.Status = status
+		if got := schedule.NextChainTemplate(); got != expected {
+			t.Errorf("NextChainTemplate() for status %s got %+v, expected %+v", status, got, expected)
+		}
+	}
+}
+
+func TestChainTemplateMaterialize(t *testing.T) {
+	Registry["http"] = func() Callback { return &HttpCallback{} }
+
+	previous := Schedule{ScheduleId: gocql.TimeUUID(), AppId: "test"}
+	now := time.Date(2023, 6, 13, 10, 0, 30, 0, time.UTC)
+	next := &ChainTemplate{Payload: "next"}
+
+	template := ChainTemplate{Delay: 300, Payload: "{}", CallbackRaw: chainCallback(), OnSuccess: next}
+	schedule, err := template.Materialize(previous, now)
+	if err != nil {
+		t.Fatalf("Materialize() failed with error: %s", err.Error())
+	}
+
+	if schedule.AppId != "test" || schedule.ScheduleTime != now.Add(300*time.Second).Unix() ||
+		schedule.PreviousScheduleId != previous.ScheduleId.String() || schedule.OnSuccess != next {
+		t.Errorf("Materialize() got %+v", schedule)
+	}
+	if _, ok := schedule.Callback.(*HttpCallback); !ok {
+		t.Errorf("Materialize() expected http callback, got %T", schedule.Callback)
+	}
+
+	template.Delay = 0
+	if schedule, _ = template.Materialize(previous, now); schedule.ScheduleTime != time.Date(2023, 6, 13, 10, 1, 0, 0, time.UTC).Unix() {
+		t.Errorf("Materialize() expected schedule at the next minute, got %d", schedule.ScheduleTime)
+	}
+}
+
+func TestChainTemplateMaterializeInherited(t *testing.T) {
+	Registry["http"] = func() Callback { return &HttpCallback{} }
+
+	callback := &HttpCallback{Type: "http", Details: Details{Url: "https://previous.url", Method: "POST"}}
+	previous := Schedule{ScheduleId: gocql.TimeUUID(), AppId: "test", Payload: "previous", Callback: callback, CallbackRaw: json.RawMessage(`{"type":"http"}`)}
+	now := time.Date(2023, 6, 13, 10, 0, 30, 0, time.UTC)
+
+	schedule, err := ChainTemplate{Delay: 60}.Materialize(previous, now)
+	if err != nil {
+		t.Fatalf("Materialize() failed with error: %s", err.Error())
+	}
+	if schedule.Payload != "previous" || schedule.Callback != callback || string(schedule.CallbackRaw) != `{"type":"http"}` {
+		t.Errorf("Materialize() expected the payload and callback of the previous schedule, got %+v", schedule)
+	}
+
+	// The template overrides what it sets
+	schedule, err = ChainTemplate{Delay: 60, Payload: "next"}.Materialize(previous, now)
+	if err != nil {
+		t.Fatalf("Materialize() failed with error: %s", err.Error())
+	}
+	if schedule.Payload != "next" || schedule.Callback != callback {
+		t.Errorf("Materialize() expected the payload of the template, got %+v", schedule)
+	}
+
+	schedule, err = ChainTemplate{Delay: 60, CallbackRaw: chainCallback()}.Materialize(previous, now)
+	if err != nil {
+		t.Fatalf("Materialize() failed with error: %s", err.Error())
+	}
+	if schedule.Payload != "previous" || schedule.Callback == Callback(callback) {
+		t.Errorf("Materialize() expected the callback of the template, got %+v", schedule)
+	}
+}
+
+func TestValidateChain(t *testing.T) {
+	Registry["http"] = func() Callback { return &HttpCallback{} }
+
+	valid := &ChainTemplate{Delay: 60, Payload: "{}", CallbackRaw: chainCallback()}
+
+	deep := &ChainTemplate{Delay: 60, Payload: "{}", CallbackRaw: chainCallback()}
+	for i := 0; i < maxChainDepth; i++ {
+		deep = &ChainTemplate{Delay: 60, Payload: "{}", CallbackRaw: chainCallback(), OnSuccess: deep}
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		wantErr  bool
+	}{
+		{"no chain", Schedule{}, false},
+		{"valid chain", Schedule{OnSuccess: valid, OnFailure: valid}, false},
+		{"recurring schedule", Schedule{CronExpression: "* * * * *", OnSuccess: valid}, true},
+		{"negative delay", Schedule{OnFailure: &ChainTemplate{Delay: -1, Payload: "{}", CallbackRaw: chainCallback()}}, true},
+		{"inherited payload and callback", Schedule{OnSuccess: &ChainTemplate{Delay: 60}}, false},
+		{"unknown callback", Schedule{OnSuccess: &ChainTemplate{Payload: "{}", CallbackRaw: json.RawMessage(`{"type":"unknown"}`)}}, true},
+		{"too deep", Schedule{OnSuccess: deep}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := validateChain(tt.schedule); (len(errs) != 0) != tt.wantErr {
+				t.Errorf("validateChain() errors = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,9 +1,110 @@
 package store
 
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+)
+
 type Configuration struct {
-	FutureScheduleCreationPeriod int `json:"futureScheduleCreationPeriod,omitempty"`
-	FiredScheduleRetentionPeriod int `json:"firedScheduleRetentionPeriod,omitempty"`
-	PayloadSize                  int `json:"payloadSize,omitempty"`
-	HttpRetries                  int `json:"httpRetries,omitempty"`
-	HttpTimeout                  int `json:"httpTimeout,omitempty"`
+	FutureScheduleCreationPeriod int                    `json:"futureScheduleCreationPeriod,omitempty"`
+	FiredScheduleRetentionPeriod int                    `json:"firedScheduleRetentionPeriod,omitempty"`
+	PayloadSize                  int                    `json:"payloadSize,omitempty"`
+	HttpRetries                  int                    `json:"httpRetries,omitempty"`
+	HttpTimeout                  int                    `json:"httpTimeout,omitempty"`
+	AuthProfiles                 map[string]AuthProfile `json:"authProfiles,omitempty"`
+	DedupPolicy                  DedupPolicy            `json:"dedupPolicy,omitempty"`
+	Jitter                       int                    `json:"jitter,omitempty"`
+	Priority                     Priority               `json:"priority,omitempty"`
+	RateLimit                    *RateLimit             `json:"rateLimit,omitempty"`
+	RetryPolicy                  *RetryPolicy           `json:"retryPolicy,omitempty"`
+	AllowedCallbackHosts         []string               `json:"allowedCallbackHosts,omitempty"`
+	Quotas                       *Quotas                `json:"quotas,omitempty"`
+}
+
+// ConfigurationVersion is an entry in the history of changes to the configuration of an app
+type ConfigurationVersion struct {
+	Version       int           `json:"version"`
+	Operation     string        `json:"operation"`
+	Configuration Configuration `json:"configuration"`
+	UpdatedAt     time.Time     `json:"updatedAt"`
+}
+
+// IsEmpty checks if none of the configurations are set
+func (c Configuration) IsEmpty() bool {
+	return reflect.DeepEqual(c, Configuration{})
+}
+
+// GetAuthProfile returns the callback auth profile registered under the given name
+func (c Configuration) GetAuthProfile(name string) (AuthProfile, bool) {
+	profile, ok := c.AuthProfiles[name]
+	return profile, ok
+}
+
+// ConfigurationPatch is a JSON merge patch (RFC 7386) of a configuration.
+// The fields present in the patch replace the existing ones, objects like authProfiles are merged field by field
+// and fields set to null are cleared, e.g. {"rateLimit": null, "authProfiles": {"legacy": null}}.
+type ConfigurationPatch []byte
+
+// ErrInvalidPatch is returned for patches which are not a JSON object
+var ErrInvalidPatch = errors.New("configuration patch must be a JSON object")
+
+// PatchOf creates a patch setting the fields set in the configuration
+func PatchOf(configuration Configuration) ConfigurationPatch {
+	patch, _ := json.Marshal(configuration)
+	return patch
+}
+
+// Validate checks if the patch is a JSON object with the fields of a configuration
+func (p ConfigurationPatch) Validate() error {
+	_, err := p.Apply(Configuration{})
+	return err
+}
+
+// Apply returns the configuration with the patch applied
+func (p ConfigurationPatch) Apply(configuration Configuration) (Configuration, error) {
+	var patch map[string]interface{}
+	if err := json.Unmarshal(p, &patch); err != nil || patch == nil {
+		return Configuration{}, ErrInvalidPatch
+	}
+
+	var target map[string]interface{}
+	data, err := json.Marshal(configuration)
+	if err != nil {
+		return Configuration{}, err
+	}
+	if err = json.Unmarshal(data, &target); err != nil {
+		return Configuration{}, err
+	}
+
+	if data, err = json.Marshal(mergePatch(target, patch)); err != nil {
+		return Configuration{}, err
+	}
+
+	var patched Configuration
+	err = json.Unmarshal(data, &patched)
+	return patched, err
+}
+
+// mergePatch applies the patch to the target as described in RFC 7386
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
 }
@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigurationPatchApply(t *testing.T) {
+	existing := Configuration{
+		PayloadSize: 1024,
+		RateLimit:   &RateLimit{Requests: 10, Period: "1m"},
+		Quotas:      &Quotas{PendingSchedules: 100},
+		AuthProfiles: map[string]AuthProfile{
+			"legacy":  {Type: "basic", Username: "old"},
+			"current": {Type: "basic", Username: "new"},
+		},
+	}
+
+	for _, test := range []struct {
+		name     string
+		patch    string
+		expected Configuration
+		valid    bool
+	}{
+		{"sets fields", `{"jitter": 60}`, Configuration{PayloadSize: 1024, Jitter: 60, RateLimit: existing.RateLimit, Quotas: existing.Quotas, AuthProfiles: existing.AuthProfiles}, true},
+		{"merges objects", `{"rateLimit": {"requests": 20}}`, Configuration{PayloadSize: 1024, RateLimit: &RateLimit{Requests: 20, Period: "1m"}, Quotas: existing.Quotas, AuthProfiles: existing.AuthProfiles}, true},
+		{"clears fields", `{"rateLimit": null, "quotas": null}`, Configuration{PayloadSize: 1024, AuthProfiles: existing.AuthProfiles}, true},
+		{"removes auth profiles", `{"authProfiles": {"legacy": null}}`, Configuration{PayloadSize: 1024, RateLimit: existing.RateLimit, Quotas: existing.Quotas, AuthProfiles: map[string]AuthProfile{"current": {Type: "basic", Username: "new"}}}, true},
+		{"not an object", `[]`, Configuration{}, false},
+		{"null", `null`, Configuration{}, false},
+		{"wrong type", `{"payloadSize": "large"}`, Configuration{}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			patched, err := ConfigurationPatch(test.patch).Apply(existing)
+			if (err == nil) != test.valid {
+				t.Fatalf("Apply() error = %v, want valid %t", err, test.valid)
+			}
+			if test.valid && !reflect.DeepEqual(patched, test.expected) {
+				t.Errorf("Apply() = %+v, want %+v", patched, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DedupPolicy decides what happens to a schedule created with the dedup key of a pending schedule of the app
+type DedupPolicy string
+
+const (
+	// DedupReplace deletes the pending schedule and creates the new one
+	DedupReplace DedupPolicy = "replace"
+	// DedupKeepFirst keeps the pending schedule and drops the new one
+	DedupKeepFirst DedupPolicy = "keep_first"
+	// DedupReject fails the creation of the new schedule
+	DedupReject DedupPolicy = "reject"
+)
+
+const maxDedupKeyLength = 256
+
+// Validate checks if the policy is a known one, an empty policy defaults to replace
+func (p DedupPolicy) Validate() error {
+	switch p {
+	case "", DedupReplace, DedupKeepFirst, DedupReject:
+		return nil
+	default:
+		return errors.New(fmt.Sprintf("invalid dedup policy %s, allowed values are %s, %s and %s", p, DedupReplace, DedupKeepFirst, DedupReject))
+	}
+}
+
+// GetDedupPolicy returns the dedup policy of the app, defaulting to replace
+func (c Configuration) GetDedupPolicy() DedupPolicy {
+	if c.DedupPolicy == "" {
+		return DedupReplace
+	}
+	return c.DedupPolicy
+}
+
+func validateDedupKey(s Schedule) []string {
+	var errs []string
+
+	if s.DedupKey == "" {
+		return errs
+	}
+
+	if s.IsRecurring() {
+		errs = append(errs, "dedupKey is not supported for recurring schedules")
+	}
+
+	if len(s.DedupKey) > maxDedupKeyLength {
+		errs = append(errs, fmt.Sprintf("dedupKey cannot be longer than %d characters", maxDedupKeyLength))
+	}
+
+	return errs
+}
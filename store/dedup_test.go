@@ -0,0 +1,63 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDedupPolicy_Validate(t *testing.T) {
+	for _, policy := range []DedupPolicy{"", DedupReplace, DedupKeepFirst, DedupReject} {
+		if err := policy.Validate(); err != nil {
+			t.Errorf("Validate(%s) returned %v", policy, err)
+		}
+	}
+
+	if err := DedupPolicy("latest").Validate(); err == nil {
+		t.Errorf("Validate(latest) should fail")
+	}
+}
+
+func TestConfiguration_GetDedupPolicy(t *testing.T) {
+	if policy := (Configuration{}).GetDedupPolicy(); policy != DedupReplace {
+		t.Errorf("GetDedupPolicy() = %s, want %s", policy, DedupReplace)
+	}
+
+	if policy := (Configuration{DedupPolicy: DedupReject}).GetDedupPolicy(); policy != DedupReject {
+		t.Errorf("GetDedupPolicy() = %s, want %s", policy, DedupReject)
+	}
+}
+
+func TestValidateDedupKey(t *testing.T) {
+	for _, test := range []struct {
+		schedule Schedule
+		errs     int
+	}{
+		{Schedule{}, 0},
+		{Schedule{DedupKey: "cart-1"}, 0},
+		{Schedule{DedupKey: "cart-1", CronExpression: "*/5 * * * *"}, 1},
+		{Schedule{DedupKey: strings.Repeat("a", maxDedupKeyLength+1)}, 1},
+	} {
+		if errs := validateDedupKey(test.schedule); len(errs) != test.errs {
+			t.Errorf("validateDedupKey(%+v) = %v, want %d errors", test.schedule, errs, test.errs)
+		}
+	}
+}
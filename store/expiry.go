@@ -0,0 +1,74 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ResolveDeadline sets the deadline of a one time schedule from its expiresAfter duration.
+// Recurring schedules keep the duration, which is resolved against the time of each run when the run is created.
+func (s *Schedule) ResolveDeadline() error {
+	if s.ExpiresAfter != "" && s.Deadline != 0 {
+		return errors.New("only one of expiresAfter and deadline can be provided")
+	}
+
+	if s.IsRecurring() || s.ExpiresAfter == "" {
+		return nil
+	}
+
+	expiresAfter, err := ParseDelay(s.ExpiresAfter)
+	if err != nil {
+		return err
+	}
+
+	s.Deadline = time.Unix(s.ScheduleTime, 0).Add(expiresAfter).Unix()
+	s.ExpiresAfter = ""
+	return nil
+}
+
+// IsExpired checks if the schedule would be fired after its deadline
+func (s Schedule) IsExpired(now time.Time) bool {
+	return s.Deadline != 0 && now.Unix() > s.Deadline
+}
+
+func validateExpiry(s Schedule) []string {
+	var errs []string
+
+	if s.ExpiresAfter != "" {
+		if expiresAfter, err := ParseDelay(s.ExpiresAfter); err != nil {
+			errs = append(errs, err.Error())
+		} else if expiresAfter == 0 {
+			errs = append(errs, "expiresAfter should be greater than 0")
+		}
+	}
+
+	switch {
+	case s.Deadline == 0:
+	case s.IsRecurring():
+		errs = append(errs, "deadline is not supported for recurring schedules, use expiresAfter instead")
+	case s.Deadline < s.ScheduleTime:
+		errs = append(errs, fmt.Sprintf("deadline %d cannot be before the schedule time %d", s.Deadline, s.ScheduleTime))
+	}
+
+	return errs
+}
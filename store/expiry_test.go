@@ -0,0 +1,95 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule_ResolveDeadline(t *testing.T) {
+	scheduleTime := time.Date(2023, 6, 13, 16, 0, 0, 0, time.UTC)
+
+	for _, test := range []struct {
+		name     string
+		schedule Schedule
+		deadline int64
+		err      bool
+	}{
+		{"no expiry", Schedule{ScheduleTime: scheduleTime.Unix()}, 0, false},
+		{"deadline", Schedule{ScheduleTime: scheduleTime.Unix(), Deadline: scheduleTime.Add(time.Hour).Unix()}, scheduleTime.Add(time.Hour).Unix(), false},
+		{"expires after", Schedule{ScheduleTime: scheduleTime.Unix(), ExpiresAfter: "30m"}, scheduleTime.Add(30 * time.Minute).Unix(), false},
+		{"iso expires after", Schedule{ScheduleTime: scheduleTime.Unix(), ExpiresAfter: "PT2H"}, scheduleTime.Add(2 * time.Hour).Unix(), false},
+		{"recurring", Schedule{CronExpression: "*/5 * * * *", ExpiresAfter: "1m"}, 0, false},
+		{"both", Schedule{ScheduleTime: scheduleTime.Unix(), ExpiresAfter: "30m", Deadline: scheduleTime.Unix()}, 0, true},
+		{"invalid", Schedule{ScheduleTime: scheduleTime.Unix(), ExpiresAfter: "soon"}, 0, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.schedule.ResolveDeadline()
+			if (err != nil) != test.err {
+				t.Fatalf("ResolveDeadline() error = %v, want error %v", err, test.err)
+			}
+			if !test.err && test.schedule.Deadline != test.deadline {
+				t.Errorf("ResolveDeadline() deadline = %d, want %d", test.schedule.Deadline, test.deadline)
+			}
+		})
+	}
+}
+
+func TestSchedule_IsExpired(t *testing.T) {
+	now := time.Now()
+
+	if (Schedule{}).IsExpired(now) {
+		t.Errorf("schedule without deadline should not expire")
+	}
+	if (Schedule{Deadline: now.Unix()}).IsExpired(now) {
+		t.Errorf("schedule should not expire at its deadline")
+	}
+	if !(Schedule{Deadline: now.Add(-time.Second).Unix()}).IsExpired(now) {
+		t.Errorf("schedule should expire after its deadline")
+	}
+}
+
+func TestValidateExpiry(t *testing.T) {
+	for _, test := range []struct {
+		schedule Schedule
+		errs     int
+	}{
+		{Schedule{ScheduleTime: 100, Deadline: 200}, 0},
+		{Schedule{ScheduleTime: 100, Deadline: 50}, 1},
+		{Schedule{CronExpression: "*/5 * * * *", ExpiresAfter: "10m"}, 0},
+		{Schedule{CronExpression: "*/5 * * * *", Deadline: 200}, 1},
+		{Schedule{CronExpression: "*/5 * * * *", ExpiresAfter: "0s"}, 1},
+		{Schedule{CronExpression: "*/5 * * * *", ExpiresAfter: "later"}, 1},
+	} {
+		if errs := validateExpiry(test.schedule); len(errs) != test.errs {
+			t.Errorf("validateExpiry(%+v) = %v, want %d errors", test.schedule, errs, test.errs)
+		}
+	}
+}
+
+func TestSchedule_CloneAsOneTimeDeadline(t *testing.T) {
+	at := time.Date(2023, 6, 13, 16, 0, 0, 0, time.UTC)
+	parent := Schedule{CronExpression: "0 16 * * *", ExpiresAfter: "15m"}
+
+	if clone := parent.CloneAsOneTime(at); clone.Deadline != at.Add(15*time.Minute).Unix() {
+		t.Errorf("CloneAsOneTime() deadline = %d, want %d", clone.Deadline, at.Add(15*time.Minute).Unix())
+	}
+}
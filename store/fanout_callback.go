@@ -0,0 +1,159 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/myntra/goscheduler/constants"
+)
+
+// Target is a single named http target of a fan-out callback
+type Target struct {
+	Name    string  `json:"name"`
+	Details Details `json:"details"`
+}
+
+// TargetStatus tracks the outcome of the callback to a single fan-out target
+type TargetStatus struct {
+	Status       Status `json:"status"`
+	Attempts     int    `json:"attempts"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// FanoutCallback notifies several http targets for a single schedule.
+// Each target is dispatched independently and the schedule status is aggregated from the target statuses.
+type FanoutCallback struct {
+	Type    string   `json:"type"`
+	Targets []Target `json:"targets"`
+}
+
+func (f *FanoutCallback) GetType() string {
+	return f.Type
+}
+
+func (f *FanoutCallback) GetDetails() (string, error) {
+	details, err := json.Marshal(f.Targets)
+	return string(details), err
+}
+
+func (f *FanoutCallback) Marshal(m map[string]interface{}) error {
+	callbackType, ok := m["callback_type"].(string)
+	if !ok {
+		return fmt.Errorf("wrong type for callback_type")
+	}
+
+	callbackDetailsJSON, ok := m["callback_details"].(string)
+	if !ok {
+		return fmt.Errorf("wrong type for callback_details")
+	}
+
+	var targets []Target
+	if err := json.Unmarshal([]byte(callbackDetailsJSON), &targets); err != nil {
+		return err
+	}
+
+	f.Type = callbackType
+	f.Targets = targets
+
+	return nil
+}
+
+// UnmarshalJSON Implement UnmarshalJSON for FanoutCallback
+func (f *FanoutCallback) UnmarshalJSON(data []byte) error {
+	type Alias FanoutCallback
+	aux := &struct {
+		*Alias
+	}{
+		Alias: (*Alias)(f),
+	}
+	return json.Unmarshal(data, &aux)
+}
+
+func (f FanoutCallback) Invoke(wrapper ScheduleWrapper) error {
+	HttpTaskQueueFor(wrapper) <- wrapper
+	return nil
+}
+
+func (f *FanoutCallback) Validate() error {
+	if len(f.Targets) == 0 {
+		return errors.New("targets cannot be empty")
+	}
+
+	names := make(map[string]bool)
+	for _, target := range f.Targets {
+		if target.Name == "" {
+			return errors.New("target name cannot be empty")
+		}
+		if names[target.Name] {
+			return fmt.Errorf("duplicate target name %s", target.Name)
+		}
+		names[target.Name] = true
+
+		if err := target.Callback().Validate(); err != nil {
+			return fmt.Errorf("invalid target %s: %s", target.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Callback returns the http callback for the target
+func (t Target) Callback() *HttpCallback {
+	return &HttpCallback{Type: constants.DefaultCallback, Details: t.Details}
+}
+
+// AggregateStatus aggregates the target statuses of a fan-out schedule.
+// The schedule is successful if all targets succeeded, failed if all failed and partial otherwise.
+func AggregateStatus(statuses map[string]TargetStatus) Status {
+	succeeded := 0
+	for _, status := range statuses {
+		if status.Status == Success {
+			succeeded++
+		}
+	}
+
+	switch succeeded {
+	case len(statuses):
+		return Success
+	case 0:
+		return Failure
+	default:
+		return Partial
+	}
+}
+
+// httpCallbacks returns the http callbacks which are fired for a callback
+func httpCallbacks(callback Callback) []*HttpCallback {
+	switch c := callback.(type) {
+	case *HttpCallback:
+		return []*HttpCallback{c}
+	case *FanoutCallback:
+		var callbacks []*HttpCallback
+		for _, target := range c.Targets {
+			callbacks = append(callbacks, target.Callback())
+		}
+		return callbacks
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+)
+
+func TestFanoutCallbackValidate(t *testing.T) {
+	valid := Details{Url: "http://example.com", Method: "POST"}
+
+	tests := []struct {
+		name    string
+		targets []Target
+		wantErr bool
+	}{
+		{"valid targets", []Target{{Name: "orders", Details: valid}, {Name: "payments", Details: valid}}, false},
+		{"no targets", nil, true},
+		{"missing name", []Target{{Details: valid}}, true},
+		{"duplicate name", []Target{{Name: "orders", Details: valid}, {Name: "orders", Details: valid}}, true},
+		{"invalid target", []Target{{Name: "orders", Details: Details{Url: "http://example.com"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callback := &FanoutCallback{Type: "fanout", Targets: tt.targets}
+			if err := callback.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFanoutCallbackMarshal(t *testing.T) {
+	callback := &FanoutCallback{
+		Type: "fanout",
+		Targets: []Target{
+			{Name: "orders", Details: Details{Url: "http://orders.example.com", Method: "POST"}},
+			{Name: "payments", Details: Details{Url: "http://payments.example.com", Method: "PUT"}},
+		},
+	}
+
+	details, err := callback.GetDetails()
+	if err != nil {
+		t.Fatalf("GetDetails() failed with error: %s", err.Error())
+	}
+
+	var got FanoutCallback
+	if err = got.Marshal(map[string]interface{}{"callback_type": "fanout", "callback_details": details}); err != nil {
+		t.Fatalf("Marshal() failed with error: %s", err.Error())
+	}
+
+	if got.Type != "fanout" || len(got.Targets) != 2 || got.Targets[1].Details.Url != "http://payments.example.com" {
+		t.Errorf("Marshal() got %+v, expected %+v", got, callback)
+	}
+}
+
+func TestAggregateStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses map[string]TargetStatus
+		expected Status
+	}{
+		{"all succeeded", map[string]TargetStatus{"a": {Status: Success}, "b": {Status: Success}}, Success},
+		{"some failed", map[string]TargetStatus{"a": {Status: Success}, "b": {Status: Failure}}, Partial},
+		{"all failed", map[string]TargetStatus{"a": {Status: Failure}, "b": {Status: Failure}}, Failure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AggregateStatus(tt.statuses); got != tt.expected {
+				t.Errorf("AggregateStatus() = %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSetStatusWithTargetStatus(t *testing.T) {
+	var schedule Schedule
+	err := schedule.SetStatus(map[string]interface{}{
+		"schedule_status":        "PARTIAL",
+		"error_msg":              "payments: 500 Internal Server Error",
+		"reconciliation_history": "",
+		"target_status":          `{"orders":{"status":"SUCCESS","attempts":1},"payments":{"status":"FAILURE","attempts":3,"errorMessage":"500 Internal Server Error"}}`,
+	})
+	if err != nil {
+		t.Fatalf("SetStatus() failed with error: %s", err.Error())
+	}
+
+	if schedule.Status != Partial || schedule.TargetStatuses["payments"].Attempts != 3 || schedule.TargetStatuses["orders"].Status != Success {
+		t.Errorf("SetStatus() got %+v", schedule)
+	}
+}
@@ -28,9 +28,11 @@ import (
 )
 
 type Details struct {
-	Url     string            `json:"url"`
-	Method  string            `json:"method"`
-	Headers map[string]string `json:"headers"`
+	Url         string            `json:"url"`
+	Method      string            `json:"method"`
+	Headers     map[string]string `json:"headers"`
+	AuthProfile string            `json:"authProfile,omitempty"`
+	Template    bool              `json:"template,omitempty"`
 }
 
 type HttpCallback struct {
@@ -82,7 +84,7 @@ func (h *HttpCallback) UnmarshalJSON(data []byte) error {
 }
 
 func (h HttpCallback) Invoke(wrapper ScheduleWrapper) error {
-	HttpTaskQueue <- wrapper
+	HttpTaskQueueFor(wrapper) <- wrapper
 	return nil
 }
 
@@ -93,11 +95,18 @@ func (h *HttpCallback) Validate() error {
 	}
 
 	// Checking if the URL is valid
-	_, err := url.ParseRequestURI(h.Details.Url)
+	u, err := url.ParseRequestURI(h.Details.Url)
 	if err != nil {
 		return errors.New("invalid url")
 	}
 
+	// Templated URLs are checked once they are rendered
+	if !h.Details.Template {
+		if err = validateCallbackUrl(u); err != nil {
+			return err
+		}
+	}
+
 	// Checking if method is empty
 	if h.Details.Method == "" {
 		return errors.New("method cannot be empty")
@@ -135,6 +135,38 @@ func TestValidate(t *testing.T) {
 			},
 			want: errors.New("Invalid http callback method INVALID"),
 		},
+		{
+			name: "Unsupported scheme",
+			details: Details{
+				Url:    "file:///etc/passwd",
+				Method: "GET",
+			},
+			want: errors.New(`callback url scheme must be http or https, got "file"`),
+		},
+		{
+			name: "Loopback address",
+			details: Details{
+				Url:    "http://127.0.0.1:8080/admin",
+				Method: "GET",
+			},
+			want: errors.New("callback url host 127.0.0.1 is in a denied network"),
+		},
+		{
+			name: "Metadata address",
+			details: Details{
+				Url:    "http://169.254.169.254/latest/meta-data",
+				Method: "GET",
+			},
+			want: errors.New("callback url host 169.254.169.254 is in a denied network"),
+		},
+		{
+			name: "Localhost",
+			details: Details{
+				Url:    "http://localhost/admin",
+				Method: "GET",
+			},
+			want: errors.New("callback url host localhost is in a denied network"),
+		},
 		{
 			name: "Valid HttpCallback",
 			details: Details{
@@ -0,0 +1,207 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+)
+
+// icalProperty is a single content line of an iCalendar document
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// ParseICal creates a calendar from the events of an iCalendar (RFC 5545) document.
+// All day events become blackout dates and timed events become blackout windows.
+// Timed events without a timezone are read in the X-WR-TIMEZONE of the document, or UTC if it has none.
+// Recurring events are not supported.
+func ParseICal(name string, r io.Reader) (Calendar, error) {
+	calendar := Calendar{Name: name}
+
+	lines, err := unfoldICal(r)
+	if err != nil {
+		return calendar, err
+	}
+
+	var event []icalProperty
+	inEvent := false
+
+	for _, line := range lines {
+		property, err := parseICalProperty(line)
+		if err != nil {
+			return calendar, err
+		}
+
+		switch {
+		case property.name == "X-WR-TIMEZONE":
+			calendar.Timezone = property.value
+		case property.name == "BEGIN" && property.value == "VEVENT":
+			inEvent = true
+			event = nil
+		case property.name == "END" && property.value == "VEVENT":
+			inEvent = false
+			if err = calendar.addICalEvent(event); err != nil {
+				return calendar, err
+			}
+		case inEvent:
+			event = append(event, property)
+		}
+	}
+
+	if errs := calendar.Validate(); len(errs) != 0 {
+		return calendar, fmt.Errorf("invalid calendar: %s", strings.Join(errs, ","))
+	}
+
+	return calendar, nil
+}
+
+// Unfold the content lines of the document, lines starting with a space or tab continue the previous line
+func unfoldICal(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+func parseICalProperty(line string) (icalProperty, error) {
+	separator := strings.Index(line, ":")
+	if separator < 0 {
+		return icalProperty{}, fmt.Errorf("invalid iCalendar line %s", line)
+	}
+
+	parts := strings.Split(line[:separator], ";")
+	property := icalProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  line[separator+1:],
+	}
+
+	for _, param := range parts[1:] {
+		if kv := strings.SplitN(param, "=", 2); len(kv) == 2 {
+			property.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+
+	return property, nil
+}
+
+func (c *Calendar) addICalEvent(event []icalProperty) error {
+	var start, end *icalProperty
+
+	for i := range event {
+		switch event[i].name {
+		case "DTSTART":
+			start = &event[i]
+		case "DTEND":
+			end = &event[i]
+		case "RRULE", "RDATE":
+			return fmt.Errorf("recurring events are not supported")
+		}
+	}
+
+	if start == nil {
+		return fmt.Errorf("event without DTSTART")
+	}
+
+	if isICalDate(*start) {
+		return c.addICalDates(*start, end)
+	}
+
+	startTime, err := c.parseICalDateTime(*start)
+	if err != nil {
+		return err
+	}
+
+	if end == nil {
+		return fmt.Errorf("timed event starting at %s without DTEND", start.value)
+	}
+
+	endTime, err := c.parseICalDateTime(*end)
+	if err != nil {
+		return err
+	}
+
+	c.Windows = append(c.Windows, Window{Start: startTime, End: endTime})
+	return nil
+}
+
+// Add every day of an all day event, the end date is exclusive
+func (c *Calendar) addICalDates(start icalProperty, end *icalProperty) error {
+	first, err := time.Parse(icalDateLayout, start.value)
+	if err != nil {
+		return fmt.Errorf("invalid date %s", start.value)
+	}
+
+	last := first
+	if end != nil {
+		if last, err = time.Parse(icalDateLayout, end.value); err != nil {
+			return fmt.Errorf("invalid date %s", end.value)
+		}
+		last = last.AddDate(0, 0, -1)
+	}
+
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		c.Dates = append(c.Dates, day.Format(calendarDateLayout))
+	}
+
+	return nil
+}
+
+func (c Calendar) parseICalDateTime(property icalProperty) (time.Time, error) {
+	if strings.HasSuffix(property.value, "Z") {
+		return time.Parse(icalDateTimeLayout+"Z", property.value)
+	}
+
+	location := c.location()
+	if tzid, ok := property.params["TZID"]; ok {
+		var err error
+		if location, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %s", tzid)
+		}
+	}
+
+	return time.ParseInLocation(icalDateTimeLayout, property.value, location)
+}
+
+func isICalDate(property icalProperty) bool {
+	return property.params["VALUE"] == "DATE" || len(property.value) == len(icalDateLayout)
+}
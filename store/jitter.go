@@ -0,0 +1,110 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/myntra/goscheduler/util"
+)
+
+// Schedules cannot be spread over windows longer than a day
+const maxJitter = 24 * time.Hour
+
+// GetJitter returns the window over which the schedule is spread.
+// The window of the schedule takes precedence over the one configured for the app, which takes precedence over the default.
+func (s Schedule) GetJitter(app App, defaultJitter int) time.Duration {
+	if s.Jitter != "" {
+		if jitter, err := ParseDelay(s.Jitter); err == nil {
+			return jitter
+		}
+		return 0
+	}
+
+	if app.Configuration.Jitter != 0 {
+		return time.Duration(app.Configuration.Jitter) * time.Second
+	}
+
+	return time.Duration(defaultJitter) * time.Second
+}
+
+// JitterOffset returns the offset in seconds within the window, derived from a stable hash of the schedule id
+func JitterOffset(uuid gocql.UUID, window time.Duration) int64 {
+	seconds := int64(window / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write(uuid.Bytes())
+	return int64(hash.Sum64() % uint64(seconds))
+}
+
+// ApplyJitter spreads the schedule time of a one time schedule deterministically within its jitter window.
+// Runs of a recurring schedule are spread by the id of the parent, so that all runs are fired at the same offset.
+// The deadline of the schedule is moved along with the schedule time.
+func (s *Schedule) ApplyJitter(app App, defaultJitter int) {
+	if s.IsRecurring() {
+		return
+	}
+
+	uuid := s.ScheduleId
+	if !util.IsZeroUUID(s.ParentScheduleId) {
+		uuid = s.ParentScheduleId
+	}
+
+	offset := JitterOffset(uuid, s.GetJitter(app, defaultJitter))
+	if offset == 0 {
+		return
+	}
+
+	s.ScheduleTime += offset
+	s.ScheduleGroup = 60 * (s.ScheduleTime / 60)
+	if s.Deadline != 0 {
+		s.Deadline += offset
+	}
+}
+
+// ValidateJitter checks if the jitter window configured for an app is within the allowed range
+func ValidateJitter(seconds int) error {
+	if seconds < 0 || time.Duration(seconds)*time.Second > maxJitter {
+		return fmt.Errorf("jitter %d should be between 0 and %d seconds", seconds, int(maxJitter/time.Second))
+	}
+	return nil
+}
+
+func validateJitter(s Schedule) []string {
+	var errs []string
+
+	if s.Jitter == "" {
+		return errs
+	}
+
+	if jitter, err := ParseDelay(s.Jitter); err != nil {
+		errs = append(errs, err.Error())
+	} else if jitter > maxJitter {
+		errs = append(errs, fmt.Sprintf("jitter %s cannot be longer than %s", s.Jitter, maxJitter))
+	}
+
+	return errs
+}
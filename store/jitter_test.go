@@ -0,0 +1,129 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+func TestJitterOffset(t *testing.T) {
+	uuid := gocql.TimeUUID()
+
+	if offset := JitterOffset(uuid, 0); offset != 0 {
+		t.Errorf("JitterOffset() without window = %d, want 0", offset)
+	}
+
+	offset := JitterOffset(uuid, 10*time.Minute)
+	if offset < 0 || offset >= 600 {
+		t.Errorf("JitterOffset() = %d, want within [0, 600)", offset)
+	}
+
+	if again := JitterOffset(uuid, 10*time.Minute); again != offset {
+		t.Errorf("JitterOffset() is not stable, got %d and %d", offset, again)
+	}
+}
+
+func TestJitterOffsetSpread(t *testing.T) {
+	buckets := make(map[int64]bool)
+	for i := 0; i < 1000; i++ {
+		buckets[JitterOffset(gocql.TimeUUID(), 10*time.Minute)/60] = true
+	}
+
+	if len(buckets) != 10 {
+		t.Errorf("schedules are spread over %d minutes, want 10", len(buckets))
+	}
+}
+
+func TestSchedule_GetJitter(t *testing.T) {
+	app := App{Configuration: Configuration{Jitter: 120}}
+
+	for _, test := range []struct {
+		schedule Schedule
+		app      App
+		jitter   time.Duration
+	}{
+		{Schedule{}, App{}, 30 * time.Second},
+		{Schedule{}, app, 2 * time.Minute},
+		{Schedule{Jitter: "5m"}, app, 5 * time.Minute},
+		{Schedule{Jitter: "PT1H"}, app, time.Hour},
+	} {
+		if jitter := test.schedule.GetJitter(test.app, 30); jitter != test.jitter {
+			t.Errorf("GetJitter(%+v) = %s, want %s", test.schedule, jitter, test.jitter)
+		}
+	}
+}
+
+func TestSchedule_ApplyJitter(t *testing.T) {
+	scheduleTime := time.Date(2023, 6, 13, 16, 0, 0, 0, time.UTC).Unix()
+	schedule := Schedule{
+		ScheduleId:   gocql.TimeUUID(),
+		ScheduleTime: scheduleTime,
+		Deadline:     scheduleTime + 300,
+		Jitter:       "15m",
+	}
+	offset := JitterOffset(schedule.ScheduleId, 15*time.Minute)
+
+	schedule.ApplyJitter(App{}, 0)
+	if schedule.ScheduleTime != scheduleTime+offset {
+		t.Errorf("ApplyJitter() schedule time = %d, want %d", schedule.ScheduleTime, scheduleTime+offset)
+	}
+	if schedule.ScheduleGroup != 60*(schedule.ScheduleTime/60) {
+		t.Errorf("ApplyJitter() schedule group = %d is not the minute of %d", schedule.ScheduleGroup, schedule.ScheduleTime)
+	}
+	if schedule.Deadline != scheduleTime+300+offset {
+		t.Errorf("ApplyJitter() deadline = %d, want %d", schedule.Deadline, scheduleTime+300+offset)
+	}
+
+	parent := Schedule{ScheduleId: gocql.TimeUUID(), CronExpression: "0 16 * * *", Jitter: "15m"}
+	first := parent.CloneAsOneTime(time.Unix(scheduleTime, 0))
+	second := parent.CloneAsOneTime(time.Unix(scheduleTime+86400, 0))
+	first.ApplyJitter(App{}, 0)
+	second.ApplyJitter(App{}, 0)
+	if second.ScheduleTime-first.ScheduleTime != 86400 {
+		t.Errorf("runs of a recurring schedule should be fired at the same offset, got %d and %d", first.ScheduleTime, second.ScheduleTime)
+	}
+}
+
+func TestValidateJitter(t *testing.T) {
+	for _, test := range []struct {
+		jitter string
+		errs   int
+	}{
+		{"", 0},
+		{"10m", 0},
+		{"PT1H", 0},
+		{"25h", 1},
+		{"soon", 1},
+	} {
+		if errs := validateJitter(Schedule{Jitter: test.jitter}); len(errs) != test.errs {
+			t.Errorf("validateJitter(%s) = %v, want %d errors", test.jitter, errs, test.errs)
+		}
+	}
+
+	if err := ValidateJitter(-1); err == nil {
+		t.Errorf("ValidateJitter(-1) should fail")
+	}
+	if err := ValidateJitter(600); err != nil {
+		t.Errorf("ValidateJitter(600) returned %v", err)
+	}
+}
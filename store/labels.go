@@ -0,0 +1,91 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	maxLabels      = 20
+	maxLabelLength = 256
+	labelSeparator = ":"
+)
+
+// LabelSelector creates the selector "key:value" of a label
+func LabelSelector(key string, value string) string {
+	return key + labelSeparator + value
+}
+
+// ParseLabelSelector parses a "key:value" label selector
+func ParseLabelSelector(selector string) (string, string, error) {
+	parts := strings.SplitN(selector, labelSeparator, 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("invalid label selector %s, expected key:value", selector)
+	}
+	return parts[0], parts[1], nil
+}
+
+// GetLabelSelectors returns the sorted selectors of all the labels of the schedule
+func (s Schedule) GetLabelSelectors() []string {
+	var selectors []string
+	for key, value := range s.Labels {
+		selectors = append(selectors, LabelSelector(key, value))
+	}
+	sort.Strings(selectors)
+	return selectors
+}
+
+// MatchLabels checks if the labels match all the selectors
+func MatchLabels(labels map[string]string, selectors []string) bool {
+	for _, selector := range selectors {
+		key, value, err := ParseLabelSelector(selector)
+		if err != nil {
+			return false
+		}
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+func validateLabels(labels map[string]string) []string {
+	var errs []string
+
+	if len(labels) > maxLabels {
+		errs = append(errs, fmt.Sprintf("cannot have more than %d labels", maxLabels))
+	}
+
+	for key, value := range labels {
+		switch {
+		case key == "":
+			errs = append(errs, "label key cannot be empty")
+		case strings.Contains(key, labelSeparator):
+			errs = append(errs, fmt.Sprintf("label key %s cannot contain %s", key, labelSeparator))
+		case len(key)+len(value) > maxLabelLength:
+			errs = append(errs, fmt.Sprintf("label %s cannot be longer than %d characters", key, maxLabelLength))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	for _, test := range []struct {
+		selector string
+		key      string
+		value    string
+		err      bool
+	}{
+		{"tenant:acme", "tenant", "acme", false},
+		{"url:http://host", "url", "http://host", false},
+		{"tenant:", "tenant", "", false},
+		{"tenant", "", "", true},
+		{":acme", "", "", true},
+	} {
+		key, value, err := ParseLabelSelector(test.selector)
+		if (err != nil) != test.err {
+			t.Errorf("ParseLabelSelector(%s) error = %v, want error %v", test.selector, err, test.err)
+			continue
+		}
+		if key != test.key || value != test.value {
+			t.Errorf("ParseLabelSelector(%s) = %s, %s, want %s, %s", test.selector, key, value, test.key, test.value)
+		}
+	}
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"tenant": "acme", "env": "prod"}
+
+	for _, test := range []struct {
+		selectors []string
+		match     bool
+	}{
+		{nil, true},
+		{[]string{"tenant:acme"}, true},
+		{[]string{"tenant:acme", "env:prod"}, true},
+		{[]string{"tenant:acme", "env:dev"}, false},
+		{[]string{"region:eu"}, false},
+		{[]string{"tenant"}, false},
+	} {
+		if match := MatchLabels(labels, test.selectors); match != test.match {
+			t.Errorf("MatchLabels(%v) = %v, want %v", test.selectors, match, test.match)
+		}
+	}
+}
+
+func TestSchedule_GetLabelSelectors(t *testing.T) {
+	schedule := Schedule{Labels: map[string]string{"tenant": "acme", "env": "prod"}}
+
+	if selectors := schedule.GetLabelSelectors(); !reflect.DeepEqual(selectors, []string{"env:prod", "tenant:acme"}) {
+		t.Errorf("GetLabelSelectors() = %v", selectors)
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= maxLabels; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	for _, test := range []struct {
+		labels map[string]string
+		errs   int
+	}{
+		{nil, 0},
+		{map[string]string{"tenant": "acme"}, 0},
+		{map[string]string{"": "acme"}, 1},
+		{map[string]string{"ten:ant": "acme"}, 1},
+		{map[string]string{"tenant": strings.Repeat("a", maxLabelLength)}, 1},
+		{tooMany, 1},
+	} {
+		if errs := validateLabels(test.labels); len(errs) != test.errs {
+			t.Errorf("validateLabels(%v) = %v, want %d errors", test.labels, errs, test.errs)
+		}
+	}
+}
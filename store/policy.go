@@ -0,0 +1,214 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Upper bound of the callback attempts an app can configure in its retry policy
+const maxRetryAttempts = 10
+
+// RateLimit allows at most Requests schedule creations per Period for an app
+type RateLimit struct {
+	Requests int    `json:"requests"`
+	Period   string `json:"period"`
+}
+
+// Validate checks if the rate limit has a positive number of requests and period
+func (r RateLimit) Validate() error {
+	if r.Requests <= 0 {
+		return fmt.Errorf("rate limit requests %d should be positive", r.Requests)
+	}
+
+	period, err := ParseDelay(r.Period)
+	if err != nil {
+		return fmt.Errorf("invalid rate limit period: %s", err.Error())
+	}
+	if period == 0 {
+		return fmt.Errorf("rate limit period should be positive")
+	}
+
+	return nil
+}
+
+// RetryPolicy decides how many times the callback of a schedule is attempted and how long to wait between the attempts.
+// The wait starts at Backoff and doubles after every attempt, up to MaxBackoff when given.
+type RetryPolicy struct {
+	MaxAttempts int    `json:"maxAttempts"`
+	Backoff     string `json:"backoff,omitempty"`
+	MaxBackoff  string `json:"maxBackoff,omitempty"`
+}
+
+// Validate checks the number of attempts and the backoff durations of the retry policy
+func (r RetryPolicy) Validate() error {
+	if r.MaxAttempts < 1 || r.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("retry policy max attempts %d should be between 1 and %d", r.MaxAttempts, maxRetryAttempts)
+	}
+
+	for name, value := range map[string]string{"backoff": r.Backoff, "max backoff": r.MaxBackoff} {
+		if value == "" {
+			continue
+		}
+		if _, err := ParseDelay(value); err != nil {
+			return fmt.Errorf("invalid retry policy %s: %s", name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Delay returns how long to wait after the given attempt, starting from 1, before attempting the callback again
+func (r RetryPolicy) Delay(attempt int) time.Duration {
+	backoff, err := ParseDelay(r.Backoff)
+	if err != nil || backoff == 0 || attempt < 1 {
+		return 0
+	}
+
+	maxBackoff, err := ParseDelay(r.MaxBackoff)
+	if err != nil {
+		maxBackoff = 0
+	}
+
+	delay := backoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if maxBackoff > 0 && delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	if maxBackoff > 0 && delay > maxBackoff {
+		return maxBackoff
+	}
+	return delay
+}
+
+// GetRetryPolicy returns the retry policy of the app, falling back to the given number of attempts without any backoff
+func (c Configuration) GetRetryPolicy(defaultAttempts int) RetryPolicy {
+	if c.RetryPolicy != nil {
+		return *c.RetryPolicy
+	}
+	return RetryPolicy{MaxAttempts: defaultAttempts}
+}
+
+// validateCallbackHosts checks the allowed callback patterns, which are either a host like "api.example.com",
+// a wildcard for its subdomains like "*.example.com" or a url prefix like "https://api.example.com:8443/hooks"
+func validateCallbackHosts(hosts []string) error {
+	for _, host := range hosts {
+		if !strings.Contains(host, "://") {
+			if !validHostPattern(host) {
+				return fmt.Errorf("invalid allowed callback host %q", host)
+			}
+			continue
+		}
+
+		u, err := url.Parse(host)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !validHostPattern(u.Hostname()) ||
+			u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("invalid allowed callback url %q", host)
+		}
+	}
+	return nil
+}
+
+func validHostPattern(host string) bool {
+	pattern := strings.TrimPrefix(host, "*.")
+	return pattern != "" && !strings.ContainsAny(pattern, "*/: ")
+}
+
+// matchHost checks the host against a host pattern, "*.example.com" matches the subdomains of example.com but not itself
+func matchHost(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return host == pattern
+}
+
+// matchUrl checks the url against a url prefix pattern.
+// The scheme and host need to be the same, the port only if the pattern has one and the path needs to be under the path of the pattern.
+func matchUrl(pattern *url.URL, u *url.URL) bool {
+	if !strings.EqualFold(pattern.Scheme, u.Scheme) || !matchHost(pattern.Hostname(), u.Hostname()) {
+		return false
+	}
+
+	if pattern.Port() != "" && pattern.Port() != u.Port() {
+		return false
+	}
+
+	prefix := strings.TrimSuffix(pattern.Path, "/")
+	p := path.Clean("/" + u.Path)
+	return prefix == "" || p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+// AllowsCallbackUrl checks the url against the allowed callback hosts of the app, every url is allowed when there are none
+func (c Configuration) AllowsCallbackUrl(rawUrl string) bool {
+	if len(c.AllowedCallbackHosts) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range c.AllowedCallbackHosts {
+		if !strings.Contains(allowed, "://") {
+			if matchHost(allowed, u.Hostname()) {
+				return true
+			}
+			continue
+		}
+
+		if pattern, err := url.Parse(allowed); err == nil && matchUrl(pattern, u) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePolicies checks the rate limit, quotas, retry policy and allowed callback hosts of the configuration
+func (c Configuration) ValidatePolicies() error {
+	if c.Quotas != nil {
+		if err := c.Quotas.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.RateLimit != nil {
+		if err := c.RateLimit.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.RetryPolicy != nil {
+		if err := c.RetryPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return validateCallbackHosts(c.AllowedCallbackHosts)
+}
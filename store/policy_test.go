@@ -0,0 +1,146 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimitValidate(t *testing.T) {
+	for _, test := range []struct {
+		limit RateLimit
+		valid bool
+	}{
+		{RateLimit{Requests: 100, Period: "1m"}, true},
+		{RateLimit{Requests: 10, Period: "PT1S"}, true},
+		{RateLimit{Requests: 0, Period: "1m"}, false},
+		{RateLimit{Requests: 10, Period: "0s"}, false},
+		{RateLimit{Requests: 10, Period: "sometimes"}, false},
+	} {
+		if err := test.limit.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %t", test.limit, err, test.valid)
+		}
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	for _, test := range []struct {
+		policy RetryPolicy
+		valid  bool
+	}{
+		{RetryPolicy{MaxAttempts: 1}, true},
+		{RetryPolicy{MaxAttempts: 5, Backoff: "1s", MaxBackoff: "PT10S"}, true},
+		{RetryPolicy{MaxAttempts: 0}, false},
+		{RetryPolicy{MaxAttempts: 11}, false},
+		{RetryPolicy{MaxAttempts: 3, Backoff: "-1s"}, false},
+	} {
+		if err := test.policy.Validate(); (err == nil) != test.valid {
+			t.Errorf("Validate(%+v) = %v, want valid %t", test.policy, err, test.valid)
+		}
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, Backoff: "1s", MaxBackoff: "5s"}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second} {
+		if got := policy.Delay(attempt); got != want {
+			t.Errorf("Delay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+
+	if got := (RetryPolicy{MaxAttempts: 3}).Delay(2); got != 0 {
+		t.Errorf("Delay() without backoff = %s, want 0", got)
+	}
+}
+
+func TestGetRetryPolicy(t *testing.T) {
+	if got := (Configuration{}).GetRetryPolicy(3); got.MaxAttempts != 3 {
+		t.Errorf("GetRetryPolicy() max attempts = %d, want 3", got.MaxAttempts)
+	}
+
+	configuration := Configuration{RetryPolicy: &RetryPolicy{MaxAttempts: 5}}
+	if got := configuration.GetRetryPolicy(3); got.MaxAttempts != 5 {
+		t.Errorf("GetRetryPolicy() max attempts = %d, want 5", got.MaxAttempts)
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	for _, test := range []struct {
+		configuration Configuration
+		valid         bool
+	}{
+		{Configuration{}, true},
+		{Configuration{AllowedCallbackHosts: []string{"api.example.com", "*.example.com"}}, true},
+		{Configuration{AllowedCallbackHosts: []string{"*."}}, false},
+		{Configuration{AllowedCallbackHosts: []string{"https://api.example.com:8443/hooks"}}, true},
+		{Configuration{AllowedCallbackHosts: []string{"ftp://api.example.com"}}, false},
+		{Configuration{AllowedCallbackHosts: []string{"api.example.com:8443"}}, false},
+		{Configuration{RateLimit: &RateLimit{}}, false},
+		{Configuration{RetryPolicy: &RetryPolicy{}}, false},
+	} {
+		if err := test.configuration.ValidatePolicies(); (err == nil) != test.valid {
+			t.Errorf("ValidatePolicies(%+v) = %v, want valid %t", test.configuration, err, test.valid)
+		}
+	}
+}
+
+func TestAllowsCallbackUrl(t *testing.T) {
+	if !(Configuration{}).AllowsCallbackUrl("https://anything.example.org") {
+		t.Errorf("AllowsCallbackUrl() without allowed hosts = false, want true")
+	}
+
+	configuration := Configuration{AllowedCallbackHosts: []string{"api.example.com", "*.internal.example.com", "https://hooks.example.com:8443/v1/"}}
+	for url, allowed := range map[string]bool{
+		"https://api.example.com/callback":             true,
+		"http://API.example.com./callback":             true,
+		"https://api.example.com.evil.org/callback":    false,
+		"https://orders.internal.example.com/callback": true,
+		"https://internal.example.com/callback":        false,
+		"https://hooks.example.com:8443/v1/orders":     true,
+		"https://hooks.example.com:8443/v1":            true,
+		"https://hooks.example.com:8443/v10":           false,
+		"https://hooks.example.com:8443/v1/../admin":   false,
+		"https://hooks.example.com/v1/orders":          false,
+		"http://hooks.example.com:8443/v1/orders":      false,
+		"https://other.example.com/callback":           false,
+	} {
+		if got := configuration.AllowsCallbackUrl(url); got != allowed {
+			t.Errorf("AllowsCallbackUrl(%s) = %t, want %t", url, got, allowed)
+		}
+	}
+}
+
+func TestValidateAllowedCallbackUrls(t *testing.T) {
+	app := App{AppId: "test", Configuration: Configuration{AllowedCallbackHosts: []string{"api.example.com"}}}
+
+	allowed := Schedule{Callback: &HttpCallback{Details: Details{Url: "https://api.example.com/callback"}}}
+	if errStr := validateAllowedCallbackUrls(allowed, app); errStr != "" {
+		t.Errorf("validateAllowedCallbackUrls() = %s, want no error", errStr)
+	}
+
+	denied := Schedule{Callback: &FanoutCallback{Targets: []Target{
+		{Name: "first", Details: Details{Url: "https://api.example.com/callback"}},
+		{Name: "second", Details: Details{Url: "https://other.example.com/callback"}},
+	}}}
+	if errStr := validateAllowedCallbackUrls(denied, app); errStr == "" {
+		t.Errorf("validateAllowedCallbackUrls() = no error, want the second target to be denied")
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Priority is the class in which due schedules are dispatched, higher priority schedules are served first
+type Priority string
+
+const (
+	High   Priority = "high"
+	Normal Priority = "normal"
+	Low    Priority = "low"
+)
+
+// Rank of the priority classes, lower ranks are served first
+var priorityRanks = map[Priority]int{High: 0, Normal: 1, Low: 2}
+
+// Validate checks if the priority is a known one, an empty priority is allowed
+func (p Priority) Validate() error {
+	if _, ok := priorityRanks[p]; !ok && p != "" {
+		return fmt.Errorf("invalid priority %s, allowed values are %s, %s and %s", p, High, Normal, Low)
+	}
+	return nil
+}
+
+// GetPriority returns the priority of the schedule, falling back to the priority of the app and then to normal
+func (s Schedule) GetPriority(app App) Priority {
+	switch {
+	case s.Priority != "":
+		return s.Priority
+	case app.Configuration.Priority != "":
+		return app.Configuration.Priority
+	default:
+		return Normal
+	}
+}
+
+// SortByPriority orders the schedules of an app by their priority, keeping the order of schedules with the same priority
+func SortByPriority(schedules []Schedule, app App) {
+	sort.SliceStable(schedules, func(i, j int) bool {
+		return priorityRanks[schedules[i].GetPriority(app)] < priorityRanks[schedules[j].GetPriority(app)]
+	})
+}
+
+// HttpTaskQueueFor returns the http task queue of the priority of the schedule
+func HttpTaskQueueFor(wrapper ScheduleWrapper) chan ScheduleWrapper {
+	switch wrapper.Schedule.GetPriority(wrapper.App) {
+	case High:
+		return HighPriorityHttpTaskQueue
+	case Low:
+		return LowPriorityHttpTaskQueue
+	default:
+		return HttpTaskQueue
+	}
+}
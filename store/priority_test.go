@@ -0,0 +1,97 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+)
+
+func TestPriorityValidate(t *testing.T) {
+	for _, p := range []Priority{"", High, Normal, Low} {
+		if err := p.Validate(); err != nil {
+			t.Errorf("Validate(%q) returned error %v", p, err)
+		}
+	}
+	if err := Priority("urgent").Validate(); err == nil {
+		t.Errorf("Validate(urgent) expected an error")
+	}
+}
+
+func TestGetPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule Schedule
+		app      App
+		want     Priority
+	}{
+		{"default", Schedule{}, App{}, Normal},
+		{"app", Schedule{}, App{Configuration: Configuration{Priority: Low}}, Low},
+		{"schedule over app", Schedule{Priority: High}, App{Configuration: Configuration{Priority: Low}}, High},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.GetPriority(tt.app); got != tt.want {
+				t.Errorf("GetPriority() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortByPriority(t *testing.T) {
+	schedules := []Schedule{
+		{AppId: "1", Priority: Low},
+		{AppId: "2"},
+		{AppId: "3", Priority: High},
+		{AppId: "4", Priority: Low},
+		{AppId: "5", Priority: High},
+	}
+
+	SortByPriority(schedules, App{})
+
+	want := []string{"3", "5", "2", "1", "4"}
+	for i, schedule := range schedules {
+		if schedule.AppId != want[i] {
+			t.Errorf("SortByPriority() position %d = %s, want %s", i, schedule.AppId, want[i])
+		}
+	}
+}
+
+func TestHttpTaskQueueFor(t *testing.T) {
+	HighPriorityHttpTaskQueue = make(chan ScheduleWrapper)
+	HttpTaskQueue = make(chan ScheduleWrapper)
+	LowPriorityHttpTaskQueue = make(chan ScheduleWrapper)
+
+	tests := []struct {
+		priority Priority
+		want     chan ScheduleWrapper
+	}{
+		{High, HighPriorityHttpTaskQueue},
+		{"", HttpTaskQueue},
+		{Normal, HttpTaskQueue},
+		{Low, LowPriorityHttpTaskQueue},
+	}
+
+	for _, tt := range tests {
+		if got := HttpTaskQueueFor(ScheduleWrapper{Schedule: Schedule{Priority: tt.priority}}); got != tt.want {
+			t.Errorf("HttpTaskQueueFor(%q) returned the wrong queue", tt.priority)
+		}
+	}
+}
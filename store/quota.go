@@ -0,0 +1,98 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"fmt"
+
+	"github.com/myntra/goscheduler/util"
+)
+
+// Quotas limit the number of schedules an app can have at a time, a zero quota is unlimited
+type Quotas struct {
+	PendingSchedules   int64 `json:"pendingSchedules,omitempty"`
+	RecurringSchedules int64 `json:"recurringSchedules,omitempty"`
+}
+
+// Validate checks that the quotas are not negative
+func (q Quotas) Validate() error {
+	if q.PendingSchedules < 0 || q.RecurringSchedules < 0 {
+		return fmt.Errorf("quotas cannot be negative")
+	}
+	return nil
+}
+
+// UsageKind is the kind of schedules counted against a quota
+type UsageKind string
+
+const (
+	PendingUsage   UsageKind = "pending"
+	RecurringUsage UsageKind = "recurring"
+)
+
+// Usage is the number of schedules of an app counted against its quotas
+type Usage struct {
+	PendingSchedules   int64 `json:"pendingSchedules"`
+	RecurringSchedules int64 `json:"recurringSchedules"`
+}
+
+// Get returns the usage of the given kind, never less than zero
+func (u Usage) Get(kind UsageKind) int64 {
+	var count int64
+	switch kind {
+	case PendingUsage:
+		count = u.PendingSchedules
+	case RecurringUsage:
+		count = u.RecurringSchedules
+	}
+
+	if count < 0 {
+		return 0
+	}
+	return count
+}
+
+// Limit returns the quota of the given kind, zero if there is none
+func (q *Quotas) Limit(kind UsageKind) int64 {
+	if q == nil {
+		return 0
+	}
+
+	switch kind {
+	case PendingUsage:
+		return q.PendingSchedules
+	case RecurringUsage:
+		return q.RecurringSchedules
+	}
+	return 0
+}
+
+// UsageKind returns the quota the schedule is counted against.
+// Runs of recurring schedules are not counted, false is returned for them.
+func (s Schedule) UsageKind() (UsageKind, bool) {
+	switch {
+	case s.IsRecurring():
+		return RecurringUsage, true
+	case !util.IsZeroUUID(s.ParentScheduleId):
+		return "", false
+	default:
+		return PendingUsage, true
+	}
+}
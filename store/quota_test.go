@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+
+	"github.com/gocql/gocql"
+)
+
+func TestQuotasValidate(t *testing.T) {
+	if err := (Quotas{PendingSchedules: 10}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := (Quotas{RecurringSchedules: -1}).Validate(); err == nil {
+		t.Errorf("Validate() with negative quota = nil, want error")
+	}
+}
+
+func TestQuotasLimit(t *testing.T) {
+	var quotas *Quotas
+	if got := quotas.Limit(PendingUsage); got != 0 {
+		t.Errorf("Limit() of nil quotas = %d, want 0", got)
+	}
+
+	quotas = &Quotas{PendingSchedules: 10, RecurringSchedules: 2}
+	if got := quotas.Limit(PendingUsage); got != 10 {
+		t.Errorf("Limit(%s) = %d, want 10", PendingUsage, got)
+	}
+	if got := quotas.Limit(RecurringUsage); got != 2 {
+		t.Errorf("Limit(%s) = %d, want 2", RecurringUsage, got)
+	}
+}
+
+func TestUsageGet(t *testing.T) {
+	usage := Usage{PendingSchedules: -2, RecurringSchedules: 3}
+	if got := usage.Get(PendingUsage); got != 0 {
+		t.Errorf("Get(%s) = %d, want 0", PendingUsage, got)
+	}
+	if got := usage.Get(RecurringUsage); got != 3 {
+		t.Errorf("Get(%s) = %d, want 3", RecurringUsage, got)
+	}
+}
+
+func TestScheduleUsageKind(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		schedule Schedule
+		kind     UsageKind
+		counted  bool
+	}{
+		{"one time", Schedule{}, PendingUsage, true},
+		{"recurring", Schedule{CronExpression: "*/5 * * * *"}, RecurringUsage, true},
+		{"run", Schedule{ParentScheduleId: gocql.TimeUUID()}, "", false},
+	} {
+		kind, counted := test.schedule.UsageKind()
+		if kind != test.kind || counted != test.counted {
+			t.Errorf("%s: UsageKind() = (%s, %t), want (%s, %t)", test.name, kind, counted, test.kind, test.counted)
+		}
+	}
+}
@@ -29,6 +29,7 @@ func InitializeCallbackRegistry(clientCallbacks map[string]Factory) {
 	// default implementations
 	defaultCallbacks := map[string]Factory{
 		constants.DefaultCallback: func() Callback { return &HttpCallback{} },
+		constants.FanoutCallback:  func() Callback { return &FanoutCallback{} },
 	}
 
 	// First, register all client-provided callbacks
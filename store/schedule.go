@@ -48,6 +48,8 @@ const (
 	Success   Status     = "SUCCESS"
 	Failure   Status     = "FAILURE"
 	Miss      Status     = "MISS"
+	Partial   Status     = "PARTIAL"
+	Expired   Status     = "EXPIRED"
 	Error     Status     = "ERROR"
 	Reconcile ActionType = "reconcile"
 	Delete    ActionType = "delete"
@@ -58,6 +60,8 @@ type Schedule struct {
 	Payload               string                  `json:"payload"`
 	AppId                 string                  `json:"appId"`
 	ScheduleTime          int64                   `json:"scheduleTime,omitempty"`
+	Delay                 string                  `json:"delay,omitempty"`
+	ScheduleAt            string                  `json:"scheduleAt,omitempty"`
 	PartitionId           int                     `json:"partitionId"`
 	ScheduleGroup         int64                   `json:"scheduleGroup,omitempty"`
 	Callback              Callback                `json:"-"`
@@ -67,6 +71,24 @@ type Schedule struct {
 	ErrorMessage          string                  `json:"errorMessage,omitempty"`
 	ParentScheduleId      gocql.UUID              `json:"-"`
 	ReconciliationHistory []ReconciliationHistory `json:"reconciliationHistory,omitempty"`
+	RunNumber             int                     `json:"runNumber,omitempty"`
+	NominalTime           int64                   `json:"nominalTime,omitempty"`
+	TargetStatuses        map[string]TargetStatus `json:"targetStatuses,omitempty"`
+	OnSuccess             *ChainTemplate          `json:"onSuccess,omitempty"`
+	OnFailure             *ChainTemplate          `json:"onFailure,omitempty"`
+	PreviousScheduleId    string                  `json:"previousScheduleId,omitempty"`
+	NextScheduleId        string                  `json:"nextScheduleId,omitempty"`
+	Labels                map[string]string       `json:"labels,omitempty"`
+	DedupKey              string                  `json:"dedupKey,omitempty"`
+	ExpiresAfter          string                  `json:"expiresAfter,omitempty"`
+	Deadline              int64                   `json:"deadline,omitempty"`
+	Jitter                string                  `json:"jitter,omitempty"`
+	Calendar              string                  `json:"calendar,omitempty"`
+	CalendarPolicy        CalendarPolicy          `json:"calendarPolicy,omitempty"`
+	Priority              Priority                `json:"priority,omitempty"`
+	Deduplicated          bool                    `json:"deduplicated,omitempty"`
+	// The pending schedule deleted in favour of the created one holding the same dedup key, if any
+	Replaced *Schedule `json:"-"`
 	//Deprecated
 	Ttl int `json:"-"`
 	//Deprecated
@@ -175,6 +197,44 @@ func (s *Schedule) CreateScheduleFromCassandraMap(m map[string]interface{}) erro
 		s.Status = Status(status.(string))
 	}
 
+	if runNumber, ok := m["run_number"].(int); ok {
+		s.RunNumber = runNumber
+	}
+
+	if nominalTime, ok := m["nominal_time"].(time.Time); ok && !nominalTime.IsZero() {
+		s.NominalTime = nominalTime.Unix()
+	}
+
+	if chain, ok := m["chain"].(string); ok {
+		if err = s.SetChain(chain); err != nil {
+			return err
+		}
+	}
+
+	if deadline, ok := m["deadline"].(time.Time); ok && !deadline.IsZero() {
+		s.Deadline = deadline.Unix()
+	}
+
+	if expiresAfter, ok := m["expires_after"].(string); ok {
+		s.ExpiresAfter = expiresAfter
+	}
+
+	if jitter, ok := m["jitter"].(string); ok {
+		s.Jitter = jitter
+	}
+
+	if priority, ok := m["priority"].(string); ok {
+		s.Priority = Priority(priority)
+	}
+
+	if calendar, ok := m["calendar"].(string); ok {
+		s.Calendar = calendar
+	}
+
+	if calendarPolicy, ok := m["calendar_policy"].(string); ok {
+		s.CalendarPolicy = CalendarPolicy(calendarPolicy)
+	}
+
 	s.ScheduleId = m["schedule_id"].(gocql.UUID)
 	if m["parent_schedule_id"] != nil && !util.IsZeroUUID(m["parent_schedule_id"].(gocql.UUID)) {
 		s.ParentScheduleId = m["parent_schedule_id"].(gocql.UUID)
@@ -238,6 +298,12 @@ func (s Schedule) CloneAsOneTime(at time.Time) Schedule {
 	}
 	clone.Payload = s.Payload
 	clone.ParentScheduleId = s.ScheduleId
+	clone.Jitter = s.Jitter
+	clone.Priority = s.Priority
+
+	if expiresAfter, err := ParseDelay(s.ExpiresAfter); s.ExpiresAfter != "" && err == nil {
+		clone.Deadline = at.Add(expiresAfter).Unix()
+	}
 
 	return clone
 }
@@ -257,7 +323,7 @@ func (s Schedule) GetTTL(app App, bufferTTL int) int {
 	return int(s.ScheduleTime-time.Now().Unix()) + app.GetBufferTTL(bufferTTL)
 }
 
-// Set status, error_msg and reconciliation_history of the schedule from map
+// Set status, error_msg, next_schedule_id, target_status and reconciliation_history of the schedule from map
 func (s *Schedule) SetStatus(m map[string]interface{}) error {
 	if len(m) == 0 {
 		return nil
@@ -265,6 +331,17 @@ func (s *Schedule) SetStatus(m map[string]interface{}) error {
 	s.Status = Status(m["schedule_status"].(string))
 	s.ErrorMessage = m["error_msg"].(string)
 
+	if nextScheduleId, ok := m["next_schedule_id"].(string); ok {
+		s.NextScheduleId = nextScheduleId
+	}
+
+	if targetStatus, ok := m["target_status"].(string); ok && targetStatus != "" {
+		if err := json.Unmarshal([]byte(targetStatus), &s.TargetStatuses); err != nil {
+			glog.Infof("Error unmarshalling: %v", err)
+			return err
+		}
+	}
+
 	if m["reconciliation_history"].(string) == "" {
 		s.ReconciliationHistory = []ReconciliationHistory{}
 		return nil
@@ -327,21 +404,8 @@ func (s *Schedule) UnmarshalJSON(data []byte) error {
 
 	// Check if CallbackRaw is present and use specific logic
 	if len(s.CallbackRaw) > 0 {
-		var callbackData struct {
-			Type string `json:"type"`
-		}
-
-		if err := json.Unmarshal(s.CallbackRaw, &callbackData); err != nil {
-			return err
-		}
-
-		factoryFunc, ok := Registry[callbackData.Type]
-		if !ok {
-			return fmt.Errorf("unknown callback type: %s", callbackData.Type)
-		}
-
-		callback := factoryFunc()
-		if err := json.Unmarshal(s.CallbackRaw, callback); err != nil {
+		callback, err := parseCallback(s.CallbackRaw)
+		if err != nil {
 			return err
 		}
 
@@ -379,6 +443,34 @@ func (s *Schedule) ValidateSchedule(app App, conf conf.AppLevelConfiguration) []
 		errs = append(errs, errStr)
 	}
 
+	if errStr := validateAuthProfile(s.Callback, app); errStr != "" {
+		errs = append(errs, errStr)
+	}
+
+	if errStr := validateAllowedCallbackUrls(*s, app); errStr != "" {
+		errs = append(errs, errStr)
+	}
+
+	if errStr := validateTemplate(*s); errStr != "" {
+		errs = append(errs, errStr)
+	}
+
+	errs = append(errs, validateChain(*s)...)
+
+	errs = append(errs, validateLabels(s.Labels)...)
+
+	errs = append(errs, validateDedupKey(*s)...)
+
+	errs = append(errs, validateExpiry(*s)...)
+
+	errs = append(errs, validateJitter(*s)...)
+
+	errs = append(errs, validateCalendar(*s)...)
+
+	if err := s.Priority.Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
 	if s.IsRecurring() {
 		if er := validateCronExpression(s.CronExpression); len(er) > 0 {
 			errs = append(errs, er...)
@@ -394,6 +486,11 @@ func (s *Schedule) ValidateSchedule(app App, conf conf.AppLevelConfiguration) []
 
 func (s *Schedule) SetFields(app App) {
 	s.ScheduleId = gocql.TimeUUID()
+	s.SetPartition(app)
+}
+
+// SetPartition sets the partition of the schedule from its id and its schedule group from its schedule time
+func (s *Schedule) SetPartition(app App) {
 	s.PartitionId = int(uuidToPartition(s.ScheduleId, app.Partitions))
 	s.ScheduleGroup = 60 * (s.ScheduleTime / 60)
 }
@@ -462,3 +559,35 @@ func validateCallback(callback Callback) string {
 	}
 	return ""
 }
+
+// validateAllowedCallbackUrls checks the callback urls against the allowed callback hosts of the app.
+// Templated urls are checked as rendered at creation, the connector checks them again when they are fired.
+func validateAllowedCallbackUrls(s Schedule, app App) string {
+	for _, httpCallback := range httpCallbacks(s.Callback) {
+		callbackUrl := httpCallback.Details.Url
+		if httpCallback.Details.Template {
+			details, _, err := httpCallback.Render(s.Payload, NewTemplateData(s, time.Now()))
+			if err != nil {
+				continue
+			}
+			callbackUrl = details.Url
+		}
+
+		if !app.Configuration.AllowsCallbackUrl(callbackUrl) {
+			return fmt.Sprintf("callback url %s is not allowed for app: %s", callbackUrl, app.AppId)
+		}
+	}
+	return ""
+}
+
+func validateAuthProfile(callback Callback, app App) string {
+	for _, httpCallback := range httpCallbacks(callback) {
+		if httpCallback.Details.AuthProfile == "" {
+			continue
+		}
+		if _, ok := app.Configuration.GetAuthProfile(httpCallback.Details.AuthProfile); !ok {
+			return fmt.Sprintf("auth profile %s is not configured for app: %s", httpCallback.Details.AuthProfile, app.AppId)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// ISO 8601 durations with weeks, days, hours, minutes and seconds. Years and months are not supported as their length varies.
+var isoDurationRegex = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ResolveScheduleTime sets the schedule time from the delay or scheduleAt fields against the server clock.
+// Schedule times which are in the past by at most tolerance are moved to the next minute, so that they are fired by
+// the next poll instead of being rejected.
+func (s *Schedule) ResolveScheduleTime(now time.Time, tolerance time.Duration) error {
+	set := 0
+	for _, ok := range []bool{s.ScheduleTime != 0, s.Delay != "", s.ScheduleAt != ""} {
+		if ok {
+			set++
+		}
+	}
+
+	if set > 1 {
+		return errors.New("only one of scheduleTime, delay and scheduleAt can be provided")
+	}
+
+	if set == 1 && s.IsRecurring() {
+		return errors.New("scheduleTime, delay and scheduleAt are not supported for recurring schedules")
+	}
+
+	switch {
+	case s.Delay != "":
+		delay, err := ParseDelay(s.Delay)
+		if err != nil {
+			return err
+		}
+		s.ScheduleTime = now.Add(delay).Unix()
+	case s.ScheduleAt != "":
+		at, err := time.Parse(time.RFC3339, s.ScheduleAt)
+		if err != nil {
+			return fmt.Errorf("invalid scheduleAt %s, expected RFC3339 time with offset", s.ScheduleAt)
+		}
+		s.ScheduleTime = at.Unix()
+	}
+
+	if s.IsRecurring() || s.ScheduleTime == 0 {
+		return nil
+	}
+
+	if at := time.Unix(s.ScheduleTime, 0); at.Before(now) && now.Sub(at) <= tolerance {
+		s.ScheduleTime = now.Truncate(time.Minute).Add(time.Minute).Unix()
+	}
+
+	return nil
+}
+
+// ParseDelay parses a non negative delay given either as a go duration like "15m" or as an ISO 8601 duration like "PT2H"
+func ParseDelay(delay string) (time.Duration, error) {
+	duration, err := time.ParseDuration(delay)
+	if err != nil {
+		if duration, err = parseISODuration(delay); err != nil {
+			return 0, fmt.Errorf("invalid delay %s, expected a duration like 15m or PT15M", delay)
+		}
+	}
+
+	if duration < 0 {
+		return 0, fmt.Errorf("delay %s cannot be negative", delay)
+	}
+
+	return duration, nil
+}
+
+func parseISODuration(value string) (time.Duration, error) {
+	matches := isoDurationRegex.FindStringSubmatch(value)
+	if matches == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %s", value)
+	}
+
+	var duration time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute} {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(matches[i+1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		duration += time.Duration(n) * unit
+	}
+
+	if matches[5] != "" {
+		seconds, err := strconv.ParseFloat(matches[5], 64)
+		if err != nil {
+			return 0, err
+		}
+		duration += time.Duration(seconds * float64(time.Second))
+	}
+
+	return duration, nil
+}
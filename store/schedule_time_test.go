@@ -0,0 +1,89 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDelay(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"15m", 15 * time.Minute, false},
+		{"1h30m", 90 * time.Minute, false},
+		{"PT2H", 2 * time.Hour, false},
+		{"P1DT30M", 24*time.Hour + 30*time.Minute, false},
+		{"P2W", 14 * 24 * time.Hour, false},
+		{"PT1.5S", 1500 * time.Millisecond, false},
+		{"P1M", 0, true},
+		{"PT", 0, true},
+		{"-5m", 0, true},
+		{"tomorrow", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDelay(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDelay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseDelay() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveScheduleTime(t *testing.T) {
+	now := time.Date(2023, 6, 13, 10, 0, 30, 0, time.UTC)
+	nextMinute := time.Date(2023, 6, 13, 10, 1, 0, 0, time.UTC).Unix()
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		expected int64
+		wantErr  bool
+	}{
+		{"delay", Schedule{Delay: "PT15M"}, now.Add(15 * time.Minute).Unix(), false},
+		{"scheduleAt with offset", Schedule{ScheduleAt: "2023-06-13T16:00:30+05:30"}, now.Add(30 * time.Minute).Unix(), false},
+		{"scheduleAt without offset", Schedule{ScheduleAt: "2023-06-13T16:00:30"}, 0, true},
+		{"schedule time within tolerance", Schedule{ScheduleTime: now.Add(-10 * time.Second).Unix()}, nextMinute, false},
+		{"schedule time beyond tolerance", Schedule{ScheduleTime: now.Add(-time.Hour).Unix()}, now.Add(-time.Hour).Unix(), false},
+		{"future schedule time", Schedule{ScheduleTime: now.Add(time.Hour).Unix()}, now.Add(time.Hour).Unix(), false},
+		{"delay and schedule time", Schedule{Delay: "15m", ScheduleTime: now.Unix()}, 0, true},
+		{"delay for recurring schedule", Schedule{Delay: "15m", CronExpression: "* * * * *"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.ResolveScheduleTime(now, time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveScheduleTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.schedule.ScheduleTime != tt.expected {
+				t.Errorf("ResolveScheduleTime() schedule time = %d, expected %d", tt.schedule.ScheduleTime, tt.expected)
+			}
+		})
+	}
+}
@@ -29,8 +29,13 @@ type Task struct {
 
 var (
 	OldHttpTaskQueue chan ScheduleWrapper
-	HttpTaskQueue    chan ScheduleWrapper
-	AirbusTaskQueue  chan ScheduleWrapper
+	// HttpTaskQueue Channel sends normal priority schedules to the http connector
+	HttpTaskQueue chan ScheduleWrapper
+	// HighPriorityHttpTaskQueue Channel sends high priority schedules to the http connector
+	HighPriorityHttpTaskQueue chan ScheduleWrapper
+	// LowPriorityHttpTaskQueue Channel sends low priority schedules to the http connector
+	LowPriorityHttpTaskQueue chan ScheduleWrapper
+	AirbusTaskQueue          chan ScheduleWrapper
 	// CronTaskQueue Channel sends the tasks to convert a recurring schedule to one time schedules
 	CronTaskQueue chan CreateScheduleTask
 	// AggregationTaskQueue Channel aggregates the schedules and forward to status update
@@ -44,6 +49,8 @@ var (
 func (t *Task) InitTaskQueues() {
 	OldHttpTaskQueue = make(chan ScheduleWrapper)
 	HttpTaskQueue = make(chan ScheduleWrapper)
+	HighPriorityHttpTaskQueue = make(chan ScheduleWrapper)
+	LowPriorityHttpTaskQueue = make(chan ScheduleWrapper)
 	AirbusTaskQueue = make(chan ScheduleWrapper)
 	CronTaskQueue = make(chan CreateScheduleTask)
 	//making the channel buffered in order to regulate the flow in a better way
@@ -0,0 +1,121 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/myntra/goscheduler/util"
+)
+
+// TemplateData holds the variables available to templated http callbacks
+type TemplateData struct {
+	ScheduleId       string
+	ParentScheduleId string
+	AppId            string
+	ScheduleTime     time.Time
+	FireTime         time.Time
+	RunNumber        int
+}
+
+// NewTemplateData creates the template variables of a schedule fired at fireTime
+func NewTemplateData(s Schedule, fireTime time.Time) TemplateData {
+	data := TemplateData{
+		ScheduleId:   s.ScheduleId.String(),
+		AppId:        s.AppId,
+		ScheduleTime: time.Unix(s.ScheduleTime, 0),
+		FireTime:     fireTime,
+		RunNumber:    s.RunNumber,
+	}
+	if !util.IsZeroUUID(s.ParentScheduleId) {
+		data.ParentScheduleId = s.ParentScheduleId.String()
+	}
+	return data
+}
+
+// Render renders the url, headers and payload of the callback with the given template variables.
+// Callbacks which haven't opted in for templating are returned as is.
+func (h *HttpCallback) Render(payload string, data TemplateData) (Details, string, error) {
+	if !h.Details.Template {
+		return h.Details, payload, nil
+	}
+
+	var err error
+	details := h.Details
+
+	if details.Url, err = renderTemplate("url", h.Details.Url, data); err != nil {
+		return Details{}, "", err
+	}
+
+	details.Headers = make(map[string]string, len(h.Details.Headers))
+	for header, value := range h.Details.Headers {
+		if details.Headers[header], err = renderTemplate(header, value, data); err != nil {
+			return Details{}, "", err
+		}
+	}
+
+	if payload, err = renderTemplate("payload", payload, data); err != nil {
+		return Details{}, "", err
+	}
+
+	return details, payload, nil
+}
+
+func renderTemplate(name string, text string, data TemplateData) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func validateTemplate(s Schedule) string {
+	for _, httpCallback := range httpCallbacks(s.Callback) {
+		if !httpCallback.Details.Template {
+			continue
+		}
+
+		details, _, err := httpCallback.Render(s.Payload, NewTemplateData(s, time.Now()))
+		if err != nil {
+			return fmt.Sprintf("invalid callback template: %s", err.Error())
+		}
+
+		u, err := url.ParseRequestURI(details.Url)
+		if err != nil {
+			return fmt.Sprintf("callback template renders an invalid url: %s", details.Url)
+		}
+
+		if err = validateCallbackUrl(u); err != nil {
+			return err.Error()
+		}
+	}
+
+	return ""
+}
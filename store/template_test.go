@@ -0,0 +1,102 @@
+// Copyright (c) 2023 Myntra Designs Private Limited.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+func TestHttpCallbackRender(t *testing.T) {
+	scheduleId := gocql.TimeUUID()
+	parentId := gocql.TimeUUID()
+	fireTime := time.Unix(1686676960, 0)
+
+	schedule := Schedule{
+		ScheduleId:       scheduleId,
+		ParentScheduleId: parentId,
+		AppId:            "test",
+		ScheduleTime:     1686676947,
+		RunNumber:        3,
+		Payload:          `{"run": {{.RunNumber}}, "at": {{.ScheduleTime.Unix}}, "firedAt": {{.FireTime.Unix}}}`,
+	}
+
+	callback := &HttpCallback{
+		Type: "http",
+		Details: Details{
+			Url:      "http://example.com/runs/{{.ScheduleId}}",
+			Method:   "POST",
+			Headers:  map[string]string{"X-Parent": "{{.ParentScheduleId}}"},
+			Template: true,
+		},
+	}
+
+	details, payload, err := callback.Render(schedule.Payload, NewTemplateData(schedule, fireTime))
+	if err != nil {
+		t.Fatalf("Render() failed with error: %s", err.Error())
+	}
+
+	if details.Url != "http://example.com/runs/"+scheduleId.String() {
+		t.Errorf("Render() url = %s", details.Url)
+	}
+	if details.Headers["X-Parent"] != parentId.String() {
+		t.Errorf("Render() header = %s", details.Headers["X-Parent"])
+	}
+	if payload != `{"run": 3, "at": 1686676947, "firedAt": 1686676960}` {
+		t.Errorf("Render() payload = %s", payload)
+	}
+	if callback.Details.Url != "http://example.com/runs/{{.ScheduleId}}" {
+		t.Errorf("Render() must not modify the callback")
+	}
+
+	callback.Details.Template = false
+	if _, payload, _ = callback.Render(schedule.Payload, NewTemplateData(schedule, fireTime)); payload != schedule.Payload {
+		t.Errorf("Render() must not render callbacks without template enabled")
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		payload string
+		wantErr bool
+	}{
+		{"valid template", "http://example.com/{{.AppId}}", `{"run": {{.RunNumber}}}`, false},
+		{"unknown variable", "http://example.com", `{"run": {{.Attempt}}}`, true},
+		{"malformed template", "http://example.com", `{"run": {{.RunNumber}`, true},
+		{"invalid rendered url", "{{.AppId}}", "{}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := Schedule{
+				AppId:    "test",
+				Payload:  tt.payload,
+				Callback: &HttpCallback{Type: "http", Details: Details{Url: tt.url, Method: "POST", Template: true}},
+			}
+			if errStr := validateTemplate(schedule); (errStr != "") != tt.wantErr {
+				t.Errorf("validateTemplate() error = %s, wantErr %v", errStr, tt.wantErr)
+			}
+		})
+	}
+}